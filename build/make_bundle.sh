@@ -6,6 +6,7 @@ set -e
 
 VERSION=`grep "Version.*=.*\".*\"" version/version.go | sed "s,.*Version.*=.*\"\(.*\)\".*,\1,"`
 OLD_VERSIONS="v1beta1 v1alpha3 v1alpha2"
+OLD_VERSION_CRDS="clustermasters indexerclusters licensemasters searchheadclusters standalones"
 DOCKER_IO_PATH="docker.io/splunk"
 REDHAT_REGISTRY_PATH="registry.connect.redhat.com/splunk"
 OPERATOR_IMAGE="$DOCKER_IO_PATH/splunk-operator:${VERSION}"
@@ -26,15 +27,22 @@ for v in $OLD_VERSIONS; do
     schema:
       openAPIV3Schema:
         type: object
-        properties:
-          apiVersion:
-            type: string
+        x-kubernetes-preserve-unknown-fields: true
 EOF
 done
 
-# append older versions to CRD files
-for crd in deploy/crds/*_crd.yaml; do
-  yq w -i -s $YAML_SCRIPT_FILE $crd
+# older versions are only converted by the conversion webhook of the operator once webhooks are enabled with a
+# certificate the API server trusts, by applying deploy/conversion_webhook_patch.yaml
+cat << EOF >>$YAML_SCRIPT_FILE
+- command: update
+  path: spec.conversion
+  value:
+    strategy: None
+EOF
+
+# append older versions to the CRD files of the custom resources that existed before v1
+for crd in $OLD_VERSION_CRDS; do
+  yq w -i -s $YAML_SCRIPT_FILE deploy/crds/enterprise.splunk.com_${crd}_crd.yaml
 done
 
 RESOURCES="
@@ -47,5 +47,7 @@ yq w deploy/role_binding.yaml metadata.namespace splunk-operator | yq w - roleRe
 cat deploy/cluster_role.yaml deploy/cluster_role_binding.yaml >> release-${VERSION}/splunk-operator-cluster.yaml
 echo "---" >> release-${VERSION}/splunk-operator-cluster.yaml
 yq w deploy/operator.yaml metadata.namespace splunk-operator | yq w - "spec.template.spec.containers[0].image" $IMAGE | yq w - "spec.template.spec.containers[0].env[0].value" "" | yq d - "spec.template.spec.containers[0].env[0].valueFrom" >> release-${VERSION}/splunk-operator-cluster.yaml
+echo "---" >> release-${VERSION}/splunk-operator-cluster.yaml
+yq w deploy/webhook_service.yaml metadata.namespace splunk-operator >> release-${VERSION}/splunk-operator-cluster.yaml
 
 ls -la release-${VERSION}/
@@ -42,15 +42,25 @@ import (
 
 	"github.com/splunk/splunk-operator/pkg/apis"
 	"github.com/splunk/splunk-operator/pkg/controller"
+	"github.com/splunk/splunk-operator/pkg/webhook"
 	"github.com/splunk/splunk-operator/version"
 )
 
 // Change below variables to serve metrics on different host or port.
+// The metrics of the splunk.metrics package are served with the controller-runtime metrics on metricsPort,
+// and the kube-state custom resource metrics on operatorMetricsPort.
 var (
 	metricsHost               = "0.0.0.0"
 	metricsPort         int32 = 8383
 	operatorMetricsPort int32 = 8686
 )
+
+// Change below variables to serve webhooks on a different port, or to read their certificate from a different directory.
+var (
+	webhookPort    = 9443
+	webhookCertDir = "/tmp/k8s-webhook-server/serving-certs"
+)
+
 var log = logf.Log.WithName("cmd")
 
 func printVersion() {
@@ -112,6 +122,8 @@ func main() {
 	mgr, err := manager.New(cfg, manager.Options{
 		Namespace:          namespace,
 		MetricsBindAddress: fmt.Sprintf("%s:%d", metricsHost, metricsPort),
+		Port:               webhookPort,
+		CertDir:            webhookCertDir,
 	})
 	if err != nil {
 		log.Error(err, "")
@@ -132,6 +144,14 @@ func main() {
 		os.Exit(1)
 	}
 
+	// Setup all Webhooks, when a certificate has been provided for them
+	if os.Getenv("ENABLE_WEBHOOKS") == "true" {
+		if err := webhook.AddToManager(mgr); err != nil {
+			log.Error(err, "")
+			os.Exit(1)
+		}
+	}
+
 	// Add the Metrics Service
 	addMetrics(ctx, cfg)
 
@@ -9,8 +9,10 @@ require (
 	github.com/onsi/ginkgo v1.12.0
 	github.com/onsi/gomega v1.9.0
 	github.com/operator-framework/operator-sdk v0.18.2
+	github.com/prometheus/client_golang v1.5.1
 	github.com/spf13/pflag v1.0.5
 	k8s.io/api v0.18.17
+	k8s.io/apiextensions-apiserver v0.18.2
 	k8s.io/apimachinery v0.18.17
 	k8s.io/client-go v12.0.0+incompatible
 	k8s.io/kubectl v0.18.17
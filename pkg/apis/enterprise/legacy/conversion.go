@@ -0,0 +1,207 @@
+// Copyright (c) 2018-2021 Splunk Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package legacy converts the custom resources of the API versions that preceded v1 to and from v1.
+//
+// The spec and status fields of a legacy version are mapped to the v1 fields with the same JSON name,
+// except for the storage settings that v1 split between the etc and var volumes. The fields that the
+// version converted to can not represent are preserved in the ConversionDataAnnotation, so that objects
+// survive a round trip through any version.
+package legacy
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	enterprisev1 "github.com/splunk/splunk-operator/pkg/apis/enterprise/v1"
+)
+
+// Object points to the metadata, spec and status of a custom resource
+type Object struct {
+	Meta   *metav1.ObjectMeta
+	Spec   interface{}
+	Status interface{}
+}
+
+// conversionData holds the spec and status fields preserved in the ConversionDataAnnotation
+type conversionData struct {
+	Spec   interface{} `json:"spec,omitempty"`
+	Status interface{} `json:"status,omitempty"`
+}
+
+// storageSpec holds the storage settings of the legacy versions, which v1 split between the etc and var volumes
+type storageSpec struct {
+	StorageClassName string `json:"storageClassName"`
+	EtcStorage       string `json:"etcStorage"`
+	VarStorage       string `json:"varStorage"`
+}
+
+// ConvertTo converts src, of a legacy version, to dst, of v1. common points to the CommonSplunkSpec of dst.
+// The v1 spec and status preserved in the annotations of src are restored first.
+func ConvertTo(src, dst Object, common *enterprisev1.CommonSplunkSpec) error {
+	src.Meta.DeepCopyInto(dst.Meta)
+	if err := getConversionData(dst.Meta, &conversionData{Spec: dst.Spec, Status: dst.Status}); err != nil {
+		return err
+	}
+	etcStorageClassName := common.EtcVolumeStorageConfig.StorageClassName
+	varStorageClassName := common.VarVolumeStorageConfig.StorageClassName
+
+	if err := copyFields(src.Spec, dst.Spec, src.Spec); err != nil {
+		return err
+	}
+	if err := copyFields(src.Status, dst.Status, src.Status); err != nil {
+		return err
+	}
+
+	storage := storageSpec{}
+	if err := copyFields(src.Spec, &storage, &storage); err != nil {
+		return err
+	}
+	// v1 supports a different storage class for the etc and var volumes, which is kept until the storage class is changed
+	common.VarVolumeStorageConfig.StorageClassName = varStorageClassName
+	if varStorageClassName == "" || etcStorageClassName != storage.StorageClassName {
+		common.VarVolumeStorageConfig.StorageClassName = storage.StorageClassName
+	}
+	common.EtcVolumeStorageConfig.StorageClassName = storage.StorageClassName
+	common.EtcVolumeStorageConfig.StorageCapacity = storage.EtcStorage
+	common.VarVolumeStorageConfig.StorageCapacity = storage.VarStorage
+	return nil
+}
+
+// ConvertFrom converts src, of v1, to dst, of a legacy version. common points to the CommonSplunkSpec of src.
+// The fields of the legacy version preserved in the annotations of src are loaded into legacySpec, and
+// the v1 spec and status are preserved in the annotations of dst.
+func ConvertFrom(src, dst Object, common *enterprisev1.CommonSplunkSpec, legacySpec interface{}) error {
+	src.Meta.DeepCopyInto(dst.Meta)
+	if err := getConversionData(dst.Meta, &conversionData{Spec: legacySpec}); err != nil {
+		return err
+	}
+
+	if err := copyFields(src.Spec, dst.Spec, dst.Spec); err != nil {
+		return err
+	}
+	if err := copyFields(src.Status, dst.Status, dst.Status); err != nil {
+		return err
+	}
+
+	storage := storageSpec{
+		StorageClassName: common.EtcVolumeStorageConfig.StorageClassName,
+		EtcStorage:       common.EtcVolumeStorageConfig.StorageCapacity,
+		VarStorage:       common.VarVolumeStorageConfig.StorageCapacity,
+	}
+	if err := copyFields(&storage, dst.Spec, &storage); err != nil {
+		return err
+	}
+
+	return setConversionData(dst.Meta, &conversionData{Spec: src.Spec, Status: src.Status})
+}
+
+// SetLegacySpec preserves spec, which holds the fields of a legacy version that were removed in v1, in the annotations of obj
+func SetLegacySpec(obj metav1.Object, spec interface{}) error {
+	return setConversionData(obj, &conversionData{Spec: spec})
+}
+
+// copyFields copies the JSON fields of the struct type that fields points to from src to dst, both pointers
+// to structs. Fields that are missing in src are reset in dst.
+func copyFields(src, dst, fields interface{}) error {
+	srcFields, err := toFields(src)
+	if err != nil {
+		return err
+	}
+	dstFields, err := toFields(dst)
+	if err != nil {
+		return err
+	}
+	for _, name := range jsonFieldNames(reflect.TypeOf(fields).Elem()) {
+		if value, ok := srcFields[name]; ok {
+			dstFields[name] = value
+		} else {
+			delete(dstFields, name)
+		}
+	}
+
+	raw, err := json.Marshal(dstFields)
+	if err != nil {
+		return err
+	}
+	value := reflect.ValueOf(dst).Elem()
+	value.Set(reflect.Zero(value.Type()))
+	return json.Unmarshal(raw, dst)
+}
+
+// toFields returns the JSON fields of obj
+func toFields(obj interface{}) (map[string]json.RawMessage, error) {
+	raw, err := json.Marshal(obj)
+	if err != nil {
+		return nil, err
+	}
+	fields := make(map[string]json.RawMessage)
+	return fields, json.Unmarshal(raw, &fields)
+}
+
+// jsonFieldNames returns the names of the JSON fields of a struct type, including the fields of inlined structs
+func jsonFieldNames(t reflect.Type) []string {
+	names := []string{}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		name := strings.Split(tag, ",")[0]
+		if field.Anonymous && name == "" && field.Type.Kind() == reflect.Struct {
+			names = append(names, jsonFieldNames(field.Type)...)
+			continue
+		}
+		if field.PkgPath != "" || name == "-" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		names = append(names, name)
+	}
+	return names
+}
+
+// setConversionData stores data in the ConversionDataAnnotation of obj
+func setConversionData(obj metav1.Object, data *conversionData) error {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	annotations := obj.GetAnnotations()
+	if annotations == nil {
+		annotations = make(map[string]string)
+	}
+	annotations[enterprisev1.ConversionDataAnnotation] = string(raw)
+	obj.SetAnnotations(annotations)
+	return nil
+}
+
+// getConversionData loads the ConversionDataAnnotation of obj, if any, into data, which should hold pointers
+// for the spec and status to restore, and removes the annotation from obj
+func getConversionData(obj metav1.Object, data *conversionData) error {
+	annotations := obj.GetAnnotations()
+	raw, ok := annotations[enterprisev1.ConversionDataAnnotation]
+	if !ok {
+		return nil
+	}
+	delete(annotations, enterprisev1.ConversionDataAnnotation)
+	if len(annotations) == 0 {
+		annotations = nil
+	}
+	obj.SetAnnotations(annotations)
+	return json.Unmarshal([]byte(raw), data)
+}
@@ -0,0 +1,175 @@
+// Copyright (c) 2018-2021 Splunk Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package legacy
+
+import (
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	enterprisev1 "github.com/splunk/splunk-operator/pkg/apis/enterprise/v1"
+	splcommon "github.com/splunk/splunk-operator/pkg/splunk/common"
+)
+
+// testCommonSpec and testSpec mimic the spec of a legacy version
+type testCommonSpec struct {
+	splcommon.Spec   `json:",inline"`
+	StorageClassName string `json:"storageClassName"`
+	EtcStorage       string `json:"etcStorage"`
+	VarStorage       string `json:"varStorage"`
+	Defaults         string `json:"defaults"`
+}
+
+// testLegacySpec holds the fields of the legacy version that were removed in v1
+type testLegacySpec struct {
+	SparkImage string `json:"sparkImage,omitempty"`
+}
+
+type testSpec struct {
+	testCommonSpec `json:",inline"`
+	Replicas       int32 `json:"replicas"`
+}
+
+type testStatus struct {
+	Phase    splcommon.Phase `json:"phase"`
+	Replicas int32           `json:"replicas"`
+}
+
+func newTestHub() enterprisev1.Standalone {
+	return enterprisev1.Standalone{
+		ObjectMeta: metav1.ObjectMeta{Name: "stack1", Namespace: "test"},
+		Spec: enterprisev1.StandaloneSpec{
+			CommonSplunkSpec: enterprisev1.CommonSplunkSpec{
+				Spec:                   splcommon.Spec{Image: "splunk/splunk:8.2.0"},
+				EtcVolumeStorageConfig: enterprisev1.StorageClassSpec{StorageClassName: "gp2", StorageCapacity: "15Gi"},
+				VarVolumeStorageConfig: enterprisev1.StorageClassSpec{StorageClassName: "io1", StorageCapacity: "100Gi"},
+				Defaults:               "splunk:\n  hec_disabled: 0",
+				MonitoringConsoleRef:   corev1.ObjectReference{Name: "mc1"},
+			},
+			Replicas: 1,
+		},
+		Status: enterprisev1.StandaloneStatus{
+			Phase:          splcommon.PhaseReady,
+			Replicas:       1,
+			ResourceRevMap: map[string]string{"s3-secret": "1234"},
+		},
+	}
+}
+
+func convertTo(t *testing.T, spoke *metav1.ObjectMeta, spec *testSpec, status *testStatus, hub *enterprisev1.Standalone) {
+	err := ConvertTo(Object{Meta: spoke, Spec: spec, Status: status}, Object{Meta: &hub.ObjectMeta, Spec: &hub.Spec, Status: &hub.Status}, &hub.Spec.CommonSplunkSpec)
+	if err != nil {
+		t.Fatalf("ConvertTo() returned error: %v", err)
+	}
+}
+
+func convertFrom(t *testing.T, hub *enterprisev1.Standalone, spoke *metav1.ObjectMeta, spec *testSpec, status *testStatus, legacySpec interface{}) {
+	err := ConvertFrom(Object{Meta: &hub.ObjectMeta, Spec: &hub.Spec, Status: &hub.Status}, Object{Meta: spoke, Spec: spec, Status: status}, &hub.Spec.CommonSplunkSpec, legacySpec)
+	if err != nil {
+		t.Fatalf("ConvertFrom() returned error: %v", err)
+	}
+}
+
+func TestConvertTo(t *testing.T) {
+	meta := metav1.ObjectMeta{Name: "stack1", Namespace: "test"}
+	spec := testSpec{testCommonSpec: testCommonSpec{Spec: splcommon.Spec{Image: "splunk/splunk:8.2.1"}, StorageClassName: "gp2", EtcStorage: "10Gi", VarStorage: "50Gi"}, Replicas: 3}
+	status := testStatus{Phase: splcommon.PhaseScalingUp, Replicas: 2}
+	hub := enterprisev1.Standalone{}
+	convertTo(t, &meta, &spec, &status, &hub)
+
+	want := enterprisev1.Standalone{
+		ObjectMeta: meta,
+		Spec: enterprisev1.StandaloneSpec{
+			CommonSplunkSpec: enterprisev1.CommonSplunkSpec{
+				Spec:                   splcommon.Spec{Image: "splunk/splunk:8.2.1"},
+				EtcVolumeStorageConfig: enterprisev1.StorageClassSpec{StorageClassName: "gp2", StorageCapacity: "10Gi"},
+				VarVolumeStorageConfig: enterprisev1.StorageClassSpec{StorageClassName: "gp2", StorageCapacity: "50Gi"},
+			},
+			Replicas: 3,
+		},
+		Status: enterprisev1.StandaloneStatus{Phase: splcommon.PhaseScalingUp, Replicas: 2},
+	}
+	if !reflect.DeepEqual(hub, want) {
+		t.Errorf("ConvertTo() = %+v; want %+v", hub, want)
+	}
+
+	// the v1 fields preserved by ConvertFrom are restored, and the var storage class is kept until the storage class is changed
+	hub = newTestHub()
+	convertFrom(t, &hub, &meta, &spec, &status, &struct{}{})
+	spec.Replicas = 3
+	spec.Defaults = ""
+	restored := enterprisev1.Standalone{}
+	convertTo(t, &meta, &spec, &status, &restored)
+	want = newTestHub()
+	want.Spec.Replicas = 3
+	want.Spec.Defaults = ""
+	if !reflect.DeepEqual(restored, want) {
+		t.Errorf("ConvertTo() = %+v; want %+v", restored, want)
+	}
+
+	hub = newTestHub()
+	convertFrom(t, &hub, &meta, &spec, &status, &struct{}{})
+	spec.StorageClassName = "standard"
+	restored = enterprisev1.Standalone{}
+	convertTo(t, &meta, &spec, &status, &restored)
+	if restored.Spec.EtcVolumeStorageConfig.StorageClassName != "standard" || restored.Spec.VarVolumeStorageConfig.StorageClassName != "standard" {
+		t.Errorf("ConvertTo() storage classes = %s, %s; want standard, standard", restored.Spec.EtcVolumeStorageConfig.StorageClassName, restored.Spec.VarVolumeStorageConfig.StorageClassName)
+	}
+}
+
+func TestConvertFrom(t *testing.T) {
+	hub := newTestHub()
+	if err := SetLegacySpec(&hub, &testLegacySpec{SparkImage: "splunk/spark"}); err != nil {
+		t.Fatalf("SetLegacySpec() returned error: %v", err)
+	}
+	want := `{"spec":{"sparkImage":"splunk/spark"}}`
+	if got := hub.GetAnnotations()[enterprisev1.ConversionDataAnnotation]; got != want {
+		t.Errorf("SetLegacySpec() annotation = %s; want %s", got, want)
+	}
+
+	meta := metav1.ObjectMeta{}
+	spec := testSpec{Replicas: 5}
+	status := testStatus{}
+	legacySpec := testLegacySpec{}
+	convertFrom(t, &hub, &meta, &spec, &status, &legacySpec)
+
+	if legacySpec.SparkImage != "splunk/spark" {
+		t.Errorf("ConvertFrom() legacy sparkImage = %s; want splunk/spark", legacySpec.SparkImage)
+	}
+	wantSpec := testSpec{
+		testCommonSpec: testCommonSpec{Spec: splcommon.Spec{Image: "splunk/splunk:8.2.0"}, StorageClassName: "gp2", EtcStorage: "15Gi", VarStorage: "100Gi", Defaults: "splunk:\n  hec_disabled: 0"},
+		Replicas:       1,
+	}
+	if !reflect.DeepEqual(spec, wantSpec) {
+		t.Errorf("ConvertFrom() spec = %+v; want %+v", spec, wantSpec)
+	}
+	if wantStatus := (testStatus{Phase: splcommon.PhaseReady, Replicas: 1}); status != wantStatus {
+		t.Errorf("ConvertFrom() status = %+v; want %+v", status, wantStatus)
+	}
+
+	// the annotation of the v1 object is replaced by the v1 spec and status
+	preserved := enterprisev1.Standalone{}
+	if err := getConversionData(&meta, &conversionData{Spec: &preserved.Spec, Status: &preserved.Status}); err != nil {
+		t.Fatalf("getConversionData() returned error: %v", err)
+	}
+	if want := newTestHub(); !reflect.DeepEqual(preserved.Spec, want.Spec) || !reflect.DeepEqual(preserved.Status, want.Status) {
+		t.Errorf("ConvertFrom() preserved %+v, %+v; want %+v, %+v", preserved.Spec, preserved.Status, want.Spec, want.Status)
+	}
+	if meta.Annotations != nil {
+		t.Errorf("getConversionData() left annotations %v", meta.Annotations)
+	}
+}
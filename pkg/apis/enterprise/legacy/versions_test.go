@@ -0,0 +1,380 @@
+// Copyright (c) 2018-2021 Splunk Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package legacy_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/conversion"
+
+	enterprisev1 "github.com/splunk/splunk-operator/pkg/apis/enterprise/v1"
+	"github.com/splunk/splunk-operator/pkg/apis/enterprise/v1alpha2"
+	"github.com/splunk/splunk-operator/pkg/apis/enterprise/v1alpha3"
+	"github.com/splunk/splunk-operator/pkg/apis/enterprise/v1beta1"
+	splcommon "github.com/splunk/splunk-operator/pkg/splunk/common"
+)
+
+func newTestObjectMeta() metav1.ObjectMeta {
+	return metav1.ObjectMeta{
+		Name:      "stack1",
+		Namespace: "test",
+		Labels:    map[string]string{"app": "splunk"},
+	}
+}
+
+func newTestSpec() splcommon.Spec {
+	return splcommon.Spec{
+		Image:           "splunk/splunk:8.2.0",
+		ImagePullPolicy: "Always",
+		SchedulerName:   "custom-scheduler",
+		Tolerations: []corev1.Toleration{
+			{Key: "dedicated", Operator: corev1.TolerationOpEqual, Value: "splunk", Effect: corev1.TaintEffectNoSchedule},
+		},
+		Resources: corev1.ResourceRequirements{
+			Requests: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("4"),
+				corev1.ResourceMemory: resource.MustParse("8Gi"),
+			},
+		},
+	}
+}
+
+// newTestHubSpec returns a v1 CommonSplunkSpec that uses fields which can not be represented by the legacy versions
+func newTestHubSpec() enterprisev1.CommonSplunkSpec {
+	return enterprisev1.CommonSplunkSpec{
+		Spec: newTestSpec(),
+		EtcVolumeStorageConfig: enterprisev1.StorageClassSpec{
+			StorageClassName: "gp2",
+			StorageCapacity:  "15Gi",
+		},
+		VarVolumeStorageConfig: enterprisev1.StorageClassSpec{
+			StorageClassName: "io1",
+			StorageCapacity:  "100Gi",
+		},
+		Volumes: []corev1.Volume{
+			{Name: "licenses", VolumeSource: corev1.VolumeSource{ConfigMap: &corev1.ConfigMapVolumeSource{LocalObjectReference: corev1.LocalObjectReference{Name: "splunk-licenses"}}}},
+		},
+		Defaults:             "splunk:\n  hec_disabled: 0",
+		DefaultsURL:          "/mnt/defaults/default.yml",
+		DefaultsURLApps:      "/mnt/defaults/apps.yml",
+		LicenseURL:           "/mnt/licenses/enterprise.lic",
+		LicenseMasterRef:     corev1.ObjectReference{Name: "stack1"},
+		ClusterMasterRef:     corev1.ObjectReference{Name: "stack1"},
+		MonitoringConsoleRef: corev1.ObjectReference{Name: "mc1", Namespace: "monitoring"},
+		ServiceAccount:       "splunk",
+		ExtraEnv:             []corev1.EnvVar{{Name: "SPLUNK_DEBUG", Value: "true"}},
+		AppFrameworkConfig: enterprisev1.AppFrameworkSpec{
+			VolList:    []enterprisev1.VolumeSpec{{Name: "apps", Endpoint: "https://s3.us-west-2.amazonaws.com", Path: "splunk-apps", SecretRef: "s3-secret"}},
+			AppSources: []enterprisev1.AppSourceSpec{{Name: "security", Location: "security/", AppSourceDefaultSpec: enterprisev1.AppSourceDefaultSpec{VolName: "apps", Scope: "local"}}},
+		},
+	}
+}
+
+// newTestCommonSplunkSpec returns a v1beta1 CommonSplunkSpec that uses all of its fields, which include the fields
+// of the CommonSplunkSpec of the older legacy versions, except for those listed in legacyVersions
+func newTestCommonSplunkSpec() v1beta1.CommonSplunkSpec {
+	return v1beta1.CommonSplunkSpec{
+		Spec:             newTestSpec(),
+		StorageClassName: "gp2",
+		EtcStorage:       "15Gi",
+		VarStorage:       "100Gi",
+		Volumes: []corev1.Volume{
+			{Name: "licenses", VolumeSource: corev1.VolumeSource{ConfigMap: &corev1.ConfigMapVolumeSource{LocalObjectReference: corev1.LocalObjectReference{Name: "splunk-licenses"}}}},
+		},
+		Defaults:         "splunk:\n  hec_disabled: 0",
+		DefaultsURL:      "/mnt/defaults/default.yml",
+		LicenseURL:       "/mnt/licenses/enterprise.lic",
+		LicenseMasterRef: corev1.ObjectReference{Name: "stack1"},
+		ClusterMasterRef: corev1.ObjectReference{Name: "stack1"},
+		ServiceAccount:   "splunk",
+		SparkRef:         corev1.ObjectReference{Name: "spark1"},
+	}
+}
+
+var testSmartStore = enterprisev1.SmartStoreSpec{
+	VolList:   []enterprisev1.VolumeSpec{{Name: "s3", Endpoint: "https://s3.us-west-2.amazonaws.com", Path: "splunk-indexes", SecretRef: "s3-secret"}},
+	IndexList: []enterprisev1.IndexSpec{{Name: "main", RemotePath: "main/", IndexAndGlobalCommonSpec: enterprisev1.IndexAndGlobalCommonSpec{VolName: "s3"}}},
+}
+
+var testAppContext = enterprisev1.AppDeploymentContext{
+	LastAppInfoCheckTime: 1600000000,
+	Apps:                 []enterprisev1.AppDeploymentInfo{{AppSource: "security", Name: "security/app1.tgz", Checksum: "abc", Phase: enterprisev1.AppPhaseInstalled}},
+}
+
+var testMembers = []enterprisev1.SearchHeadClusterMemberStatus{
+	{Name: "splunk-stack1-search-head-0", Status: "Up", Registered: true, ActiveHistoricalSearchCount: 2},
+}
+
+var testPeers = []enterprisev1.IndexerClusterMemberStatus{
+	{ID: "1234", Name: "splunk-stack1-indexer-0", Status: "Up", BucketCount: 10, Searchable: true},
+}
+
+// testKinds lists the custom resources of the legacy versions
+var testKinds = []struct {
+	name string
+	// hub uses fields that can not be represented by the legacy versions
+	hub    conversion.Hub
+	newHub func() conversion.Hub
+	// spoke uses all of the fields of v1beta1, and is converted to the other legacy versions through its JSON
+	spoke conversion.Convertible
+	// annotation is the annotation of the hub converted from spoke, which preserves the fields removed in v1
+	annotation string
+}{
+	{
+		name: "Standalone",
+		hub: &enterprisev1.Standalone{
+			ObjectMeta: newTestObjectMeta(),
+			Spec:       enterprisev1.StandaloneSpec{CommonSplunkSpec: newTestHubSpec(), Replicas: 3, SmartStore: testSmartStore},
+			Status: enterprisev1.StandaloneStatus{
+				Phase:          splcommon.PhaseReady,
+				Replicas:       3,
+				ReadyReplicas:  3,
+				Selector:       "app.kubernetes.io/instance=splunk-stack1-standalone",
+				SmartStore:     testSmartStore,
+				ResourceRevMap: map[string]string{"s3-secret": "1234"},
+				AppContext:     testAppContext,
+			},
+		},
+		newHub: func() conversion.Hub { return &enterprisev1.Standalone{} },
+		spoke: &v1beta1.Standalone{
+			ObjectMeta: newTestObjectMeta(),
+			Spec:       v1beta1.StandaloneSpec{CommonSplunkSpec: newTestCommonSplunkSpec(), Replicas: 3, SmartStore: testSmartStore, SparkImage: "splunk/spark"},
+			Status: v1beta1.StandaloneStatus{
+				Phase:         splcommon.PhaseReady,
+				Replicas:      3,
+				ReadyReplicas: 3,
+				Selector:      "app.kubernetes.io/instance=splunk-stack1-standalone",
+			},
+		},
+		annotation: `{"spec":{"sparkRef":{"name":"spark1"},"sparkImage":"splunk/spark"}}`,
+	},
+	{
+		name: "SearchHeadCluster",
+		hub: &enterprisev1.SearchHeadCluster{
+			ObjectMeta: newTestObjectMeta(),
+			Spec:       enterprisev1.SearchHeadClusterSpec{CommonSplunkSpec: newTestHubSpec(), Replicas: 3},
+			Status: enterprisev1.SearchHeadClusterStatus{
+				Phase:                          splcommon.PhaseReady,
+				DeployerPhase:                  splcommon.PhaseReady,
+				Replicas:                       3,
+				ReadyReplicas:                  3,
+				Captain:                        "splunk-stack1-search-head-0",
+				CaptainReady:                   true,
+				Initialized:                    true,
+				MinPeersJoined:                 true,
+				ShcSecretChanged:               []bool{true},
+				AdminPasswordChangedSecrets:    map[string]bool{"splunk-stack1-search-head-secret-v1": true},
+				NamespaceSecretResourceVersion: "1234",
+				Members:                        testMembers,
+				AppContext:                     testAppContext,
+			},
+		},
+		newHub: func() conversion.Hub { return &enterprisev1.SearchHeadCluster{} },
+		spoke: &v1beta1.SearchHeadCluster{
+			ObjectMeta: newTestObjectMeta(),
+			Spec:       v1beta1.SearchHeadClusterSpec{CommonSplunkSpec: newTestCommonSplunkSpec(), Replicas: 3},
+			Status: v1beta1.SearchHeadClusterStatus{
+				Phase:           splcommon.PhaseScalingUp,
+				DeployerPhase:   splcommon.PhaseReady,
+				Replicas:        3,
+				ReadyReplicas:   2,
+				Captain:         "splunk-stack1-search-head-0",
+				CaptainReady:    true,
+				MaintenanceMode: true,
+				Members:         testMembers,
+			},
+		},
+		annotation: `{"spec":{"sparkRef":{"name":"spark1"}}}`,
+	},
+	{
+		name: "IndexerCluster",
+		hub: &enterprisev1.IndexerCluster{
+			ObjectMeta: newTestObjectMeta(),
+			Spec:       enterprisev1.IndexerClusterSpec{CommonSplunkSpec: newTestHubSpec(), Replicas: 3},
+			Status: enterprisev1.IndexerClusterStatus{
+				Phase:                      splcommon.PhaseReady,
+				ClusterMasterPhase:         splcommon.PhaseReady,
+				Replicas:                   3,
+				ReadyReplicas:              3,
+				Initialized:                true,
+				IndexingReady:              true,
+				ServiceReady:               true,
+				IndexerSecretChanged:       []bool{false},
+				IdxcPasswordChangedSecrets: map[string]bool{"splunk-stack1-indexer-secret-v1": true},
+				Peers:                      testPeers,
+			},
+		},
+		newHub: func() conversion.Hub { return &enterprisev1.IndexerCluster{} },
+		spoke: &v1beta1.IndexerCluster{
+			ObjectMeta: newTestObjectMeta(),
+			Spec:       v1beta1.IndexerClusterSpec{CommonSplunkSpec: newTestCommonSplunkSpec(), Replicas: 3},
+			Status: v1beta1.IndexerClusterStatus{
+				Phase:              splcommon.PhaseUpdating,
+				ClusterMasterPhase: splcommon.PhaseReady,
+				Replicas:           3,
+				ReadyReplicas:      3,
+				Initialized:        true,
+				MaintenanceMode:    true,
+				Peers:              testPeers,
+			},
+		},
+		annotation: `{"spec":{"sparkRef":{"name":"spark1"}}}`,
+	},
+	{
+		name: "ClusterMaster",
+		hub: &enterprisev1.ClusterMaster{
+			ObjectMeta: newTestObjectMeta(),
+			Spec:       enterprisev1.ClusterMasterSpec{CommonSplunkSpec: newTestHubSpec(), SmartStore: testSmartStore},
+			Status: enterprisev1.ClusterMasterStatus{
+				Phase:             splcommon.PhaseReady,
+				Selector:          "app.kubernetes.io/instance=splunk-stack1-cluster-master",
+				SmartStore:        testSmartStore,
+				BundlePushTracker: enterprisev1.BundlePushInfo{NeedToPushMasterApps: true, LastCheckInterval: 1600000000},
+				AppContext:        testAppContext,
+			},
+		},
+		newHub: func() conversion.Hub { return &enterprisev1.ClusterMaster{} },
+		spoke: &v1beta1.ClusterMaster{
+			ObjectMeta: newTestObjectMeta(),
+			Spec:       v1beta1.ClusterMasterSpec{CommonSplunkSpec: newTestCommonSplunkSpec(), SmartStore: testSmartStore},
+			Status:     v1beta1.ClusterMasterStatus{Phase: splcommon.PhaseReady, Selector: "app.kubernetes.io/instance=splunk-stack1-cluster-master"},
+		},
+		annotation: `{"spec":{"sparkRef":{"name":"spark1"}}}`,
+	},
+	{
+		name: "LicenseMaster",
+		hub: &enterprisev1.LicenseMaster{
+			ObjectMeta: newTestObjectMeta(),
+			Spec:       enterprisev1.LicenseMasterSpec{CommonSplunkSpec: newTestHubSpec()},
+			Status:     enterprisev1.LicenseMasterStatus{Phase: splcommon.PhaseReady},
+		},
+		newHub: func() conversion.Hub { return &enterprisev1.LicenseMaster{} },
+		spoke: &v1beta1.LicenseMaster{
+			ObjectMeta: newTestObjectMeta(),
+			Spec:       v1beta1.LicenseMasterSpec{CommonSplunkSpec: newTestCommonSplunkSpec()},
+			Status:     v1beta1.LicenseMasterStatus{Phase: splcommon.PhasePending},
+		},
+		annotation: `{"spec":{"sparkRef":{"name":"spark1"}}}`,
+	},
+}
+
+// legacyVersions lists the legacy versions with the spec fields that v1beta1 does not have
+var legacyVersions = []struct {
+	apiVersion string
+	newSpoke   map[string]func() conversion.Convertible
+	spec       map[string]interface{}
+}{
+	{
+		apiVersion: v1alpha2.APIVersion,
+		newSpoke: map[string]func() conversion.Convertible{
+			"Standalone":        func() conversion.Convertible { return &v1alpha2.Standalone{} },
+			"SearchHeadCluster": func() conversion.Convertible { return &v1alpha2.SearchHeadCluster{} },
+			"IndexerCluster":    func() conversion.Convertible { return &v1alpha2.IndexerCluster{} },
+			"ClusterMaster":     func() conversion.Convertible { return &v1alpha2.ClusterMaster{} },
+			"LicenseMaster":     func() conversion.Convertible { return &v1alpha2.LicenseMaster{} },
+		},
+		spec: map[string]interface{}{"indexerClusterRef": map[string]interface{}{"name": "stack1"}},
+	},
+	{
+		apiVersion: v1alpha3.APIVersion,
+		newSpoke: map[string]func() conversion.Convertible{
+			"Standalone":        func() conversion.Convertible { return &v1alpha3.Standalone{} },
+			"SearchHeadCluster": func() conversion.Convertible { return &v1alpha3.SearchHeadCluster{} },
+			"IndexerCluster":    func() conversion.Convertible { return &v1alpha3.IndexerCluster{} },
+			"ClusterMaster":     func() conversion.Convertible { return &v1alpha3.ClusterMaster{} },
+			"LicenseMaster":     func() conversion.Convertible { return &v1alpha3.LicenseMaster{} },
+		},
+	},
+	{
+		apiVersion: v1beta1.APIVersion,
+		newSpoke: map[string]func() conversion.Convertible{
+			"Standalone":        func() conversion.Convertible { return &v1beta1.Standalone{} },
+			"SearchHeadCluster": func() conversion.Convertible { return &v1beta1.SearchHeadCluster{} },
+			"IndexerCluster":    func() conversion.Convertible { return &v1beta1.IndexerCluster{} },
+			"ClusterMaster":     func() conversion.Convertible { return &v1beta1.ClusterMaster{} },
+			"LicenseMaster":     func() conversion.Convertible { return &v1beta1.LicenseMaster{} },
+		},
+	},
+}
+
+// convertSpoke converts the v1beta1 spoke to another legacy version through its JSON, which drops the fields that
+// the version does not have, and sets the spec fields that v1beta1 does not have
+func convertSpoke(t *testing.T, src conversion.Convertible, dst conversion.Convertible, spec map[string]interface{}) {
+	raw, err := json.Marshal(src)
+	if err != nil {
+		t.Fatalf("json.Marshal() returned error: %v", err)
+	}
+	obj := map[string]interface{}{}
+	if err := json.Unmarshal(raw, &obj); err != nil {
+		t.Fatalf("json.Unmarshal() returned error: %v", err)
+	}
+	for k, v := range spec {
+		obj["spec"].(map[string]interface{})[k] = v
+	}
+	raw, err = json.Marshal(obj)
+	if err != nil {
+		t.Fatalf("json.Marshal() returned error: %v", err)
+	}
+	if err := json.Unmarshal(raw, dst); err != nil {
+		t.Fatalf("json.Unmarshal() returned error: %v", err)
+	}
+}
+
+func TestConversion(t *testing.T) {
+	for _, version := range legacyVersions {
+		for _, test := range testKinds {
+			t.Run(version.apiVersion+"/"+test.name, func(t *testing.T) {
+				newSpoke := version.newSpoke[test.name]
+				want := newSpoke()
+				convertSpoke(t, test.spoke, want, version.spec)
+
+				// nothing is lost in a round trip of a v1 object through this version
+				spoke := newSpoke()
+				if err := spoke.ConvertFrom(test.hub); err != nil {
+					t.Fatalf("ConvertFrom() returned error: %v", err)
+				}
+				hub := test.newHub()
+				if err := spoke.ConvertTo(hub); err != nil {
+					t.Fatalf("ConvertTo() returned error: %v", err)
+				}
+				if !equality.Semantic.DeepEqual(hub, test.hub) {
+					t.Errorf("round trip through %s: got %+v; want %+v", version.apiVersion, hub, test.hub)
+				}
+
+				// nothing is lost in a round trip through v1, which preserves the fields of this version that were removed in v1
+				hub = test.newHub()
+				if err := want.ConvertTo(hub); err != nil {
+					t.Fatalf("ConvertTo() returned error: %v", err)
+				}
+				if got := hub.(metav1.Object).GetAnnotations()[enterprisev1.ConversionDataAnnotation]; got != test.annotation {
+					t.Errorf("ConvertTo() annotation = %s; want %s", got, test.annotation)
+				}
+				spoke = newSpoke()
+				if err := spoke.ConvertFrom(hub); err != nil {
+					t.Fatalf("ConvertFrom() returned error: %v", err)
+				}
+				spoke.(metav1.Object).SetAnnotations(nil)
+				if !equality.Semantic.DeepEqual(spoke, want) {
+					t.Errorf("round trip through %s: got %+v; want %+v", enterprisev1.APIVersion, spoke, want)
+				}
+			})
+		}
+	}
+}
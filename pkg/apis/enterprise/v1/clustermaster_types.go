@@ -33,6 +33,41 @@ type ClusterMasterSpec struct {
 
 	// Splunk Smartstore configuration. Refer to indexes.conf.spec and server.conf.spec on docs.splunk.com
 	SmartStore SmartStoreSpec `json:"smartstore,omitempty"`
+
+	// Multisite configuration of the indexer cluster. Refer to the multisite clustering section of server.conf.spec on docs.splunk.com
+	Multisite MultisiteSpec `json:"multisite,omitempty"`
+}
+
+// MultisiteSpec defines the sites of a multisite indexer cluster
+type MultisiteSpec struct {
+	// Names of all the sites of the indexer cluster, e.g. site1, site2
+	Sites []string `json:"sites,omitempty"`
+
+	// Site of the cluster master; defaults to the first of the sites
+	Site string `json:"site,omitempty"`
+
+	// Number of copies of each bucket kept on its origin site and in total across all sites
+	SiteReplicationFactor SiteFactorSpec `json:"siteReplicationFactor,omitempty"`
+
+	// Number of searchable copies of each bucket kept on its origin site and in total across all sites
+	SiteSearchFactor SiteFactorSpec `json:"siteSearchFactor,omitempty"`
+
+	// Node label used to map sites to Kubernetes zones; defaults to topology.kubernetes.io/zone when siteZones is set
+	ZoneLabel string `json:"zoneLabel,omitempty"`
+
+	// Value of the zone label of the nodes running the pods of each site, keyed by site name
+	SiteZones map[string]string `json:"siteZones,omitempty"`
+}
+
+// SiteFactorSpec defines a site replication factor or site search factor of a multisite indexer cluster
+type SiteFactorSpec struct {
+	// Number of copies kept on the site where the data originates
+	// +kubebuilder:validation:Minimum=0
+	Origin int32 `json:"origin,omitempty"`
+
+	// Total number of copies kept across all sites
+	// +kubebuilder:validation:Minimum=0
+	Total int32 `json:"total,omitempty"`
 }
 
 // ClusterMasterStatus defines the observed state of ClusterMaster
@@ -40,23 +75,80 @@ type ClusterMasterStatus struct {
 	// current phase of the cluster master
 	Phase splcommon.Phase `json:"phase"`
 
+	// conditions of the cluster master, with the reason and message of their last transition
+	Conditions []Condition `json:"conditions,omitempty"`
+
+	// most recent generation of the cluster master observed by the operator
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// image run by the cluster master, recorded once it is ready on the image of the spec
+	Image string `json:"image,omitempty"`
+
+	// version of Splunk Enterprise run by the cluster master, as reported by it
+	Version string `json:"version,omitempty"`
+
+	// true if the cluster master is in maintenance mode, as reported by it
+	MaintenanceMode bool `json:"maintenanceMode"`
+
 	// selector for pods, used by HorizontalPodAutoscaler
 	Selector string `json:"selector"`
 
 	// Splunk Smartstore configuration. Refer to indexes.conf.spec and server.conf.spec on docs.splunk.com
 	SmartStore SmartStoreSpec `json:"smartstore,omitempty"`
 
+	// Results of the preflight checks of the SmartStore remote volumes, run before their configuration is rolled out
+	SmartStoreVolumes []RemoteVolumeStatus `json:"smartstoreVolumes,omitempty"`
+
 	// Bundle push status tracker
 	BundlePushTracker BundlePushInfo `json:"bundlePushInfo"`
 
 	// Resource Revision tracker
 	ResourceRevMap map[string]string `json:"resourceRevMap"`
+
+	// App packages installed from the app repository
+	AppContext AppDeploymentContext `json:"appContext,omitempty"`
 }
 
 // BundlePushInfo Indicates if bundle push required
 type BundlePushInfo struct {
 	NeedToPushMasterApps bool  `json:"needToPushMasterApps"`
 	LastCheckInterval    int64 `json:"lastCheckInterval"`
+
+	// time when the validation of the bundle was requested, while waiting for its result
+	ValidationStartTime int64 `json:"validationStartTime,omitempty"`
+
+	// checksum of the last bundle validated by the cluster master, which is pushed to the peers if it is valid
+	Checksum string `json:"checksum,omitempty"`
+
+	// true if the last bundle validated by the cluster master is valid
+	Valid bool `json:"valid,omitempty"`
+
+	// true if applying the bundle restarts the peers
+	RestartRequired bool `json:"restartRequired,omitempty"`
+
+	// true while the peers apply the pushed bundle
+	ApplyInProgress bool `json:"applyInProgress,omitempty"`
+
+	// errors reported by the cluster master or the peers about the bundle
+	Errors []string `json:"errors,omitempty"`
+
+	// state of the bundle on each peer
+	Peers []BundlePeerStatus `json:"peers,omitempty"`
+}
+
+// BundlePeerStatus is the state of the master apps bundle on an indexer cluster peer
+type BundlePeerStatus struct {
+	// name of the peer
+	Name string `json:"name"`
+
+	// checksum of the bundle active on the peer
+	ActiveBundleID string `json:"activeBundleId,omitempty"`
+
+	// status of the application of the bundle by the peer, as reported by the cluster master
+	Status string `json:"status,omitempty"`
+
+	// true once the peer runs the pushed bundle
+	Applied bool `json:"applied,omitempty"`
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
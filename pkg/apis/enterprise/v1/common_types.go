@@ -16,6 +16,8 @@ package v1
 
 import (
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
 
 	splcommon "github.com/splunk/splunk-operator/pkg/splunk/common"
 )
@@ -67,6 +69,10 @@ type CommonSplunkSpec struct {
 	// ClusterMasterRef refers to a Splunk Enterprise indexer cluster managed by the operator within Kubernetes
 	ClusterMasterRef corev1.ObjectReference `json:"clusterMasterRef"`
 
+	// MonitoringConsoleRef refers to a Splunk Enterprise monitoring console managed by the operator within Kubernetes.
+	// When not set, the resource is registered with the monitoring console the operator creates for the namespace
+	MonitoringConsoleRef corev1.ObjectReference `json:"monitoringConsoleRef"`
+
 	// Mock to differentiate between UTs and actual reconcile
 	Mock bool `json:"Mock"`
 
@@ -78,6 +84,26 @@ type CommonSplunkSpec struct {
 	// ExtraEnv refers to extra environment variables to be passed to the Splunk instance containers
 	// WARNING: Setting environment variables used by Splunk or Ansible will affect Splunk installation and operation
 	ExtraEnv []corev1.EnvVar `json:"extraEnv,omitempty"`
+
+	// Splunk app packages to install from a remote object store repository.
+	// Used by Standalone, ClusterMaster and SearchHeadCluster; ignored by all other kinds
+	AppFrameworkConfig AppFrameworkSpec `json:"appRepo,omitempty"`
+
+	// PodDisruptionBudget of the pods, overriding the default of their role: one unavailable indexer, a quorum of
+	// search heads, and none for single instances such as the cluster master or the license master
+	PodDisruptionBudget PodDisruptionBudgetSpec `json:"podDisruptionBudget,omitempty"`
+}
+
+// PodDisruptionBudgetSpec defines the PodDisruptionBudget created for the pods of a StatefulSet
+type PodDisruptionBudgetSpec struct {
+	// Set to true to not create a PodDisruptionBudget
+	Disabled bool `json:"disabled,omitempty"`
+
+	// Number or percentage of pods that must remain available when pods are evicted, for example by a node drain
+	MinAvailable *intstr.IntOrString `json:"minAvailable,omitempty"`
+
+	// Number or percentage of pods that can be unavailable when pods are evicted; ignored if minAvailable is set
+	MaxUnavailable *intstr.IntOrString `json:"maxUnavailable,omitempty"`
 }
 
 // StorageClassSpec defines storage class configuration
@@ -145,8 +171,107 @@ type VolumeSpec struct {
 
 	// Secret object name
 	SecretRef string `json:"secretRef"`
+
+	// Remote storage provider of the volume: s3, gcs or azure (default=s3)
+	Provider RemoteStorageProvider `json:"provider,omitempty"`
+
+	// How Splunk Enterprise authenticates to the volume: accessKey, with the keys of secretRef, or iamRole, with the
+	// IAM role of the service account of the pods (default=accessKey)
+	AuthMode RemoteVolumeAuthMode `json:"authMode,omitempty"`
+
+	// ARN of the IAM role annotated on the service account of the pods, for IAM roles for service accounts (IRSA) with authMode iamRole
+	RoleARN string `json:"roleArn,omitempty"`
+
+	// Region used to sign the requests to an S3 volume, rendered as remote.s3.auth_region
+	Region string `json:"region,omitempty"`
+
+	// Signature version of the requests to an S3 volume, rendered as remote.s3.signature_version
+	// +kubebuilder:validation:Enum=v2;v4
+	SignatureVersion string `json:"signatureVersion,omitempty"`
+
+	// If true, the bucket of an S3 volume is addressed in the path of its URLs instead of the host name
+	PathStyle bool `json:"pathStyle,omitempty"`
+
+	// If true, the certificate of the endpoint of an S3 volume is verified, rendered as remote.s3.sslVerifyServerCert
+	SSLVerifyServerCert bool `json:"sslVerifyServerCert,omitempty"`
+
+	// Certificate authority bundle used to verify the certificate of the endpoint of an S3 volume
+	CABundle CABundleSpec `json:"caBundle,omitempty"`
+
+	// Server-side encryption of the objects of an S3 volume: sse-s3 or sse-kms, rendered as remote.s3.encryption
+	// +kubebuilder:validation:Enum=sse-s3;sse-kms
+	Encryption string `json:"encryption,omitempty"`
+
+	// Key ID or ARN of the AWS KMS key used with sse-kms encryption, rendered as remote.s3.kms.key_id
+	KMSKeyID string `json:"kmsKeyId,omitempty"`
+}
+
+// CABundleSpec refers to a certificate authority bundle held by a Secret or a ConfigMap, which is mounted in the pods
+type CABundleSpec struct {
+	// Name of the Secret holding the CA bundle
+	SecretRef string `json:"secretRef,omitempty"`
+
+	// Name of the ConfigMap holding the CA bundle
+	ConfigMapRef string `json:"configMapRef,omitempty"`
+
+	// Key of the CA bundle in the Secret or ConfigMap (default="ca.crt")
+	Key string `json:"key,omitempty"`
+}
+
+// RemoteVolumeAuthMode is the way Splunk Enterprise authenticates to a remote volume
+// +kubebuilder:validation:Enum=accessKey;iamRole
+type RemoteVolumeAuthMode string
+
+const (
+	// RemoteVolumeAuthModeAccessKey authenticates with the access key and secret key of a secret
+	RemoteVolumeAuthModeAccessKey RemoteVolumeAuthMode = "accessKey"
+
+	// RemoteVolumeAuthModeIAMRole authenticates with the IAM role of the pods, without static keys
+	RemoteVolumeAuthModeIAMRole RemoteVolumeAuthMode = "iamRole"
+)
+
+// RemoteStorageProvider is the object storage service holding a remote volume
+// +kubebuilder:validation:Enum=s3;gcs;azure
+type RemoteStorageProvider string
+
+const (
+	// RemoteStorageProviderS3 is Amazon S3, or an S3 compatible object store
+	RemoteStorageProviderS3 RemoteStorageProvider = "s3"
+
+	// RemoteStorageProviderGCS is Google Cloud Storage
+	RemoteStorageProviderGCS RemoteStorageProvider = "gcs"
+
+	// RemoteStorageProviderAzure is Azure Blob Storage
+	RemoteStorageProviderAzure RemoteStorageProvider = "azure"
+)
+
+// RemoteVolumeStatus is the result of the preflight check of a SmartStore remote volume, run before its configuration is rolled out
+type RemoteVolumeStatus struct {
+	// Remote volume name
+	Name string `json:"name"`
+
+	// Result of the last preflight check of the volume
+	Result RemoteVolumeCheckResult `json:"result"`
+
+	// Error of a failed check, or why the check was skipped
+	Message string `json:"message,omitempty"`
 }
 
+// RemoteVolumeCheckResult is the result of the preflight check of a remote volume
+// +kubebuilder:validation:Enum=Passed;Failed;Skipped
+type RemoteVolumeCheckResult string
+
+const (
+	// RemoteVolumeCheckPassed means a probe object was written, listed and deleted under the path of the volume
+	RemoteVolumeCheckPassed RemoteVolumeCheckResult = "Passed"
+
+	// RemoteVolumeCheckFailed means the volume could not be reached or accessed with its credentials
+	RemoteVolumeCheckFailed RemoteVolumeCheckResult = "Failed"
+
+	// RemoteVolumeCheckSkipped means the operator can not check the volume, like gcs and azure volumes, or volumes using an IAM role
+	RemoteVolumeCheckSkipped RemoteVolumeCheckResult = "Skipped"
+)
+
 // IndexSpec defines Splunk index name and storage path
 type IndexSpec struct {
 	// Splunk index name
@@ -181,3 +306,151 @@ type IndexAndCacheManagerCommonSpec struct {
 	// Time period relative to the bucket's age, during which the bloom filter file is protected from cache eviction
 	HotlistBloomFilterRecencyHours uint `json:"hotlistBloomFilterRecencyHours,omitempty"`
 }
+
+// AppFrameworkSpec defines the remote object store repository used to install Splunk app packages
+type AppFrameworkSpec struct {
+	// Default configuration for app sources
+	Defaults AppSourceDefaultSpec `json:"defaults,omitempty"`
+
+	// List of remote storage volumes holding the app packages
+	VolList []VolumeSpec `json:"volumes,omitempty"`
+
+	// List of app sources on the remote storage volumes
+	AppSources []AppSourceSpec `json:"appSources,omitempty"`
+
+	// Interval in seconds between checks of the app repository for new and updated app packages (default=3600)
+	AppsRepoPollInterval int64 `json:"appsRepoPollIntervalSeconds,omitempty"`
+}
+
+// AppSourceDefaultSpec defines configuration that can be set for all app sources or per app source
+type AppSourceDefaultSpec struct {
+	// Remote storage volume name
+	VolName string `json:"volumeName,omitempty"`
+
+	// Scope of the app deployment: local, cluster or clusterWithPreConfig
+	Scope string `json:"scope,omitempty"`
+}
+
+// AppSourceSpec defines a location on a remote storage volume holding app packages (*.tgz, *.spl)
+type AppSourceSpec struct {
+	// Logical name for the set of apps placed in this location. Must be unique within the app repository
+	Name string `json:"name"`
+
+	// Location of the app packages, relative to the volume path
+	Location string `json:"location"`
+
+	AppSourceDefaultSpec `json:",inline"`
+}
+
+// AppDeploymentContext tracks the app packages installed from the app repository
+type AppDeploymentContext struct {
+	// App repository configuration used for the last check of the app repository
+	AppFrameworkConfig AppFrameworkSpec `json:"appRepo,omitempty"`
+
+	// Time of the last check of the app repository, in seconds since the epoch
+	LastAppInfoCheckTime int64 `json:"lastAppInfoCheckTime,omitempty"`
+
+	// Number of pods the app packages were last installed on
+	Replicas int32 `json:"replicas,omitempty"`
+
+	// True if installed app packages are waiting for a bundle push from the cluster master or deployer
+	NeedToPushBundle bool `json:"needToPushBundle,omitempty"`
+
+	// Pods waiting to be restarted one at a time to load the installed app packages
+	PendingRestarts []int32 `json:"pendingRestarts,omitempty"`
+
+	// Startup time of splunkd on the first pending pod before it was restarted, or 0 if it was not restarted yet
+	RestartStartupTime int64 `json:"restartStartupTime,omitempty"`
+
+	// App packages installed from the app repository
+	Apps []AppDeploymentInfo `json:"apps,omitempty"`
+}
+
+// AppDeploymentInfo describes an app package installed from the app repository
+type AppDeploymentInfo struct {
+	// Name of the app source the package belongs to
+	AppSource string `json:"appSource"`
+
+	// Object key of the app package on the remote storage volume
+	Name string `json:"name"`
+
+	// Checksum (ETag) of the installed app package
+	Checksum string `json:"checksum"`
+
+	// Name of the Splunk app contained in the package
+	AppName string `json:"appName,omitempty"`
+
+	// Version of the app reported by Splunk after installation
+	Version string `json:"version,omitempty"`
+
+	// Installation phase of the app package
+	Phase AppPhase `json:"phase,omitempty"`
+
+	// Last error that occurred while installing the app package
+	LastError string `json:"lastError,omitempty"`
+}
+
+// AppPhase is used to represent the installation phase of an app package
+// +kubebuilder:validation:Enum=Pending;Installed;Error
+type AppPhase string
+
+const (
+	// AppPhasePending means an app package was extracted, and is waiting for a restart or bundle push to be loaded
+	AppPhasePending AppPhase = "Pending"
+
+	// AppPhaseInstalled means an app was loaded by Splunk, or pushed to the cluster
+	AppPhaseInstalled AppPhase = "Installed"
+
+	// AppPhaseError means an error occurred while installing an app package
+	AppPhaseError AppPhase = "Error"
+)
+
+// Condition describes one aspect of the current state of a Splunk Enterprise custom resource.
+// It has the same fields as the metav1.Condition type of later Kubernetes versions.
+type Condition struct {
+	// Type of the condition, such as Available or Degraded
+	Type string `json:"type"`
+
+	// Status of the condition, one of True, False or Unknown
+	// +kubebuilder:validation:Enum=True;False;Unknown
+	Status corev1.ConditionStatus `json:"status"`
+
+	// Generation of the custom resource that the condition was set for
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// Last time the status of the condition changed
+	LastTransitionTime metav1.Time `json:"lastTransitionTime"`
+
+	// Reason for the last transition of the condition, in CamelCase
+	Reason string `json:"reason"`
+
+	// Human readable message with details about the last transition
+	Message string `json:"message"`
+}
+
+const (
+	// ConditionAvailable means that all the Splunk Enterprise instances of a custom resource are ready
+	ConditionAvailable = "Available"
+
+	// ConditionProgressing means that the operator is creating, scaling or updating a custom resource
+	ConditionProgressing = "Progressing"
+
+	// ConditionDegraded means that the last reconcile of a custom resource failed
+	ConditionDegraded = "Degraded"
+
+	// ConditionBundlePushed means that the cluster master pushed its latest configuration bundle to the peers
+	ConditionBundlePushed = "BundlePushed"
+
+	// ConditionSecretsSynced means that the secrets and configuration resources of a custom resource are up to date
+	ConditionSecretsSynced = "SecretsSynced"
+
+	// ConditionSmartStoreConfigured means that the SmartStore configuration of a custom resource was applied
+	ConditionSmartStoreConfigured = "SmartStoreConfigured"
+
+	// ConditionLicenseConnected means that the license master referenced by a custom resource is ready
+	ConditionLicenseConnected = "LicenseConnected"
+
+	// ConditionImageUpToDate means that the pods of a custom resource run the image of its spec. It is false
+	// while an image upgrade waits for the upstream custom resources to be ready on the new image, or is rolled out.
+	ConditionImageUpToDate = "ImageUpToDate"
+)
@@ -0,0 +1,51 @@
+// Copyright (c) 2018-2021 Splunk Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1
+
+import (
+	"sigs.k8s.io/controller-runtime/pkg/conversion"
+)
+
+const (
+	// ConversionDataAnnotation is used to preserve the fields of an object that can not be represented
+	// by the API version it is converted to. On v1 objects it holds the fields only known to older API versions,
+	// and on objects of older API versions it holds the v1 spec and status.
+	ConversionDataAnnotation = "enterprise.splunk.com/conversion-data"
+)
+
+// v1 is the hub of the conversions between the API versions of the custom resources.
+// Custom resources that were not introduced before v1 are not convertible.
+var (
+	_ conversion.Hub = &ClusterMaster{}
+	_ conversion.Hub = &IndexerCluster{}
+	_ conversion.Hub = &LicenseMaster{}
+	_ conversion.Hub = &SearchHeadCluster{}
+	_ conversion.Hub = &Standalone{}
+)
+
+// Hub marks ClusterMaster as the hub type for conversions
+func (*ClusterMaster) Hub() {}
+
+// Hub marks IndexerCluster as the hub type for conversions
+func (*IndexerCluster) Hub() {}
+
+// Hub marks LicenseMaster as the hub type for conversions
+func (*LicenseMaster) Hub() {}
+
+// Hub marks SearchHeadCluster as the hub type for conversions
+func (*SearchHeadCluster) Hub() {}
+
+// Hub marks Standalone as the hub type for conversions
+func (*Standalone) Hub() {}
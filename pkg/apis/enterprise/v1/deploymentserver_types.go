@@ -0,0 +1,126 @@
+// Copyright (c) 2018-2021 Splunk Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	splcommon "github.com/splunk/splunk-operator/pkg/splunk/common"
+)
+
+// default all fields to being optional
+// +kubebuilder:validation:Optional
+
+// NOTE: json tags are required.  Any new fields you add must have json tags for the fields to be serialized.
+// Important: Run "operator-sdk generate k8s" to regenerate code after modifying this file
+// Add custom validation using kubebuilder tags: https://book-v1.book.kubebuilder.io/beyond_basics/generating_crd.html
+// see also https://book.kubebuilder.io/reference/markers/crd.html
+
+// DeploymentServerSpec defines the desired state of a Splunk Enterprise deployment server.
+type DeploymentServerSpec struct {
+	CommonSplunkSpec `json:",inline"`
+
+	// Server classes used to map deployment clients to apps. Refer to serverclass.conf.spec on docs.splunk.com
+	ServerClasses []ServerClassSpec `json:"serverClasses,omitempty"`
+}
+
+// ServerClassSpec defines a server class of the deployment server
+type ServerClassSpec struct {
+	// Name of the server class
+	Name string `json:"name"`
+
+	// Deployment clients included in the server class, matched by client name, IP address, host name or DNS name
+	Whitelist []string `json:"whitelist,omitempty"`
+
+	// Deployment clients excluded from the server class, matched by client name, IP address, host name or DNS name
+	Blacklist []string `json:"blacklist,omitempty"`
+
+	// Apps in the deployment-apps directory that are deployed to the clients of the server class
+	Apps []string `json:"apps,omitempty"`
+
+	// Restart splunkd on the deployment clients after the apps of the server class are installed
+	RestartSplunkd bool `json:"restartSplunkd,omitempty"`
+}
+
+// DeploymentClientStatus defines the observed state of a deployment client that phoned home to the deployment server
+type DeploymentClientStatus struct {
+	// Name of the deployment client
+	Name string `json:"name"`
+
+	// Host name of the deployment client
+	Hostname string `json:"hostname,omitempty"`
+
+	// IP address of the deployment client
+	IP string `json:"ip,omitempty"`
+
+	// Time of the last phone home from the deployment client, in seconds since epoch
+	LastPhoneHomeTime int64 `json:"lastPhoneHomeTime,omitempty"`
+
+	// Server classes the deployment client belongs to
+	ServerClasses []string `json:"serverClasses,omitempty"`
+}
+
+// DeploymentServerStatus defines the observed state of a Splunk Enterprise deployment server.
+type DeploymentServerStatus struct {
+	// current phase of the deployment server
+	Phase splcommon.Phase `json:"phase"`
+
+	// conditions of the deployment server, with the reason and message of their last transition
+	Conditions []Condition `json:"conditions,omitempty"`
+
+	// most recent generation of the deployment server observed by the operator
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// image run by the deployment server, recorded once it is ready on the image of the spec
+	Image string `json:"image,omitempty"`
+
+	// version of Splunk Enterprise run by the deployment server, as reported by it
+	Version string `json:"version,omitempty"`
+
+	// deployment clients that phoned home to the deployment server
+	Clients []DeploymentClientStatus `json:"clients,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// DeploymentServer is the Schema for a Splunk Enterprise deployment server.
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:path=deploymentservers,scope=Namespaced,shortName=ds
+// +kubebuilder:printcolumn:name="Phase",type="string",JSONPath=".status.phase",description="Status of deployment server"
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp",description="Age of deployment server"
+// +kubebuilder:storageversion
+type DeploymentServer struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   DeploymentServerSpec   `json:"spec,omitempty"`
+	Status DeploymentServerStatus `json:"status,omitempty"`
+}
+
+// blank assignment to verify that DeploymentServer implements splcommon.MetaObject
+var _ splcommon.MetaObject = &DeploymentServer{}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// DeploymentServerList contains a list of DeploymentServer
+type DeploymentServerList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []DeploymentServer `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&DeploymentServer{}, &DeploymentServerList{})
+}
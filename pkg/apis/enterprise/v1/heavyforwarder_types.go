@@ -0,0 +1,103 @@
+// Copyright (c) 2018-2021 Splunk Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	splcommon "github.com/splunk/splunk-operator/pkg/splunk/common"
+)
+
+// default all fields to being optional
+// +kubebuilder:validation:Optional
+
+// NOTE: json tags are required.  Any new fields you add must have json tags for the fields to be serialized.
+// Important: Run "operator-sdk generate k8s" to regenerate code after modifying this file
+// Add custom validation using kubebuilder tags: https://book-v1.book.kubebuilder.io/beyond_basics/generating_crd.html
+// see also https://book.kubebuilder.io/reference/markers/crd.html
+
+// HeavyForwarderSpec defines the desired state of Splunk Enterprise heavy forwarders
+type HeavyForwarderSpec struct {
+	CommonSplunkSpec `json:",inline"`
+
+	// Number of heavy forwarder pods
+	Replicas int32 `json:"replicas"`
+
+	// List of indexers to forward data to, in the form <host>[:<port>], used when clusterMasterRef is not set.
+	// The receiving port defaults to 9997.
+	IndexerTargets []string `json:"indexerTargets,omitempty"`
+}
+
+// HeavyForwarderStatus defines the observed state of Splunk Enterprise heavy forwarders
+type HeavyForwarderStatus struct {
+	// current phase of the heavy forwarders
+	Phase splcommon.Phase `json:"phase"`
+
+	// conditions of the heavy forwarders, with the reason and message of their last transition
+	Conditions []Condition `json:"conditions,omitempty"`
+
+	// most recent generation of the heavy forwarders observed by the operator
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// image run by the heavy forwarders, recorded once they are all ready on the image of the spec
+	Image string `json:"image,omitempty"`
+
+	// version of Splunk Enterprise run by the heavy forwarders, as reported by them
+	Version string `json:"version,omitempty"`
+
+	// number of desired heavy forwarders
+	Replicas int32 `json:"replicas"`
+
+	// current number of ready heavy forwarders
+	ReadyReplicas int32 `json:"readyReplicas"`
+
+	// selector for pods, used by HorizontalPodAutoscaler
+	Selector string `json:"selector"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// HeavyForwarder is the Schema for Splunk Enterprise heavy forwarders
+// +kubebuilder:subresource:status
+// +kubebuilder:subresource:scale:specpath=.spec.replicas,statuspath=.status.replicas,selectorpath=.status.selector
+// +kubebuilder:resource:path=heavyforwarders,scope=Namespaced,shortName=hwf
+// +kubebuilder:printcolumn:name="Phase",type="string",JSONPath=".status.phase",description="Status of heavy forwarders"
+// +kubebuilder:printcolumn:name="Desired",type="integer",JSONPath=".status.replicas",description="Number of desired heavy forwarders"
+// +kubebuilder:printcolumn:name="Ready",type="integer",JSONPath=".status.readyReplicas",description="Current number of ready heavy forwarders"
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp",description="Age of heavy forwarder resource"
+// +kubebuilder:storageversion
+type HeavyForwarder struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   HeavyForwarderSpec   `json:"spec,omitempty"`
+	Status HeavyForwarderStatus `json:"status,omitempty"`
+}
+
+// blank assignment to verify that HeavyForwarder implements splcommon.MetaObject
+var _ splcommon.MetaObject = &HeavyForwarder{}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// HeavyForwarderList contains a list of HeavyForwarder
+type HeavyForwarderList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []HeavyForwarder `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&HeavyForwarder{}, &HeavyForwarderList{})
+}
@@ -34,6 +34,9 @@ type IndexerClusterSpec struct {
 
 	// Number of search head pods; a search head cluster will be created if > 1
 	Replicas int32 `json:"replicas"`
+
+	// Site of the indexer cluster peers; must be one of the sites of a multisite cluster master
+	Site string `json:"site,omitempty"`
 }
 
 // IndexerClusterMemberStatus is used to track the status of each indexer cluster peer.
@@ -57,11 +60,48 @@ type IndexerClusterMemberStatus struct {
 	Searchable bool `json:"is_searchable"`
 }
 
+// IndexerClusterRestartStatus tracks a searchable rolling restart of the indexer cluster peers by the cluster master
+type IndexerClusterRestartStatus struct {
+	// revision of the defaults ConfigMap applied by the rolling restart
+	ConfigRev string `json:"configRev,omitempty"`
+
+	// true while the cluster master restarts the peers
+	InProgress bool `json:"inProgress,omitempty"`
+
+	// time when the rolling restart was started
+	StartTime int64 `json:"startTime,omitempty"`
+
+	// peers that have been restarted
+	Done []string `json:"done,omitempty"`
+
+	// peers being restarted or waiting to be restarted
+	Pending []string `json:"pending,omitempty"`
+
+	// peers that could not be restarted
+	Failed []string `json:"failed,omitempty"`
+
+	// checksum of the defaults of the peers without the values of their splunk.conf settings, which are the only
+	// changes of the defaults applied by a rolling restart
+	DefaultsChecksum string `json:"defaultsChecksum,omitempty"`
+}
+
 // IndexerClusterStatus defines the observed state of a Splunk Enterprise indexer cluster
 type IndexerClusterStatus struct {
 	// current phase of the indexer cluster
 	Phase splcommon.Phase `json:"phase"`
 
+	// conditions of the indexer cluster, with the reason and message of their last transition
+	Conditions []Condition `json:"conditions,omitempty"`
+
+	// most recent generation of the indexer cluster observed by the operator
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// image run by the indexer peers, recorded once they are all ready on the image of the spec
+	Image string `json:"image,omitempty"`
+
+	// version of Splunk Enterprise run by the indexer peers, as reported by them
+	Version string `json:"version,omitempty"`
+
 	// current phase of the cluster master
 	ClusterMasterPhase splcommon.Phase `json:"clusterMasterPhase"`
 
@@ -95,6 +135,12 @@ type IndexerClusterStatus struct {
 	// Indicates if the cluster is in maintenance mode.
 	MaintenanceMode bool `json:"maintenance_mode"`
 
+	// true while the operator keeps the cluster master in maintenance mode to recycle the peers
+	RecycleMaintenanceMode bool `json:"recycleMaintenanceMode,omitempty"`
+
+	// searchable rolling restart used to apply changes of the splunk.conf settings of the defaults without recreating the pods
+	RollingRestart IndexerClusterRestartStatus `json:"rollingRestart,omitempty"`
+
 	// status of each indexer cluster peer
 	Peers []IndexerClusterMemberStatus `json:"peers"`
 }
@@ -37,6 +37,18 @@ type LicenseMasterSpec struct {
 type LicenseMasterStatus struct {
 	// current phase of the license master
 	Phase splcommon.Phase `json:"phase"`
+
+	// conditions of the license master, with the reason and message of their last transition
+	Conditions []Condition `json:"conditions,omitempty"`
+
+	// most recent generation of the license master observed by the operator
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// image run by the license master, recorded once it is ready on the image of the spec
+	Image string `json:"image,omitempty"`
+
+	// version of Splunk Enterprise run by the license master, as reported by it
+	Version string `json:"version,omitempty"`
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
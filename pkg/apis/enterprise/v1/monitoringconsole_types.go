@@ -0,0 +1,96 @@
+// Copyright (c) 2018-2021 Splunk Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	splcommon "github.com/splunk/splunk-operator/pkg/splunk/common"
+)
+
+// default all fields to being optional
+// +kubebuilder:validation:Optional
+
+// NOTE: json tags are required.  Any new fields you add must have json tags for the fields to be serialized.
+// Important: Run "operator-sdk generate k8s" to regenerate code after modifying this file
+// Add custom validation using kubebuilder tags: https://book-v1.book.kubebuilder.io/beyond_basics/generating_crd.html
+// see also https://book.kubebuilder.io/reference/markers/crd.html
+
+// MonitoringConsoleSpec defines the desired state of a Splunk Enterprise monitoring console.
+type MonitoringConsoleSpec struct {
+	CommonSplunkSpec `json:",inline"`
+}
+
+// MonitoringConsolePeerStatus defines the observed state of a Splunk Enterprise instance registered with a monitoring console
+type MonitoringConsolePeerStatus struct {
+	// Fully qualified domain name of the peer
+	Name string `json:"name"`
+
+	// Role of the peer in the monitoring console
+	Role string `json:"role"`
+}
+
+// MonitoringConsoleStatus defines the observed state of a Splunk Enterprise monitoring console.
+type MonitoringConsoleStatus struct {
+	// current phase of the monitoring console
+	Phase splcommon.Phase `json:"phase"`
+
+	// conditions of the monitoring console, with the reason and message of their last transition
+	Conditions []Condition `json:"conditions,omitempty"`
+
+	// most recent generation of the monitoring console observed by the operator
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// image run by the monitoring console, recorded once it is ready on the image of the spec
+	Image string `json:"image,omitempty"`
+
+	// version of Splunk Enterprise run by the monitoring console, as reported by it
+	Version string `json:"version,omitempty"`
+
+	// peers registered with the monitoring console
+	Peers []MonitoringConsolePeerStatus `json:"peers,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// MonitoringConsole is the Schema for a Splunk Enterprise monitoring console.
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:path=monitoringconsoles,scope=Namespaced,shortName=mc
+// +kubebuilder:printcolumn:name="Phase",type="string",JSONPath=".status.phase",description="Status of monitoring console"
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp",description="Age of monitoring console"
+// +kubebuilder:storageversion
+type MonitoringConsole struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   MonitoringConsoleSpec   `json:"spec,omitempty"`
+	Status MonitoringConsoleStatus `json:"status,omitempty"`
+}
+
+// blank assignment to verify that MonitoringConsole implements splcommon.MetaObject
+var _ splcommon.MetaObject = &MonitoringConsole{}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// MonitoringConsoleList contains a list of MonitoringConsole
+type MonitoringConsoleList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []MonitoringConsole `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&MonitoringConsole{}, &MonitoringConsoleList{})
+}
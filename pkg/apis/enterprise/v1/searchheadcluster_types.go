@@ -34,6 +34,16 @@ type SearchHeadClusterSpec struct {
 
 	// Number of search head pods; a search head cluster will be created if > 1
 	Replicas int32 `json:"replicas"`
+
+	// Site of the search heads, for a cluster master with a multisite configuration; use site0 to disable search affinity
+	Site string `json:"site,omitempty"`
+
+	// PodDisruptionBudget of the deployer, which has none by default; podDisruptionBudget applies to the search heads
+	DeployerPodDisruptionBudget PodDisruptionBudgetSpec `json:"deployerPodDisruptionBudget,omitempty"`
+
+	// Time in seconds that a detained member waits for its active searches to complete before it is removed or
+	// recycled, killing the searches still running (default=600)
+	SearchDrainTimeout int64 `json:"searchDrainTimeoutSeconds,omitempty"`
 }
 
 // SearchHeadClusterMemberStatus is used to track the status of each search head cluster member
@@ -55,6 +65,21 @@ type SearchHeadClusterMemberStatus struct {
 
 	// Number of currently running realtime searches.
 	ActiveRealtimeSearchCount int `json:"active_realtime_search_count"`
+
+	// Time when the operator started to wait for the active searches of the detained member to complete
+	DrainStartTime int64 `json:"drainStartTime,omitempty"`
+}
+
+// CaptaincyTransferInfo tracks the transfer of the captaincy of a search head cluster before its captain is recycled
+type CaptaincyTransferInfo struct {
+	// member that held the captaincy when the transfer was requested
+	Captain string `json:"captain,omitempty"`
+
+	// member that the captaincy is transferred to
+	Target string `json:"target,omitempty"`
+
+	// time when the transfer was requested
+	StartTime int64 `json:"startTime,omitempty"`
 }
 
 // SearchHeadClusterStatus defines the observed state of a Splunk Enterprise search head cluster
@@ -62,6 +87,18 @@ type SearchHeadClusterStatus struct {
 	// current phase of the search head cluster
 	Phase splcommon.Phase `json:"phase"`
 
+	// conditions of the search head cluster, with the reason and message of their last transition
+	Conditions []Condition `json:"conditions,omitempty"`
+
+	// most recent generation of the search head cluster observed by the operator
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// image run by the search heads, recorded once they are all ready on the image of the spec
+	Image string `json:"image,omitempty"`
+
+	// version of Splunk Enterprise run by the search heads, as reported by them
+	Version string `json:"version,omitempty"`
+
 	// current phase of the deployer
 	DeployerPhase splcommon.Phase `json:"deployerPhase"`
 
@@ -80,6 +117,9 @@ type SearchHeadClusterStatus struct {
 	// true if the search head cluster's captain is ready to service requests
 	CaptainReady bool `json:"captainReady"`
 
+	// transfer of the captaincy requested before recycling the captain, which is pending until another member is elected
+	CaptaincyTransfer CaptaincyTransferInfo `json:"captaincyTransfer,omitempty"`
+
 	// true if the search head cluster has finished initialization
 	Initialized bool `json:"initialized"`
 
@@ -89,6 +129,12 @@ type SearchHeadClusterStatus struct {
 	// true if the search head cluster is in maintenance mode
 	MaintenanceMode bool `json:"maintenanceMode"`
 
+	// time in seconds that a detained member waits for its active searches to complete before it is removed or recycled
+	SearchDrainTimeout int64 `json:"searchDrainTimeoutSeconds,omitempty"`
+
+	// number of active searches killed by removing or recycling members after the search drain timeout
+	KilledSearches int64 `json:"killedSearches,omitempty"`
+
 	// Indicates when the shc_secret has been changed for a peer
 	ShcSecretChanged []bool `json:"shcSecretChangedFlag"`
 
@@ -103,6 +149,9 @@ type SearchHeadClusterStatus struct {
 
 	// status of each search head cluster member
 	Members []SearchHeadClusterMemberStatus `json:"members"`
+
+	// App packages installed from the app repository
+	AppContext AppDeploymentContext `json:"appContext,omitempty"`
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
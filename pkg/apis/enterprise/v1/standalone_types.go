@@ -44,6 +44,18 @@ type StandaloneStatus struct {
 	// current phase of the standalone instances
 	Phase splcommon.Phase `json:"phase"`
 
+	// conditions of the standalone instances, with the reason and message of their last transition
+	Conditions []Condition `json:"conditions,omitempty"`
+
+	// most recent generation of the standalone instances observed by the operator
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// image run by the standalone instances, recorded once they are all ready on the image of the spec
+	Image string `json:"image,omitempty"`
+
+	// version of Splunk Enterprise run by the standalone instances, as reported by them
+	Version string `json:"version,omitempty"`
+
 	// number of desired standalone instances
 	Replicas int32 `json:"replicas"`
 
@@ -56,8 +68,14 @@ type StandaloneStatus struct {
 	//Splunk Smartstore configuration. Refer to indexes.conf.spec and server.conf.spec on docs.splunk.com
 	SmartStore SmartStoreSpec `json:"smartstore,omitempty"`
 
+	// Results of the preflight checks of the SmartStore remote volumes, run before their configuration is rolled out
+	SmartStoreVolumes []RemoteVolumeStatus `json:"smartstoreVolumes,omitempty"`
+
 	// Resource Revision tracker
 	ResourceRevMap map[string]string `json:"resourceRevMap"`
+
+	// App packages installed from the app repository
+	AppContext AppDeploymentContext `json:"appContext,omitempty"`
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
@@ -7,11 +7,141 @@ package v1
 import (
 	corev1 "k8s.io/api/core/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AppDeploymentContext) DeepCopyInto(out *AppDeploymentContext) {
+	*out = *in
+	in.AppFrameworkConfig.DeepCopyInto(&out.AppFrameworkConfig)
+	if in.PendingRestarts != nil {
+		in, out := &in.PendingRestarts, &out.PendingRestarts
+		*out = make([]int32, len(*in))
+		copy(*out, *in)
+	}
+	if in.Apps != nil {
+		in, out := &in.Apps, &out.Apps
+		*out = make([]AppDeploymentInfo, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AppDeploymentContext.
+func (in *AppDeploymentContext) DeepCopy() *AppDeploymentContext {
+	if in == nil {
+		return nil
+	}
+	out := new(AppDeploymentContext)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AppDeploymentInfo) DeepCopyInto(out *AppDeploymentInfo) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AppDeploymentInfo.
+func (in *AppDeploymentInfo) DeepCopy() *AppDeploymentInfo {
+	if in == nil {
+		return nil
+	}
+	out := new(AppDeploymentInfo)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AppFrameworkSpec) DeepCopyInto(out *AppFrameworkSpec) {
+	*out = *in
+	out.Defaults = in.Defaults
+	if in.VolList != nil {
+		in, out := &in.VolList, &out.VolList
+		*out = make([]VolumeSpec, len(*in))
+		copy(*out, *in)
+	}
+	if in.AppSources != nil {
+		in, out := &in.AppSources, &out.AppSources
+		*out = make([]AppSourceSpec, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AppFrameworkSpec.
+func (in *AppFrameworkSpec) DeepCopy() *AppFrameworkSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(AppFrameworkSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AppSourceDefaultSpec) DeepCopyInto(out *AppSourceDefaultSpec) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AppSourceDefaultSpec.
+func (in *AppSourceDefaultSpec) DeepCopy() *AppSourceDefaultSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(AppSourceDefaultSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AppSourceSpec) DeepCopyInto(out *AppSourceSpec) {
+	*out = *in
+	out.AppSourceDefaultSpec = in.AppSourceDefaultSpec
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AppSourceSpec.
+func (in *AppSourceSpec) DeepCopy() *AppSourceSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(AppSourceSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BundlePeerStatus) DeepCopyInto(out *BundlePeerStatus) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BundlePeerStatus.
+func (in *BundlePeerStatus) DeepCopy() *BundlePeerStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(BundlePeerStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *BundlePushInfo) DeepCopyInto(out *BundlePushInfo) {
 	*out = *in
+	if in.Errors != nil {
+		in, out := &in.Errors, &out.Errors
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Peers != nil {
+		in, out := &in.Peers, &out.Peers
+		*out = make([]BundlePeerStatus, len(*in))
+		copy(*out, *in)
+	}
 	return
 }
 
@@ -25,6 +155,22 @@ func (in *BundlePushInfo) DeepCopy() *BundlePushInfo {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CABundleSpec) DeepCopyInto(out *CABundleSpec) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CABundleSpec.
+func (in *CABundleSpec) DeepCopy() *CABundleSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(CABundleSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CacheManagerSpec) DeepCopyInto(out *CacheManagerSpec) {
 	*out = *in
@@ -42,6 +188,22 @@ func (in *CacheManagerSpec) DeepCopy() *CacheManagerSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CaptaincyTransferInfo) DeepCopyInto(out *CaptaincyTransferInfo) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CaptaincyTransferInfo.
+func (in *CaptaincyTransferInfo) DeepCopy() *CaptaincyTransferInfo {
+	if in == nil {
+		return nil
+	}
+	out := new(CaptaincyTransferInfo)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ClusterMaster) DeepCopyInto(out *ClusterMaster) {
 	*out = *in
@@ -96,7 +258,316 @@ func (in *ClusterMasterList) DeepCopy() *ClusterMasterList {
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *ClusterMasterList) DeepCopyObject() runtime.Object {
+func (in *ClusterMasterList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterMasterSpec) DeepCopyInto(out *ClusterMasterSpec) {
+	*out = *in
+	in.CommonSplunkSpec.DeepCopyInto(&out.CommonSplunkSpec)
+	in.SmartStore.DeepCopyInto(&out.SmartStore)
+	in.Multisite.DeepCopyInto(&out.Multisite)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterMasterSpec.
+func (in *ClusterMasterSpec) DeepCopy() *ClusterMasterSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterMasterSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterMasterStatus) DeepCopyInto(out *ClusterMasterStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	in.SmartStore.DeepCopyInto(&out.SmartStore)
+	if in.SmartStoreVolumes != nil {
+		in, out := &in.SmartStoreVolumes, &out.SmartStoreVolumes
+		*out = make([]RemoteVolumeStatus, len(*in))
+		copy(*out, *in)
+	}
+	in.BundlePushTracker.DeepCopyInto(&out.BundlePushTracker)
+	if in.ResourceRevMap != nil {
+		in, out := &in.ResourceRevMap, &out.ResourceRevMap
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	in.AppContext.DeepCopyInto(&out.AppContext)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterMasterStatus.
+func (in *ClusterMasterStatus) DeepCopy() *ClusterMasterStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterMasterStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CommonSplunkSpec) DeepCopyInto(out *CommonSplunkSpec) {
+	*out = *in
+	in.Spec.DeepCopyInto(&out.Spec)
+	out.EtcVolumeStorageConfig = in.EtcVolumeStorageConfig
+	out.VarVolumeStorageConfig = in.VarVolumeStorageConfig
+	if in.Volumes != nil {
+		in, out := &in.Volumes, &out.Volumes
+		*out = make([]corev1.Volume, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	out.LicenseMasterRef = in.LicenseMasterRef
+	out.ClusterMasterRef = in.ClusterMasterRef
+	out.MonitoringConsoleRef = in.MonitoringConsoleRef
+	if in.ExtraEnv != nil {
+		in, out := &in.ExtraEnv, &out.ExtraEnv
+		*out = make([]corev1.EnvVar, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	in.AppFrameworkConfig.DeepCopyInto(&out.AppFrameworkConfig)
+	in.PodDisruptionBudget.DeepCopyInto(&out.PodDisruptionBudget)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CommonSplunkSpec.
+func (in *CommonSplunkSpec) DeepCopy() *CommonSplunkSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(CommonSplunkSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Condition) DeepCopyInto(out *Condition) {
+	*out = *in
+	in.LastTransitionTime.DeepCopyInto(&out.LastTransitionTime)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Condition.
+func (in *Condition) DeepCopy() *Condition {
+	if in == nil {
+		return nil
+	}
+	out := new(Condition)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DeploymentClientStatus) DeepCopyInto(out *DeploymentClientStatus) {
+	*out = *in
+	if in.ServerClasses != nil {
+		in, out := &in.ServerClasses, &out.ServerClasses
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeploymentClientStatus.
+func (in *DeploymentClientStatus) DeepCopy() *DeploymentClientStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(DeploymentClientStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DeploymentServer) DeepCopyInto(out *DeploymentServer) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeploymentServer.
+func (in *DeploymentServer) DeepCopy() *DeploymentServer {
+	if in == nil {
+		return nil
+	}
+	out := new(DeploymentServer)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *DeploymentServer) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DeploymentServerList) DeepCopyInto(out *DeploymentServerList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]DeploymentServer, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeploymentServerList.
+func (in *DeploymentServerList) DeepCopy() *DeploymentServerList {
+	if in == nil {
+		return nil
+	}
+	out := new(DeploymentServerList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *DeploymentServerList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DeploymentServerSpec) DeepCopyInto(out *DeploymentServerSpec) {
+	*out = *in
+	in.CommonSplunkSpec.DeepCopyInto(&out.CommonSplunkSpec)
+	if in.ServerClasses != nil {
+		in, out := &in.ServerClasses, &out.ServerClasses
+		*out = make([]ServerClassSpec, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeploymentServerSpec.
+func (in *DeploymentServerSpec) DeepCopy() *DeploymentServerSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(DeploymentServerSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DeploymentServerStatus) DeepCopyInto(out *DeploymentServerStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Clients != nil {
+		in, out := &in.Clients, &out.Clients
+		*out = make([]DeploymentClientStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeploymentServerStatus.
+func (in *DeploymentServerStatus) DeepCopy() *DeploymentServerStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(DeploymentServerStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HeavyForwarder) DeepCopyInto(out *HeavyForwarder) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HeavyForwarder.
+func (in *HeavyForwarder) DeepCopy() *HeavyForwarder {
+	if in == nil {
+		return nil
+	}
+	out := new(HeavyForwarder)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *HeavyForwarder) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HeavyForwarderList) DeepCopyInto(out *HeavyForwarderList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]HeavyForwarder, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HeavyForwarderList.
+func (in *HeavyForwarderList) DeepCopy() *HeavyForwarderList {
+	if in == nil {
+		return nil
+	}
+	out := new(HeavyForwarderList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *HeavyForwarderList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -104,66 +575,33 @@ func (in *ClusterMasterList) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ClusterMasterSpec) DeepCopyInto(out *ClusterMasterSpec) {
+func (in *HeavyForwarderSpec) DeepCopyInto(out *HeavyForwarderSpec) {
 	*out = *in
 	in.CommonSplunkSpec.DeepCopyInto(&out.CommonSplunkSpec)
-	in.SmartStore.DeepCopyInto(&out.SmartStore)
-	return
-}
-
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterMasterSpec.
-func (in *ClusterMasterSpec) DeepCopy() *ClusterMasterSpec {
-	if in == nil {
-		return nil
-	}
-	out := new(ClusterMasterSpec)
-	in.DeepCopyInto(out)
-	return out
-}
-
-// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ClusterMasterStatus) DeepCopyInto(out *ClusterMasterStatus) {
-	*out = *in
-	in.SmartStore.DeepCopyInto(&out.SmartStore)
-	out.BundlePushTracker = in.BundlePushTracker
-	if in.ResourceRevMap != nil {
-		in, out := &in.ResourceRevMap, &out.ResourceRevMap
-		*out = make(map[string]string, len(*in))
-		for key, val := range *in {
-			(*out)[key] = val
-		}
+	if in.IndexerTargets != nil {
+		in, out := &in.IndexerTargets, &out.IndexerTargets
+		*out = make([]string, len(*in))
+		copy(*out, *in)
 	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterMasterStatus.
-func (in *ClusterMasterStatus) DeepCopy() *ClusterMasterStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HeavyForwarderSpec.
+func (in *HeavyForwarderSpec) DeepCopy() *HeavyForwarderSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(ClusterMasterStatus)
+	out := new(HeavyForwarderSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *CommonSplunkSpec) DeepCopyInto(out *CommonSplunkSpec) {
+func (in *HeavyForwarderStatus) DeepCopyInto(out *HeavyForwarderStatus) {
 	*out = *in
-	in.Spec.DeepCopyInto(&out.Spec)
-	out.EtcVolumeStorageConfig = in.EtcVolumeStorageConfig
-	out.VarVolumeStorageConfig = in.VarVolumeStorageConfig
-	if in.Volumes != nil {
-		in, out := &in.Volumes, &out.Volumes
-		*out = make([]corev1.Volume, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
-	}
-	out.LicenseMasterRef = in.LicenseMasterRef
-	out.ClusterMasterRef = in.ClusterMasterRef
-	if in.ExtraEnv != nil {
-		in, out := &in.ExtraEnv, &out.ExtraEnv
-		*out = make([]corev1.EnvVar, len(*in))
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]Condition, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
@@ -171,12 +609,12 @@ func (in *CommonSplunkSpec) DeepCopyInto(out *CommonSplunkSpec) {
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CommonSplunkSpec.
-func (in *CommonSplunkSpec) DeepCopy() *CommonSplunkSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HeavyForwarderStatus.
+func (in *HeavyForwarderStatus) DeepCopy() *HeavyForwarderStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(CommonSplunkSpec)
+	out := new(HeavyForwarderStatus)
 	in.DeepCopyInto(out)
 	return out
 }
@@ -325,6 +763,37 @@ func (in *IndexerClusterMemberStatus) DeepCopy() *IndexerClusterMemberStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IndexerClusterRestartStatus) DeepCopyInto(out *IndexerClusterRestartStatus) {
+	*out = *in
+	if in.Done != nil {
+		in, out := &in.Done, &out.Done
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Pending != nil {
+		in, out := &in.Pending, &out.Pending
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Failed != nil {
+		in, out := &in.Failed, &out.Failed
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IndexerClusterRestartStatus.
+func (in *IndexerClusterRestartStatus) DeepCopy() *IndexerClusterRestartStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(IndexerClusterRestartStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *IndexerClusterSpec) DeepCopyInto(out *IndexerClusterSpec) {
 	*out = *in
@@ -345,6 +814,13 @@ func (in *IndexerClusterSpec) DeepCopy() *IndexerClusterSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *IndexerClusterStatus) DeepCopyInto(out *IndexerClusterStatus) {
 	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	if in.IndexerSecretChanged != nil {
 		in, out := &in.IndexerSecretChanged, &out.IndexerSecretChanged
 		*out = make([]bool, len(*in))
@@ -357,6 +833,7 @@ func (in *IndexerClusterStatus) DeepCopyInto(out *IndexerClusterStatus) {
 			(*out)[key] = val
 		}
 	}
+	in.RollingRestart.DeepCopyInto(&out.RollingRestart)
 	if in.Peers != nil {
 		in, out := &in.Peers, &out.Peers
 		*out = make([]IndexerClusterMemberStatus, len(*in))
@@ -381,7 +858,7 @@ func (in *LicenseMaster) DeepCopyInto(out *LicenseMaster) {
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
-	out.Status = in.Status
+	in.Status.DeepCopyInto(&out.Status)
 	return
 }
 
@@ -456,6 +933,13 @@ func (in *LicenseMasterSpec) DeepCopy() *LicenseMasterSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *LicenseMasterStatus) DeepCopyInto(out *LicenseMasterStatus) {
 	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	return
 }
 
@@ -469,6 +953,200 @@ func (in *LicenseMasterStatus) DeepCopy() *LicenseMasterStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MonitoringConsole) DeepCopyInto(out *MonitoringConsole) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MonitoringConsole.
+func (in *MonitoringConsole) DeepCopy() *MonitoringConsole {
+	if in == nil {
+		return nil
+	}
+	out := new(MonitoringConsole)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *MonitoringConsole) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MonitoringConsoleList) DeepCopyInto(out *MonitoringConsoleList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]MonitoringConsole, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MonitoringConsoleList.
+func (in *MonitoringConsoleList) DeepCopy() *MonitoringConsoleList {
+	if in == nil {
+		return nil
+	}
+	out := new(MonitoringConsoleList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *MonitoringConsoleList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MonitoringConsolePeerStatus) DeepCopyInto(out *MonitoringConsolePeerStatus) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MonitoringConsolePeerStatus.
+func (in *MonitoringConsolePeerStatus) DeepCopy() *MonitoringConsolePeerStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(MonitoringConsolePeerStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MonitoringConsoleSpec) DeepCopyInto(out *MonitoringConsoleSpec) {
+	*out = *in
+	in.CommonSplunkSpec.DeepCopyInto(&out.CommonSplunkSpec)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MonitoringConsoleSpec.
+func (in *MonitoringConsoleSpec) DeepCopy() *MonitoringConsoleSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(MonitoringConsoleSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MonitoringConsoleStatus) DeepCopyInto(out *MonitoringConsoleStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Peers != nil {
+		in, out := &in.Peers, &out.Peers
+		*out = make([]MonitoringConsolePeerStatus, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MonitoringConsoleStatus.
+func (in *MonitoringConsoleStatus) DeepCopy() *MonitoringConsoleStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(MonitoringConsoleStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MultisiteSpec) DeepCopyInto(out *MultisiteSpec) {
+	*out = *in
+	if in.Sites != nil {
+		in, out := &in.Sites, &out.Sites
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	out.SiteReplicationFactor = in.SiteReplicationFactor
+	out.SiteSearchFactor = in.SiteSearchFactor
+	if in.SiteZones != nil {
+		in, out := &in.SiteZones, &out.SiteZones
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MultisiteSpec.
+func (in *MultisiteSpec) DeepCopy() *MultisiteSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(MultisiteSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PodDisruptionBudgetSpec) DeepCopyInto(out *PodDisruptionBudgetSpec) {
+	*out = *in
+	if in.MinAvailable != nil {
+		in, out := &in.MinAvailable, &out.MinAvailable
+		*out = new(intstr.IntOrString)
+		**out = **in
+	}
+	if in.MaxUnavailable != nil {
+		in, out := &in.MaxUnavailable, &out.MaxUnavailable
+		*out = new(intstr.IntOrString)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PodDisruptionBudgetSpec.
+func (in *PodDisruptionBudgetSpec) DeepCopy() *PodDisruptionBudgetSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(PodDisruptionBudgetSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RemoteVolumeStatus) DeepCopyInto(out *RemoteVolumeStatus) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RemoteVolumeStatus.
+func (in *RemoteVolumeStatus) DeepCopy() *RemoteVolumeStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(RemoteVolumeStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *SearchHeadCluster) DeepCopyInto(out *SearchHeadCluster) {
 	*out = *in
@@ -550,6 +1228,7 @@ func (in *SearchHeadClusterMemberStatus) DeepCopy() *SearchHeadClusterMemberStat
 func (in *SearchHeadClusterSpec) DeepCopyInto(out *SearchHeadClusterSpec) {
 	*out = *in
 	in.CommonSplunkSpec.DeepCopyInto(&out.CommonSplunkSpec)
+	in.DeployerPodDisruptionBudget.DeepCopyInto(&out.DeployerPodDisruptionBudget)
 	return
 }
 
@@ -566,6 +1245,14 @@ func (in *SearchHeadClusterSpec) DeepCopy() *SearchHeadClusterSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *SearchHeadClusterStatus) DeepCopyInto(out *SearchHeadClusterStatus) {
 	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	out.CaptaincyTransfer = in.CaptaincyTransfer
 	if in.ShcSecretChanged != nil {
 		in, out := &in.ShcSecretChanged, &out.ShcSecretChanged
 		*out = make([]bool, len(*in))
@@ -588,6 +1275,7 @@ func (in *SearchHeadClusterStatus) DeepCopyInto(out *SearchHeadClusterStatus) {
 		*out = make([]SearchHeadClusterMemberStatus, len(*in))
 		copy(*out, *in)
 	}
+	in.AppContext.DeepCopyInto(&out.AppContext)
 	return
 }
 
@@ -601,6 +1289,53 @@ func (in *SearchHeadClusterStatus) DeepCopy() *SearchHeadClusterStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ServerClassSpec) DeepCopyInto(out *ServerClassSpec) {
+	*out = *in
+	if in.Whitelist != nil {
+		in, out := &in.Whitelist, &out.Whitelist
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Blacklist != nil {
+		in, out := &in.Blacklist, &out.Blacklist
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Apps != nil {
+		in, out := &in.Apps, &out.Apps
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ServerClassSpec.
+func (in *ServerClassSpec) DeepCopy() *ServerClassSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ServerClassSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SiteFactorSpec) DeepCopyInto(out *SiteFactorSpec) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SiteFactorSpec.
+func (in *SiteFactorSpec) DeepCopy() *SiteFactorSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(SiteFactorSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *SmartStoreSpec) DeepCopyInto(out *SmartStoreSpec) {
 	*out = *in
@@ -711,7 +1446,19 @@ func (in *StandaloneSpec) DeepCopy() *StandaloneSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *StandaloneStatus) DeepCopyInto(out *StandaloneStatus) {
 	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	in.SmartStore.DeepCopyInto(&out.SmartStore)
+	if in.SmartStoreVolumes != nil {
+		in, out := &in.SmartStoreVolumes, &out.SmartStoreVolumes
+		*out = make([]RemoteVolumeStatus, len(*in))
+		copy(*out, *in)
+	}
 	if in.ResourceRevMap != nil {
 		in, out := &in.ResourceRevMap, &out.ResourceRevMap
 		*out = make(map[string]string, len(*in))
@@ -719,6 +1466,7 @@ func (in *StandaloneStatus) DeepCopyInto(out *StandaloneStatus) {
 			(*out)[key] = val
 		}
 	}
+	in.AppContext.DeepCopyInto(&out.AppContext)
 	return
 }
 
@@ -751,6 +1499,7 @@ func (in *StorageClassSpec) DeepCopy() *StorageClassSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *VolumeSpec) DeepCopyInto(out *VolumeSpec) {
 	*out = *in
+	out.CABundle = in.CABundle
 	return
 }
 
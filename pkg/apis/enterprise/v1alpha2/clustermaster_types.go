@@ -0,0 +1,64 @@
+// Copyright (c) 2018-2021 Splunk Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1alpha2
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	splcommon "github.com/splunk/splunk-operator/pkg/splunk/common"
+)
+
+// default all fields to being optional
+// +kubebuilder:validation:Optional
+
+// ClusterMasterSpec defines the desired state of a Splunk Enterprise cluster master.
+type ClusterMasterSpec struct {
+	CommonSplunkSpec `json:",inline"`
+}
+
+// ClusterMasterStatus defines the observed state of a Splunk Enterprise cluster master.
+type ClusterMasterStatus struct {
+	// current phase of the cluster master
+	Phase splcommon.Phase `json:"phase"`
+
+	// selector for pods, used by HorizontalPodAutoscaler
+	Selector string `json:"selector"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// ClusterMaster is the Schema for a Splunk Enterprise cluster master.
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:path=clustermasters,scope=Namespaced,shortName=cm-idxc
+type ClusterMaster struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ClusterMasterSpec   `json:"spec,omitempty"`
+	Status ClusterMasterStatus `json:"status,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// ClusterMasterList contains a list of ClusterMaster
+type ClusterMasterList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ClusterMaster `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&ClusterMaster{}, &ClusterMasterList{})
+}
@@ -0,0 +1,92 @@
+// Copyright (c) 2018-2021 Splunk Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1alpha3
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	enterprisev1 "github.com/splunk/splunk-operator/pkg/apis/enterprise/v1"
+	splcommon "github.com/splunk/splunk-operator/pkg/splunk/common"
+)
+
+// default all fields to being optional
+// +kubebuilder:validation:Optional
+
+// IndexerClusterSpec defines the desired state of a Splunk Enterprise indexer cluster
+type IndexerClusterSpec struct {
+	CommonSplunkSpec `json:",inline"`
+
+	// Number of indexer pods
+	Replicas int32 `json:"replicas"`
+}
+
+// IndexerClusterStatus defines the observed state of a Splunk Enterprise indexer cluster
+type IndexerClusterStatus struct {
+	// current phase of the indexer cluster
+	Phase splcommon.Phase `json:"phase"`
+
+	// current phase of the cluster master
+	ClusterMasterPhase splcommon.Phase `json:"clusterMasterPhase"`
+
+	// desired number of indexer peers
+	Replicas int32 `json:"replicas"`
+
+	// current number of ready indexer peers
+	ReadyReplicas int32 `json:"readyReplicas"`
+
+	// selector for pods, used by HorizontalPodAutoscaler
+	Selector string `json:"selector"`
+
+	// Indicates if the cluster is initialized.
+	Initialized bool `json:"initialized_flag"`
+
+	// Indicates if the cluster is ready for indexing.
+	IndexingReady bool `json:"indexing_ready_flag"`
+
+	// Indicates whether the master is ready to begin servicing, based on whether it is initialized.
+	ServiceReady bool `json:"service_ready_flag"`
+
+	// Indicates if the cluster is in maintenance mode.
+	MaintenanceMode bool `json:"maintenance_mode"`
+
+	// status of each indexer cluster peer
+	Peers []enterprisev1.IndexerClusterMemberStatus `json:"peers"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// IndexerCluster is the Schema for a Splunk Enterprise indexer cluster
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:path=indexerclusters,scope=Namespaced,shortName=idc;idxc
+type IndexerCluster struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   IndexerClusterSpec   `json:"spec,omitempty"`
+	Status IndexerClusterStatus `json:"status,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// IndexerClusterList contains a list of IndexerCluster
+type IndexerClusterList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []IndexerCluster `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&IndexerCluster{}, &IndexerClusterList{})
+}
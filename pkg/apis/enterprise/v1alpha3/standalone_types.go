@@ -0,0 +1,76 @@
+// Copyright (c) 2018-2021 Splunk Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1alpha3
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	splcommon "github.com/splunk/splunk-operator/pkg/splunk/common"
+)
+
+// default all fields to being optional
+// +kubebuilder:validation:Optional
+
+// StandaloneSpec defines the desired state of a Splunk Enterprise standalone instances.
+type StandaloneSpec struct {
+	CommonSplunkSpec `json:",inline"`
+
+	// Number of standalone pods
+	Replicas int32 `json:"replicas"`
+
+	// Image to use for Spark pod containers (overrides RELATED_IMAGE_SPLUNK_SPARK environment variables)
+	SparkImage string `json:"sparkImage"`
+}
+
+// StandaloneStatus defines the observed state of a Splunk Enterprise standalone instances.
+type StandaloneStatus struct {
+	// current phase of the standalone instances
+	Phase splcommon.Phase `json:"phase"`
+
+	// number of desired standalone instances
+	Replicas int32 `json:"replicas"`
+
+	// current number of ready standalone instances
+	ReadyReplicas int32 `json:"readyReplicas"`
+
+	// selector for pods, used by HorizontalPodAutoscaler
+	Selector string `json:"selector"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// Standalone is the Schema for a Splunk Enterprise standalone instances.
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:path=standalones,scope=Namespaced,shortName=stdaln
+type Standalone struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   StandaloneSpec   `json:"spec,omitempty"`
+	Status StandaloneStatus `json:"status,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// StandaloneList contains a list of Standalone
+type StandaloneList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []Standalone `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&Standalone{}, &StandaloneList{})
+}
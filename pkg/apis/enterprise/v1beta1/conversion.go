@@ -0,0 +1,149 @@
+// Copyright (c) 2018-2021 Splunk Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1beta1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/conversion"
+
+	"github.com/splunk/splunk-operator/pkg/apis/enterprise/legacy"
+	enterprisev1 "github.com/splunk/splunk-operator/pkg/apis/enterprise/v1"
+)
+
+// blank assignments to verify that all custom resources of this version can be converted to and from v1
+var (
+	_ conversion.Convertible = &ClusterMaster{}
+	_ conversion.Convertible = &IndexerCluster{}
+	_ conversion.Convertible = &LicenseMaster{}
+	_ conversion.Convertible = &SearchHeadCluster{}
+	_ conversion.Convertible = &Standalone{}
+)
+
+// legacySpec holds the spec fields of this version that were removed in v1
+type legacySpec struct {
+	SparkRef   *corev1.ObjectReference `json:"sparkRef,omitempty"`
+	SparkImage string                  `json:"sparkImage,omitempty"`
+}
+
+// convertTo converts src to v1, and preserves the spec fields of this version that were removed in v1 in the annotations of dst
+func convertTo(src, dst legacy.Object, srcCommon *CommonSplunkSpec, dstCommon *enterprisev1.CommonSplunkSpec, sparkImage string) error {
+	if err := legacy.ConvertTo(src, dst, dstCommon); err != nil {
+		return err
+	}
+
+	spec := legacySpec{SparkImage: sparkImage}
+	if srcCommon.SparkRef != (corev1.ObjectReference{}) {
+		spec.SparkRef = srcCommon.SparkRef.DeepCopy()
+	}
+	if spec == (legacySpec{}) {
+		return nil
+	}
+	return legacy.SetLegacySpec(dst.Meta, &spec)
+}
+
+// convertFrom converts src from v1, and restores the spec fields of this version that were removed in v1 from the annotations of src
+func convertFrom(src, dst legacy.Object, srcCommon *enterprisev1.CommonSplunkSpec, dstCommon *CommonSplunkSpec, sparkImage *string) error {
+	spec := legacySpec{}
+	if err := legacy.ConvertFrom(src, dst, srcCommon, &spec); err != nil {
+		return err
+	}
+	if spec.SparkRef != nil {
+		dstCommon.SparkRef = *spec.SparkRef
+	}
+	if sparkImage != nil {
+		*sparkImage = spec.SparkImage
+	}
+	return nil
+}
+
+// ConvertTo converts this Standalone to the hub version (v1)
+func (src *Standalone) ConvertTo(dstRaw conversion.Hub) error {
+	dst := dstRaw.(*enterprisev1.Standalone)
+	return convertTo(legacy.Object{Meta: &src.ObjectMeta, Spec: &src.Spec, Status: &src.Status},
+		legacy.Object{Meta: &dst.ObjectMeta, Spec: &dst.Spec, Status: &dst.Status},
+		&src.Spec.CommonSplunkSpec, &dst.Spec.CommonSplunkSpec, src.Spec.SparkImage)
+}
+
+// ConvertFrom converts a Standalone from the hub version (v1) to this version
+func (dst *Standalone) ConvertFrom(srcRaw conversion.Hub) error {
+	src := srcRaw.(*enterprisev1.Standalone)
+	return convertFrom(legacy.Object{Meta: &src.ObjectMeta, Spec: &src.Spec, Status: &src.Status},
+		legacy.Object{Meta: &dst.ObjectMeta, Spec: &dst.Spec, Status: &dst.Status},
+		&src.Spec.CommonSplunkSpec, &dst.Spec.CommonSplunkSpec, &dst.Spec.SparkImage)
+}
+
+// ConvertTo converts this SearchHeadCluster to the hub version (v1)
+func (src *SearchHeadCluster) ConvertTo(dstRaw conversion.Hub) error {
+	dst := dstRaw.(*enterprisev1.SearchHeadCluster)
+	return convertTo(legacy.Object{Meta: &src.ObjectMeta, Spec: &src.Spec, Status: &src.Status},
+		legacy.Object{Meta: &dst.ObjectMeta, Spec: &dst.Spec, Status: &dst.Status},
+		&src.Spec.CommonSplunkSpec, &dst.Spec.CommonSplunkSpec, src.Spec.SparkImage)
+}
+
+// ConvertFrom converts a SearchHeadCluster from the hub version (v1) to this version
+func (dst *SearchHeadCluster) ConvertFrom(srcRaw conversion.Hub) error {
+	src := srcRaw.(*enterprisev1.SearchHeadCluster)
+	return convertFrom(legacy.Object{Meta: &src.ObjectMeta, Spec: &src.Spec, Status: &src.Status},
+		legacy.Object{Meta: &dst.ObjectMeta, Spec: &dst.Spec, Status: &dst.Status},
+		&src.Spec.CommonSplunkSpec, &dst.Spec.CommonSplunkSpec, &dst.Spec.SparkImage)
+}
+
+// ConvertTo converts this IndexerCluster to the hub version (v1)
+func (src *IndexerCluster) ConvertTo(dstRaw conversion.Hub) error {
+	dst := dstRaw.(*enterprisev1.IndexerCluster)
+	return convertTo(legacy.Object{Meta: &src.ObjectMeta, Spec: &src.Spec, Status: &src.Status},
+		legacy.Object{Meta: &dst.ObjectMeta, Spec: &dst.Spec, Status: &dst.Status},
+		&src.Spec.CommonSplunkSpec, &dst.Spec.CommonSplunkSpec, "")
+}
+
+// ConvertFrom converts an IndexerCluster from the hub version (v1) to this version
+func (dst *IndexerCluster) ConvertFrom(srcRaw conversion.Hub) error {
+	src := srcRaw.(*enterprisev1.IndexerCluster)
+	return convertFrom(legacy.Object{Meta: &src.ObjectMeta, Spec: &src.Spec, Status: &src.Status},
+		legacy.Object{Meta: &dst.ObjectMeta, Spec: &dst.Spec, Status: &dst.Status},
+		&src.Spec.CommonSplunkSpec, &dst.Spec.CommonSplunkSpec, nil)
+}
+
+// ConvertTo converts this ClusterMaster to the hub version (v1)
+func (src *ClusterMaster) ConvertTo(dstRaw conversion.Hub) error {
+	dst := dstRaw.(*enterprisev1.ClusterMaster)
+	return convertTo(legacy.Object{Meta: &src.ObjectMeta, Spec: &src.Spec, Status: &src.Status},
+		legacy.Object{Meta: &dst.ObjectMeta, Spec: &dst.Spec, Status: &dst.Status},
+		&src.Spec.CommonSplunkSpec, &dst.Spec.CommonSplunkSpec, "")
+}
+
+// ConvertFrom converts a ClusterMaster from the hub version (v1) to this version
+func (dst *ClusterMaster) ConvertFrom(srcRaw conversion.Hub) error {
+	src := srcRaw.(*enterprisev1.ClusterMaster)
+	return convertFrom(legacy.Object{Meta: &src.ObjectMeta, Spec: &src.Spec, Status: &src.Status},
+		legacy.Object{Meta: &dst.ObjectMeta, Spec: &dst.Spec, Status: &dst.Status},
+		&src.Spec.CommonSplunkSpec, &dst.Spec.CommonSplunkSpec, nil)
+}
+
+// ConvertTo converts this LicenseMaster to the hub version (v1)
+func (src *LicenseMaster) ConvertTo(dstRaw conversion.Hub) error {
+	dst := dstRaw.(*enterprisev1.LicenseMaster)
+	return convertTo(legacy.Object{Meta: &src.ObjectMeta, Spec: &src.Spec, Status: &src.Status},
+		legacy.Object{Meta: &dst.ObjectMeta, Spec: &dst.Spec, Status: &dst.Status},
+		&src.Spec.CommonSplunkSpec, &dst.Spec.CommonSplunkSpec, "")
+}
+
+// ConvertFrom converts a LicenseMaster from the hub version (v1) to this version
+func (dst *LicenseMaster) ConvertFrom(srcRaw conversion.Hub) error {
+	src := srcRaw.(*enterprisev1.LicenseMaster)
+	return convertFrom(legacy.Object{Meta: &src.ObjectMeta, Spec: &src.Spec, Status: &src.Status},
+		legacy.Object{Meta: &dst.ObjectMeta, Spec: &dst.Spec, Status: &dst.Status},
+		&src.Spec.CommonSplunkSpec, &dst.Spec.CommonSplunkSpec, nil)
+}
@@ -0,0 +1,98 @@
+// Copyright (c) 2018-2021 Splunk Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1beta1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	enterprisev1 "github.com/splunk/splunk-operator/pkg/apis/enterprise/v1"
+	splcommon "github.com/splunk/splunk-operator/pkg/splunk/common"
+)
+
+// default all fields to being optional
+// +kubebuilder:validation:Optional
+
+// SearchHeadClusterSpec defines the desired state of a Splunk Enterprise search head cluster
+type SearchHeadClusterSpec struct {
+	CommonSplunkSpec `json:",inline"`
+
+	// Number of search head pods; a search head cluster will be created if > 1
+	Replicas int32 `json:"replicas"`
+
+	// Image to use for Spark pod containers (overrides RELATED_IMAGE_SPLUNK_SPARK environment variables)
+	SparkImage string `json:"sparkImage"`
+}
+
+// SearchHeadClusterStatus defines the observed state of a Splunk Enterprise search head cluster
+type SearchHeadClusterStatus struct {
+	// current phase of the search head cluster
+	Phase splcommon.Phase `json:"phase"`
+
+	// current phase of the deployer
+	DeployerPhase splcommon.Phase `json:"deployerPhase"`
+
+	// desired number of search head cluster members
+	Replicas int32 `json:"replicas"`
+
+	// current number of ready search head cluster members
+	ReadyReplicas int32 `json:"readyReplicas"`
+
+	// selector for pods, used by HorizontalPodAutoscaler
+	Selector string `json:"selector"`
+
+	// name or label of the search head captain
+	Captain string `json:"captain"`
+
+	// true if the search head cluster's captain is ready to service requests
+	CaptainReady bool `json:"captainReady"`
+
+	// true if the search head cluster has finished initialization
+	Initialized bool `json:"initialized"`
+
+	// true if the minimum number of search head cluster members have joined
+	MinPeersJoined bool `json:"minPeersJoined"`
+
+	// true if the search head cluster is in maintenance mode
+	MaintenanceMode bool `json:"maintenanceMode"`
+
+	// status of each search head cluster member
+	Members []enterprisev1.SearchHeadClusterMemberStatus `json:"members"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// SearchHeadCluster is the Schema for a Splunk Enterprise search head cluster
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:path=searchheadclusters,scope=Namespaced,shortName=shc
+type SearchHeadCluster struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   SearchHeadClusterSpec   `json:"spec,omitempty"`
+	Status SearchHeadClusterStatus `json:"status,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// SearchHeadClusterList contains a list of SearchHeadCluster
+type SearchHeadClusterList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []SearchHeadCluster `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&SearchHeadCluster{}, &SearchHeadClusterList{})
+}
@@ -21,8 +21,10 @@ import (
 	enterprise "github.com/splunk/splunk-operator/pkg/splunk/enterprise"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	policyv1beta1 "k8s.io/api/policy/v1beta1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 )
@@ -49,11 +51,11 @@ func (ctrl ClusterMasterController) GetInstance() splcommon.MetaObject {
 
 // GetWatchTypes returns a list of types owned by the controller that it would like to receive watch events for
 func (ctrl ClusterMasterController) GetWatchTypes() []runtime.Object {
-	return []runtime.Object{&appsv1.StatefulSet{}, &corev1.Secret{}}
+	return []runtime.Object{&appsv1.StatefulSet{}, &corev1.Secret{}, &policyv1beta1.PodDisruptionBudget{}}
 }
 
 // Reconcile is used to perform an idempotent reconciliation of the custom resource managed by this controller
-func (ctrl ClusterMasterController) Reconcile(client client.Client, cr splcommon.MetaObject) (reconcile.Result, error) {
+func (ctrl ClusterMasterController) Reconcile(client client.Client, recorder record.EventRecorder, cr splcommon.MetaObject) (reconcile.Result, error) {
 	instance := cr.(*enterprisev1.ClusterMaster)
-	return enterprise.ApplyClusterMaster(client, instance)
+	return enterprise.ApplyClusterMaster(client, recorder, instance)
 }
@@ -17,8 +17,10 @@ package controller
 import (
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	policyv1beta1 "k8s.io/api/policy/v1beta1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
@@ -50,11 +52,11 @@ func (ctrl IndexerClusterController) GetInstance() splcommon.MetaObject {
 
 // GetWatchTypes returns a list of types owned by the controller that it would like to receive watch events for
 func (ctrl IndexerClusterController) GetWatchTypes() []runtime.Object {
-	return []runtime.Object{&appsv1.StatefulSet{}, &corev1.Secret{}}
+	return []runtime.Object{&appsv1.StatefulSet{}, &corev1.Secret{}, &policyv1beta1.PodDisruptionBudget{}}
 }
 
 // Reconcile is used to perform an idempotent reconciliation of the custom resource managed by this controller
-func (ctrl IndexerClusterController) Reconcile(client client.Client, cr splcommon.MetaObject) (reconcile.Result, error) {
+func (ctrl IndexerClusterController) Reconcile(client client.Client, recorder record.EventRecorder, cr splcommon.MetaObject) (reconcile.Result, error) {
 	instance := cr.(*enterprisev1.IndexerCluster)
-	return enterprise.ApplyIndexerCluster(client, instance)
+	return enterprise.ApplyIndexerCluster(client, recorder, instance)
 }
@@ -14,6 +14,7 @@
 
 /*
 Package client provides a simple client for the Splunk Enterprise REST API.
-This package has no depedencies outside of the standard go library.
+This package has no depedencies outside of the standard go library, other than splunk.metrics
+which is used to record the latency of requests.
 */
 package client
@@ -20,12 +20,15 @@ import (
 	"fmt"
 	"io/ioutil"
 	"net/http"
+	"net/url"
 	"regexp"
 	"strconv"
 	"strings"
 	"time"
 
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	splmetrics "github.com/splunk/splunk-operator/pkg/splunk/metrics"
 )
 
 // kubernetes logger used by splunk.enterprise package
@@ -67,11 +70,11 @@ func NewSplunkClient(managementURI, username, password string) *SplunkClient {
 	}
 }
 
-// Do processes a Splunk REST API request and unmarshals response into obj, if not nil.
-func (c *SplunkClient) Do(request *http.Request, expectedStatus []int, obj interface{}) error {
+// Do processes a Splunk REST API request and unmarshals response into obj, if not nil. The request is recorded in
+// the metrics with methodName, the name of the SplunkClient method sending it.
+func (c *SplunkClient) Do(methodName string, request *http.Request, expectedStatus []int, obj interface{}) error {
 	// send HTTP response and check status
-	request.SetBasicAuth(c.Username, c.Password)
-	response, err := c.Client.Do(request)
+	response, err := c.send(methodName, request)
 	if err != nil {
 		return err
 	}
@@ -98,15 +101,74 @@ func (c *SplunkClient) Do(request *http.Request, expectedStatus []int, obj inter
 	return json.Unmarshal(data, obj)
 }
 
-// Get sends a REST API request and unmarshals response into obj, if not nil.
-func (c *SplunkClient) Get(path string, obj interface{}) error {
+// send authenticates and sends a Splunk REST API request, recording its latency and status code
+func (c *SplunkClient) send(methodName string, request *http.Request) (*http.Response, error) {
+	request.SetBasicAuth(c.Username, c.Password)
+	start := time.Now()
+	response, err := c.Client.Do(request)
+	statusCode := 0
+	if err == nil {
+		statusCode = response.StatusCode
+	}
+	splmetrics.ObserveSplunkRequest(methodName, statusCode, time.Since(start))
+	return response, err
+}
+
+// Get sends a REST API request and unmarshals response into obj, if not nil. The request is recorded in the metrics
+// with methodName, the name of the SplunkClient method sending it.
+func (c *SplunkClient) Get(methodName string, path string, obj interface{}) error {
 	endpoint := fmt.Sprintf("%s%s?count=0&output_mode=json", c.ManagementURI, path)
 	request, err := http.NewRequest("GET", endpoint, nil)
 	if err != nil {
 		return err
 	}
 	expectedStatus := []int{200}
-	return c.Do(request, expectedStatus, obj)
+	return c.Do(methodName, request, expectedStatus, obj)
+}
+
+// ServerInfo represents the version and roles of a Splunk Enterprise instance.
+// See https://docs.splunk.com/Documentation/Splunk/latest/RESTREF/RESTsystem#server.2Finfo
+type ServerInfo struct {
+	// Name of the Splunk Enterprise instance
+	ServerName string `json:"serverName"`
+
+	// Globally unique identifier of the instance
+	GUID string `json:"guid"`
+
+	// Version of Splunk Enterprise, such as 8.2.0
+	Version string `json:"version"`
+
+	// Build of Splunk Enterprise
+	Build string `json:"build"`
+
+	// Product type, such as enterprise
+	ProductType string `json:"product_type"`
+
+	// Roles of the instance, such as cluster_master or indexer
+	ServerRoles []string `json:"server_roles"`
+
+	// Timestamp of the last start of the instance
+	StartupTime int64 `json:"startup_time"`
+}
+
+// GetServerInfo queries the version and roles of a Splunk Enterprise instance.
+// You can use this on any instance.
+// See https://docs.splunk.com/Documentation/Splunk/latest/RESTREF/RESTsystem#server.2Finfo
+func (c *SplunkClient) GetServerInfo() (*ServerInfo, error) {
+	apiResponse := struct {
+		Entry []struct {
+			Content ServerInfo `json:"content"`
+		} `json:"entry"`
+	}{}
+	path := "/services/server/info"
+	err := c.Get("GetServerInfo", path, &apiResponse)
+	if err != nil {
+		return nil, err
+	}
+	if len(apiResponse.Entry) < 1 {
+		return nil, fmt.Errorf("Invalid response from %s%s", c.ManagementURI, path)
+	}
+	return &apiResponse.Entry[0].Content, nil
 }
 
 // SearchHeadCaptainInfo represents the status of the search head cluster.
@@ -153,7 +215,7 @@ func (c *SplunkClient) GetSearchHeadCaptainInfo() (*SearchHeadCaptainInfo, error
 		} `json:"entry"`
 	}{}
 	path := "/services/shcluster/captain/info"
-	err := c.Get(path, &apiResponse)
+	err := c.Get("GetSearchHeadCaptainInfo", path, &apiResponse)
 	if err != nil {
 		return nil, err
 	}
@@ -222,7 +284,7 @@ func (c *SplunkClient) GetSearchHeadCaptainMembers() (map[string]SearchHeadCapta
 		} `json:"entry"`
 	}{}
 	path := "/services/shcluster/captain/members"
-	err := c.Get(path, &apiResponse)
+	err := c.Get("GetSearchHeadCaptainMembers", path, &apiResponse)
 	if err != nil {
 		return nil, err
 	}
@@ -279,7 +341,7 @@ func (c *SplunkClient) GetSearchHeadClusterMemberInfo() (*SearchHeadClusterMembe
 		} `json:"entry"`
 	}{}
 	path := "/services/shcluster/member/info"
-	err := c.Get(path, &apiResponse)
+	err := c.Get("GetSearchHeadClusterMemberInfo", path, &apiResponse)
 	if err != nil {
 		return nil, err
 	}
@@ -303,7 +365,20 @@ func (c *SplunkClient) SetSearchHeadDetention(detain bool) error {
 		return err
 	}
 	expectedStatus := []int{200}
-	return c.Do(request, expectedStatus, nil)
+	return c.Do("SetSearchHeadDetention", request, expectedStatus, nil)
+}
+
+// TransferCaptaincy transfers the captaincy of a search head cluster to the member with management URI mgmtURI.
+// You can use this on any member of a search head cluster.
+// See https://docs.splunk.com/Documentation/Splunk/latest/DistSearch/Transfercaptaincy
+func (c *SplunkClient) TransferCaptaincy(mgmtURI string) error {
+	endpoint := fmt.Sprintf("%s/services/shcluster/member/control/control/transfer_captaincy?mgmt_uri=%s", c.ManagementURI, url.QueryEscape(mgmtURI))
+	request, err := http.NewRequest("POST", endpoint, nil)
+	if err != nil {
+		return err
+	}
+	expectedStatus := []int{200}
+	return c.Do("TransferCaptaincy", request, expectedStatus, nil)
 }
 
 // RemoveSearchHeadClusterMember removes a search head cluster member.
@@ -318,8 +393,7 @@ func (c *SplunkClient) RemoveSearchHeadClusterMember() error {
 	}
 
 	// send HTTP response and check status
-	request.SetBasicAuth(c.Username, c.Password)
-	response, err := c.Client.Do(request)
+	response, err := c.send("RemoveSearchHeadClusterMember", request)
 	if err != nil {
 		return err
 	}
@@ -371,6 +445,31 @@ type ClusterBundleInfo struct {
 	Timestamp int64 `json:"timestamp"`
 }
 
+// ClusterValidatedBundleInfo represents the last configuration bundle validated by the cluster master.
+type ClusterValidatedBundleInfo struct {
+	ClusterBundleInfo
+
+	// Indicates if the bundle passed the validation
+	IsValidBundle bool `json:"is_valid_bundle"`
+}
+
+// ClusterApplyBundleStatus represents the status of the last configuration bundle push of the cluster master.
+type ClusterApplyBundleStatus struct {
+	// Status of the bundle push, such as None or Bundle validation is in progress
+	Status string `json:"status"`
+
+	// Indicates if the peers were asked to reload the bundle, without a restart
+	ReloadBundleIssued bool `json:"reload_bundle_issued"`
+
+	// Provides information about the last bundle which failed the validation
+	InvalidBundle struct {
+		ClusterBundleInfo
+
+		// Errors found by the cluster master while validating the bundle
+		ValidationErrors []string `json:"bundle_validation_errors_on_master"`
+	} `json:"invalid_bundle"`
+}
+
 // ClusterMasterInfo represents the status of the indexer cluster master.
 // See https://docs.splunk.com/Documentation/Splunk/latest/RESTREF/RESTcluster#cluster.2Fmaster.2Finfo
 type ClusterMasterInfo struct {
@@ -399,6 +498,15 @@ type ClusterMasterInfo struct {
 	// In steady state, this is equal to active_bundle. If it is not equal, then pushing the latest bundle to all peers is in process (or needs to be started).
 	LatestBundle ClusterBundleInfo `json:"latest_bundle"`
 
+	// Provides information about the last bundle validated by the master, with the result of the validation.
+	LastValidatedBundle ClusterValidatedBundleInfo `json:"last_validated_bundle"`
+
+	// Indicates if applying the last validated bundle requires restarting the peers.
+	LastCheckRestartBundleResult bool `json:"last_check_restart_bundle_result"`
+
+	// Provides information about the last bundle push.
+	ApplyBundleStatus ClusterApplyBundleStatus `json:"apply_bundle_status"`
+
 	// Timestamp corresponding to the creation of the master.
 	StartTime int64 `json:"start_time"`
 }
@@ -413,7 +521,110 @@ func (c *SplunkClient) GetClusterMasterInfo() (*ClusterMasterInfo, error) {
 		} `json:"entry"`
 	}{}
 	path := "/services/cluster/master/info"
-	err := c.Get(path, &apiResponse)
+	err := c.Get("GetClusterMasterInfo", path, &apiResponse)
+	if err != nil {
+		return nil, err
+	}
+	if len(apiResponse.Entry) < 1 {
+		return nil, fmt.Errorf("Invalid response from %s%s", c.ManagementURI, path)
+	}
+	return &apiResponse.Entry[0].Content, nil
+}
+
+// ClusterRestartProgress represents the progress of a rolling restart of the indexer cluster peers.
+type ClusterRestartProgress struct {
+	// Peers that have been restarted.
+	Done []string `json:"done"`
+
+	// Peers being restarted.
+	InProgress []string `json:"in_progress"`
+
+	// Peers waiting to be restarted.
+	ToBeRestarted []string `json:"to_be_restarted"`
+
+	// Peers that could not be restarted.
+	Failed []string `json:"failed"`
+}
+
+// ClusterRestartStatus represents the status of a rolling restart of the indexer cluster peers (cluster master status endpoint).
+// See https://docs.splunk.com/Documentation/Splunk/latest/RESTREF/RESTcluster#cluster.2Fmaster.2Fstatus
+type ClusterRestartStatus struct {
+	// Indicates if a rolling restart or rolling upgrade of the peers is in progress.
+	RollingRestartOrUpgrade bool `json:"rolling_restart_or_upgrade"`
+
+	// Indicates if the rolling restart keeps the indexer cluster searchable.
+	SearchableRolling bool `json:"searchable_rolling"`
+
+	// Progress of the rolling restart.
+	RestartProgress ClusterRestartProgress `json:"restart_progress"`
+}
+
+// RestartClusterPeers starts a rolling restart of the indexer cluster peers, which keeps the cluster searchable if
+// searchable is true. You can only use this on a cluster master.
+// See https://docs.splunk.com/Documentation/Splunk/latest/Indexer/Userollingrestart
+func (c *SplunkClient) RestartClusterPeers(searchable bool) error {
+	endpoint := fmt.Sprintf("%s/services/cluster/master/control/control/restart", c.ManagementURI)
+	reqBody := fmt.Sprintf("searchable=%t", searchable)
+	request, err := http.NewRequest("POST", endpoint, strings.NewReader(reqBody))
+	if err != nil {
+		return err
+	}
+	expectedStatus := []int{200}
+	return c.Do("RestartClusterPeers", request, expectedStatus, nil)
+}
+
+// UpdateConfStanza updates settings of an existing stanza of a .conf file in etc/system/local, where splunk-ansible
+// writes the splunk.conf settings of the defaults. Most settings only take effect once splunkd restarts.
+// See https://docs.splunk.com/Documentation/Splunk/latest/RESTREF/RESTconf#configs.2Fconf-.7Bfile.7D.2F.7Bstanza.7D
+func (c *SplunkClient) UpdateConfStanza(file, stanza string, settings map[string]string) error {
+	endpoint := fmt.Sprintf("%s/servicesNS/nobody/system/configs/conf-%s/%s", c.ManagementURI, file, url.PathEscape(stanza))
+	values := url.Values{}
+	for key, value := range settings {
+		values.Set(key, value)
+	}
+	request, err := http.NewRequest("POST", endpoint, strings.NewReader(values.Encode()))
+	if err != nil {
+		return err
+	}
+	expectedStatus := []int{200}
+	return c.Do("UpdateConfStanza", request, expectedStatus, nil)
+}
+
+// SetMaintenanceMode enables or disables maintenance mode on the cluster master, which halts most bucket fixup activity
+// while the peers restart. You can only use this on a cluster master.
+// See https://docs.splunk.com/Documentation/Splunk/latest/RESTREF/RESTcluster#cluster.2Fmaster.2Fcontrol.2Fdefault.2Fmaintenance
+func (c *SplunkClient) SetMaintenanceMode(enable bool) error {
+	endpoint := fmt.Sprintf("%s/services/cluster/master/control/default/maintenance", c.ManagementURI)
+	reqBody := fmt.Sprintf("mode=%t", enable)
+	request, err := http.NewRequest("POST", endpoint, strings.NewReader(reqBody))
+	if err != nil {
+		return err
+	}
+	expectedStatus := []int{200}
+	return c.Do("SetMaintenanceMode", request, expectedStatus, nil)
+}
+
+// GetMaintenanceMode returns true if the cluster master is in maintenance mode. The maintenance endpoint only accepts
+// POST requests, so the mode is read from the cluster master info. You can only use this on a cluster master.
+func (c *SplunkClient) GetMaintenanceMode() (bool, error) {
+	info, err := c.GetClusterMasterInfo()
+	if err != nil {
+		return false, err
+	}
+	return info.MaintenanceMode, nil
+}
+
+// GetClusterRestartStatus queries the cluster master for the progress of a rolling restart of the indexer cluster peers.
+// You can only use this on a cluster master.
+// See https://docs.splunk.com/Documentation/Splunk/latest/RESTREF/RESTcluster#cluster.2Fmaster.2Fstatus
+func (c *SplunkClient) GetClusterRestartStatus() (*ClusterRestartStatus, error) {
+	apiResponse := struct {
+		Entry []struct {
+			Content ClusterRestartStatus `json:"content"`
+		} `json:"entry"`
+	}{}
+	path := "/services/cluster/master/status"
+	err := c.Get("GetClusterRestartStatus", path, &apiResponse)
 	if err != nil {
 		return nil, err
 	}
@@ -460,7 +671,7 @@ func (c *SplunkClient) GetIndexerClusterPeerInfo() (*IndexerClusterPeerInfo, err
 		} `json:"entry"`
 	}{}
 	path := "/services/cluster/slave/info"
-	err := c.Get(path, &apiResponse)
+	err := c.Get("GetIndexerClusterPeerInfo", path, &apiResponse)
 	if err != nil {
 		return nil, err
 	}
@@ -509,6 +720,27 @@ type ClusterMasterPeerInfo struct {
 	// The ID of the configuration bundle this peer is using.
 	LatestBundleID string `json:"latest_bundle_id"`
 
+	// Status of the application of the latest configuration bundle by this peer.
+	ApplyBundleStatus struct {
+		// Status of the bundle application, such as None or Reloading
+		Status string `json:"status"`
+
+		// Indicates if the peer restarts to apply the bundle
+		RestartRequired bool `json:"restart_required_for_apply_bundle"`
+
+		// Reasons why the peer restarts to apply the bundle
+		ReasonsForRestart []string `json:"reasons_for_restart"`
+
+		// Provides information about the last bundle which the peer failed to apply
+		InvalidBundle struct {
+			// Errors found by the peer while validating the bundle
+			ValidationErrors []string `json:"bundle_validation_errors"`
+
+			// The ID of the bundle
+			InvalidBundleID string `json:"invalid_bundle_id"`
+		} `json:"invalid_bundle"`
+	} `json:"apply_bundle_status"`
+
 	// Used by the master to keep track of pending jobs requested by the master to this peer.
 	PendingJobCount int `json:"pending_job_count"`
 
@@ -583,7 +815,7 @@ func (c *SplunkClient) GetClusterMasterPeers() (map[string]ClusterMasterPeerInfo
 		} `json:"entry"`
 	}{}
 	path := "/services/cluster/master/peers"
-	err := c.Get(path, &apiResponse)
+	err := c.Get("GetClusterMasterPeers", path, &apiResponse)
 	if err != nil {
 		return nil, err
 	}
@@ -608,7 +840,7 @@ func (c *SplunkClient) RemoveIndexerClusterPeer(id string) error {
 		return err
 	}
 	expectedStatus := []int{200}
-	return c.Do(request, expectedStatus, nil)
+	return c.Do("RemoveIndexerClusterPeer", request, expectedStatus, nil)
 }
 
 // DecommissionIndexerClusterPeer takes an indexer cluster peer offline using the decommission endpoint.
@@ -625,7 +857,7 @@ func (c *SplunkClient) DecommissionIndexerClusterPeer(enforceCounts bool) error
 		return err
 	}
 	expectedStatus := []int{200}
-	return c.Do(request, expectedStatus, nil)
+	return c.Do("DecommissionIndexerClusterPeer", request, expectedStatus, nil)
 }
 
 // BundlePush pushes the CM master apps bundle to all the indexer peers
@@ -639,7 +871,36 @@ func (c *SplunkClient) BundlePush(ignoreIdenticalBundle bool) error {
 	}
 	expectedStatus := []int{200}
 
-	return c.Do(request, expectedStatus, nil)
+	return c.Do("BundlePush", request, expectedStatus, nil)
+}
+
+// DeploySearchHeadClusterBundle pushes the configuration bundle of a deployer to the search head cluster members,
+// through the member at target. You can only use this on a search head cluster deployer.
+// See https://docs.splunk.com/Documentation/Splunk/latest/RESTREF/RESTapps#apps.2Fdeploy
+func (c *SplunkClient) DeploySearchHeadClusterBundle(target string) error {
+	endpoint := fmt.Sprintf("%s/services/apps/deploy", c.ManagementURI)
+	reqBody := url.Values{"action": {"all"}, "target": {target}}.Encode()
+	request, err := http.NewRequest("POST", endpoint, strings.NewReader(reqBody))
+	if err != nil {
+		return err
+	}
+	expectedStatus := []int{200}
+	return c.Do("DeploySearchHeadClusterBundle", request, expectedStatus, nil)
+}
+
+// ValidateBundle asks the cluster master to validate its master-apps configuration bundle, and to check if applying it
+// requires restarting the peers when checkRestart is true. The result is reported by GetClusterMasterInfo once the
+// validation completes. You can only use this on a cluster master.
+// See https://docs.splunk.com/Documentation/Splunk/latest/RESTREF/RESTcluster#cluster.2Fmaster.2Fcontrol.2Fdefault.2Fvalidate_bundle
+func (c *SplunkClient) ValidateBundle(checkRestart bool) error {
+	endpoint := fmt.Sprintf("%s/services/cluster/master/control/default/validate_bundle", c.ManagementURI)
+	reqBody := fmt.Sprintf("check-restart=%t", checkRestart)
+	request, err := http.NewRequest("POST", endpoint, strings.NewReader(reqBody))
+	if err != nil {
+		return err
+	}
+	expectedStatus := []int{200}
+	return c.Do("ValidateBundle", request, expectedStatus, nil)
 }
 
 //MCServerRolesInfo is the struct for the server roles of the localhost, in this case SplunkMonitoringConsole
@@ -671,7 +932,7 @@ func (c *SplunkClient) AutomateMCApplyChanges(mock bool) error {
 		} `json:"entry"`
 	}{}
 	path := "/services/search/distributed/peers"
-	err = c.Get(path, &apiResponseMCDistributedPeers)
+	err = c.Get("AutomateMCApplyChanges", path, &apiResponseMCDistributedPeers)
 	if err != nil {
 		return err
 	}
@@ -775,7 +1036,7 @@ func (c *SplunkClient) GetMonitoringconsoleServerRoles() (*MCServerRolesInfo, er
 		} `json:"entry"`
 	}{}
 	path := "/services/server/info/server-info"
-	err := c.Get(path, &apiResponseServerRoles)
+	err := c.Get("GetMonitoringconsoleServerRoles", path, &apiResponseServerRoles)
 	if err != nil {
 		return nil, err
 	}
@@ -790,7 +1051,7 @@ func (c *SplunkClient) UpdateDMCGroups(dmcGroupName string, groupMembers string)
 	endpoint := fmt.Sprintf("%s/services/search/distributed/groups/%s/edit", c.ManagementURI, dmcGroupName)
 	request, err := http.NewRequest("POST", endpoint, strings.NewReader(groupMembers))
 	expectedStatus := []int{200, 201, 409}
-	err = c.Do(request, expectedStatus, nil)
+	err = c.Do("UpdateDMCGroups", request, expectedStatus, nil)
 	return err
 }
 
@@ -800,7 +1061,7 @@ func (c *SplunkClient) UpdateDMCClusteringLabelGroup(groupName string, groupMemb
 	reqBodyClusterGroup := groupMembers + "&default=false"
 	request, err := http.NewRequest("POST", endpoint, strings.NewReader(reqBodyClusterGroup))
 	expectedStatus := []int{200, 201, 409}
-	err = c.Do(request, expectedStatus, nil)
+	err = c.Do("UpdateDMCClusteringLabelGroup", request, expectedStatus, nil)
 	return err
 }
 
@@ -818,7 +1079,7 @@ func (c *SplunkClient) GetMonitoringconsoleAssetTable() (*MCAssetBuildTable, err
 		} `json:"entry"`
 	}{}
 	path := "/servicesNS/nobody/splunk_monitoring_console/saved/searches/DMC%20Asset%20-%20Build%20Full"
-	err := c.Get(path, &apiResponseMCAssetTableBuild)
+	err := c.Get("GetMonitoringconsoleAssetTable", path, &apiResponseMCAssetTableBuild)
 	if err != nil {
 		return nil, err
 	}
@@ -835,7 +1096,7 @@ func (c *SplunkClient) PostMonitoringConsoleAssetTable(apiResponseMCAssetTableBu
 	request, err := http.NewRequest("POST", endpoint, strings.NewReader(reqBodyAssetTable))
 	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
 	expectedStatus := []int{200, 201, 409}
-	err = c.Do(request, expectedStatus, nil)
+	err = c.Do("PostMonitoringConsoleAssetTable", request, expectedStatus, nil)
 	return err
 }
 
@@ -856,7 +1117,7 @@ func (c *SplunkClient) GetMonitoringConsoleUISettings() (*UISettings, error) {
 		} `json:"entry"`
 	}{}
 	path := "/servicesNS/nobody/splunk_monitoring_console/data/ui/nav/default.distributed"
-	err := c.Get(path, &apiResponseUISettings)
+	err := c.Get("GetMonitoringConsoleUISettings", path, &apiResponseUISettings)
 	if err != nil {
 		return nil, err
 	}
@@ -873,7 +1134,7 @@ func (c *SplunkClient) UpdateLookupUISettings(configuredPeers string, apiRespons
 	request, err := http.NewRequest("POST", endpoint, strings.NewReader(reqBodyMCLookups))
 	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
 	expectedStatus := []int{200, 201, 409}
-	err = c.Do(request, expectedStatus, nil)
+	err = c.Do("UpdateLookupUISettings", request, expectedStatus, nil)
 	return err
 }
 
@@ -885,7 +1146,7 @@ func (c *SplunkClient) UpdateMonitoringConsoleApp() error {
 		return err
 	}
 	expectedStatus := []int{200, 201}
-	err = c.Do(request, expectedStatus, nil)
+	err = c.Do("UpdateMonitoringConsoleApp", request, expectedStatus, nil)
 	return err
 }
 
@@ -894,6 +1155,7 @@ type ClusterInfo struct {
 	MultiSite             string `json:"multisite"`
 	ReplicationFactor     int32  `json:"replication_factor"`
 	SiteReplicationFactor string `json:"site_replication_factor,omitempty"`
+	SiteSearchFactor      string `json:"site_search_factor,omitempty"`
 }
 
 // GetClusterInfo queries the cluster about multi-site or single-site.
@@ -908,7 +1170,7 @@ func (c *SplunkClient) GetClusterInfo(mockCall bool) (*ClusterInfo, error) {
 		} `json:"entry"`
 	}{}
 	path := "/services/cluster/config"
-	err := c.Get(path, &apiResponse)
+	err := c.Get("GetClusterInfo", path, &apiResponse)
 	if err != nil {
 		return nil, err
 	}
@@ -929,7 +1191,7 @@ func (c *SplunkClient) SetIdxcSecret(idxcSecret string) error {
 	}
 	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
 	expectedStatus := []int{200}
-	return c.Do(request, expectedStatus, nil)
+	return c.Do("SetIdxcSecret", request, expectedStatus, nil)
 }
 
 // RestartSplunk restarts specific Splunk instance
@@ -942,5 +1204,114 @@ func (c *SplunkClient) RestartSplunk() error {
 		return err
 	}
 	expectedStatus := []int{200}
-	return c.Do(request, expectedStatus, nil)
+	return c.Do("RestartSplunk", request, expectedStatus, nil)
+}
+
+// AppInfo represents the status of an app installed on a Splunk instance.
+// See https://docs.splunk.com/Documentation/Splunk/latest/RESTREF/RESTapps#apps.2Flocal
+type AppInfo struct {
+	// Name of the app directory
+	Name string `json:"-"`
+
+	// Version of the app, as set in app.conf
+	Version string `json:"version"`
+
+	// Label of the app, as displayed in Splunk Web
+	Label string `json:"label"`
+
+	// True if the app is disabled
+	Disabled bool `json:"disabled"`
+
+	// True if the app has been configured
+	Configured bool `json:"configured"`
+}
+
+// GetAppsLocal returns the apps installed on a Splunk instance, indexed by app name.
+// Can be used for any Splunk Instance
+// See https://docs.splunk.com/Documentation/Splunk/latest/RESTREF/RESTapps#apps.2Flocal
+func (c *SplunkClient) GetAppsLocal() (map[string]AppInfo, error) {
+	apiResponse := struct {
+		Entry []struct {
+			Name    string  `json:"name"`
+			Content AppInfo `json:"content"`
+		} `json:"entry"`
+	}{}
+	path := "/services/apps/local"
+	err := c.Get("GetAppsLocal", path, &apiResponse)
+	if err != nil {
+		return nil, err
+	}
+
+	apps := make(map[string]AppInfo)
+	for _, e := range apiResponse.Entry {
+		e.Content.Name = e.Name
+		apps[e.Name] = e.Content
+	}
+
+	return apps, nil
+}
+
+// DeploymentClientInfo represents the status of a deployment client that phoned home to a deployment server.
+// See https://docs.splunk.com/Documentation/Splunk/latest/RESTREF/RESTdeploy#deployment.2Fserver.2Fclients
+type DeploymentClientInfo struct {
+	// Unique identifier for the deployment client
+	ID string `json:"-"`
+
+	// Name of the deployment client, as set in deploymentclient.conf (defaults to the GUID of the client)
+	ClientName string `json:"clientName"`
+
+	// Host name of the deployment client
+	Hostname string `json:"hostname"`
+
+	// IP address of the deployment client
+	IP string `json:"ip"`
+
+	// DNS name of the deployment client
+	DNS string `json:"dns"`
+
+	// Machine type of the deployment client
+	Utsname string `json:"utsname"`
+
+	// Splunk version of the deployment client
+	SplunkVersion string `json:"splunkVersion"`
+
+	// Time of the last phone home from the deployment client, in seconds since epoch
+	LastPhoneHomeTime int64 `json:"lastPhoneHomeTime"`
+
+	// Server classes the deployment client belongs to, indexed by server class name
+	ServerClasses map[string]DeploymentClientServerClassInfo `json:"serverClasses"`
+}
+
+// DeploymentClientServerClassInfo represents the status of a server class on a deployment client.
+type DeploymentClientServerClassInfo struct {
+	// Time the deployment client last fetched the apps of the server class, in seconds since epoch
+	LastFetchTime int64 `json:"lastFetchTime"`
+
+	// State of the server class on the deployment client
+	StateOnClient string `json:"stateOnClient"`
+}
+
+// GetDeploymentServerClients returns the deployment clients that phoned home to a deployment server, indexed by client ID.
+// You can only use this on a deployment server.
+// See https://docs.splunk.com/Documentation/Splunk/latest/RESTREF/RESTdeploy#deployment.2Fserver.2Fclients
+func (c *SplunkClient) GetDeploymentServerClients() (map[string]DeploymentClientInfo, error) {
+	apiResponse := struct {
+		Entry []struct {
+			Name    string               `json:"name"`
+			Content DeploymentClientInfo `json:"content"`
+		} `json:"entry"`
+	}{}
+	path := "/services/deployment/server/clients"
+	err := c.Get("GetDeploymentServerClients", path, &apiResponse)
+	if err != nil {
+		return nil, err
+	}
+
+	clients := make(map[string]DeploymentClientInfo)
+	for _, e := range apiResponse.Entry {
+		e.Content.ID = e.Name
+		clients[e.Name] = e.Content
+	}
+
+	return clients, nil
 }
@@ -17,9 +17,12 @@ package client
 import (
 	"fmt"
 	"net/http"
+	"reflect"
 	"strings"
 	"testing"
 
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+
 	spltest "github.com/splunk/splunk-operator/pkg/splunk/test"
 )
 
@@ -49,6 +52,45 @@ func splunkClientMultipleRequestTester(t *testing.T, testMethod string, status [
 	mockSplunkClient.CheckRequests(t, testMethod)
 }
 
+func TestGetServerInfo(t *testing.T) {
+	wantRequest, _ := http.NewRequest("GET", "https://localhost:8089/services/server/info?count=0&output_mode=json", nil)
+	wantInfo := ServerInfo{
+		ServerName:  "splunk-s1-cluster-master-0",
+		GUID:        "2D6F1E3C-1A6B-4F43-8C3E-49B6D1D5E8A3",
+		Version:     "8.2.0",
+		Build:       "e053ef3c985f",
+		ProductType: "enterprise",
+		ServerRoles: []string{"license_master", "cluster_master", "kv_store"},
+		StartupTime: 1623250382,
+	}
+	test := func(c SplunkClient) error {
+		gotInfo, err := c.GetServerInfo()
+		if err != nil {
+			return err
+		}
+		if !reflect.DeepEqual(*gotInfo, wantInfo) {
+			t.Errorf("info=%v; want %v", *gotInfo, wantInfo)
+		}
+		return nil
+	}
+	body := `{"links":{},"origin":"https://localhost:8089/services/server/info","updated":"2021-06-09T15:00:02+00:00","generator":{"build":"e053ef3c985f","version":"8.2.0"},"entry":[{"name":"server-info","id":"https://localhost:8089/services/server/info/server-info","updated":"1970-01-01T00:00:00+00:00","links":{"alternate":"/services/server/info/server-info","list":"/services/server/info/server-info"},"author":"system","acl":{"app":"","can_list":true,"can_write":true,"modifiable":false,"owner":"system","perms":{"read":["*"],"write":[]},"removable":false,"sharing":"system"},"content":{"activeLicenseGroup":"Trial","build":"e053ef3c985f","cpu_arch":"x86_64","eai:acl":null,"guid":"2D6F1E3C-1A6B-4F43-8C3E-49B6D1D5E8A3","health_info":"green","isFree":false,"isTrial":true,"mode":"normal","numberOfCores":4,"os_name":"Linux","product_type":"enterprise","serverName":"splunk-s1-cluster-master-0","server_roles":["license_master","cluster_master","kv_store"],"startup_time":1623250382,"version":"8.2.0"}}],"paging":{"total":1,"perPage":30,"offset":0},"messages":[]}`
+	splunkClientTester(t, "TestGetServerInfo", 200, body, wantRequest, test)
+
+	// test body with no entries
+	test = func(c SplunkClient) error {
+		_, err := c.GetServerInfo()
+		if err == nil {
+			t.Errorf("GetServerInfo returned nil; want error")
+		}
+		return nil
+	}
+	body = `{"links":{},"origin":"https://localhost:8089/services/server/info","updated":"2021-06-09T15:00:02+00:00","generator":{"build":"e053ef3c985f","version":"8.2.0"},"entry":[]}`
+	splunkClientTester(t, "TestGetServerInfo", 200, body, wantRequest, test)
+
+	// test error code
+	splunkClientTester(t, "TestGetServerInfo", 500, "", wantRequest, test)
+}
+
 func TestGetSearchHeadCaptainInfo(t *testing.T) {
 	wantRequest, _ := http.NewRequest("GET", "https://localhost:8089/services/shcluster/captain/info?count=0&output_mode=json", nil)
 	wantCaptainLabel := "splunk-s2-search-head-0"
@@ -174,6 +216,14 @@ func TestSetSearchHeadDetention(t *testing.T) {
 	splunkClientTester(t, "TestSetSearchHeadDetention", 200, "", wantRequest, test)
 }
 
+func TestTransferCaptaincy(t *testing.T) {
+	wantRequest, _ := http.NewRequest("POST", "https://localhost:8089/services/shcluster/member/control/control/transfer_captaincy?mgmt_uri=https%3A%2F%2Fsplunk-s2-search-head-1.splunk-s2-search-head-headless.splunk.svc.cluster.local%3A8089", nil)
+	test := func(c SplunkClient) error {
+		return c.TransferCaptaincy("https://splunk-s2-search-head-1.splunk-s2-search-head-headless.splunk.svc.cluster.local:8089")
+	}
+	splunkClientTester(t, "TestTransferCaptaincy", 200, "", wantRequest, test)
+}
+
 func TestBundlePush(t *testing.T) {
 	body := strings.NewReader("&ignore_identical_bundle=true")
 	wantRequest, _ := http.NewRequest("POST", "https://localhost:8089/services/cluster/master/control/default/apply", body)
@@ -226,6 +276,111 @@ func TestRemoveSearchHeadClusterMember(t *testing.T) {
 	splunkClientTester(t, "TestRemoveSearchHeadClusterMember", 404, "", wantRequest, test)
 }
 
+func TestRestartClusterPeers(t *testing.T) {
+	body := strings.NewReader("searchable=true")
+	wantRequest, _ := http.NewRequest("POST", "https://localhost:8089/services/cluster/master/control/control/restart", body)
+	test := func(c SplunkClient) error {
+		return c.RestartClusterPeers(true)
+	}
+	splunkClientTester(t, "TestRestartClusterPeers", 200, "", wantRequest, test)
+}
+
+func TestUpdateConfStanza(t *testing.T) {
+	body := strings.NewReader("maxDataSize=auto_high_volume&maxTotalDataSizeMB=500000")
+	wantRequest, _ := http.NewRequest("POST", "https://localhost:8089/servicesNS/nobody/system/configs/conf-indexes/main%20index", body)
+	test := func(c SplunkClient) error {
+		return c.UpdateConfStanza("indexes", "main index", map[string]string{"maxTotalDataSizeMB": "500000", "maxDataSize": "auto_high_volume"})
+	}
+	splunkClientTester(t, "TestUpdateConfStanza", 200, "", wantRequest, test)
+}
+
+func TestSetMaintenanceMode(t *testing.T) {
+	for _, enable := range []bool{true, false} {
+		body := strings.NewReader(fmt.Sprintf("mode=%t", enable))
+		wantRequest, _ := http.NewRequest("POST", "https://localhost:8089/services/cluster/master/control/default/maintenance", body)
+		test := func(c SplunkClient) error {
+			return c.SetMaintenanceMode(enable)
+		}
+		splunkClientTester(t, "TestSetMaintenanceMode", 200, "", wantRequest, test)
+	}
+}
+
+func TestGetMaintenanceMode(t *testing.T) {
+	wantRequest, _ := http.NewRequest("GET", "https://localhost:8089/services/cluster/master/info?count=0&output_mode=json", nil)
+	test := func(c SplunkClient) error {
+		enabled, err := c.GetMaintenanceMode()
+		if err != nil {
+			return err
+		}
+		if !enabled {
+			t.Errorf("GetMaintenanceMode()=false; want true")
+		}
+		return nil
+	}
+	body := `{"entry":[{"name":"master","content":{"initialized_flag":true,"indexing_ready_flag":true,"maintenance_mode":true,"rolling_restart_flag":false,"service_ready_flag":true}}]}`
+	splunkClientTester(t, "TestGetMaintenanceMode", 200, body, wantRequest, test)
+
+	// test error response
+	test = func(c SplunkClient) error {
+		_, err := c.GetMaintenanceMode()
+		if err == nil {
+			t.Errorf("GetMaintenanceMode returned nil; want error")
+		}
+		return nil
+	}
+	splunkClientTester(t, "TestGetMaintenanceMode", 503, "", wantRequest, test)
+}
+
+func TestDeploySearchHeadClusterBundle(t *testing.T) {
+	body := strings.NewReader("action=all&target=https%3A%2F%2Fsplunk-s1-search-head-0%3A8089")
+	wantRequest, _ := http.NewRequest("POST", "https://localhost:8089/services/apps/deploy", body)
+	test := func(c SplunkClient) error {
+		return c.DeploySearchHeadClusterBundle("https://splunk-s1-search-head-0:8089")
+	}
+	splunkClientTester(t, "TestDeploySearchHeadClusterBundle", 200, "", wantRequest, test)
+}
+
+func TestValidateBundle(t *testing.T) {
+	body := strings.NewReader("check-restart=true")
+	wantRequest, _ := http.NewRequest("POST", "https://localhost:8089/services/cluster/master/control/default/validate_bundle", body)
+	test := func(c SplunkClient) error {
+		return c.ValidateBundle(true)
+	}
+	splunkClientTester(t, "TestValidateBundle", 200, "", wantRequest, test)
+}
+
+func TestGetClusterRestartStatus(t *testing.T) {
+	wantRequest, _ := http.NewRequest("GET", "https://localhost:8089/services/cluster/master/status?count=0&output_mode=json", nil)
+	test := func(c SplunkClient) error {
+		status, err := c.GetClusterRestartStatus()
+		if err != nil {
+			return err
+		}
+		if !status.RollingRestartOrUpgrade || !status.SearchableRolling {
+			t.Errorf("status=%v; want a searchable rolling restart", *status)
+		}
+		progress := status.RestartProgress
+		if !reflect.DeepEqual(progress.Done, []string{"splunk-s1-indexer-2"}) || !reflect.DeepEqual(progress.InProgress, []string{"splunk-s1-indexer-1"}) ||
+			!reflect.DeepEqual(progress.ToBeRestarted, []string{"splunk-s1-indexer-0"}) || len(progress.Failed) != 0 {
+			t.Errorf("status.RestartProgress=%v", progress)
+		}
+		return nil
+	}
+	body := `{"links":{},"origin":"https://localhost:8089/services/cluster/master/status","updated":"2021-06-22T18:11:02+00:00","generator":{"build":"e053ef3c985f","version":"8.2.0"},"entry":[{"name":"master","id":"https://localhost:8089/services/cluster/master/status/master","updated":"1970-01-01T00:00:00+00:00","links":{"alternate":"/services/cluster/master/status/master","list":"/services/cluster/master/status/master"},"author":"system","acl":{"app":"","can_list":true,"can_write":true,"modifiable":false,"owner":"system","perms":{"read":["admin","splunk-system-role"],"write":["admin","splunk-system-role"]},"removable":false,"sharing":"system"},"content":{"available_sites":"","decommission_force_timeout":"180","eai:acl":null,"ha_mode":"Disabled","maintenance_mode":true,"multisite":false,"restart_inactivity_timeout":"600","restart_progress":{"done":["splunk-s1-indexer-2"],"failed":[],"in_progress":["splunk-s1-indexer-1"],"to_be_restarted":["splunk-s1-indexer-0"]},"rolling_restart_or_upgrade":true,"searchable_rolling":true,"service_ready_flag":true}}],"paging":{"total":1,"perPage":30,"offset":0},"messages":[]}`
+	splunkClientTester(t, "TestGetClusterRestartStatus", 200, body, wantRequest, test)
+
+	// test body with no entries
+	test = func(c SplunkClient) error {
+		_, err := c.GetClusterRestartStatus()
+		if err == nil {
+			t.Errorf("GetClusterRestartStatus returned nil; want error")
+		}
+		return nil
+	}
+	body = `{"links":{},"origin":"https://localhost:8089/services/cluster/master/status","updated":"2021-06-22T18:11:02+00:00","generator":{"build":"e053ef3c985f","version":"8.2.0"},"entry":[],"paging":{"total":1,"perPage":30,"offset":0},"messages":[]}`
+	splunkClientTester(t, "TestGetClusterRestartStatus", 200, body, wantRequest, test)
+}
+
 func TestGetClusterMasterInfo(t *testing.T) {
 	wantRequest, _ := http.NewRequest("GET", "https://localhost:8089/services/cluster/master/info?count=0&output_mode=json", nil)
 	wantInfo := ClusterMasterInfo{
@@ -245,14 +400,24 @@ func TestGetClusterMasterInfo(t *testing.T) {
 			Checksum:   "14310A4AABD23E85BBD4559C4A3B59F8",
 			Timestamp:  1583870198,
 		},
+		LastValidatedBundle: ClusterValidatedBundleInfo{
+			ClusterBundleInfo: ClusterBundleInfo{
+				BundlePath: "/opt/splunk/var/run/splunk/cluster/remote-bundle/0af7c0e95f313f7be3b0cb1d878df9a1-1583948640.bundle",
+				Checksum:   "14310A4AABD23E85BBD4559C4A3B59F8",
+				Timestamp:  1583948640,
+			},
+			IsValidBundle: true,
+		},
 		StartTime: 1583948636,
 	}
+	wantInfo.ApplyBundleStatus.Status = "None"
+	wantInfo.ApplyBundleStatus.InvalidBundle.ValidationErrors = []string{}
 	test := func(c SplunkClient) error {
 		gotInfo, err := c.GetClusterMasterInfo()
 		if err != nil {
 			return err
 		}
-		if *gotInfo != wantInfo {
+		if !reflect.DeepEqual(*gotInfo, wantInfo) {
 			t.Errorf("info.Status=%v; want %v", *gotInfo, wantInfo)
 		}
 		return nil
@@ -556,3 +721,113 @@ func TestRestartSplunk(t *testing.T) {
 	}
 	splunkClientTester(t, "TestRestartSplunk", 200, "", wantRequest, test)
 }
+
+func TestGetAppsLocal(t *testing.T) {
+	wantRequest, _ := http.NewRequest("GET", "https://localhost:8089/services/apps/local?count=0&output_mode=json", nil)
+	wantApps := map[string]AppInfo{
+		"search":        {Name: "search", Version: "8.2.0", Label: "Search & Reporting", Configured: true},
+		"Splunk_TA_nix": {Name: "Splunk_TA_nix", Version: "8.3.0", Label: "Splunk Add-on for Unix and Linux", Disabled: true},
+	}
+	test := func(c SplunkClient) error {
+		apps, err := c.GetAppsLocal()
+		if err != nil {
+			return err
+		}
+		if !reflect.DeepEqual(apps, wantApps) {
+			t.Errorf("apps=%v; want %v", apps, wantApps)
+		}
+		return nil
+	}
+	body := `{"links":{"create":"/services/apps/local/_new"},"origin":"https://localhost:8089/services/apps/local","updated":"2021-06-21T18:32:42+00:00","generator":{"build":"e9494146ae5c","version":"8.2.0"},"entry":[{"name":"search","id":"https://localhost:8089/servicesNS/nobody/system/apps/local/search","updated":"2021-06-21T18:32:42+00:00","author":"nobody","content":{"check_for_updates":true,"configured":true,"core":true,"disabled":false,"label":"Search & Reporting","version":"8.2.0","visible":true}},{"name":"Splunk_TA_nix","id":"https://localhost:8089/servicesNS/nobody/system/apps/local/Splunk_TA_nix","updated":"2021-06-21T18:32:42+00:00","author":"nobody","content":{"check_for_updates":true,"configured":false,"disabled":true,"label":"Splunk Add-on for Unix and Linux","version":"8.3.0","visible":true}}],"paging":{"total":2,"perPage":30,"offset":0},"messages":[]}`
+	splunkClientTester(t, "TestGetAppsLocal", 200, body, wantRequest, test)
+
+	// test error response
+	test = func(c SplunkClient) error {
+		_, err := c.GetAppsLocal()
+		if err == nil {
+			t.Errorf("GetAppsLocal returned nil; want error")
+		}
+		return nil
+	}
+	splunkClientTester(t, "TestGetAppsLocal", 503, "", wantRequest, test)
+}
+
+func TestGetDeploymentServerClients(t *testing.T) {
+	wantRequest, _ := http.NewRequest("GET", "https://localhost:8089/services/deployment/server/clients?count=0&output_mode=json", nil)
+	wantClients := map[string]DeploymentClientInfo{
+		"5b9f6b3c2f4e2a6f1e3f8a1b2c3d4e5f": {
+			ID:                "5b9f6b3c2f4e2a6f1e3f8a1b2c3d4e5f",
+			ClientName:        "uf-1",
+			Hostname:          "uf-1.example.com",
+			IP:                "10.0.0.11",
+			DNS:               "uf-1.example.com",
+			Utsname:           "linux-x86_64",
+			SplunkVersion:     "8.2.0",
+			LastPhoneHomeTime: 1624300362,
+			ServerClasses: map[string]DeploymentClientServerClassInfo{
+				"linux": {LastFetchTime: 1624300302, StateOnClient: "enabled"},
+			},
+		},
+	}
+	test := func(c SplunkClient) error {
+		clients, err := c.GetDeploymentServerClients()
+		if err != nil {
+			return err
+		}
+		if !reflect.DeepEqual(clients, wantClients) {
+			t.Errorf("clients=%v; want %v", clients, wantClients)
+		}
+		return nil
+	}
+	body := `{"links":{},"origin":"https://localhost:8089/services/deployment/server/clients","updated":"2021-06-21T18:32:42+00:00","generator":{"build":"e9494146ae5c","version":"8.2.0"},"entry":[{"name":"5b9f6b3c2f4e2a6f1e3f8a1b2c3d4e5f","id":"https://localhost:8089/services/deployment/server/clients/5b9f6b3c2f4e2a6f1e3f8a1b2c3d4e5f","updated":"2021-06-21T18:32:42+00:00","author":"system","content":{"applications":{"Splunk_TA_nix":{"archive":"Splunk_TA_nix.bundle","checksum":"6a6f6b3e","restartSplunkWeb":false,"restartSplunkd":true,"serverclasses":["linux"],"stateOnClient":"enabled"}},"build":"e9494146ae5c","clientName":"uf-1","dns":"uf-1.example.com","guid":"5b9f6b3c2f4e2a6f1e3f8a1b2c3d4e5f","hostname":"uf-1.example.com","ip":"10.0.0.11","lastPhoneHomeTime":1624300362,"mgmt":"8089","name":"5b9f6b3c2f4e2a6f1e3f8a1b2c3d4e5f","package":"universal_forwarder","serverClasses":{"linux":{"lastFetchTime":1624300302,"restartSplunkWeb":false,"restartSplunkd":true,"stateOnClient":"enabled"}},"splunkVersion":"8.2.0","utsname":"linux-x86_64"}}],"paging":{"total":1,"perPage":30,"offset":0},"messages":[]}`
+	splunkClientTester(t, "TestGetDeploymentServerClients", 200, body, wantRequest, test)
+
+	// test error response
+	test = func(c SplunkClient) error {
+		_, err := c.GetDeploymentServerClients()
+		if err == nil {
+			t.Errorf("GetDeploymentServerClients returned nil; want error")
+		}
+		return nil
+	}
+	splunkClientTester(t, "TestGetDeploymentServerClients", 503, "", wantRequest, test)
+}
+
+// failingHTTPClient fails every request without a response
+type failingHTTPClient struct{}
+
+func (c failingHTTPClient) Do(request *http.Request) (*http.Response, error) {
+	return nil, fmt.Errorf("unreachable")
+}
+
+func TestSplunkRequestMethodName(t *testing.T) {
+	c := NewSplunkClient("https://localhost:8089", "admin", "p@ssw0rd")
+	c.Client = failingHTTPClient{}
+
+	c.GetClusterMasterInfo()
+	c.RemoveSearchHeadClusterMember()
+	c.Get("TestSplunkRequestMethodName", "/services/server/info", nil)
+
+	families, err := metrics.Registry.Gather()
+	if err != nil {
+		t.Fatalf("Gather() returned error: %v", err)
+	}
+	got := map[string]bool{}
+	for _, family := range families {
+		if !strings.HasSuffix(family.GetName(), "splunk_request_duration_seconds") {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			for _, label := range metric.GetLabel() {
+				if label.GetName() == "method" {
+					got[label.GetValue()] = true
+				}
+			}
+		}
+	}
+	for _, want := range []string{"GetClusterMasterInfo", "RemoveSearchHeadClusterMember", "TestSplunkRequestMethodName"} {
+		if !got[want] {
+			t.Errorf("Splunk requests were not recorded with method %s; got %v", want, got)
+		}
+	}
+}
@@ -0,0 +1,237 @@
+// Copyright (c) 2018-2021 Splunk Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"bytes"
+	"crypto/tls"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
+)
+
+// defaultS3Region is used when the region can not be derived from the endpoint.
+// S3 compatible object stores like MinIO accept any region.
+const defaultS3Region = "us-east-1"
+
+// S3Client is used to access objects on an S3 compatible remote object store
+type S3Client interface {
+	// ListObjects returns all the objects having the given prefix
+	ListObjects(prefix string) ([]RemoteObject, error)
+
+	// GetObject returns the contents of the object with the given key
+	GetObject(key string) ([]byte, error)
+
+	// PutObject writes an object with the given key and contents
+	PutObject(key string, data []byte) error
+
+	// DeleteObject deletes the object with the given key
+	DeleteObject(key string) error
+}
+
+// RemoteObject describes an object stored on a remote object store
+type RemoteObject struct {
+	// Key of the object, including its prefix
+	Key string
+
+	// Entity tag of the object, which changes whenever the object is modified
+	Etag string
+
+	// Size of the object in bytes
+	Size int64
+
+	// Time the object was last modified
+	LastModified time.Time
+}
+
+// AWSS3Client is an S3Client backed by the AWS SDK
+type AWSS3Client struct {
+	// Name of the bucket holding the objects
+	Bucket string
+
+	// S3 API client used to process requests
+	Client s3iface.S3API
+
+	// Server-side encryption requested for the objects written: AES256 or aws:kms (optional)
+	ServerSideEncryption string
+
+	// Key ID or ARN of the AWS KMS key used with aws:kms encryption (optional)
+	KMSKeyID string
+}
+
+// S3ClientConfig describes how an AWSS3Client accesses a bucket
+type S3ClientConfig struct {
+	// URI of the S3 endpoint
+	Endpoint string
+
+	// Name of the bucket holding the objects
+	Bucket string
+
+	// Region used to sign the requests, derived from the endpoint if empty
+	Region string
+
+	// Static credentials of the requests
+	AccessKey string
+	SecretKey string
+
+	// PEM encoded certificate authorities trusted instead of the system ones (optional)
+	CABundle []byte
+
+	// If true, the certificate of the endpoint is not verified
+	InsecureSkipVerify bool
+
+	// Server-side encryption requested for the objects written: AES256 or aws:kms (optional)
+	ServerSideEncryption string
+
+	// Key ID or ARN of the AWS KMS key used with aws:kms encryption (optional)
+	KMSKeyID string
+}
+
+// NewAWSS3Client returns a new S3Client for a bucket, using static credentials.
+// Path style addressing is used so that S3 compatible object stores, such as MinIO, work as well.
+func NewAWSS3Client(endpoint, bucket, accessKey, secretKey string) (S3Client, error) {
+	return NewAWSS3ClientWithConfig(S3ClientConfig{
+		Endpoint:  endpoint,
+		Bucket:    bucket,
+		AccessKey: accessKey,
+		SecretKey: secretKey,
+	})
+}
+
+// NewAWSS3ClientWithConfig returns a new S3Client for the bucket of a configuration, using path style addressing
+func NewAWSS3ClientWithConfig(cfg S3ClientConfig) (S3Client, error) {
+	region := cfg.Region
+	if region == "" {
+		region = GetS3RegionFromEndpoint(cfg.Endpoint)
+	}
+	awsConfig := aws.Config{
+		Endpoint:         aws.String(cfg.Endpoint),
+		Region:           aws.String(region),
+		Credentials:      credentials.NewStaticCredentials(cfg.AccessKey, cfg.SecretKey, ""),
+		S3ForcePathStyle: aws.Bool(true),
+	}
+
+	if cfg.InsecureSkipVerify {
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
+		awsConfig.HTTPClient = &http.Client{Transport: transport}
+	}
+
+	// the CA bundle is passed as a session option, which takes precedence over the AWS_CA_BUNDLE environment variable
+	options := session.Options{Config: awsConfig}
+	if len(cfg.CABundle) > 0 {
+		options.CustomCABundle = bytes.NewReader(cfg.CABundle)
+	}
+	sess, err := session.NewSessionWithOptions(options)
+	if err != nil {
+		return nil, err
+	}
+
+	return &AWSS3Client{
+		Bucket:               cfg.Bucket,
+		Client:               s3.New(sess),
+		ServerSideEncryption: cfg.ServerSideEncryption,
+		KMSKeyID:             cfg.KMSKeyID,
+	}, nil
+}
+
+// GetS3RegionFromEndpoint returns the AWS region embedded in an endpoint URI (e.g. "https://s3-us-west-2.amazonaws.com"),
+// or a default region for endpoints that don't include one.
+func GetS3RegionFromEndpoint(endpoint string) string {
+	re := regexp.MustCompile(`s3[.-]([a-z0-9-]+)\.amazonaws\.com`)
+	match := re.FindStringSubmatch(endpoint)
+	if len(match) == 2 && match[1] != "external-1" {
+		return match[1]
+	}
+	return defaultS3Region
+}
+
+// ListObjects returns all the objects having the given prefix
+func (c *AWSS3Client) ListObjects(prefix string) ([]RemoteObject, error) {
+	var objects []RemoteObject
+	input := &s3.ListObjectsV2Input{
+		Bucket: aws.String(c.Bucket),
+		Prefix: aws.String(prefix),
+	}
+	err := c.Client.ListObjectsV2Pages(input, func(page *s3.ListObjectsV2Output, lastPage bool) bool {
+		for _, obj := range page.Contents {
+			objects = append(objects, RemoteObject{
+				Key:          aws.StringValue(obj.Key),
+				Etag:         strings.Trim(aws.StringValue(obj.ETag), `"`),
+				Size:         aws.Int64Value(obj.Size),
+				LastModified: aws.TimeValue(obj.LastModified),
+			})
+		}
+		return true
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Unable to list objects in bucket %s with prefix %s: %v", c.Bucket, prefix, err)
+	}
+	return objects, nil
+}
+
+// GetObject returns the contents of the object with the given key
+func (c *AWSS3Client) GetObject(key string) ([]byte, error) {
+	output, err := c.Client.GetObject(&s3.GetObjectInput{
+		Bucket: aws.String(c.Bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Unable to download object %s from bucket %s: %v", key, c.Bucket, err)
+	}
+	defer output.Body.Close()
+	return ioutil.ReadAll(output.Body)
+}
+
+// PutObject writes an object with the given key and contents
+func (c *AWSS3Client) PutObject(key string, data []byte) error {
+	input := &s3.PutObjectInput{
+		Bucket: aws.String(c.Bucket),
+		Key:    aws.String(key),
+		Body:   bytes.NewReader(data),
+	}
+	if c.ServerSideEncryption != "" {
+		input.ServerSideEncryption = aws.String(c.ServerSideEncryption)
+	}
+	if c.KMSKeyID != "" {
+		input.SSEKMSKeyId = aws.String(c.KMSKeyID)
+	}
+	_, err := c.Client.PutObject(input)
+	if err != nil {
+		return fmt.Errorf("Unable to upload object %s to bucket %s: %v", key, c.Bucket, err)
+	}
+	return nil
+}
+
+// DeleteObject deletes the object with the given key
+func (c *AWSS3Client) DeleteObject(key string) error {
+	_, err := c.Client.DeleteObject(&s3.DeleteObjectInput{
+		Bucket: aws.String(c.Bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return fmt.Errorf("Unable to delete object %s from bucket %s: %v", key, c.Bucket, err)
+	}
+	return nil
+}
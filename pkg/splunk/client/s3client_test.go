@@ -0,0 +1,215 @@
+// Copyright (c) 2018-2021 Splunk Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"errors"
+	"io/ioutil"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
+)
+
+// mockS3API is used to replicate the AWS S3 API for unit tests
+type mockS3API struct {
+	s3iface.S3API
+	pages   []*s3.ListObjectsV2Output
+	objects map[string]string
+	puts    []*s3.PutObjectInput
+	err     error
+}
+
+func (m *mockS3API) ListObjectsV2Pages(input *s3.ListObjectsV2Input, fn func(*s3.ListObjectsV2Output, bool) bool) error {
+	if m.err != nil {
+		return m.err
+	}
+	for n, page := range m.pages {
+		if !fn(page, n == len(m.pages)-1) {
+			break
+		}
+	}
+	return nil
+}
+
+func (m *mockS3API) GetObject(input *s3.GetObjectInput) (*s3.GetObjectOutput, error) {
+	body, ok := m.objects[aws.StringValue(input.Key)]
+	if !ok {
+		return nil, errors.New("NoSuchKey")
+	}
+	return &s3.GetObjectOutput{Body: ioutil.NopCloser(strings.NewReader(body))}, nil
+}
+
+func (m *mockS3API) PutObject(input *s3.PutObjectInput) (*s3.PutObjectOutput, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	body, err := ioutil.ReadAll(input.Body)
+	if err != nil {
+		return nil, err
+	}
+	m.puts = append(m.puts, input)
+	m.objects[aws.StringValue(input.Key)] = string(body)
+	return &s3.PutObjectOutput{}, nil
+}
+
+func (m *mockS3API) DeleteObject(input *s3.DeleteObjectInput) (*s3.DeleteObjectOutput, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	delete(m.objects, aws.StringValue(input.Key))
+	return &s3.DeleteObjectOutput{}, nil
+}
+
+func TestGetS3RegionFromEndpoint(t *testing.T) {
+	test := func(endpoint, want string) {
+		got := GetS3RegionFromEndpoint(endpoint)
+		if got != want {
+			t.Errorf("GetS3RegionFromEndpoint(\"%s\") = %s; want %s", endpoint, got, want)
+		}
+	}
+
+	test("https://s3-us-west-2.amazonaws.com", "us-west-2")
+	test("https://s3.eu-central-1.amazonaws.com", "eu-central-1")
+	test("https://s3-external-1.amazonaws.com", defaultS3Region)
+	test("http://minio.minio.svc.cluster.local:9000", defaultS3Region)
+}
+
+func TestAWSS3ClientListObjects(t *testing.T) {
+	modified := time.Date(2021, 3, 1, 10, 0, 0, 0, time.UTC)
+	mock := &mockS3API{
+		pages: []*s3.ListObjectsV2Output{
+			{Contents: []*s3.Object{
+				{Key: aws.String("apps/adminApps/app1.tgz"), ETag: aws.String(`"abc"`), Size: aws.Int64(10), LastModified: aws.Time(modified)},
+			}},
+			{Contents: []*s3.Object{
+				{Key: aws.String("apps/adminApps/app2.spl"), ETag: aws.String(`"def"`), Size: aws.Int64(20), LastModified: aws.Time(modified)},
+			}},
+		},
+	}
+	c := AWSS3Client{Bucket: "splunk-apps", Client: mock}
+	got, err := c.ListObjects("apps/adminApps/")
+	if err != nil {
+		t.Errorf("ListObjects() returned error: %v", err)
+	}
+	want := []RemoteObject{
+		{Key: "apps/adminApps/app1.tgz", Etag: "abc", Size: 10, LastModified: modified},
+		{Key: "apps/adminApps/app2.spl", Etag: "def", Size: 20, LastModified: modified},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ListObjects() = %v; want %v", got, want)
+	}
+
+	mock.err = errors.New("AccessDenied")
+	_, err = c.ListObjects("apps/adminApps/")
+	if err == nil {
+		t.Errorf("ListObjects() returned nil; want error")
+	}
+}
+
+func TestAWSS3ClientGetObject(t *testing.T) {
+	mock := &mockS3API{objects: map[string]string{"apps/adminApps/app1.tgz": "package"}}
+	c := AWSS3Client{Bucket: "splunk-apps", Client: mock}
+	got, err := c.GetObject("apps/adminApps/app1.tgz")
+	if err != nil {
+		t.Errorf("GetObject() returned error: %v", err)
+	}
+	if string(got) != "package" {
+		t.Errorf("GetObject() = %s; want %s", string(got), "package")
+	}
+
+	_, err = c.GetObject("apps/adminApps/missing.tgz")
+	if err == nil {
+		t.Errorf("GetObject() returned nil; want error")
+	}
+}
+
+func TestAWSS3ClientPutObject(t *testing.T) {
+	mock := &mockS3API{objects: map[string]string{}}
+	c := AWSS3Client{Bucket: "smartstore", Client: mock}
+	err := c.PutObject("indexes/probe", []byte("probe"))
+	if err != nil {
+		t.Errorf("PutObject() returned error: %v", err)
+	}
+	if mock.objects["indexes/probe"] != "probe" {
+		t.Errorf("PutObject() did not upload the object: %v", mock.objects)
+	}
+	if mock.puts[0].ServerSideEncryption != nil || mock.puts[0].SSEKMSKeyId != nil {
+		t.Errorf("PutObject() requested server-side encryption without being configured")
+	}
+
+	c.ServerSideEncryption = "aws:kms"
+	c.KMSKeyID = "alias/splunk"
+	err = c.PutObject("indexes/probe", []byte("probe"))
+	if err != nil {
+		t.Errorf("PutObject() returned error: %v", err)
+	}
+	if aws.StringValue(mock.puts[1].ServerSideEncryption) != "aws:kms" || aws.StringValue(mock.puts[1].SSEKMSKeyId) != "alias/splunk" {
+		t.Errorf("PutObject() requested encryption %s with key %s; want aws:kms with alias/splunk",
+			aws.StringValue(mock.puts[1].ServerSideEncryption), aws.StringValue(mock.puts[1].SSEKMSKeyId))
+	}
+
+	mock.err = errors.New("AccessDenied")
+	err = c.PutObject("indexes/probe", []byte("probe"))
+	if err == nil {
+		t.Errorf("PutObject() returned nil; want error")
+	}
+}
+
+func TestAWSS3ClientDeleteObject(t *testing.T) {
+	mock := &mockS3API{objects: map[string]string{"indexes/probe": "probe"}}
+	c := AWSS3Client{Bucket: "smartstore", Client: mock}
+	err := c.DeleteObject("indexes/probe")
+	if err != nil {
+		t.Errorf("DeleteObject() returned error: %v", err)
+	}
+	if _, ok := mock.objects["indexes/probe"]; ok {
+		t.Errorf("DeleteObject() did not delete the object")
+	}
+
+	mock.err = errors.New("AccessDenied")
+	err = c.DeleteObject("indexes/probe")
+	if err == nil {
+		t.Errorf("DeleteObject() returned nil; want error")
+	}
+}
+
+func TestNewAWSS3ClientWithConfig(t *testing.T) {
+	cfg := S3ClientConfig{
+		Endpoint:             "https://s3.eu-west-2.amazonaws.com",
+		Bucket:               "smartstore",
+		AccessKey:            "access",
+		SecretKey:            "secret",
+		ServerSideEncryption: "AES256",
+	}
+	c, err := NewAWSS3ClientWithConfig(cfg)
+	if err != nil {
+		t.Errorf("NewAWSS3ClientWithConfig() returned error: %v", err)
+	}
+	got := c.(*AWSS3Client)
+	if got.Bucket != "smartstore" || got.ServerSideEncryption != "AES256" {
+		t.Errorf("NewAWSS3ClientWithConfig() = %v; want bucket smartstore with AES256 encryption", got)
+	}
+
+	cfg.CABundle = []byte("not a certificate")
+	_, err = NewAWSS3ClientWithConfig(cfg)
+	if err == nil {
+		t.Errorf("NewAWSS3ClientWithConfig() returned nil with an invalid CA bundle; want error")
+	}
+}
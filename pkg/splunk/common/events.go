@@ -0,0 +1,104 @@
+// Copyright (c) 2018-2021 Splunk Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/record"
+)
+
+// reasons of the Events recorded on custom resources by the operator
+const (
+	// EventReasonScalingUp is recorded when the replicas of a StatefulSet are increased
+	EventReasonScalingUp = "ScalingUp"
+
+	// EventReasonScalingDown is recorded when the replicas of a StatefulSet are decreased
+	EventReasonScalingDown = "ScalingDown"
+
+	// EventReasonPodRecycle is recorded when a pod is deleted to pick up a new StatefulSet revision
+	EventReasonPodRecycle = "PodRecycle"
+
+	// EventReasonCaptaincyTransfer is recorded when the captaincy of a search head cluster is transferred to another member
+	EventReasonCaptaincyTransfer = "CaptaincyTransfer"
+
+	// EventReasonSearchDrainTimeout is recorded when a search head is removed or recycled with active searches after the drain timeout
+	EventReasonSearchDrainTimeout = "SearchDrainTimeout"
+
+	// EventReasonDecommissionStarted is recorded when an indexer cluster peer starts decommissioning
+	EventReasonDecommissionStarted = "DecommissionStarted"
+
+	// EventReasonDecommissionComplete is recorded when an indexer cluster peer has finished decommissioning
+	EventReasonDecommissionComplete = "DecommissionComplete"
+
+	// EventReasonBundlePush is recorded when the cluster master apps bundle is pushed to the peers
+	EventReasonBundlePush = "BundlePush"
+
+	// EventReasonRollingRestart is recorded when the cluster master starts or completes a searchable rolling restart of the peers
+	EventReasonRollingRestart = "RollingRestart"
+
+	// EventReasonMaintenanceMode is recorded when cluster master maintenance mode is enabled or disabled
+	EventReasonMaintenanceMode = "MaintenanceMode"
+
+	// EventReasonServiceAccountMissing is recorded when SmartStore volumes use an IAM role, but the pods have no service account to annotate
+	EventReasonServiceAccountMissing = "ServiceAccountMissing"
+
+	// EventReasonUpgradeStarted is recorded when the pods of a custom resource start rolling out a new image
+	EventReasonUpgradeStarted = "UpgradeStarted"
+
+	// EventReasonUpgradeComplete is recorded when all the pods of a custom resource are ready on a new image
+	EventReasonUpgradeComplete = "UpgradeComplete"
+
+	// EventReasonUpgradeRefused is recorded when the version checks refuse the new image of a custom resource
+	EventReasonUpgradeRefused = "UpgradeRefused"
+
+	// EventReasonSecretChanged is recorded when a secret from the namespace scoped secret is applied to a Splunk instance
+	EventReasonSecretChanged = "SecretChanged"
+
+	// EventReasonFinalizerProcessed is recorded when a finalizer of a custom resource has been processed
+	EventReasonFinalizerProcessed = "FinalizerProcessed"
+
+	// EventReasonFinalizerFailed is recorded when a finalizer of a custom resource could not be processed
+	EventReasonFinalizerFailed = "FinalizerFailed"
+)
+
+// EventPublisher records Kubernetes Events on a custom resource.
+// Events are dropped by a nil EventPublisher, or one created without an EventRecorder.
+type EventPublisher struct {
+	recorder record.EventRecorder
+	instance MetaObject
+}
+
+// NewEventPublisher returns an EventPublisher that records Events on instance
+func NewEventPublisher(recorder record.EventRecorder, instance MetaObject) *EventPublisher {
+	return &EventPublisher{recorder: recorder, instance: instance}
+}
+
+// Normal records an Event of type Normal
+func (p *EventPublisher) Normal(reason, messageFmt string, args ...interface{}) {
+	p.publish(corev1.EventTypeNormal, reason, messageFmt, args...)
+}
+
+// Warning records an Event of type Warning
+func (p *EventPublisher) Warning(reason, messageFmt string, args ...interface{}) {
+	p.publish(corev1.EventTypeWarning, reason, messageFmt, args...)
+}
+
+// publish records an Event of the given type, unless events are disabled
+func (p *EventPublisher) publish(eventType, reason, messageFmt string, args ...interface{}) {
+	if p == nil || p.recorder == nil || p.instance == nil {
+		return
+	}
+	p.recorder.Eventf(p.instance, eventType, reason, messageFmt, args...)
+}
@@ -0,0 +1,55 @@
+// Copyright (c) 2018-2021 Splunk Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+)
+
+func TestEventPublisher(t *testing.T) {
+	cr := TestResource{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "stack1",
+			Namespace: "test",
+		},
+	}
+	recorder := record.NewFakeRecorder(10)
+	publisher := NewEventPublisher(recorder, &cr)
+
+	publisher.Normal(EventReasonScalingUp, "Scaling %s up to %d replicas", "stack1", 3)
+	publisher.Warning(EventReasonFinalizerFailed, "Failed")
+
+	want := []string{
+		"Normal ScalingUp Scaling stack1 up to 3 replicas",
+		"Warning FinalizerFailed Failed",
+	}
+	for _, w := range want {
+		got := <-recorder.Events
+		if got != w {
+			t.Errorf("EventPublisher recorded %q; want %q", got, w)
+		}
+	}
+
+	// events are dropped without a recorder
+	var nilPublisher *EventPublisher
+	nilPublisher.Normal(EventReasonScalingUp, "dropped")
+	NewEventPublisher(nil, &cr).Normal(EventReasonScalingUp, "dropped")
+	if len(recorder.Events) != 0 {
+		t.Errorf("EventPublisher recorded %d unexpected events", len(recorder.Events))
+	}
+}
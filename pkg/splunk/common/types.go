@@ -131,3 +131,10 @@ type StatefulSetPodManager interface {
 	// FinishRecycle completes recycle event for pod and returns true, or returns false if nothing to do
 	FinishRecycle(int32) (bool, error)
 }
+
+// StatefulSetPodRecycleOrderer is implemented by a StatefulSetPodManager that needs one of the pods to be recycled after
+// all the others, such as the captain of a search head cluster
+type StatefulSetPodRecycleOrderer interface {
+	// RecycleLast returns the ordinal of the pod to recycle last, or -1 if there is none
+	RecycleLast() int32
+}
@@ -310,6 +310,38 @@ func AppendPodAntiAffinity(affinity *corev1.Affinity, identifier string, typeLab
 	return affinity
 }
 
+// AppendNodeAffinity appends a Kubernetes Affinity object to require nodes with the given label value, and returns the result.
+// The requirement is added to each of the existing node selector terms, since only one of them needs to match.
+func AppendNodeAffinity(affinity *corev1.Affinity, key string, value string) *corev1.Affinity {
+	if affinity == nil {
+		affinity = &corev1.Affinity{}
+	} else {
+		affinity = affinity.DeepCopy()
+	}
+
+	if affinity.NodeAffinity == nil {
+		affinity.NodeAffinity = &corev1.NodeAffinity{}
+	}
+	if affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution == nil {
+		affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution = &corev1.NodeSelector{}
+	}
+	nodeSelector := affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution
+	if len(nodeSelector.NodeSelectorTerms) == 0 {
+		nodeSelector.NodeSelectorTerms = []corev1.NodeSelectorTerm{{}}
+	}
+
+	requirement := corev1.NodeSelectorRequirement{
+		Key:      key,
+		Operator: corev1.NodeSelectorOpIn,
+		Values:   []string{value},
+	}
+	for i := range nodeSelector.NodeSelectorTerms {
+		nodeSelector.NodeSelectorTerms[i].MatchExpressions = append(nodeSelector.NodeSelectorTerms[i].MatchExpressions, requirement)
+	}
+
+	return affinity
+}
+
 // ValidateImagePullPolicy checks validity of the ImagePullPolicy spec parameter, and returns error if it is invalid.
 func ValidateImagePullPolicy(imagePullPolicy *string) error {
 	// ImagePullPolicy
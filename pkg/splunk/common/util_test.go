@@ -692,6 +692,60 @@ func TestAppendPodAffinity(t *testing.T) {
 	})
 }
 
+func TestAppendNodeAffinity(t *testing.T) {
+	var affinity corev1.Affinity
+
+	test := func(want corev1.Affinity) {
+		got := AppendNodeAffinity(&affinity, "topology.kubernetes.io/zone", "zone-1a")
+		f := func() bool {
+			return CompareByMarshall(got, want)
+		}
+		compareTester(t, "AppendNodeAffinity()", f, got, want, false)
+	}
+
+	wantAppended := corev1.NodeSelectorRequirement{
+		Key:      "topology.kubernetes.io/zone",
+		Operator: corev1.NodeSelectorOpIn,
+		Values:   []string{"zone-1a"},
+	}
+	test(corev1.Affinity{
+		NodeAffinity: &corev1.NodeAffinity{
+			RequiredDuringSchedulingIgnoredDuringExecution: &corev1.NodeSelector{
+				NodeSelectorTerms: []corev1.NodeSelectorTerm{
+					{MatchExpressions: []corev1.NodeSelectorRequirement{wantAppended}},
+				},
+			},
+		},
+	})
+
+	// the requirement is added to each existing term
+	diskType := corev1.NodeSelectorRequirement{Key: "disktype", Operator: corev1.NodeSelectorOpIn, Values: []string{"ssd"}}
+	gpu := corev1.NodeSelectorRequirement{Key: "gpu", Operator: corev1.NodeSelectorOpExists}
+	affinity = corev1.Affinity{
+		NodeAffinity: &corev1.NodeAffinity{
+			RequiredDuringSchedulingIgnoredDuringExecution: &corev1.NodeSelector{
+				NodeSelectorTerms: []corev1.NodeSelectorTerm{
+					{MatchExpressions: []corev1.NodeSelectorRequirement{diskType}},
+					{MatchExpressions: []corev1.NodeSelectorRequirement{gpu}},
+				},
+			},
+		},
+	}
+	test(corev1.Affinity{
+		NodeAffinity: &corev1.NodeAffinity{
+			RequiredDuringSchedulingIgnoredDuringExecution: &corev1.NodeSelector{
+				NodeSelectorTerms: []corev1.NodeSelectorTerm{
+					{MatchExpressions: []corev1.NodeSelectorRequirement{diskType, wantAppended}},
+					{MatchExpressions: []corev1.NodeSelectorRequirement{gpu, wantAppended}},
+				},
+			},
+		},
+	})
+	if len(affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms[0].MatchExpressions) != 1 {
+		t.Errorf("AppendNodeAffinity() modified its argument")
+	}
+}
+
 func TestValidateSpec(t *testing.T) {
 	spec := Spec{}
 	defaultResources := corev1.ResourceRequirements{
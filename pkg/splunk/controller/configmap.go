@@ -75,3 +75,22 @@ func GetConfigMapResourceVersion(client splcommon.ControllerClient, namespacedNa
 	}
 	return configMap.ResourceVersion, nil
 }
+
+// SetConfigMapOwnerRef sets owner references for configMap
+func SetConfigMapOwnerRef(client splcommon.ControllerClient, cr splcommon.MetaObject, namespacedName types.NamespacedName) error {
+	configMap, err := GetConfigMap(client, namespacedName)
+	if err != nil {
+		return err
+	}
+
+	// Check if owner ref exists
+	for _, ownerRef := range configMap.GetOwnerReferences() {
+		if reflect.DeepEqual(ownerRef, splcommon.AsOwner(cr, false)) {
+			return nil
+		}
+	}
+
+	// Owner ref doesn't exist, update configMap with owner references
+	configMap.SetOwnerReferences(append(configMap.GetOwnerReferences(), splcommon.AsOwner(cr, false)))
+	return splutil.UpdateResource(client, configMap)
+}
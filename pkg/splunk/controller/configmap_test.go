@@ -21,6 +21,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 
+	enterprisev1 "github.com/splunk/splunk-operator/pkg/apis/enterprise/v1"
 	spltest "github.com/splunk/splunk-operator/pkg/splunk/test"
 )
 
@@ -101,3 +102,47 @@ func TestGetConfigMapResourceVersion(t *testing.T) {
 		t.Errorf("Should not return an error, when the configMap exists")
 	}
 }
+
+func TestSetConfigMapOwnerRef(t *testing.T) {
+	cr := enterprisev1.MonitoringConsole{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "stack1",
+			Namespace: "test",
+		},
+	}
+	current := corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "splunk-stack1-monitoring-console",
+			Namespace: "test",
+		},
+	}
+
+	client := spltest.NewMockClient()
+	namespacedName := types.NamespacedName{Namespace: current.GetNamespace(), Name: current.GetName()}
+
+	err := SetConfigMapOwnerRef(client, &cr, namespacedName)
+	if err == nil {
+		t.Errorf("Should return an error, when the configMap doesn't exist")
+	}
+
+	_, err = ApplyConfigMap(client, &current)
+	if err != nil {
+		t.Errorf("Failed to create the configMap. Error: %s", err.Error())
+	}
+
+	err = SetConfigMapOwnerRef(client, &cr, namespacedName)
+	if err != nil {
+		t.Errorf("Couldn't set owner ref for configMap %s", current.GetName())
+	}
+
+	// Try adding same owner again
+	err = SetConfigMapOwnerRef(client, &cr, namespacedName)
+	if err != nil {
+		t.Errorf("Couldn't set owner ref for configMap %s", current.GetName())
+	}
+
+	configMap, _ := GetConfigMap(client, namespacedName)
+	if len(configMap.GetOwnerReferences()) != 1 {
+		t.Errorf("configMap has %d owner references; want 1", len(configMap.GetOwnerReferences()))
+	}
+}
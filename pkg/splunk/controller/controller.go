@@ -16,9 +16,12 @@ package controller
 
 import (
 	"context"
+	"time"
 
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
@@ -27,8 +30,12 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/source"
 
 	splcommon "github.com/splunk/splunk-operator/pkg/splunk/common"
+	splmetrics "github.com/splunk/splunk-operator/pkg/splunk/metrics"
 )
 
+// eventSourceComponent is the component reported as the source of the Events recorded by Splunk controllers
+const eventSourceComponent = "splunk-operator"
+
 // SplunkController is used to represent common interfaces of Splunk controllers
 type SplunkController interface {
 
@@ -38,8 +45,9 @@ type SplunkController interface {
 	// GetWatchTypes returns a list of types owned by the controller that it would like to receive watch events for
 	GetWatchTypes() []runtime.Object
 
-	// Reconcile is used to perform an idempotent reconciliation of the custom resource managed by this controller
-	Reconcile(client.Client, splcommon.MetaObject) (reconcile.Result, error)
+	// Reconcile is used to perform an idempotent reconciliation of the custom resource managed by this controller.
+	// Events about significant actions taken on the custom resource are recorded with the EventRecorder.
+	Reconcile(client.Client, record.EventRecorder, splcommon.MetaObject) (reconcile.Result, error)
 }
 
 // AddToManager adds a specific Splunk Controller to the Manager.
@@ -50,8 +58,9 @@ func AddToManager(mgr manager.Manager, splctrl SplunkController, c client.Client
 	kind := instance.GetObjectKind().GroupVersionKind().Kind
 	opts := controller.Options{
 		Reconciler: splunkReconciler{
-			client:  c,
-			splctrl: splctrl,
+			client:   c,
+			recorder: mgr.GetEventRecorderFor(eventSourceComponent),
+			splctrl:  splctrl,
 		},
 	}
 	ctrl, err := controller.New(kind, mgr, opts)
@@ -86,8 +95,9 @@ var _ reconcile.Reconciler = &splunkReconciler{}
 type splunkReconciler struct {
 	// This client, initialized using mgr.Client() above, is a split client
 	// that reads objects from the cache and writes to the apiserver
-	client  client.Client
-	splctrl SplunkController
+	client   client.Client
+	recorder record.EventRecorder
+	splctrl  SplunkController
 }
 
 // Reconcile reads that state of the cluster for a custom resource
@@ -108,6 +118,7 @@ func (r splunkReconciler) Reconcile(request reconcile.Request) (reconcile.Result
 			// Request object not found, could have been deleted after reconcile request.
 			// Owned objects are automatically garbage collected. For additional cleanup logic use finalizers.
 			// Return and don't requeue
+			splmetrics.DeletePhase(gvk.Kind, request.Namespace, request.Name)
 			return reconcile.Result{}, nil
 		}
 		// Error reading the object - requeue the request.
@@ -118,7 +129,12 @@ func (r splunkReconciler) Reconcile(request reconcile.Request) (reconcile.Result
 	instance.SetGroupVersionKind(gvk)
 
 	// call Reconcile method defined for the controller
-	result, err := r.splctrl.Reconcile(r.client, instance)
+	start := time.Now()
+	result, err := r.splctrl.Reconcile(r.client, r.recorder, instance)
+	splmetrics.ObserveReconcile(gvk.Kind, time.Since(start), err)
+	if phase := getPhase(instance); phase != "" {
+		splmetrics.SetPhase(gvk.Kind, request.Namespace, request.Name, phase)
+	}
 
 	// log what happens next
 	if err != nil {
@@ -133,3 +149,13 @@ func (r splunkReconciler) Reconcile(request reconcile.Request) (reconcile.Result
 	scopedLog.Info("Reconciliation complete")
 	return reconcile.Result{}, nil
 }
+
+// getPhase returns the phase reported in the status of a custom resource, or an empty string if it has none
+func getPhase(instance splcommon.MetaObject) string {
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(instance)
+	if err != nil {
+		return ""
+	}
+	phase, _, _ := unstructured.NestedString(content, "status", "phase")
+	return phase
+}
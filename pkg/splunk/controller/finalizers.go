@@ -34,8 +34,8 @@ type SplunkFinalizerMethod func(splcommon.MetaObject, splcommon.ControllerClient
 var SplunkFinalizerRegistry map[string]SplunkFinalizerMethod
 
 // CheckForDeletion checks to see if deletion was requested for the custom resource.
-// If so, it will process and remove any remaining finalizers.
-func CheckForDeletion(cr splcommon.MetaObject, c splcommon.ControllerClient) (bool, error) {
+// If so, it will process and remove any remaining finalizers, recording an Event for each of them.
+func CheckForDeletion(cr splcommon.MetaObject, c splcommon.ControllerClient, eventPublisher *splcommon.EventPublisher) (bool, error) {
 	scopedLog := log.WithName("CheckSplunkDeletion").WithValues("kind", cr.GetObjectKind().GroupVersionKind().Kind,
 		"name", cr.GetName(), "namespace", cr.GetNamespace())
 	currentTime := metav1.Now()
@@ -68,14 +68,17 @@ func CheckForDeletion(cr splcommon.MetaObject, c splcommon.ControllerClient) (bo
 		scopedLog.Info("Processing callback", "Finalizer", finalizer)
 		err := callback(cr, c)
 		if err != nil {
+			eventPublisher.Warning(splcommon.EventReasonFinalizerFailed, "Failed to process finalizer %s: %v", finalizer, err)
 			return false, err
 		}
 
 		// remove finalizer from custom resource
 		err = removeSplunkFinalizer(cr, c, finalizer)
 		if err != nil {
+			eventPublisher.Warning(splcommon.EventReasonFinalizerFailed, "Failed to remove finalizer %s: %v", finalizer, err)
 			return false, err
 		}
+		eventPublisher.Normal(splcommon.EventReasonFinalizerProcessed, "Processed finalizer %s", finalizer)
 	}
 
 	scopedLog.Info("Deletion complete")
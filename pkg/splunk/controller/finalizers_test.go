@@ -20,6 +20,7 @@ import (
 
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
 
 	splcommon "github.com/splunk/splunk-operator/pkg/splunk/common"
 	spltest "github.com/splunk/splunk-operator/pkg/splunk/test"
@@ -50,7 +51,8 @@ func TestCheckForDeletion(t *testing.T) {
 
 	mockCalls := make(map[string][]spltest.MockFuncCall)
 	mockCalls["Update"] = []spltest.MockFuncCall{{MetaName: "*v1.ConfigMap-test-defaults"}}
-	_, err := CheckForDeletion(&cr, c)
+	recorder := record.NewFakeRecorder(10)
+	_, err := CheckForDeletion(&cr, c, splcommon.NewEventPublisher(recorder, &cr))
 	if err != nil {
 		t.Errorf("TestCheckForDeletion() returned %v; want nil", err)
 	}
@@ -58,4 +60,8 @@ func TestCheckForDeletion(t *testing.T) {
 	if !gotCallback {
 		t.Errorf("TestCheckForDeletion() did not call finalizer method")
 	}
+	want := "Normal FinalizerProcessed Processed finalizer " + dummyFinalizer
+	if len(recorder.Events) != 1 || <-recorder.Events != want {
+		t.Errorf("TestCheckForDeletion() did not record event %q", want)
+	}
 }
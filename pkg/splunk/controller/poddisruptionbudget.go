@@ -0,0 +1,77 @@
+// Copyright (c) 2018-2021 Splunk Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"context"
+	"reflect"
+
+	policyv1beta1 "k8s.io/api/policy/v1beta1"
+	"k8s.io/apimachinery/pkg/types"
+
+	splcommon "github.com/splunk/splunk-operator/pkg/splunk/common"
+	splutil "github.com/splunk/splunk-operator/pkg/splunk/util"
+)
+
+// ApplyPodDisruptionBudget creates or updates a Kubernetes PodDisruptionBudget
+func ApplyPodDisruptionBudget(client splcommon.ControllerClient, revised *policyv1beta1.PodDisruptionBudget) error {
+	scopedLog := log.WithName("ApplyPodDisruptionBudget").WithValues(
+		"name", revised.GetObjectMeta().GetName(),
+		"namespace", revised.GetObjectMeta().GetNamespace())
+
+	namespacedName := types.NamespacedName{Namespace: revised.GetNamespace(), Name: revised.GetName()}
+	var current policyv1beta1.PodDisruptionBudget
+
+	err := client.Get(context.TODO(), namespacedName, &current)
+	if err != nil {
+		return splutil.CreateResource(client, revised)
+	}
+
+	// the selector of a PodDisruptionBudget is immutable before Kubernetes 1.15, and never changes for a StatefulSet
+	hasUpdates := !reflect.DeepEqual(current.Spec.MinAvailable, revised.Spec.MinAvailable) ||
+		!reflect.DeepEqual(current.Spec.MaxUnavailable, revised.Spec.MaxUnavailable)
+	current.Spec.MinAvailable = revised.Spec.MinAvailable
+	current.Spec.MaxUnavailable = revised.Spec.MaxUnavailable
+	*revised = current // caller expects that object passed represents latest state
+
+	// only update if there are material differences
+	if hasUpdates {
+		scopedLog.Info("Updating existing PodDisruptionBudget")
+		return splutil.UpdateResource(client, revised)
+	}
+
+	// all is good!
+	scopedLog.Info("No update to existing PodDisruptionBudget")
+	return nil
+}
+
+// DeletePodDisruptionBudget deletes a Kubernetes PodDisruptionBudget owned by cr, if it exists
+func DeletePodDisruptionBudget(client splcommon.ControllerClient, cr splcommon.MetaObject, namespacedName types.NamespacedName) error {
+	var current policyv1beta1.PodDisruptionBudget
+	err := client.Get(context.TODO(), namespacedName, &current)
+	if err != nil {
+		return nil
+	}
+
+	// keep PodDisruptionBudgets that were not created for cr
+	for _, ownerRef := range current.GetOwnerReferences() {
+		if ownerRef.UID == cr.GetUID() {
+			log.WithName("DeletePodDisruptionBudget").Info("Deleting PodDisruptionBudget",
+				"name", namespacedName.Name, "namespace", namespacedName.Namespace)
+			return splutil.DeleteResource(client, &current)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,95 @@
+// Copyright (c) 2018-2021 Splunk Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"testing"
+
+	policyv1beta1 "k8s.io/api/policy/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	enterprisev1 "github.com/splunk/splunk-operator/pkg/apis/enterprise/v1"
+	spltest "github.com/splunk/splunk-operator/pkg/splunk/test"
+)
+
+func TestApplyPodDisruptionBudget(t *testing.T) {
+	funcCalls := []spltest.MockFuncCall{{MetaName: "*v1beta1.PodDisruptionBudget-test-splunk-stack1-indexer"}}
+	createCalls := map[string][]spltest.MockFuncCall{"Get": funcCalls, "Create": funcCalls}
+	updateCalls := map[string][]spltest.MockFuncCall{"Get": funcCalls, "Update": funcCalls}
+	maxUnavailable := intstr.FromInt(1)
+	current := policyv1beta1.PodDisruptionBudget{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "splunk-stack1-indexer",
+			Namespace: "test",
+		},
+		Spec: policyv1beta1.PodDisruptionBudgetSpec{
+			MaxUnavailable: &maxUnavailable,
+		},
+	}
+	revised := current.DeepCopy()
+	minAvailable := intstr.FromInt(2)
+	revised.Spec.MinAvailable = &minAvailable
+	revised.Spec.MaxUnavailable = nil
+	reconcile := func(c *spltest.MockClient, cr interface{}) error {
+		return ApplyPodDisruptionBudget(c, cr.(*policyv1beta1.PodDisruptionBudget))
+	}
+	spltest.ReconcileTester(t, "TestApplyPodDisruptionBudget", &current, revised, createCalls, updateCalls, reconcile, false)
+}
+
+func TestDeletePodDisruptionBudget(t *testing.T) {
+	cr := enterprisev1.IndexerCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "stack1",
+			Namespace: "test",
+			UID:       "0123",
+		},
+	}
+	namespacedName := types.NamespacedName{Namespace: "test", Name: "splunk-stack1-indexer"}
+	funcCalls := []spltest.MockFuncCall{{MetaName: "*v1beta1.PodDisruptionBudget-test-splunk-stack1-indexer"}}
+	pdb := policyv1beta1.PodDisruptionBudget{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "splunk-stack1-indexer",
+			Namespace: "test",
+		},
+	}
+
+	// nothing to delete if there is no PodDisruptionBudget
+	c := spltest.NewMockClient()
+	err := DeletePodDisruptionBudget(c, &cr, namespacedName)
+	if err != nil {
+		t.Errorf("DeletePodDisruptionBudget() returned error: %v", err)
+	}
+	c.CheckCalls(t, "TestDeletePodDisruptionBudget(missing)", map[string][]spltest.MockFuncCall{"Get": funcCalls})
+
+	// PodDisruptionBudgets created by someone else are kept
+	c = spltest.NewMockClient()
+	c.AddObject(&pdb)
+	err = DeletePodDisruptionBudget(c, &cr, namespacedName)
+	if err != nil {
+		t.Errorf("DeletePodDisruptionBudget() returned error: %v", err)
+	}
+	c.CheckCalls(t, "TestDeletePodDisruptionBudget(not owned)", map[string][]spltest.MockFuncCall{"Get": funcCalls})
+
+	pdb.SetOwnerReferences([]metav1.OwnerReference{{Kind: "IndexerCluster", Name: "stack1", UID: "0123"}})
+	c = spltest.NewMockClient()
+	c.AddObject(&pdb)
+	err = DeletePodDisruptionBudget(c, &cr, namespacedName)
+	if err != nil {
+		t.Errorf("DeletePodDisruptionBudget() returned error: %v", err)
+	}
+	c.CheckCalls(t, "TestDeletePodDisruptionBudget(owned)", map[string][]spltest.MockFuncCall{"Get": funcCalls, "Delete": funcCalls})
+}
@@ -25,17 +25,21 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	splcommon "github.com/splunk/splunk-operator/pkg/splunk/common"
+	splmetrics "github.com/splunk/splunk-operator/pkg/splunk/metrics"
 	splutil "github.com/splunk/splunk-operator/pkg/splunk/util"
 )
 
 // DefaultStatefulSetPodManager is a simple StatefulSetPodManager that does nothing
-type DefaultStatefulSetPodManager struct{}
+type DefaultStatefulSetPodManager struct {
+	// EventPublisher records Events for scaling and pod recycling on the custom resource owning the StatefulSet
+	EventPublisher *splcommon.EventPublisher
+}
 
 // Update for DefaultStatefulSetPodManager handles all updates for a statefulset of standard pods
 func (mgr *DefaultStatefulSetPodManager) Update(client splcommon.ControllerClient, statefulSet *appsv1.StatefulSet, desiredReplicas int32) (splcommon.Phase, error) {
 	phase, err := ApplyStatefulSet(client, statefulSet)
 	if err == nil && phase == splcommon.PhaseReady {
-		phase, err = UpdateStatefulSetPods(client, statefulSet, mgr, desiredReplicas)
+		phase, err = UpdateStatefulSetPods(client, mgr.EventPublisher, statefulSet, mgr, desiredReplicas)
 	}
 	return phase, err
 }
@@ -96,7 +100,7 @@ func ApplyStatefulSet(c splcommon.ControllerClient, revised *appsv1.StatefulSet)
 }
 
 // UpdateStatefulSetPods manages scaling and config updates for StatefulSets
-func UpdateStatefulSetPods(c splcommon.ControllerClient, statefulSet *appsv1.StatefulSet, mgr splcommon.StatefulSetPodManager, desiredReplicas int32) (splcommon.Phase, error) {
+func UpdateStatefulSetPods(c splcommon.ControllerClient, eventPublisher *splcommon.EventPublisher, statefulSet *appsv1.StatefulSet, mgr splcommon.StatefulSetPodManager, desiredReplicas int32) (splcommon.Phase, error) {
 	scopedLog := log.WithName("UpdateStatefulSetPods").WithValues(
 		"name", statefulSet.GetObjectMeta().GetName(),
 		"namespace", statefulSet.GetObjectMeta().GetNamespace())
@@ -121,6 +125,7 @@ func UpdateStatefulSetPods(c splcommon.ControllerClient, statefulSet *appsv1.Sta
 	if readyReplicas < desiredReplicas {
 		// scale up StatefulSet to match desiredReplicas
 		scopedLog.Info("Scaling replicas up", "replicas", desiredReplicas)
+		eventPublisher.Normal(splcommon.EventReasonScalingUp, "Scaling %s up from %d to %d replicas", statefulSet.GetName(), readyReplicas, desiredReplicas)
 		*statefulSet.Spec.Replicas = desiredReplicas
 		return splcommon.PhaseScalingUp, splutil.UpdateResource(c, statefulSet)
 	}
@@ -142,6 +147,7 @@ func UpdateStatefulSetPods(c splcommon.ControllerClient, statefulSet *appsv1.Sta
 
 		// scale down statefulset to terminate pod
 		scopedLog.Info("Scaling replicas down", "replicas", n)
+		eventPublisher.Normal(splcommon.EventReasonScalingDown, "Scaling %s down from %d to %d replicas", statefulSet.GetName(), readyReplicas, n)
 		*statefulSet.Spec.Replicas = n
 		err = splutil.UpdateResource(c, statefulSet)
 		if err != nil {
@@ -175,8 +181,21 @@ func UpdateStatefulSetPods(c splcommon.ControllerClient, statefulSet *appsv1.Sta
 	// ready and no StatefulSet scaling is required
 	// readyReplicas == desiredReplicas
 
-	// check existing pods for desired updates
+	// check existing pods for desired updates, from the highest ordinal unless the manager recycles a pod last
+	last := int32(-1)
+	if orderer, ok := mgr.(splcommon.StatefulSetPodRecycleOrderer); ok {
+		last = orderer.RecycleLast()
+	}
+	ordinals := make([]int32, 0, readyReplicas)
 	for n := readyReplicas - 1; n >= 0; n-- {
+		if n != last {
+			ordinals = append(ordinals, n)
+		}
+	}
+	if last >= 0 && last < readyReplicas {
+		ordinals = append(ordinals, last)
+	}
+	for _, n := range ordinals {
 		// get Pod
 		podName := fmt.Sprintf("%s-%d", statefulSet.GetName(), n)
 		namespacedName := types.NamespacedName{Namespace: statefulSet.GetNamespace(), Name: podName}
@@ -208,12 +227,15 @@ func UpdateStatefulSetPods(c splcommon.ControllerClient, statefulSet *appsv1.Sta
 			scopedLog.Info("Recycling Pod for updates", "podName", podName,
 				"statefulSetRevision", statefulSet.Status.UpdateRevision,
 				"podRevision", pod.GetLabels()["controller-revision-hash"])
+			eventPublisher.Normal(splcommon.EventReasonPodRecycle, "Recycling pod %s from revision %s to %s",
+				podName, pod.GetLabels()["controller-revision-hash"], statefulSet.Status.UpdateRevision)
 			preconditions := client.Preconditions{UID: &pod.ObjectMeta.UID, ResourceVersion: &pod.ObjectMeta.ResourceVersion}
 			err = c.Delete(context.Background(), &pod, preconditions)
 			if err != nil {
 				scopedLog.Error(err, "Unable to delete Pod", "podName", podName)
 				return splcommon.PhaseError, err
 			}
+			splmetrics.IncPodsRecycled(statefulSet.GetNamespace(), statefulSet.GetName())
 
 			// only delete one at a time
 			return splcommon.PhaseUpdating, nil
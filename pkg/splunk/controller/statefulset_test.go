@@ -15,6 +15,7 @@
 package controller
 
 import (
+	"fmt"
 	"testing"
 
 	appsv1 "k8s.io/api/apps/v1"
@@ -22,6 +23,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 
 	spltest "github.com/splunk/splunk-operator/pkg/splunk/test"
 	splutil "github.com/splunk/splunk-operator/pkg/splunk/util"
@@ -64,7 +66,7 @@ func updateStatefulSetPodsTester(t *testing.T, mgr splcommon.StatefulSetPodManag
 	// initialize client
 	c := spltest.NewMockClient()
 	c.AddObjects(initObjects)
-	phase, err := UpdateStatefulSetPods(c, statefulSet, mgr, desiredReplicas)
+	phase, err := UpdateStatefulSetPods(c, nil, statefulSet, mgr, desiredReplicas)
 	return phase, err
 }
 
@@ -146,6 +148,136 @@ func TestUpdateStatefulSetPods(t *testing.T) {
 	}
 }
 
+func TestUpdateStatefulSetPodsEvents(t *testing.T) {
+	cr := enterprisev1.Standalone{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "stack1",
+			Namespace: "test",
+		},
+	}
+	recorder := record.NewFakeRecorder(10)
+	mgr := DefaultStatefulSetPodManager{EventPublisher: splcommon.NewEventPublisher(recorder, &cr)}
+	var replicas int32 = 1
+	statefulSet := &appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "splunk-stack1",
+			Namespace: "test",
+		},
+		Spec: appsv1.StatefulSetSpec{
+			Replicas: &replicas,
+		},
+		Status: appsv1.StatefulSetStatus{
+			Replicas:       replicas,
+			ReadyReplicas:  replicas,
+			UpdateRevision: "v1",
+		},
+	}
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "splunk-stack1-0",
+			Namespace: "test",
+			Labels: map[string]string{
+				"controller-revision-hash": "v0",
+			},
+		},
+		Status: corev1.PodStatus{
+			Phase: corev1.PodRunning,
+			ContainerStatuses: []corev1.ContainerStatus{
+				{Ready: true},
+			},
+		},
+	}
+	test := func(desiredReplicas int32, want string) {
+		c := spltest.NewMockClient()
+		c.AddObjects([]runtime.Object{statefulSet, pod})
+		_, err := UpdateStatefulSetPods(c, mgr.EventPublisher, statefulSet, &mgr, desiredReplicas)
+		if err != nil {
+			t.Errorf("UpdateStatefulSetPods() returned %v; want nil", err)
+		}
+		if len(recorder.Events) != 1 {
+			t.Errorf("UpdateStatefulSetPods() recorded %d events; want %q", len(recorder.Events), want)
+			return
+		}
+		if got := <-recorder.Events; got != want {
+			t.Errorf("UpdateStatefulSetPods() recorded %q; want %q", got, want)
+		}
+	}
+
+	test(1, "Normal PodRecycle Recycling pod splunk-stack1-0 from revision v0 to v1")
+	test(3, "Normal ScalingUp Scaling splunk-stack1 up from 1 to 3 replicas")
+	replicas = 3
+	statefulSet.Status.Replicas = 3
+	statefulSet.Status.ReadyReplicas = 3
+	test(1, "Normal ScalingDown Scaling splunk-stack1 down from 3 to 2 replicas")
+}
+
+// recycleLastPodManager is a DefaultStatefulSetPodManager that recycles one of the pods last
+type recycleLastPodManager struct {
+	DefaultStatefulSetPodManager
+	last int32
+}
+
+func (mgr *recycleLastPodManager) RecycleLast() int32 {
+	return mgr.last
+}
+
+func TestUpdateStatefulSetPodsRecycleLast(t *testing.T) {
+	var replicas int32 = 3
+	statefulSet := &appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "splunk-stack1",
+			Namespace: "test",
+		},
+		Spec: appsv1.StatefulSetSpec{
+			Replicas: &replicas,
+		},
+		Status: appsv1.StatefulSetStatus{
+			Replicas:       replicas,
+			ReadyReplicas:  replicas,
+			UpdateRevision: "v1",
+		},
+	}
+	newPod := func(name, revision string) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: "test",
+				Labels: map[string]string{
+					"controller-revision-hash": revision,
+				},
+			},
+			Status: corev1.PodStatus{
+				Phase: corev1.PodRunning,
+				ContainerStatuses: []corev1.ContainerStatus{
+					{Ready: true},
+				},
+			},
+		}
+	}
+	test := func(mgr splcommon.StatefulSetPodManager, revisions []string, want string) {
+		c := spltest.NewMockClient()
+		c.AddObject(statefulSet)
+		for n, revision := range revisions {
+			c.AddObject(newPod(fmt.Sprintf("splunk-stack1-%d", n), revision))
+		}
+		phase, err := UpdateStatefulSetPods(c, nil, statefulSet, mgr, replicas)
+		if err != nil || phase != splcommon.PhaseUpdating {
+			t.Errorf("UpdateStatefulSetPods() returned phase=%s, err=%v; want %s", phase, err, splcommon.PhaseUpdating)
+		}
+		deleted := c.Calls["Delete"]
+		if len(deleted) != 1 || deleted[0].Obj.(*corev1.Pod).GetName() != want {
+			t.Errorf("UpdateStatefulSetPods() deleted %v; want pod %s", deleted, want)
+		}
+	}
+
+	// pods are recycled from the highest ordinal, except for the one recycled last
+	test(&DefaultStatefulSetPodManager{}, []string{"v0", "v0", "v0"}, "splunk-stack1-2")
+	test(&recycleLastPodManager{last: 2}, []string{"v0", "v0", "v0"}, "splunk-stack1-1")
+	test(&recycleLastPodManager{last: 2}, []string{"v0", "v1", "v0"}, "splunk-stack1-0")
+	test(&recycleLastPodManager{last: 2}, []string{"v1", "v1", "v0"}, "splunk-stack1-2")
+	test(&recycleLastPodManager{last: -1}, []string{"v0", "v0", "v0"}, "splunk-stack1-2")
+}
+
 func TestSetStatefulSetOwnerRef(t *testing.T) {
 	cr := enterprisev1.Standalone{
 		ObjectMeta: metav1.ObjectMeta{
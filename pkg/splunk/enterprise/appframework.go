@@ -0,0 +1,635 @@
+// Copyright (c) 2018-2021 Splunk Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package enterprise
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"path"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	enterprisev1 "github.com/splunk/splunk-operator/pkg/apis/enterprise/v1"
+	splclient "github.com/splunk/splunk-operator/pkg/splunk/client"
+	splcommon "github.com/splunk/splunk-operator/pkg/splunk/common"
+	splutil "github.com/splunk/splunk-operator/pkg/splunk/util"
+)
+
+// isAppFrameworkConfigured returns true if the app repository has at least one app source
+func isAppFrameworkConfigured(appFramework *enterprisev1.AppFrameworkSpec) bool {
+	return appFramework != nil && len(appFramework.AppSources) > 0
+}
+
+// ValidateAppFrameworkSpec checks and validates the app repository config for the given instance type,
+// and sets the default scope for the app sources.
+func ValidateAppFrameworkSpec(appFramework *enterprisev1.AppFrameworkSpec, instanceType InstanceType) error {
+	var err error
+
+	// App framework is an optional config
+	if !isAppFrameworkConfigured(appFramework) {
+		return nil
+	}
+
+	duplicateChecker := make(map[string]bool)
+	for i, volume := range appFramework.VolList {
+		if _, ok := duplicateChecker[volume.Name]; ok {
+			return fmt.Errorf("Duplicate app repository volume name detected: %s. Remove the duplicate entry and reapply the configuration", volume.Name)
+		}
+		duplicateChecker[volume.Name] = true
+
+		if volume.Name == "" {
+			return fmt.Errorf("App repository volume name is missing for volume at : %d", i)
+		}
+
+		if provider := getRemoteVolumeProvider(&volume); provider != enterprisev1.RemoteStorageProviderS3 {
+			return fmt.Errorf("App repository volume: %s, has an unsupported provider: %s. App repositories only support s3", volume.Name, provider)
+		}
+
+		if !usesRemoteVolumeKeys(&volume) {
+			return fmt.Errorf("App repository volume: %s, uses authMode iamRole. App repositories only support accessKey", volume.Name)
+		}
+
+		if volume.Endpoint == "" {
+			return fmt.Errorf("App repository volume Endpoint URI is missing")
+		}
+
+		if volume.Path == "" {
+			return fmt.Errorf("App repository volume Path is missing")
+		}
+
+		if volume.SecretRef == "" {
+			return fmt.Errorf("App repository volume SecretRef is missing")
+		}
+	}
+
+	defaults := &appFramework.Defaults
+	if defaults.VolName != "" {
+		_, err = checkIfVolumeExists(appFramework.VolList, defaults.VolName)
+		if err != nil {
+			return fmt.Errorf("Invalid configuration for app repository defaults volume. %s", err)
+		}
+	}
+	if defaults.Scope == "" {
+		defaults.Scope = appScopeLocal
+	}
+	if appFramework.AppsRepoPollInterval <= 0 {
+		appFramework.AppsRepoPollInterval = defaultAppsRepoPollInterval
+	}
+
+	duplicateChecker = make(map[string]bool)
+	for i := range appFramework.AppSources {
+		appSrc := &appFramework.AppSources[i]
+		if appSrc.Name == "" {
+			return fmt.Errorf("App source name is missing for app source at: %d", i)
+		}
+
+		if _, ok := duplicateChecker[appSrc.Name]; ok {
+			return fmt.Errorf("Duplicate app source name detected: %s. Remove the duplicate entry and reapply the configuration", appSrc.Name)
+		}
+		duplicateChecker[appSrc.Name] = true
+
+		if appSrc.Location == "" {
+			return fmt.Errorf("App source location is missing for app source: %s", appSrc.Name)
+		}
+
+		if appSrc.VolName == "" {
+			appSrc.VolName = defaults.VolName
+		}
+		if appSrc.VolName == "" {
+			return fmt.Errorf("volumeName is missing for app source: %s", appSrc.Name)
+		}
+		_, err = checkIfVolumeExists(appFramework.VolList, appSrc.VolName)
+		if err != nil {
+			return fmt.Errorf("Invalid configuration for app source: %s. %s", appSrc.Name, err)
+		}
+
+		if appSrc.Scope == "" {
+			appSrc.Scope = defaults.Scope
+		}
+		switch appSrc.Scope {
+		case appScopeLocal:
+		case appScopeCluster, appScopeClusterWithPreConfig:
+			if instanceType == SplunkStandalone {
+				return fmt.Errorf("Scope %s is not supported by a standalone instance for app source: %s", appSrc.Scope, appSrc.Name)
+			}
+		default:
+			return fmt.Errorf("Invalid scope %s for app source: %s. Valid scopes are %s, %s and %s",
+				appSrc.Scope, appSrc.Name, appScopeLocal, appScopeCluster, appScopeClusterWithPreConfig)
+		}
+	}
+
+	return nil
+}
+
+// getAppInstallDirs returns the directories an app package is extracted to on the given instance type for a scope
+func getAppInstallDirs(instanceType InstanceType, scope string) []string {
+	var clusterDir string
+	switch instanceType {
+	case SplunkClusterMaster:
+		clusterDir = appsMasterDir
+	case SplunkDeployer:
+		clusterDir = appsShClusterDir
+	}
+
+	switch scope {
+	case appScopeCluster:
+		return []string{clusterDir}
+	case appScopeClusterWithPreConfig:
+		return []string{appsLocalDir, clusterDir}
+	default:
+		return []string{appsLocalDir}
+	}
+}
+
+// isAppPackage returns true if a remote object is a Splunk app package
+func isAppPackage(key string) bool {
+	return strings.HasSuffix(key, ".tgz") || strings.HasSuffix(key, ".tar.gz") || strings.HasSuffix(key, ".spl")
+}
+
+// getAppRepoVolumeSecrets returns the access key and secret key for an app repository volume
+func getAppRepoVolumeSecrets(client splcommon.ControllerClient, cr splcommon.MetaObject, volume *enterprisev1.VolumeSpec) (string, string, error) {
+	secret, err := splutil.GetSecretByName(client, cr, volume.SecretRef)
+	if err != nil {
+		return "", "", err
+	}
+
+	accessKey := string(secret.Data[s3AccessKey])
+	secretKey := string(secret.Data[s3SecretKey])
+	if accessKey == "" {
+		return "", "", fmt.Errorf("S3 Access Key is missing")
+	} else if secretKey == "" {
+		return "", "", fmt.Errorf("S3 Secret Key is missing")
+	}
+
+	return accessKey, secretKey, nil
+}
+
+// getAppsRepoPollInterval returns the interval in seconds between checks of the app repository
+func getAppsRepoPollInterval(appFramework *enterprisev1.AppFrameworkSpec) int64 {
+	if appFramework.AppsRepoPollInterval <= 0 {
+		return defaultAppsRepoPollInterval
+	}
+	return appFramework.AppsRepoPollInterval
+}
+
+// getAppName returns the name of the Splunk app in an app package, which is the top level directory of the archive
+func getAppName(pkg []byte) (string, error) {
+	gzr, err := gzip.NewReader(bytes.NewReader(pkg))
+	if err != nil {
+		return "", err
+	}
+	defer gzr.Close()
+
+	tr := tar.NewReader(gzr)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return "", fmt.Errorf("no app directory found")
+		} else if err != nil {
+			return "", err
+		}
+		name := strings.Split(strings.TrimPrefix(hdr.Name, "./"), "/")[0]
+		if name != "" && name != "." {
+			return name, nil
+		}
+	}
+}
+
+// setAppFrameworkRequeue schedules the next reconcile of a custom resource using the app framework: soon while apps
+// are waiting to be loaded or pushed, otherwise when the app repository is due to be checked again
+func setAppFrameworkRequeue(result *reconcile.Result, appFramework *enterprisev1.AppFrameworkSpec, appContext *enterprisev1.AppDeploymentContext) {
+	if !isAppFrameworkConfigured(appFramework) {
+		return
+	}
+
+	result.Requeue = true
+	if appContext.NeedToPushBundle || len(appContext.PendingRestarts) > 0 {
+		return
+	}
+	for _, app := range appContext.Apps {
+		if app.Phase == enterprisev1.AppPhasePending {
+			return
+		}
+	}
+
+	wait := appContext.LastAppInfoCheckTime + getAppsRepoPollInterval(appFramework) - time.Now().Unix()
+	if wait < 5 {
+		wait = 5
+	}
+	result.RequeueAfter = time.Second * time.Duration(wait)
+}
+
+// appInstallManager is used to install the app packages from an app repository on the pods of a custom resource
+type appInstallManager struct {
+	c               splcommon.ControllerClient
+	log             logr.Logger
+	cr              splcommon.MetaObject
+	instanceType    InstanceType
+	replicas        int32
+	appFramework    *enterprisev1.AppFrameworkSpec
+	appContext      *enterprisev1.AppDeploymentContext
+	secrets         *corev1.Secret
+	newS3Client     func(endpoint, bucket, accessKey, secretKey string) (splclient.S3Client, error)
+	newSplunkClient func(managementURI, username, password string) *splclient.SplunkClient
+	podExec         func(c splcommon.ControllerClient, podName string, namespace string, cmd []string, stdin string, tty bool, mock bool) (string, string, error)
+}
+
+// ApplyAppFramework installs new and updated app packages from the app repository on the pods of a custom resource.
+// Cluster scoped apps are then pushed to the indexer cluster peers by a cluster master, or to the search head cluster
+// members by a deployer.
+func ApplyAppFramework(client splcommon.ControllerClient, cr splcommon.MetaObject, spec *enterprisev1.CommonSplunkSpec,
+	appContext *enterprisev1.AppDeploymentContext, instanceType InstanceType, replicas int32, secrets *corev1.Secret) error {
+	mgr := appInstallManager{
+		c:               client,
+		log:             log.WithName("ApplyAppFramework").WithValues("name", cr.GetName(), "namespace", cr.GetNamespace()),
+		cr:              cr,
+		instanceType:    instanceType,
+		replicas:        replicas,
+		appFramework:    &spec.AppFrameworkConfig,
+		appContext:      appContext,
+		secrets:         secrets,
+		newS3Client:     splclient.NewAWSS3Client,
+		newSplunkClient: splclient.NewSplunkClient,
+		podExec:         splutil.PodExecCommand,
+	}
+	return mgr.install()
+}
+
+// install for appInstallManager installs the new and updated app packages, pushes the cluster scoped apps,
+// and verifies the apps were loaded by Splunk
+func (mgr *appInstallManager) install() error {
+	if !isAppFrameworkConfigured(mgr.appFramework) {
+		return nil
+	}
+
+	// the app repository is checked again once the pods have loaded the apps of the previous check
+	if len(mgr.appContext.PendingRestarts) == 0 && mgr.isAppRepoCheckDue() {
+		err := mgr.checkAppRepo()
+		if err != nil {
+			return err
+		}
+	}
+
+	// wait for the restarts to complete before pushing the bundle or verifying the apps
+	if len(mgr.appContext.PendingRestarts) > 0 {
+		restarted, err := mgr.restartPods()
+		if err != nil || !restarted {
+			return err
+		}
+	}
+
+	if mgr.appContext.NeedToPushBundle {
+		err := mgr.pushBundle()
+		if err != nil {
+			return err
+		}
+		mgr.log.Info("Bundle push success")
+		mgr.appContext.NeedToPushBundle = false
+
+		// cluster scoped apps are not loaded by the cluster master itself, so the push completes their installation
+		if mgr.instanceType == SplunkClusterMaster {
+			for i := range mgr.appContext.Apps {
+				app := &mgr.appContext.Apps[i]
+				if app.Phase == enterprisev1.AppPhasePending && mgr.getAppScope(app.AppSource) == appScopeCluster {
+					app.Phase = enterprisev1.AppPhaseInstalled
+				}
+			}
+		}
+	}
+
+	mgr.verifyApps()
+	return nil
+}
+
+// isAppRepoCheckDue for appInstallManager returns true if the app repository needs to be checked for new and updated packages
+func (mgr *appInstallManager) isAppRepoCheckDue() bool {
+	if !reflect.DeepEqual(mgr.appContext.AppFrameworkConfig, *mgr.appFramework) || mgr.appContext.Replicas != mgr.replicas {
+		return true
+	}
+	return time.Now().Unix()-mgr.appContext.LastAppInfoCheckTime >= getAppsRepoPollInterval(mgr.appFramework)
+}
+
+// checkAppRepo for appInstallManager installs the new and updated app packages from the app repository,
+// and records the pods to restart to load them. The check is complete once these pods were restarted.
+func (mgr *appInstallManager) checkAppRepo() error {
+	s3Clients := make(map[string]splclient.S3Client)
+	restartPods := make(map[int32]bool)
+	var apps []enterprisev1.AppDeploymentInfo
+	for _, appSrc := range mgr.appFramework.AppSources {
+		s3Client, prefix, err := mgr.getS3Client(s3Clients, appSrc.VolName)
+		if err != nil {
+			return err
+		}
+
+		objects, err := s3Client.ListObjects(path.Join(prefix, appSrc.Location) + "/")
+		if err != nil {
+			return err
+		}
+
+		for _, obj := range objects {
+			if !isAppPackage(obj.Key) {
+				continue
+			}
+			appInfo := enterprisev1.AppDeploymentInfo{AppSource: appSrc.Name, Name: obj.Key, Checksum: obj.Etag}
+
+			// new, updated and failed packages are installed on all the pods, others only on pods added by a scale up
+			first := int32(0)
+			if prev := mgr.getInstalledApp(appInfo); prev != nil && prev.Phase != enterprisev1.AppPhaseError {
+				appInfo = *prev
+				first = mgr.appContext.Replicas
+			}
+			if first < mgr.replicas {
+				mgr.log.Info("Installing app package", "appSource", appSrc.Name, "package", obj.Key, "checksum", obj.Etag)
+				err = mgr.installApp(s3Client, &appInfo, appSrc.Scope, first)
+				if err != nil {
+					mgr.log.Error(err, "Unable to install app package", "appSource", appSrc.Name, "package", obj.Key)
+					appInfo.Phase = enterprisev1.AppPhaseError
+					appInfo.LastError = err.Error()
+				} else {
+					appInfo.Phase = enterprisev1.AppPhasePending
+					appInfo.LastError = ""
+					for n := first; n < mgr.replicas; n++ {
+						if appSrc.Scope != appScopeCluster {
+							restartPods[n] = true
+						}
+					}
+					if appSrc.Scope != appScopeLocal {
+						mgr.appContext.NeedToPushBundle = true
+					}
+				}
+			}
+			apps = append(apps, appInfo)
+		}
+	}
+	mgr.appContext.Apps = apps
+	mgr.appContext.Replicas = mgr.replicas
+	mgr.appContext.AppFrameworkConfig = *mgr.appFramework.DeepCopy()
+
+	// apps installed locally are loaded after restarting splunkd
+	mgr.appContext.PendingRestarts = nil
+	mgr.appContext.RestartStartupTime = 0
+	for n := int32(0); n < mgr.replicas; n++ {
+		if restartPods[n] {
+			mgr.appContext.PendingRestarts = append(mgr.appContext.PendingRestarts, n)
+		}
+	}
+	if len(mgr.appContext.PendingRestarts) == 0 {
+		mgr.appContext.LastAppInfoCheckTime = time.Now().Unix()
+	}
+	return nil
+}
+
+// restartPods for appInstallManager restarts splunkd on the pending pods one at a time, waiting for each pod to be
+// ready again before restarting the next one. It returns true once all the pods were restarted, which completes the
+// check of the app repository, so that failed restarts are retried on the next reconcile.
+func (mgr *appInstallManager) restartPods() (bool, error) {
+	appContext := mgr.appContext
+	for len(appContext.PendingRestarts) > 0 {
+		n := appContext.PendingRestarts[0]
+		if n >= mgr.replicas {
+			// the pod was removed by a scale down
+			appContext.PendingRestarts = appContext.PendingRestarts[1:]
+			appContext.RestartStartupTime = 0
+			continue
+		}
+		podName := GetSplunkStatefulsetPodName(mgr.instanceType, mgr.cr.GetName(), n)
+		c := mgr.getClient(n)
+
+		if appContext.RestartStartupTime == 0 {
+			// the startup time tells when splunkd is back after the restart
+			info, err := c.GetServerInfo()
+			if err != nil {
+				return false, err
+			}
+			mgr.log.Info("Restarting Splunk to load the installed apps", "pod", podName)
+			err = c.RestartSplunk()
+			if err != nil {
+				return false, err
+			}
+			appContext.RestartStartupTime = info.StartupTime
+			return false, nil
+		}
+
+		if !mgr.isPodReady(podName) {
+			return false, nil
+		}
+		info, err := c.GetServerInfo()
+		if err != nil || info.StartupTime <= appContext.RestartStartupTime {
+			// splunkd is still restarting
+			return false, nil
+		}
+		appContext.PendingRestarts = appContext.PendingRestarts[1:]
+		appContext.RestartStartupTime = 0
+	}
+
+	appContext.PendingRestarts = nil
+	appContext.LastAppInfoCheckTime = time.Now().Unix()
+	return true, nil
+}
+
+// isPodReady for appInstallManager returns true if the splunk container of a pod is running and ready
+func (mgr *appInstallManager) isPodReady(podName string) bool {
+	var pod corev1.Pod
+	namespacedName := types.NamespacedName{Namespace: mgr.cr.GetNamespace(), Name: podName}
+	err := mgr.c.Get(context.TODO(), namespacedName, &pod)
+	if err != nil {
+		mgr.log.Info("Unable to get the restarted pod", "pod", podName, "error", err.Error())
+		return false
+	}
+	return pod.Status.Phase == corev1.PodRunning && len(pod.Status.ContainerStatuses) > 0 && pod.Status.ContainerStatuses[0].Ready
+}
+
+// getInstalledApp for appInstallManager returns the status of an app package previously installed with the same checksum
+func (mgr *appInstallManager) getInstalledApp(appInfo enterprisev1.AppDeploymentInfo) *enterprisev1.AppDeploymentInfo {
+	for i := range mgr.appContext.Apps {
+		app := &mgr.appContext.Apps[i]
+		if app.AppSource == appInfo.AppSource && app.Name == appInfo.Name && app.Checksum == appInfo.Checksum {
+			return app
+		}
+	}
+	return nil
+}
+
+// getAppScope for appInstallManager returns the scope of an app source
+func (mgr *appInstallManager) getAppScope(appSource string) string {
+	for _, appSrc := range mgr.appFramework.AppSources {
+		if appSrc.Name == appSource {
+			return appSrc.Scope
+		}
+	}
+	return appScopeLocal
+}
+
+// installApp for appInstallManager downloads an app package and extracts it on pods first to replicas-1
+func (mgr *appInstallManager) installApp(s3Client splclient.S3Client, appInfo *enterprisev1.AppDeploymentInfo, scope string, first int32) error {
+	pkg, err := s3Client.GetObject(appInfo.Name)
+	if err != nil {
+		return err
+	}
+	appInfo.AppName, err = getAppName(pkg)
+	if err != nil {
+		return fmt.Errorf("Invalid app package %s: %v", appInfo.Name, err)
+	}
+	for n := first; n < mgr.replicas; n++ {
+		for _, dir := range getAppInstallDirs(mgr.instanceType, scope) {
+			err = mgr.installPackage(n, dir, pkg)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// verifyApps for appInstallManager marks the pending apps as installed once they are reported by Splunk,
+// and records their versions
+func (mgr *appInstallManager) verifyApps() {
+	localApps := make(map[string]map[string]splclient.AppInfo)
+	for i := range mgr.appContext.Apps {
+		app := &mgr.appContext.Apps[i]
+		if app.Phase != enterprisev1.AppPhasePending {
+			continue
+		}
+
+		targets := mgr.getVerifyTargets(mgr.getAppScope(app.AppSource))
+		if len(targets) == 0 {
+			continue
+		}
+		var version string
+		verified := true
+		for _, target := range targets {
+			apps, ok := localApps[target]
+			if !ok {
+				var err error
+				apps, err = mgr.getVerifyClient(target).GetAppsLocal()
+				if err != nil {
+					// splunkd may still be starting, so try again on the next reconcile
+					mgr.log.Info("Unable to get the installed apps", "target", target, "error", err.Error())
+					apps = nil
+				}
+				localApps[target] = apps
+			}
+			info, ok := apps[app.AppName]
+			if !ok {
+				verified = false
+				break
+			}
+			version = info.Version
+		}
+		if verified {
+			mgr.log.Info("App installed", "appSource", app.AppSource, "app", app.AppName, "version", version)
+			app.Phase = enterprisev1.AppPhaseInstalled
+			app.Version = version
+		}
+	}
+}
+
+// getVerifyTargets for appInstallManager returns the instances that load the apps of a scope.
+// Each target is either a pod index of the custom resource, or "searchhead" for the search head cluster members.
+func (mgr *appInstallManager) getVerifyTargets(scope string) []string {
+	var targets []string
+	if scope != appScopeCluster {
+		for n := int32(0); n < mgr.replicas; n++ {
+			targets = append(targets, fmt.Sprintf("%d", n))
+		}
+	}
+	if scope != appScopeLocal && mgr.instanceType == SplunkDeployer {
+		targets = append(targets, verifyTargetSearchHead)
+	}
+	return targets
+}
+
+// getVerifyClient for appInstallManager returns a SplunkClient for a verify target
+func (mgr *appInstallManager) getVerifyClient(target string) *splclient.SplunkClient {
+	if target == verifyTargetSearchHead {
+		fqdnName := GetSplunkStatefulsetURL(mgr.cr.GetNamespace(), SplunkSearchHead, mgr.cr.GetName(), 0, false)
+		return mgr.newSplunkClient(fmt.Sprintf("https://%s:8089", fqdnName), "admin", string(mgr.secrets.Data["password"]))
+	}
+	n, _ := strconv.Atoi(target)
+	return mgr.getClient(int32(n))
+}
+
+// getS3Client for appInstallManager returns an S3Client and key prefix for an app repository volume
+func (mgr *appInstallManager) getS3Client(s3Clients map[string]splclient.S3Client, volName string) (splclient.S3Client, string, error) {
+	index, err := checkIfVolumeExists(mgr.appFramework.VolList, volName)
+	if err != nil {
+		return nil, "", err
+	}
+	volume := &mgr.appFramework.VolList[index]
+
+	// volume path is of the form <bucket>/<prefix>
+	bucket := strings.Split(strings.Trim(volume.Path, "/"), "/")[0]
+	prefix := strings.TrimPrefix(strings.Trim(volume.Path, "/"), bucket)
+
+	s3Client, ok := s3Clients[volName]
+	if !ok {
+		accessKey, secretKey, err := getAppRepoVolumeSecrets(mgr.c, mgr.cr, volume)
+		if err != nil {
+			return nil, "", err
+		}
+		s3Client, err = mgr.newS3Client(volume.Endpoint, bucket, accessKey, secretKey)
+		if err != nil {
+			return nil, "", err
+		}
+		s3Clients[volName] = s3Client
+	}
+
+	return s3Client, strings.TrimPrefix(prefix, "/"), nil
+}
+
+// installPackage for appInstallManager extracts an app package into a directory on pod n
+func (mgr *appInstallManager) installPackage(n int32, dir string, pkg []byte) error {
+	podName := GetSplunkStatefulsetPodName(mgr.instanceType, mgr.cr.GetName(), n)
+	command := []string{"/bin/sh", "-c", fmt.Sprintf("mkdir -p %s && tar -xzf - -C %s", dir, dir)}
+	stdOut, stdErr, err := mgr.podExec(mgr.c, podName, mgr.cr.GetNamespace(), command, string(pkg), false, false)
+	if err != nil {
+		return fmt.Errorf("Failed to install app package on pod %s. stdout=%s, stderror=%s, error=%v", podName, stdOut, stdErr, err)
+	}
+	return nil
+}
+
+// pushBundle for appInstallManager pushes the cluster scoped apps to the indexer cluster peers or search head cluster members
+func (mgr *appInstallManager) pushBundle() error {
+	switch mgr.instanceType {
+	case SplunkClusterMaster:
+		return mgr.getClient(0).BundlePush(true)
+	case SplunkDeployer:
+		target := GetSplunkStatefulsetURL(mgr.cr.GetNamespace(), SplunkSearchHead, mgr.cr.GetName(), 0, false)
+		return mgr.getClient(0).DeploySearchHeadClusterBundle(fmt.Sprintf("https://%s:8089", target))
+	}
+	return nil
+}
+
+// getClient for appInstallManager returns a SplunkClient for pod n
+func (mgr *appInstallManager) getClient(n int32) *splclient.SplunkClient {
+	var fqdnName string
+	if mgr.instanceType == SplunkStandalone {
+		fqdnName = GetSplunkStatefulsetURL(mgr.cr.GetNamespace(), mgr.instanceType, mgr.cr.GetName(), n, false)
+	} else {
+		fqdnName = splcommon.GetServiceFQDN(mgr.cr.GetNamespace(), GetSplunkServiceName(mgr.instanceType, mgr.cr.GetName(), false))
+	}
+	return mgr.newSplunkClient(fmt.Sprintf("https://%s:8089", fqdnName), "admin", string(mgr.secrets.Data["password"]))
+}
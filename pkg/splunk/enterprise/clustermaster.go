@@ -18,21 +18,31 @@ import (
 	"context"
 	"fmt"
 	"reflect"
+	"sort"
 	"time"
 
 	enterprisev1 "github.com/splunk/splunk-operator/pkg/apis/enterprise/v1"
 	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	splclient "github.com/splunk/splunk-operator/pkg/splunk/client"
 	splcommon "github.com/splunk/splunk-operator/pkg/splunk/common"
 	splctrl "github.com/splunk/splunk-operator/pkg/splunk/controller"
+	splmetrics "github.com/splunk/splunk-operator/pkg/splunk/metrics"
 	splutil "github.com/splunk/splunk-operator/pkg/splunk/util"
 	corev1 "k8s.io/api/core/v1"
 )
 
 // ApplyClusterMaster reconciles the state of a Splunk Enterprise cluster master.
-func ApplyClusterMaster(client splcommon.ControllerClient, cr *enterprisev1.ClusterMaster) (reconcile.Result, error) {
+func ApplyClusterMaster(client splcommon.ControllerClient, recorder record.EventRecorder, cr *enterprisev1.ClusterMaster) (reconcile.Result, error) {
+	return applyClusterMaster(client, recorder, cr, splclient.NewAWSS3ClientWithConfig)
+}
+
+// applyClusterMaster reconciles the state of a Splunk Enterprise cluster master, running the preflight check of the
+// SmartStore volumes with the S3 clients returned by newS3Client
+func applyClusterMaster(client splcommon.ControllerClient, recorder record.EventRecorder, cr *enterprisev1.ClusterMaster,
+	newS3Client func(cfg splclient.S3ClientConfig) (splclient.S3Client, error)) (reconcile.Result, error) {
 
 	// unless modified, reconcile for this object will be requeued after 5 seconds
 	result := reconcile.Result{
@@ -44,21 +54,44 @@ func ApplyClusterMaster(client splcommon.ControllerClient, cr *enterprisev1.Clus
 		cr.Status.ResourceRevMap = make(map[string]string)
 	}
 
+	eventPublisher := splcommon.NewEventPublisher(recorder, cr)
+
 	// validate and updates defaults for CR
 	err := validateClusterMasterSpec(cr)
 	if err != nil {
+		cr.Status.Phase = splcommon.PhaseError
+		setErrorCondition(cr, &cr.Status.Conditions, "", reasonInvalidSpec, err)
+		cr.Status.ObservedGeneration = cr.GetGeneration()
+		client.Status().Update(context.TODO(), cr)
 		return result, err
 	}
 
 	// updates status after function completes
 	cr.Status.Phase = splcommon.PhaseError
 	cr.Status.Selector = fmt.Sprintf("app.kubernetes.io/instance=splunk-%s-cluster-master", cr.GetName())
+	defer func() {
+		setPhaseConditions(cr, &cr.Status.Conditions, cr.Status.Phase)
+		cr.Status.ObservedGeneration = cr.GetGeneration()
+		err = client.Status().Update(context.TODO(), cr)
+		if err != nil {
+			scopedLog.Error(err, "Status update failed")
+		}
+	}()
 
 	if !reflect.DeepEqual(cr.Status.SmartStore, cr.Spec.SmartStore) ||
 		AreRemoteVolumeKeysChanged(client, cr, SplunkClusterMaster, &cr.Spec.SmartStore, cr.Status.ResourceRevMap, &err) {
 
+		// the remote volumes must be reachable with their credentials before the configuration is pushed to the peers
+		volumes, err := checkSmartstoreVolumes(client, cr, &cr.Spec.SmartStore, newS3Client)
+		cr.Status.SmartStoreVolumes = volumes
+		if err != nil {
+			setErrorCondition(cr, &cr.Status.Conditions, enterprisev1.ConditionSmartStoreConfigured, reasonSmartStorePreflight, err)
+			return result, err
+		}
+
 		_, configMapDataChanged, err := ApplySmartstoreConfigMap(client, cr, &cr.Spec.SmartStore)
 		if err != nil {
+			setErrorCondition(cr, &cr.Status.Conditions, enterprisev1.ConditionSmartStoreConfigured, reasonSmartStoreFailed, err)
 			return result, err
 		} else if configMapDataChanged {
 			// Do not auto populate with configMapDataChanged flag to NeedToPushMasterApps. Set it only  if
@@ -69,63 +102,89 @@ func ApplyClusterMaster(client splcommon.ControllerClient, cr *enterprisev1.Clus
 		}
 
 		cr.Status.SmartStore = cr.Spec.SmartStore
+		setSmartStoreCondition(cr, &cr.Status.Conditions, &cr.Spec.SmartStore)
 	}
 
 	// This is to take care of case where AreRemoteVolumeKeysChanged returns an error if it returns false.
 	if err != nil {
+		setErrorCondition(cr, &cr.Status.Conditions, enterprisev1.ConditionSmartStoreConfigured, reasonSmartStoreFailed, err)
 		return result, err
 	}
 
-	defer func() {
-		err = client.Status().Update(context.TODO(), cr)
-		if err != nil {
-			scopedLog.Error(err, "Status update failed")
-		}
-	}()
-
 	// create or update general config resources
-	_, err = ApplySplunkConfig(client, cr, cr.Spec.CommonSplunkSpec, SplunkIndexer)
+	namespaceScopedSecret, err := ApplySplunkConfig(client, cr, cr.Spec.CommonSplunkSpec, SplunkIndexer)
 	if err != nil {
+		setErrorCondition(cr, &cr.Status.Conditions, enterprisev1.ConditionSecretsSynced, reasonSecretsSyncFailed, err)
 		return result, err
 	}
+	setCondition(cr, &cr.Status.Conditions, enterprisev1.ConditionSecretsSynced, corev1.ConditionTrue, reasonSecretsSynced, "")
 
 	// check if deletion has been requested
 	if cr.ObjectMeta.DeletionTimestamp != nil {
 		err = ApplyMonitoringConsole(client, cr, cr.Spec.CommonSplunkSpec, getClusterMasterExtraEnv(cr, &cr.Spec.CommonSplunkSpec))
 		if err != nil {
+			setErrorCondition(cr, &cr.Status.Conditions, "", reasonMonitoringConsoleFailed, err)
 			return result, err
 		}
 
 		DeleteOwnerReferencesForResources(client, cr, &cr.Spec.SmartStore)
-		terminating, err := splctrl.CheckForDeletion(cr, client)
+		terminating, err := splctrl.CheckForDeletion(cr, client, eventPublisher)
 		if terminating && err != nil { // don't bother if no error, since it will just be removed immmediately after
 			cr.Status.Phase = splcommon.PhaseTerminating
+			setErrorCondition(cr, &cr.Status.Conditions, "", reasonDeletionFailed, err)
 		} else {
 			result.Requeue = false
 		}
 		return result, err
 	}
 
+	setLicenseCondition(client, cr, &cr.Status.Conditions, cr.Spec.LicenseMasterRef)
+
 	// create or update a regular service for indexer cluster (ingestion)
 	err = splctrl.ApplyService(client, getSplunkService(cr, &cr.Spec.CommonSplunkSpec, SplunkIndexer, false))
 	if err != nil {
+		setErrorCondition(cr, &cr.Status.Conditions, "", reasonServiceFailed, err)
 		return result, err
 	}
 
 	// create or update a regular service for the cluster master
 	err = splctrl.ApplyService(client, getSplunkService(cr, &cr.Spec.CommonSplunkSpec, SplunkClusterMaster, false))
 	if err != nil {
+		setErrorCondition(cr, &cr.Status.Conditions, "", reasonServiceFailed, err)
 		return result, err
 	}
 
+	// the IAM role must be annotated on the service account before the pod is created
+	warning, err := applyRemoteVolumeServiceAccount(client, &cr.Spec.CommonSplunkSpec, cr.GetNamespace(), &cr.Spec.SmartStore)
+	if err != nil {
+		setErrorCondition(cr, &cr.Status.Conditions, "", reasonServiceAccountFailed, err)
+		return result, err
+	}
+	if warning != "" {
+		eventPublisher.Warning(splcommon.EventReasonServiceAccountMissing, "%s", warning)
+	}
+
 	// create or update statefulset for the cluster master
 	statefulSet, err := getClusterMasterStatefulSet(client, cr)
 	if err != nil {
+		setErrorCondition(cr, &cr.Status.Conditions, "", reasonStatefulSetFailed, err)
+		return result, err
+	}
+	// upgrade the cluster master after its license master
+	_, err = applyUpgradeOrder(client, cr, &cr.Status.Conditions, cr.Status.Image, cr.Status.Version, statefulSet, eventPublisher)
+	if err != nil {
+		setErrorCondition(cr, &cr.Status.Conditions, enterprisev1.ConditionImageUpToDate, reasonUpgradeFailed, err)
 		return result, err
 	}
-	clusterMasterManager := splctrl.DefaultStatefulSetPodManager{}
+	err = applySplunkPodDisruptionBudget(client, cr, &cr.Spec.PodDisruptionBudget, getDefaultDisruptionBudget(SplunkClusterMaster, 1, 0), statefulSet)
+	if err != nil {
+		setErrorCondition(cr, &cr.Status.Conditions, "", reasonDisruptionBudgetFailed, err)
+		return result, err
+	}
+	clusterMasterManager := splctrl.DefaultStatefulSetPodManager{EventPublisher: eventPublisher}
 	phase, err := clusterMasterManager.Update(client, statefulSet, 1)
 	if err != nil {
+		setErrorCondition(cr, &cr.Status.Conditions, "", reasonStatefulSetFailed, err)
 		return result, err
 	}
 	cr.Status.Phase = phase
@@ -134,20 +193,61 @@ func ApplyClusterMaster(client splcommon.ControllerClient, cr *enterprisev1.Clus
 	if cr.Status.Phase == splcommon.PhaseReady {
 		err = ApplyMonitoringConsole(client, cr, cr.Spec.CommonSplunkSpec, getClusterMasterExtraEnv(cr, &cr.Spec.CommonSplunkSpec))
 		if err != nil {
+			setErrorCondition(cr, &cr.Status.Conditions, "", reasonMonitoringConsoleFailed, err)
+			return result, err
+		}
+		err = updateVersion(&cr.Spec.CommonSplunkSpec, cr.Status.Image, &cr.Status.Version, statefulSet, func() *splclient.SplunkClient {
+			return getServiceClient(cr, SplunkClusterMaster, namespaceScopedSecret)
+		})
+		if err != nil {
+			setErrorCondition(cr, &cr.Status.Conditions, "", reasonServerInfoFailed, err)
+			return result, err
+		}
+		setRunningImage(cr, &cr.Status.Conditions, &cr.Status.Image, cr.Spec.Image, statefulSet, eventPublisher)
+
+		// maintenance mode is enabled and disabled by the indexer clusters, or by hand
+		if !cr.Spec.Mock {
+			cr.Status.MaintenanceMode, err = getServiceClient(cr, SplunkClusterMaster, namespaceScopedSecret).GetMaintenanceMode()
+			if err != nil {
+				setErrorCondition(cr, &cr.Status.Conditions, "", reasonClusterMasterUnreachable, err)
+				return result, err
+			}
+		}
+
+		// install apps from the app repository, and push the cluster scoped apps to the peers
+		err = ApplyAppFramework(client, cr, &cr.Spec.CommonSplunkSpec, &cr.Status.AppContext, SplunkClusterMaster, 1, namespaceScopedSecret)
+		if err != nil {
+			setErrorCondition(cr, &cr.Status.Conditions, "", reasonAppFrameworkFailed, err)
 			return result, err
 		}
 
 		// Master apps bundle push requires multiple reconcile iterations in order to reflect the configMap on the CM pod.
 		// So keep PerformCmBundlePush() as the last call in this block of code, so that other functionalities are not blocked
+		tracker := &cr.Status.BundlePushTracker
+		needToPushMasterApps, applyInProgress, pushError := tracker.NeedToPushMasterApps, tracker.ApplyInProgress, getBundlePushError(tracker)
 		err = PerformCmBundlePush(client, cr)
 		if err != nil {
+			setErrorCondition(cr, &cr.Status.Conditions, enterprisev1.ConditionBundlePushed, reasonBundlePushFailed, err)
 			return result, err
 		}
+		if needToPushMasterApps && tracker.ApplyInProgress {
+			eventPublisher.Normal(splcommon.EventReasonBundlePush, "Pushed the master apps bundle %s to the indexer cluster peers (restartRequired=%t)", tracker.Checksum, tracker.RestartRequired)
+		}
+		if applyInProgress && !tracker.ApplyInProgress && len(tracker.Errors) == 0 {
+			eventPublisher.Normal(splcommon.EventReasonBundlePush, "The indexer cluster peers applied the master apps bundle %s", tracker.Checksum)
+		}
+		if newError := getBundlePushError(tracker); newError != "" && newError != pushError {
+			eventPublisher.Warning(splcommon.EventReasonBundlePush, "%s", newError)
+		}
 
-		if cr.Status.BundlePushTracker.NeedToPushMasterApps == false {
+		// a failed push is not retried until the master apps change again
+		setBundlePushCondition(cr, &cr.Status.Conditions, tracker)
+		if !tracker.NeedToPushMasterApps && !tracker.ApplyInProgress {
 			result.Requeue = false
+			setAppFrameworkRequeue(&result, &cr.Spec.AppFrameworkConfig, &cr.Status.AppContext)
 		}
 	}
+	setReconciledCondition(cr, &cr.Status.Conditions)
 	return result, nil
 }
 
@@ -158,6 +258,16 @@ func validateClusterMasterSpec(cr *enterprisev1.ClusterMaster) error {
 		return err
 	}
 
+	err = validateMultisiteSpec(&cr.Spec.Multisite)
+	if err != nil {
+		return err
+	}
+
+	err = ValidateAppFrameworkSpec(&cr.Spec.AppFrameworkConfig, SplunkClusterMaster)
+	if err != nil {
+		return err
+	}
+
 	return validateCommonSplunkSpec(&cr.Spec.CommonSplunkSpec)
 }
 
@@ -174,12 +284,14 @@ func getClusterMasterStatefulSet(client splcommon.ControllerClient, cr *enterpri
 	if exists {
 		setupInitContainer(&ss.Spec.Template, cr.Spec.Image, cr.Spec.ImagePullPolicy, commandForCMSmartstore)
 	}
+	addRemoteVolumeCABundles(&ss.Spec.Template, &cr.Spec.SmartStore)
+	addRemoteVolumeGCSCredentials(&ss.Spec.Template, &cr.Spec.SmartStore)
 
 	return ss, err
 }
 
 // CheckIfsmartstoreConfigMapUpdatedToPod checks if the smartstore configMap is updated on Pod or not
-func CheckIfsmartstoreConfigMapUpdatedToPod(c splcommon.ControllerClient, cr *enterprisev1.ClusterMaster) error {
+func CheckIfsmartstoreConfigMapUpdatedToPod(c splcommon.ControllerClient, cr *enterprisev1.ClusterMaster) (bool, error) {
 	scopedLog := log.WithName("CheckIfsmartstoreConfigMapUpdatedToPod").WithValues("name", cr.GetName(), "namespace", cr.GetNamespace())
 
 	masterIdxcName := cr.GetName()
@@ -188,7 +300,7 @@ func CheckIfsmartstoreConfigMapUpdatedToPod(c splcommon.ControllerClient, cr *en
 	command := fmt.Sprintf("cat /mnt/splunk-operator/local/%s", configToken)
 	stdOut, stdErr, err := splutil.PodExecCommand(c, cmPodName, cr.GetNamespace(), []string{"/bin/sh"}, command, false, false)
 	if err != nil || stdErr != "" {
-		return fmt.Errorf("Failed to check config token value on pod. stdout=%s, stderror=%s, error=%v", stdOut, stdErr, err)
+		return false, fmt.Errorf("Failed to check config token value on pod. stdout=%s, stderror=%s, error=%v", stdOut, stdErr, err)
 	}
 
 	configMap, exists := getSmartstoreConfigMap(c, cr, SplunkClusterMaster)
@@ -196,32 +308,45 @@ func CheckIfsmartstoreConfigMapUpdatedToPod(c splcommon.ControllerClient, cr *en
 		tokenFromConfigMap := configMap.Data[configToken]
 		if tokenFromConfigMap == stdOut {
 			scopedLog.Info("Token Matched.", "on Pod=", stdOut, "from configMap=", tokenFromConfigMap)
-			return nil
+			return true, nil
 		}
-		return fmt.Errorf("Waiting for the configMap update to the Pod. Token on Pod=%s, Token from configMap=%s", stdOut, tokenFromConfigMap)
+		scopedLog.Info("Waiting for the configMap update to the Pod", "on Pod=", stdOut, "from configMap=", tokenFromConfigMap)
+		return false, nil
 	}
 
 	// Somehow the configmap was deleted, ideally this should not happen
-	return fmt.Errorf("Smartstore ConfigMap is missing")
+	return false, fmt.Errorf("Smartstore ConfigMap is missing")
 }
 
-// PerformCmBundlePush initiates the bundle push from cluster master
+// PerformCmBundlePush validates the master apps bundle, pushes it to the peers once it is valid, and tracks how the peers apply it.
+// Waiting for the cluster master is not an error: the push stays pending in the BundlePushTracker until the next reconcile.
 func PerformCmBundlePush(c splcommon.ControllerClient, cr *enterprisev1.ClusterMaster) error {
-	if cr.Status.BundlePushTracker.NeedToPushMasterApps == false {
+	tracker := &cr.Status.BundlePushTracker
+	if !tracker.NeedToPushMasterApps && !tracker.ApplyInProgress {
 		return nil
 	}
 
+	// track the pushed bundle until the peers apply it, unless the master apps changed again
+	if !tracker.NeedToPushMasterApps {
+		splunkClient, err := getClusterMasterAdminClient(c, cr)
+		if err != nil {
+			return err
+		}
+		return updateBundleApplyStatus(tracker, splunkClient)
+	}
+
 	scopedLog := log.WithName("PerformCmBundlePush").WithValues("name", cr.GetName(), "namespace", cr.GetNamespace())
 	// Reconciler can be called for multiple reasons. If we are waiting on configMap update to happen,
 	// do not increment the Retry Count unless the last check was 5 seconds ago.
-	// This helps, to wait for the required time
+	// This helps, to wait for the required time. The push stays pending until then, which is not an error.
 	currentEpoch := time.Now().Unix()
-	if cr.Status.BundlePushTracker.LastCheckInterval+5 > currentEpoch {
-		return fmt.Errorf("Will re-attempt to push the bundle after the 5 seconds period passed from last check. LastCheckInterval=%d, current epoch=%d", cr.Status.BundlePushTracker.LastCheckInterval, currentEpoch)
+	if tracker.LastCheckInterval+5 > currentEpoch {
+		scopedLog.Info("Will re-attempt to push the bundle after the 5 seconds period passed from last check", "LastCheckInterval", tracker.LastCheckInterval, "currentEpoch", currentEpoch)
+		return nil
 	}
 
 	scopedLog.Info("Attempting to push the bundle")
-	cr.Status.BundlePushTracker.LastCheckInterval = currentEpoch
+	tracker.LastCheckInterval = currentEpoch
 
 	// The amount of time it takes for the configMap update to Pod depends on
 	// how often the Kubelet on the K8 node refreshes its cache with API server.
@@ -229,43 +354,129 @@ func PerformCmBundlePush(c splcommon.ControllerClient, cr *enterprisev1.ClusterM
 	// for the configMap update to the Pod before proceeding for the master apps
 	// bundle push.
 
-	err := CheckIfsmartstoreConfigMapUpdatedToPod(c, cr)
-	if err != nil {
+	updated, err := CheckIfsmartstoreConfigMapUpdatedToPod(c, cr)
+	if err != nil || !updated {
 		return err
 	}
 
-	err = PushMasterAppsBundle(c, cr)
-	if err == nil {
-		scopedLog.Info("Bundle push success")
-		cr.Status.BundlePushTracker.NeedToPushMasterApps = false
+	return PushMasterAppsBundle(c, cr)
+}
+
+// PushMasterAppsBundle validates the master apps bundle, and pushes it to the peers once the cluster master reports it is valid
+func PushMasterAppsBundle(c splcommon.ControllerClient, cr *enterprisev1.ClusterMaster) error {
+	splunkClient, err := getClusterMasterAdminClient(c, cr)
+	if err != nil {
+		return err
 	}
 
+	pushed, err := pushMasterAppsBundle(&cr.Status.BundlePushTracker, splunkClient)
+	if pushed {
+		splmetrics.ObserveBundlePush(cr.GetNamespace(), cr.GetName(), err)
+	}
 	return err
 }
 
-// PushMasterAppsBundle issues the REST command to for cluster master bundle push
-func PushMasterAppsBundle(c splcommon.ControllerClient, cr *enterprisev1.ClusterMaster) error {
-	scopedLog := log.WithName("PushMasterApps").WithValues("name", cr.GetName(), "namespace", cr.GetNamespace())
-
+// getClusterMasterAdminClient returns a SplunkClient for the service of a cluster master, using the admin password
+// of the namespace scoped secret
+func getClusterMasterAdminClient(c splcommon.ControllerClient, cr *enterprisev1.ClusterMaster) (*splclient.SplunkClient, error) {
 	defaultSecretObjName := splcommon.GetNamespaceScopedSecretName(cr.GetNamespace())
 	defaultSecret, err := splutil.GetSecretByName(c, cr, defaultSecretObjName)
 	if err != nil {
-		return fmt.Errorf("Could not access default secret object to fetch admin password. Reason %v", err)
+		return nil, fmt.Errorf("Could not access default secret object to fetch admin password. Reason %v", err)
 	}
 
 	//Get the admin password from the secret object
 	adminPwd, foundSecret := defaultSecret.Data["password"]
 	if foundSecret == false {
-		return fmt.Errorf("Could not find admin password while trying to push the master apps bundle")
+		return nil, fmt.Errorf("Could not find admin password while trying to push the master apps bundle")
 	}
 
-	scopedLog.Info("Issuing REST call to push master aps bundle")
-
 	masterIdxcName := cr.GetName()
 	fqdnName := splcommon.GetServiceFQDN(cr.GetNamespace(), GetSplunkServiceName(SplunkClusterMaster, masterIdxcName, false))
 
 	// Get a Splunk client to execute the REST call
-	splunkClient := splclient.NewSplunkClient(fmt.Sprintf("https://%s:8089", fqdnName), "admin", string(adminPwd))
+	return splclient.NewSplunkClient(fmt.Sprintf("https://%s:8089", fqdnName), "admin", string(adminPwd)), nil
+}
 
-	return splunkClient.BundlePush(true)
+// pushMasterAppsBundle requests the validation of the master apps bundle, waits for its result, and pushes the bundle
+// if it is valid. An invalid bundle is not pushed, and its errors are kept until the master apps change again.
+// It returns true if the bundle push was requested.
+func pushMasterAppsBundle(tracker *enterprisev1.BundlePushInfo, splunkClient *splclient.SplunkClient) (bool, error) {
+	scopedLog := log.WithName("pushMasterAppsBundle").WithValues("uri", splunkClient.ManagementURI)
+
+	if tracker.ValidationStartTime == 0 {
+		scopedLog.Info("Issuing REST call to validate master apps bundle")
+		err := splunkClient.ValidateBundle(true)
+		if err != nil {
+			return false, err
+		}
+		tracker.ValidationStartTime = time.Now().Unix()
+		tracker.Errors = nil
+		tracker.Peers = nil
+		return false, nil
+	}
+
+	info, err := splunkClient.GetClusterMasterInfo()
+	if err != nil {
+		return false, err
+	}
+
+	// the result of an earlier validation stands for the same bundle
+	validated := info.LastValidatedBundle
+	if validated.Timestamp < tracker.ValidationStartTime && validated.Checksum != info.LatestBundle.Checksum {
+		scopedLog.Info("Waiting for the cluster master to validate the bundle")
+		return false, nil
+	}
+	tracker.ValidationStartTime = 0
+	tracker.Checksum = validated.Checksum
+	tracker.Valid = validated.IsValidBundle
+	tracker.RestartRequired = info.LastCheckRestartBundleResult
+	if !tracker.Valid {
+		tracker.Errors = info.ApplyBundleStatus.InvalidBundle.ValidationErrors
+		tracker.NeedToPushMasterApps = false
+		return false, nil
+	}
+
+	scopedLog.Info("Issuing REST call to push master apps bundle", "checksum", tracker.Checksum, "restartRequired", tracker.RestartRequired)
+	err = splunkClient.BundlePush(true)
+	if err != nil {
+		return false, err
+	}
+	tracker.NeedToPushMasterApps = false
+	tracker.ApplyInProgress = true
+	return true, nil
+}
+
+// updateBundleApplyStatus records the state of the pushed bundle on each peer, until they all run it or one of them
+// fails to apply it
+func updateBundleApplyStatus(tracker *enterprisev1.BundlePushInfo, splunkClient *splclient.SplunkClient) error {
+	peers, err := splunkClient.GetClusterMasterPeers()
+	if err != nil {
+		return err
+	}
+	names := make([]string, 0, len(peers))
+	for name := range peers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	applied := true
+	tracker.Peers = make([]enterprisev1.BundlePeerStatus, 0, len(names))
+	tracker.Errors = nil
+	for _, name := range names {
+		peer := peers[name]
+		peerStatus := enterprisev1.BundlePeerStatus{
+			Name:           name,
+			ActiveBundleID: peer.ActiveBundleID,
+			Status:         peer.ApplyBundleStatus.Status,
+			Applied:        peer.ActiveBundleID == tracker.Checksum,
+		}
+		applied = applied && peerStatus.Applied
+		for _, validationError := range peer.ApplyBundleStatus.InvalidBundle.ValidationErrors {
+			tracker.Errors = append(tracker.Errors, fmt.Sprintf("%s: %s", name, validationError))
+		}
+		tracker.Peers = append(tracker.Peers, peerStatus)
+	}
+	tracker.ApplyInProgress = !applied && len(tracker.Errors) == 0
+	return nil
 }
@@ -15,17 +15,19 @@
 package enterprise
 
 import (
+	"errors"
 	"fmt"
-	"strings"
 	"testing"
 	"time"
 
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	enterprisev1 "github.com/splunk/splunk-operator/pkg/apis/enterprise/v1"
+	splclient "github.com/splunk/splunk-operator/pkg/splunk/client"
 	splcommon "github.com/splunk/splunk-operator/pkg/splunk/common"
 	splctrl "github.com/splunk/splunk-operator/pkg/splunk/controller"
 	spltest "github.com/splunk/splunk-operator/pkg/splunk/test"
@@ -42,6 +44,7 @@ func TestApplyClusterMaster(t *testing.T) {
 		{MetaName: "*v1.Secret-test-splunk-stack1-cluster-master-secret-v1"},
 		{MetaName: "*v1.ConfigMap-test-splunk-stack1-clustermaster-smartstore"},
 		{MetaName: "*v1.ConfigMap-test-splunk-stack1-clustermaster-smartstore"},
+		{MetaName: "*v1beta1.PodDisruptionBudget-test-splunk-stack1-cluster-master"},
 		{MetaName: "*v1.StatefulSet-test-splunk-stack1-cluster-master"},
 	}
 
@@ -55,8 +58,8 @@ func TestApplyClusterMaster(t *testing.T) {
 	}
 	listmockCall := []spltest.MockFuncCall{
 		{ListOpts: listOpts}}
-	createCalls := map[string][]spltest.MockFuncCall{"Get": funcCalls, "Create": {funcCalls[0], funcCalls[2], funcCalls[3], funcCalls[5], funcCalls[8]}, "List": {listmockCall[0]}, "Update": {funcCalls[0]}}
-	updateCalls := map[string][]spltest.MockFuncCall{"Get": {funcCalls[0], funcCalls[0], funcCalls[2], funcCalls[3], funcCalls[4], funcCalls[5], funcCalls[6], funcCalls[7], funcCalls[8]}, "Update": {funcCalls[8]}, "List": {listmockCall[0]}}
+	createCalls := map[string][]spltest.MockFuncCall{"Get": funcCalls, "Create": {funcCalls[0], funcCalls[2], funcCalls[3], funcCalls[5], funcCalls[9]}, "List": {listmockCall[0]}, "Update": {funcCalls[0]}}
+	updateCalls := map[string][]spltest.MockFuncCall{"Get": {funcCalls[0], funcCalls[0], funcCalls[2], funcCalls[3], funcCalls[4], funcCalls[5], funcCalls[6], funcCalls[7], funcCalls[8], funcCalls[9]}, "Update": {funcCalls[9]}, "List": {listmockCall[0]}}
 
 	current := enterprisev1.ClusterMaster{
 		TypeMeta: metav1.TypeMeta{
@@ -75,7 +78,7 @@ func TestApplyClusterMaster(t *testing.T) {
 	revised := current.DeepCopy()
 	revised.Spec.Image = "splunk/test"
 	reconcile := func(c *spltest.MockClient, cr interface{}) error {
-		_, err := ApplyClusterMaster(c, cr.(*enterprisev1.ClusterMaster))
+		_, err := ApplyClusterMaster(c, nil, cr.(*enterprisev1.ClusterMaster))
 		return err
 	}
 	spltest.ReconcileTesterWithoutRedundantCheck(t, "TestApplyClusterMaster", &current, revised, createCalls, updateCalls, reconcile, true)
@@ -85,7 +88,7 @@ func TestApplyClusterMaster(t *testing.T) {
 	revised.ObjectMeta.DeletionTimestamp = &currentTime
 	revised.ObjectMeta.Finalizers = []string{"enterprise.splunk.com/delete-pvc"}
 	deleteFunc := func(cr splcommon.MetaObject, c splcommon.ControllerClient) (bool, error) {
-		_, err := ApplyClusterMaster(c, cr.(*enterprisev1.ClusterMaster))
+		_, err := ApplyClusterMaster(c, nil, cr.(*enterprisev1.ClusterMaster))
 		return true, err
 	}
 	splunkDeletionTester(t, revised, deleteFunc)
@@ -151,6 +154,8 @@ func TestGetClusterMasterStatefulSet(t *testing.T) {
 
 func TestApplyClusterMasterWithSmartstore(t *testing.T) {
 	funcCalls := []spltest.MockFuncCall{
+		{MetaName: "*v1.Secret-test-splunk-test-secret"},
+		{MetaName: "*v1.Secret-test-splunk-test-secret"},
 		{MetaName: "*v1.Secret-test-splunk-test-secret"},
 		{MetaName: "*v1.Secret-test-splunk-test-secret"},
 		{MetaName: "*v1.ConfigMap-test-splunk-stack1-clustermaster-smartstore"},
@@ -163,6 +168,7 @@ func TestApplyClusterMasterWithSmartstore(t *testing.T) {
 		{MetaName: "*v1.Secret-test-splunk-stack1-cluster-master-secret-v1"},
 		{MetaName: "*v1.ConfigMap-test-splunk-stack1-clustermaster-smartstore"},
 		{MetaName: "*v1.ConfigMap-test-splunk-stack1-clustermaster-smartstore"},
+		{MetaName: "*v1beta1.PodDisruptionBudget-test-splunk-stack1-cluster-master"},
 		{MetaName: "*v1.StatefulSet-test-splunk-stack1-cluster-master"},
 		{MetaName: "*v1.Pod-test-splunk-stack1-cluster-master-0"},
 		{MetaName: "*v1.Secret-test-splunk-test-secret"},
@@ -184,8 +190,8 @@ func TestApplyClusterMasterWithSmartstore(t *testing.T) {
 	}
 	listmockCall := []spltest.MockFuncCall{
 		{ListOpts: listOpts}}
-	createCalls := map[string][]spltest.MockFuncCall{"Get": funcCalls, "Create": {funcCalls[6], funcCalls[7], funcCalls[9], funcCalls[15], funcCalls[16], funcCalls[17], funcCalls[18], funcCalls[20]}, "List": {listmockCall[0], listmockCall[0], listmockCall[0]}, "Update": {funcCalls[0], funcCalls[3], funcCalls[20]}}
-	updateCalls := map[string][]spltest.MockFuncCall{"Get": {funcCalls[0], funcCalls[1], funcCalls[2], funcCalls[3], funcCalls[5], funcCalls[5], funcCalls[6], funcCalls[7], funcCalls[8], funcCalls[9], funcCalls[11], funcCalls[11], funcCalls[12]}, "Update": {funcCalls[10], funcCalls[12]}, "List": {listmockCall[0]}}
+	createCalls := map[string][]spltest.MockFuncCall{"Get": funcCalls, "Create": {funcCalls[8], funcCalls[9], funcCalls[11], funcCalls[18], funcCalls[19], funcCalls[20], funcCalls[21], funcCalls[23]}, "List": {listmockCall[0], listmockCall[0], listmockCall[0]}, "Update": {funcCalls[0], funcCalls[5], funcCalls[23]}}
+	updateCalls := map[string][]spltest.MockFuncCall{"Get": {funcCalls[0], funcCalls[1], funcCalls[2], funcCalls[3], funcCalls[4], funcCalls[5], funcCalls[7], funcCalls[7], funcCalls[8], funcCalls[9], funcCalls[10], funcCalls[11], funcCalls[13], funcCalls[13], funcCalls[14], funcCalls[15]}, "Update": {funcCalls[12], funcCalls[15]}, "List": {listmockCall[0]}}
 
 	current := enterprisev1.ClusterMaster{
 		TypeMeta: metav1.TypeMeta{
@@ -224,7 +230,7 @@ func TestApplyClusterMasterWithSmartstore(t *testing.T) {
 	client := spltest.NewMockClient()
 
 	// Without S3 keys, ApplyClusterMaster should fail
-	_, err := ApplyClusterMaster(client, &current)
+	_, err := applyClusterMaster(client, nil, &current, newMockS3Client(&mockS3Client{}))
 	if err == nil {
 		t.Errorf("ApplyClusterMaster should fail without S3 secrets configured")
 	}
@@ -253,7 +259,7 @@ func TestApplyClusterMasterWithSmartstore(t *testing.T) {
 	revised := current.DeepCopy()
 	revised.Spec.Image = "splunk/test"
 	reconcile := func(c *spltest.MockClient, cr interface{}) error {
-		_, err := ApplyClusterMaster(c, cr.(*enterprisev1.ClusterMaster))
+		_, err := applyClusterMaster(c, nil, cr.(*enterprisev1.ClusterMaster), newMockS3Client(&mockS3Client{}))
 		return err
 	}
 
@@ -280,12 +286,12 @@ func TestApplyClusterMasterWithSmartstore(t *testing.T) {
 	spltest.ReconcileTesterWithoutRedundantCheck(t, "TestApplyClusterMasterWithSmartstore-0", &current, revised, createCalls, updateCalls, reconcile, true, secret, &smartstoreConfigMap, ss, pod)
 
 	current.Status.BundlePushTracker.NeedToPushMasterApps = true
-	if _, err = ApplyClusterMaster(client, &current); err != nil {
+	if _, err = applyClusterMaster(client, nil, &current, newMockS3Client(&mockS3Client{})); err != nil {
 		t.Errorf("ApplyClusterMaster() should not have returned error")
 	}
 
 	current.Spec.CommonSplunkSpec.EtcVolumeStorageConfig.StorageCapacity = "-abcd"
-	if _, err := ApplyClusterMaster(client, &current); err == nil {
+	if _, err := applyClusterMaster(client, nil, &current, newMockS3Client(&mockS3Client{})); err == nil {
 		t.Errorf("ApplyClusterMaster() should have returned error")
 	}
 
@@ -295,7 +301,7 @@ func TestApplyClusterMasterWithSmartstore(t *testing.T) {
 	ss.Spec.Replicas = &replicas
 	ss.Spec.Template.Spec.Containers[0].Image = "splunk/splunk"
 	client.AddObject(ss)
-	if result, err := ApplyClusterMaster(client, &current); err == nil && !result.Requeue {
+	if result, err := applyClusterMaster(client, nil, &current, newMockS3Client(&mockS3Client{})); err == nil && !result.Requeue {
 		t.Errorf("ApplyClusterMaster() should have returned error or result.requeue should have been false")
 	}
 
@@ -306,7 +312,7 @@ func TestApplyClusterMasterWithSmartstore(t *testing.T) {
 	current.Spec.CommonSplunkSpec.Mock = false
 
 	// This should fail at ApplyMonitoringConsole
-	if _, err := ApplyClusterMaster(client, &current); err == nil {
+	if _, err := applyClusterMaster(client, nil, &current, newMockS3Client(&mockS3Client{})); err == nil {
 		t.Errorf("ApplyClusterMaster() should have returned error")
 	}
 }
@@ -362,18 +368,26 @@ func TestPerformCmBundlePush(t *testing.T) {
 
 	current.Status.BundlePushTracker.NeedToPushMasterApps = true
 
-	//Re-attempting to push the CM bundle in less than 5 seconds should return an error
-	current.Status.BundlePushTracker.LastCheckInterval = time.Now().Unix() - 1
+	//Re-attempting to push the CM bundle in less than 5 seconds should keep the push pending, without an error
+	lastCheckInterval := time.Now().Unix() - 1
+	current.Status.BundlePushTracker.LastCheckInterval = lastCheckInterval
 	err = PerformCmBundlePush(client, &current)
-	if err == nil {
-		t.Errorf("Bundle Push Should fail, if attempted to push within 5 seconds interval")
+	if err != nil {
+		t.Errorf("Bundle Push Should not fail, if attempted to push within 5 seconds interval. Error: %s", err.Error())
+	}
+	if !current.Status.BundlePushTracker.NeedToPushMasterApps || current.Status.BundlePushTracker.LastCheckInterval != lastCheckInterval {
+		t.Errorf("Bundle Push Should stay pending, if attempted to push within 5 seconds interval. Tracker: %+v", current.Status.BundlePushTracker)
 	}
+	conditions := []enterprisev1.Condition{}
+	setBundlePushCondition(&current, &conditions, &current.Status.BundlePushTracker)
+	checkCondition(t, conditions, enterprisev1.ConditionBundlePushed, corev1.ConditionFalse, reasonBundlePushPending)
 
-	//Re-attempting to push the CM bundle after 5 seconds passed, should not return an error
-	current.Status.BundlePushTracker.LastCheckInterval = time.Now().Unix() - 10
+	//Re-attempting to push the CM bundle after 5 seconds passed, checks the configMap update to the Pod again
+	lastCheckInterval = time.Now().Unix() - 10
+	current.Status.BundlePushTracker.LastCheckInterval = lastCheckInterval
 	err = PerformCmBundlePush(client, &current)
-	if err != nil && strings.HasPrefix(err.Error(), "Will re-attempt to push the bundle after the 5 seconds") {
-		t.Errorf("Bundle Push Should not fail if reattempted after 5 seconds interval passed. Error: %s", err.Error())
+	if current.Status.BundlePushTracker.LastCheckInterval == lastCheckInterval {
+		t.Errorf("Bundle Push Should be reattempted after 5 seconds interval passed. Error: %v", err)
 	}
 
 	// When the CM Bundle push is not pending, should not return an error
@@ -431,3 +445,173 @@ func TestPushMasterAppsBundle(t *testing.T) {
 		t.Errorf("Bundle push should fail, when the password is not found")
 	}
 }
+
+func TestPushMasterAppsBundleValidation(t *testing.T) {
+	method := "pushMasterAppsBundle"
+	cmURI := "https://splunk-stack1-cluster-master-service.test.svc.cluster.local:8089"
+	newClient := func(handlers ...spltest.MockHTTPHandler) (*splclient.SplunkClient, *spltest.MockHTTPClient) {
+		mockSplunkClient := &spltest.MockHTTPClient{}
+		mockSplunkClient.AddHandlers(handlers...)
+		c := splclient.NewSplunkClient(cmURI, "admin", "123")
+		c.Client = mockSplunkClient
+		return c, mockSplunkClient
+	}
+	infoHandler := func(validated, latest string, valid bool, validationErrors string) spltest.MockHTTPHandler {
+		return spltest.MockHTTPHandler{
+			Method: "GET",
+			URL:    cmURI + "/services/cluster/master/info?count=0&output_mode=json",
+			Status: 200,
+			Body: fmt.Sprintf(`{"entry":[{"name":"master","content":{"apply_bundle_status":{"invalid_bundle":{"bundle_validation_errors_on_master":[%s]},"status":"None"},"last_check_restart_bundle_result":true,"last_validated_bundle":{"checksum":"%s","is_valid_bundle":%t,"timestamp":1600000000},"latest_bundle":{"checksum":"%s","timestamp":1600000000}}}]}`,
+				validationErrors, validated, valid, latest),
+		}
+	}
+	test := func(tracker *enterprisev1.BundlePushInfo, wantPushed bool, handlers ...spltest.MockHTTPHandler) {
+		splunkClient, mockSplunkClient := newClient(handlers...)
+		pushed, err := pushMasterAppsBundle(tracker, splunkClient)
+		if err != nil {
+			t.Errorf("%s returned error: %v", method, err)
+		}
+		if pushed != wantPushed {
+			t.Errorf("%s returned %t; want %t", method, pushed, wantPushed)
+		}
+		mockSplunkClient.CheckRequests(t, method)
+	}
+
+	// the validation of the bundle is requested first
+	tracker := &enterprisev1.BundlePushInfo{NeedToPushMasterApps: true}
+	test(tracker, false, spltest.MockHTTPHandler{Method: "POST", URL: cmURI + "/services/cluster/master/control/default/validate_bundle", Status: 200})
+	if tracker.ValidationStartTime == 0 {
+		t.Errorf("%s did not record the validation start time", method)
+	}
+
+	// the bundle is not pushed until it is validated
+	tracker.ValidationStartTime = 2000000000
+	test(tracker, false, infoHandler("AAA", "BBB", true, ""))
+	if tracker.ValidationStartTime == 0 || !tracker.NeedToPushMasterApps {
+		t.Errorf("%s did not wait for the validation of the bundle", method)
+	}
+
+	// an invalid bundle is not pushed, and is not validated again until the master apps change
+	test(tracker, false, infoHandler("BBB", "BBB", false, `"Invalid key in stanza [main]"`))
+	if tracker.NeedToPushMasterApps || tracker.ApplyInProgress || tracker.Valid || tracker.Checksum != "BBB" {
+		t.Errorf("%s set %v for an invalid bundle", method, *tracker)
+	}
+	if want := "The cluster master found the master apps bundle BBB invalid: Invalid key in stanza [main]"; getBundlePushError(tracker) != want {
+		t.Errorf("getBundlePushError() returned %q; want %q", getBundlePushError(tracker), want)
+	}
+
+	// a valid bundle is pushed, and its application by the peers is tracked
+	tracker.NeedToPushMasterApps = true
+	test(tracker, false, spltest.MockHTTPHandler{Method: "POST", URL: cmURI + "/services/cluster/master/control/default/validate_bundle", Status: 200})
+	if len(tracker.Errors) != 0 {
+		t.Errorf("%s did not clear the errors of the previous bundle", method)
+	}
+	tracker.ValidationStartTime = 2000000000
+	test(tracker, true, infoHandler("CCC", "CCC", true, ""),
+		spltest.MockHTTPHandler{Method: "POST", URL: cmURI + "/services/cluster/master/control/default/apply", Status: 200})
+	if tracker.NeedToPushMasterApps || !tracker.ApplyInProgress || !tracker.Valid || !tracker.RestartRequired || tracker.Checksum != "CCC" || getBundlePushError(tracker) != "" {
+		t.Errorf("%s set %v for a valid bundle", method, *tracker)
+	}
+
+	method = "updateBundleApplyStatus"
+	testApply := func(peer0, peer1, peerErrors string, wantInProgress bool) {
+		splunkClient, mockSplunkClient := newClient(spltest.MockHTTPHandler{
+			Method: "GET",
+			URL:    cmURI + "/services/cluster/master/peers?count=0&output_mode=json",
+			Status: 200,
+			Body: fmt.Sprintf(`{"entry":[{"name":"D39B1729","content":{"label":"splunk-stack1-indexer-0","active_bundle_id":"%s","apply_bundle_status":{"invalid_bundle":{"bundle_validation_errors":[]},"status":"None"}}},{"name":"E2B8C1A5","content":{"label":"splunk-stack1-indexer-1","active_bundle_id":"%s","apply_bundle_status":{"invalid_bundle":{"bundle_validation_errors":[%s]},"status":"None"}}}]}`,
+				peer0, peer1, peerErrors),
+		})
+		err := updateBundleApplyStatus(tracker, splunkClient)
+		if err != nil {
+			t.Errorf("%s returned error: %v", method, err)
+		}
+		if tracker.ApplyInProgress != wantInProgress || len(tracker.Peers) != 2 || tracker.Peers[0].Name != "splunk-stack1-indexer-0" {
+			t.Errorf("%s set %v; want applyInProgress %t", method, *tracker, wantInProgress)
+		}
+		mockSplunkClient.CheckRequests(t, method)
+	}
+	testApply("CCC", "BBB", "", true)
+	if !tracker.Peers[0].Applied || tracker.Peers[1].Applied {
+		t.Errorf("%s set peers %v", method, tracker.Peers)
+	}
+	testApply("CCC", "CCC", "", false)
+	if getBundlePushError(tracker) != "" {
+		t.Errorf("getBundlePushError() returned %q for an applied bundle", getBundlePushError(tracker))
+	}
+
+	tracker.ApplyInProgress = true
+	testApply("CCC", "BBB", `"Unable to load app"`, false)
+	if want := "The indexer cluster peers failed to apply the master apps bundle CCC: splunk-stack1-indexer-1: Unable to load app"; getBundlePushError(tracker) != want {
+		t.Errorf("getBundlePushError() returned %q; want %q", getBundlePushError(tracker), want)
+	}
+}
+
+func TestApplyClusterMasterSmartstorePreflight(t *testing.T) {
+	current := enterprisev1.ClusterMaster{
+		TypeMeta: metav1.TypeMeta{
+			Kind: "ClusterMaster",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "stack1",
+			Namespace: "test",
+		},
+		Spec: enterprisev1.ClusterMasterSpec{
+			SmartStore: enterprisev1.SmartStoreSpec{
+				VolList: []enterprisev1.VolumeSpec{
+					{Name: "msos_s2s3_vol", Endpoint: "https://s3-eu-west-2.amazonaws.com", Path: "testbucket-rs-london", SecretRef: "splunk-test-secret"},
+				},
+				IndexList: []enterprisev1.IndexSpec{
+					{Name: "salesdata1", RemotePath: "remotepath1",
+						IndexAndGlobalCommonSpec: enterprisev1.IndexAndGlobalCommonSpec{
+							VolName: "msos_s2s3_vol"},
+					},
+				},
+			},
+			CommonSplunkSpec: enterprisev1.CommonSplunkSpec{
+				Mock: true,
+			},
+		},
+	}
+	client := spltest.NewMockClient()
+	secret, err := splutil.ApplyNamespaceScopedSecretObject(client, "test")
+	if err != nil {
+		t.Errorf(err.Error())
+	}
+	secret.Data[s3AccessKey] = []byte("abcdJDckRkxhMEdmSk5FekFRRzBFOXV6bGNldzJSWE9IenhVUy80aa")
+	secret.Data[s3SecretKey] = []byte("g4NVp0a29PTzlPdGczWk1vekVUcVBSa0o4NkhBWWMvR1NadDV4YVEy")
+	_, err = splctrl.ApplySecret(client, secret)
+	if err != nil {
+		t.Errorf(err.Error())
+	}
+	namespacedName := types.NamespacedName{Namespace: "test", Name: GetSplunkSmartstoreConfigMapName("stack1", "ClusterMaster")}
+
+	// the configuration is not pushed to the peers while the volume does not accept the probe object
+	s3Client := &mockS3Client{putErr: errors.New("AccessDenied")}
+	if _, err = applyClusterMaster(client, nil, &current, newMockS3Client(s3Client)); err == nil {
+		t.Errorf("ApplyClusterMaster should fail when the preflight check of the remote volumes fails")
+	}
+	if _, err = splctrl.GetConfigMap(client, namespacedName); err == nil {
+		t.Errorf("ApplyClusterMaster created the smartstore configMap although the preflight check failed")
+	}
+	if current.Status.BundlePushTracker.NeedToPushMasterApps {
+		t.Errorf("ApplyClusterMaster requested a bundle push although the preflight check failed")
+	}
+	checkCondition(t, current.Status.Conditions, enterprisev1.ConditionSmartStoreConfigured, corev1.ConditionFalse, reasonSmartStorePreflight)
+	if len(current.Status.SmartStoreVolumes) != 1 || current.Status.SmartStoreVolumes[0].Result != enterprisev1.RemoteVolumeCheckFailed {
+		t.Errorf("ApplyClusterMaster did not report the failed volume: %v", current.Status.SmartStoreVolumes)
+	}
+
+	// once the probe object is accepted, the configuration is pushed to the peers
+	s3Client.putErr = nil
+	if _, err = applyClusterMaster(client, nil, &current, newMockS3Client(s3Client)); err != nil {
+		t.Errorf("ApplyClusterMaster should not fail once the preflight check passes: %v", err)
+	}
+	if _, err = splctrl.GetConfigMap(client, namespacedName); err != nil {
+		t.Errorf("ApplyClusterMaster did not create the smartstore configMap: %v", err)
+	}
+	checkCondition(t, current.Status.Conditions, enterprisev1.ConditionSmartStoreConfigured, corev1.ConditionTrue, reasonSmartStoreConfigured)
+	if len(current.Status.SmartStoreVolumes) != 1 || current.Status.SmartStoreVolumes[0].Result != enterprisev1.RemoteVolumeCheckPassed {
+		t.Errorf("ApplyClusterMaster did not report the checked volume: %v", current.Status.SmartStoreVolumes)
+	}
+}
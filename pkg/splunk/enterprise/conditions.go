@@ -0,0 +1,215 @@
+// Copyright (c) 2018-2021 Splunk Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package enterprise
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	enterprisev1 "github.com/splunk/splunk-operator/pkg/apis/enterprise/v1"
+	splcommon "github.com/splunk/splunk-operator/pkg/splunk/common"
+)
+
+// reasons of the conditions set by the operator
+const (
+	reasonReconciled               = "Reconciled"
+	reasonInvalidSpec              = "InvalidSpec"
+	reasonSecretsSynced            = "SecretsSynced"
+	reasonSecretsSyncFailed        = "SecretsSyncFailed"
+	reasonSmartStoreConfigured     = "SmartStoreConfigured"
+	reasonSmartStoreFailed         = "SmartStoreConfigFailed"
+	reasonSmartStorePreflight      = "SmartStorePreflightFailed"
+	reasonServiceFailed            = "ServiceFailed"
+	reasonServiceAccountFailed     = "ServiceAccountFailed"
+	reasonConfigMapFailed          = "ConfigMapFailed"
+	reasonStatefulSetFailed        = "StatefulSetFailed"
+	reasonDisruptionBudgetFailed   = "PodDisruptionBudgetFailed"
+	reasonMonitoringConsoleFailed  = "MonitoringConsoleFailed"
+	reasonAppFrameworkFailed       = "AppFrameworkFailed"
+	reasonBundlePushed             = "BundlePushed"
+	reasonBundlePushPending        = "BundlePushPending"
+	reasonBundlePushFailed         = "BundlePushFailed"
+	reasonBundleInvalid            = "BundleInvalid"
+	reasonClusterMasterUnreachable = "ClusterMasterUnreachable"
+	reasonDeploymentServerFailed   = "DeploymentServerUnreachable"
+	reasonDeletionFailed           = "DeletionFailed"
+	reasonMultisiteMismatch        = "MultisiteMismatch"
+	reasonLicenseMasterReady       = "LicenseMasterReady"
+	reasonLicenseMasterNotReady    = "LicenseMasterNotReady"
+	reasonLicenseMasterNotFound    = "LicenseMasterNotFound"
+	reasonImageUpToDate            = "ImageUpToDate"
+	reasonUpgradeWaiting           = "UpgradeWaiting"
+	reasonUpgradeInProgress        = "UpgradeInProgress"
+	reasonUpgradeFailed            = "UpgradeFailed"
+	reasonUpgradeRefused           = "UpgradeRefused"
+	reasonServerInfoFailed         = "ServerInfoFailed"
+)
+
+// getCondition returns the condition of the given type, or nil if there is none
+func getCondition(conditions []enterprisev1.Condition, conditionType string) *enterprisev1.Condition {
+	for i := range conditions {
+		if conditions[i].Type == conditionType {
+			return &conditions[i]
+		}
+	}
+	return nil
+}
+
+// setCondition sets the condition of the given type for the current generation of a custom resource.
+// The last transition time of the condition only changes when its status does.
+func setCondition(cr splcommon.MetaObject, conditions *[]enterprisev1.Condition, conditionType string, status corev1.ConditionStatus, reason, message string) {
+	condition := enterprisev1.Condition{
+		Type:               conditionType,
+		Status:             status,
+		ObservedGeneration: cr.GetGeneration(),
+		LastTransitionTime: metav1.Now(),
+		Reason:             reason,
+		Message:            message,
+	}
+
+	current := getCondition(*conditions, conditionType)
+	if current == nil {
+		*conditions = append(*conditions, condition)
+		return
+	}
+	if current.Status == status {
+		condition.LastTransitionTime = current.LastTransitionTime
+	}
+	*current = condition
+}
+
+// removeCondition removes the condition of the given type, if it no longer applies to a custom resource
+func removeCondition(conditions *[]enterprisev1.Condition, conditionType string) {
+	for i := range *conditions {
+		if (*conditions)[i].Type == conditionType {
+			*conditions = append((*conditions)[:i], (*conditions)[i+1:]...)
+			return
+		}
+	}
+}
+
+// setErrorCondition marks a custom resource as degraded because of an error returned by its reconcile.
+// If conditionType is not empty, the condition of that type is set to false with the same reason.
+func setErrorCondition(cr splcommon.MetaObject, conditions *[]enterprisev1.Condition, conditionType, reason string, err error) {
+	if conditionType != "" {
+		setCondition(cr, conditions, conditionType, corev1.ConditionFalse, reason, err.Error())
+	}
+	setCondition(cr, conditions, enterprisev1.ConditionDegraded, corev1.ConditionTrue, reason, err.Error())
+}
+
+// setReconciledCondition marks a custom resource as no longer degraded after a successful reconcile
+func setReconciledCondition(cr splcommon.MetaObject, conditions *[]enterprisev1.Condition) {
+	setCondition(cr, conditions, enterprisev1.ConditionDegraded, corev1.ConditionFalse, reasonReconciled, "")
+}
+
+// setPhaseConditions sets the Available and Progressing conditions of a custom resource from its phase
+func setPhaseConditions(cr splcommon.MetaObject, conditions *[]enterprisev1.Condition, phase splcommon.Phase) {
+	switch phase {
+	case splcommon.PhaseReady:
+		setCondition(cr, conditions, enterprisev1.ConditionAvailable, corev1.ConditionTrue, string(phase), "All instances are ready")
+		setCondition(cr, conditions, enterprisev1.ConditionProgressing, corev1.ConditionFalse, string(phase), "")
+	case splcommon.PhaseError:
+		setCondition(cr, conditions, enterprisev1.ConditionAvailable, corev1.ConditionFalse, string(phase), "See the Degraded condition")
+		setCondition(cr, conditions, enterprisev1.ConditionProgressing, corev1.ConditionFalse, string(phase), "")
+	default:
+		setCondition(cr, conditions, enterprisev1.ConditionAvailable, corev1.ConditionFalse, string(phase), "Not all instances are ready")
+		setCondition(cr, conditions, enterprisev1.ConditionProgressing, corev1.ConditionTrue, string(phase), "")
+	}
+}
+
+// setSmartStoreCondition sets the SmartStoreConfigured condition of a custom resource after its SmartStore configuration was applied
+func setSmartStoreCondition(cr splcommon.MetaObject, conditions *[]enterprisev1.Condition, smartstore *enterprisev1.SmartStoreSpec) {
+	if len(smartstore.VolList) == 0 && len(smartstore.IndexList) == 0 {
+		removeCondition(conditions, enterprisev1.ConditionSmartStoreConfigured)
+		return
+	}
+	setCondition(cr, conditions, enterprisev1.ConditionSmartStoreConfigured, corev1.ConditionTrue, reasonSmartStoreConfigured,
+		fmt.Sprintf("%d volumes and %d indexes are configured", len(smartstore.VolList), len(smartstore.IndexList)))
+}
+
+// getBundlePushError returns why the last master apps bundle of a cluster master was not applied by the peers, if it failed
+func getBundlePushError(tracker *enterprisev1.BundlePushInfo) string {
+	if tracker.ValidationStartTime != 0 || tracker.NeedToPushMasterApps || tracker.Checksum == "" {
+		return ""
+	}
+	if !tracker.Valid {
+		return fmt.Sprintf("The cluster master found the master apps bundle %s invalid: %s", tracker.Checksum, strings.Join(tracker.Errors, "; "))
+	}
+	if len(tracker.Errors) > 0 {
+		return fmt.Sprintf("The indexer cluster peers failed to apply the master apps bundle %s: %s", tracker.Checksum, strings.Join(tracker.Errors, "; "))
+	}
+	return ""
+}
+
+// setBundlePushCondition sets the BundlePushed condition of a cluster master from the state of its master apps bundle push
+func setBundlePushCondition(cr splcommon.MetaObject, conditions *[]enterprisev1.Condition, tracker *enterprisev1.BundlePushInfo) {
+	pushError := getBundlePushError(tracker)
+	switch {
+	case tracker.ValidationStartTime != 0:
+		setCondition(cr, conditions, enterprisev1.ConditionBundlePushed, corev1.ConditionFalse, reasonBundlePushPending,
+			"Waiting for the cluster master to validate the bundle")
+	case tracker.NeedToPushMasterApps:
+		setCondition(cr, conditions, enterprisev1.ConditionBundlePushed, corev1.ConditionFalse, reasonBundlePushPending,
+			"Waiting for the cluster master to load the latest configuration before pushing the bundle")
+	case pushError != "" && !tracker.Valid:
+		setCondition(cr, conditions, enterprisev1.ConditionBundlePushed, corev1.ConditionFalse, reasonBundleInvalid, pushError)
+	case pushError != "":
+		setCondition(cr, conditions, enterprisev1.ConditionBundlePushed, corev1.ConditionFalse, reasonBundlePushFailed, pushError)
+	case tracker.ApplyInProgress:
+		pending := len(tracker.Peers)
+		for _, peer := range tracker.Peers {
+			if peer.Applied {
+				pending--
+			}
+		}
+		message := fmt.Sprintf("Waiting for the peers to apply the bundle %s", tracker.Checksum)
+		if pending > 0 {
+			message = fmt.Sprintf("Waiting for %d of %d peers to apply the bundle %s", pending, len(tracker.Peers), tracker.Checksum)
+		}
+		setCondition(cr, conditions, enterprisev1.ConditionBundlePushed, corev1.ConditionFalse, reasonBundlePushPending, message)
+	default:
+		setCondition(cr, conditions, enterprisev1.ConditionBundlePushed, corev1.ConditionTrue, reasonBundlePushed, "")
+	}
+}
+
+// setLicenseCondition sets the LicenseConnected condition of a custom resource from the phase of the license master it uses
+func setLicenseCondition(c splcommon.ControllerClient, cr splcommon.MetaObject, conditions *[]enterprisev1.Condition, licenseMasterRef corev1.ObjectReference) {
+	if licenseMasterRef.Name == "" {
+		removeCondition(conditions, enterprisev1.ConditionLicenseConnected)
+		return
+	}
+
+	namespacedName := types.NamespacedName{Namespace: licenseMasterRef.Namespace, Name: licenseMasterRef.Name}
+	if namespacedName.Namespace == "" {
+		namespacedName.Namespace = cr.GetNamespace()
+	}
+	var lm enterprisev1.LicenseMaster
+	err := c.Get(context.TODO(), namespacedName, &lm)
+	if err != nil {
+		setCondition(cr, conditions, enterprisev1.ConditionLicenseConnected, corev1.ConditionFalse, reasonLicenseMasterNotFound,
+			fmt.Sprintf("Unable to get license master %s: %v", namespacedName, err))
+	} else if lm.Status.Phase != splcommon.PhaseReady {
+		setCondition(cr, conditions, enterprisev1.ConditionLicenseConnected, corev1.ConditionFalse, reasonLicenseMasterNotReady,
+			fmt.Sprintf("License master %s is in phase %s", namespacedName, lm.Status.Phase))
+	} else {
+		setCondition(cr, conditions, enterprisev1.ConditionLicenseConnected, corev1.ConditionTrue, reasonLicenseMasterReady,
+			fmt.Sprintf("Using license master %s", namespacedName))
+	}
+}
@@ -0,0 +1,192 @@
+// Copyright (c) 2018-2021 Splunk Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package enterprise
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	enterprisev1 "github.com/splunk/splunk-operator/pkg/apis/enterprise/v1"
+	splcommon "github.com/splunk/splunk-operator/pkg/splunk/common"
+	spltest "github.com/splunk/splunk-operator/pkg/splunk/test"
+)
+
+func checkCondition(t *testing.T, conditions []enterprisev1.Condition, conditionType string, status corev1.ConditionStatus, reason string) {
+	condition := getCondition(conditions, conditionType)
+	if condition == nil {
+		t.Errorf("condition %s is missing; want status %s and reason %s", conditionType, status, reason)
+		return
+	}
+	if condition.Status != status || condition.Reason != reason {
+		t.Errorf("condition %s has status %s and reason %s; want %s and %s", conditionType, condition.Status, condition.Reason, status, reason)
+	}
+}
+
+func TestSetCondition(t *testing.T) {
+	cr := enterprisev1.Standalone{
+		ObjectMeta: metav1.ObjectMeta{Name: "stack1", Namespace: "test", Generation: 2},
+	}
+	conditions := &cr.Status.Conditions
+
+	setCondition(&cr, conditions, enterprisev1.ConditionSecretsSynced, corev1.ConditionTrue, reasonSecretsSynced, "")
+	checkCondition(t, *conditions, enterprisev1.ConditionSecretsSynced, corev1.ConditionTrue, reasonSecretsSynced)
+	if (*conditions)[0].ObservedGeneration != 2 {
+		t.Errorf("setCondition() set observedGeneration %d; want 2", (*conditions)[0].ObservedGeneration)
+	}
+
+	// the transition time is only updated when the status changes
+	transitionTime := metav1.NewTime(time.Now().Add(-time.Hour))
+	(*conditions)[0].LastTransitionTime = transitionTime
+	cr.ObjectMeta.Generation = 3
+	setCondition(&cr, conditions, enterprisev1.ConditionSecretsSynced, corev1.ConditionTrue, reasonSecretsSynced, "")
+	if len(*conditions) != 1 || !(*conditions)[0].LastTransitionTime.Equal(&transitionTime) || (*conditions)[0].ObservedGeneration != 3 {
+		t.Errorf("setCondition() with unchanged status returned %v; want one condition with the same transition time and observedGeneration 3", *conditions)
+	}
+	setCondition(&cr, conditions, enterprisev1.ConditionSecretsSynced, corev1.ConditionFalse, reasonSecretsSyncFailed, "failed")
+	if (*conditions)[0].LastTransitionTime.Equal(&transitionTime) {
+		t.Errorf("setCondition() with changed status kept transition time %v", transitionTime)
+	}
+
+	removeCondition(conditions, enterprisev1.ConditionSecretsSynced)
+	if len(*conditions) != 0 {
+		t.Errorf("removeCondition() left conditions %v", *conditions)
+	}
+}
+
+func TestSetErrorCondition(t *testing.T) {
+	cr := enterprisev1.ClusterMaster{
+		ObjectMeta: metav1.ObjectMeta{Name: "master1", Namespace: "test"},
+	}
+	conditions := &cr.Status.Conditions
+
+	setErrorCondition(&cr, conditions, enterprisev1.ConditionBundlePushed, reasonBundlePushFailed, errors.New("push failed"))
+	checkCondition(t, *conditions, enterprisev1.ConditionBundlePushed, corev1.ConditionFalse, reasonBundlePushFailed)
+	checkCondition(t, *conditions, enterprisev1.ConditionDegraded, corev1.ConditionTrue, reasonBundlePushFailed)
+	if getCondition(*conditions, enterprisev1.ConditionDegraded).Message != "push failed" {
+		t.Errorf("setErrorCondition() set message %s; want push failed", getCondition(*conditions, enterprisev1.ConditionDegraded).Message)
+	}
+
+	setErrorCondition(&cr, conditions, "", reasonInvalidSpec, errors.New("invalid"))
+	checkCondition(t, *conditions, enterprisev1.ConditionDegraded, corev1.ConditionTrue, reasonInvalidSpec)
+	if len(*conditions) != 2 {
+		t.Errorf("setErrorCondition() without condition type returned %d conditions; want 2", len(*conditions))
+	}
+
+	setReconciledCondition(&cr, conditions)
+	checkCondition(t, *conditions, enterprisev1.ConditionDegraded, corev1.ConditionFalse, reasonReconciled)
+}
+
+func TestSetBundlePushCondition(t *testing.T) {
+	cr := enterprisev1.ClusterMaster{
+		ObjectMeta: metav1.ObjectMeta{Name: "master1", Namespace: "test"},
+	}
+	test := func(tracker enterprisev1.BundlePushInfo, status corev1.ConditionStatus, reason string) {
+		setBundlePushCondition(&cr, &cr.Status.Conditions, &tracker)
+		checkCondition(t, cr.Status.Conditions, enterprisev1.ConditionBundlePushed, status, reason)
+	}
+
+	test(enterprisev1.BundlePushInfo{}, corev1.ConditionTrue, reasonBundlePushed)
+	test(enterprisev1.BundlePushInfo{NeedToPushMasterApps: true}, corev1.ConditionFalse, reasonBundlePushPending)
+	test(enterprisev1.BundlePushInfo{NeedToPushMasterApps: true, ValidationStartTime: 1600000000}, corev1.ConditionFalse, reasonBundlePushPending)
+	test(enterprisev1.BundlePushInfo{Checksum: "AAA"}, corev1.ConditionFalse, reasonBundleInvalid)
+	test(enterprisev1.BundlePushInfo{Checksum: "AAA", Valid: true, ApplyInProgress: true}, corev1.ConditionFalse, reasonBundlePushPending)
+	test(enterprisev1.BundlePushInfo{Checksum: "AAA", Valid: true, Errors: []string{"failed"}}, corev1.ConditionFalse, reasonBundlePushFailed)
+	test(enterprisev1.BundlePushInfo{Checksum: "AAA", Valid: true}, corev1.ConditionTrue, reasonBundlePushed)
+
+	tracker := enterprisev1.BundlePushInfo{Checksum: "AAA", Valid: true, ApplyInProgress: true,
+		Peers: []enterprisev1.BundlePeerStatus{{Name: "splunk-stack1-indexer-0", Applied: true}, {Name: "splunk-stack1-indexer-1"}}}
+	setBundlePushCondition(&cr, &cr.Status.Conditions, &tracker)
+	if message := getCondition(cr.Status.Conditions, enterprisev1.ConditionBundlePushed).Message; message != "Waiting for 1 of 2 peers to apply the bundle AAA" {
+		t.Errorf("setBundlePushCondition() set message %q", message)
+	}
+}
+
+func TestSetPhaseConditions(t *testing.T) {
+	cr := enterprisev1.IndexerCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "stack1", Namespace: "test"},
+	}
+	test := func(phase splcommon.Phase, available, progressing corev1.ConditionStatus) {
+		setPhaseConditions(&cr, &cr.Status.Conditions, phase)
+		checkCondition(t, cr.Status.Conditions, enterprisev1.ConditionAvailable, available, string(phase))
+		checkCondition(t, cr.Status.Conditions, enterprisev1.ConditionProgressing, progressing, string(phase))
+	}
+
+	test(splcommon.PhasePending, corev1.ConditionFalse, corev1.ConditionTrue)
+	test(splcommon.PhaseScalingUp, corev1.ConditionFalse, corev1.ConditionTrue)
+	test(splcommon.PhaseReady, corev1.ConditionTrue, corev1.ConditionFalse)
+	test(splcommon.PhaseError, corev1.ConditionFalse, corev1.ConditionFalse)
+}
+
+func TestSetSmartStoreCondition(t *testing.T) {
+	cr := enterprisev1.Standalone{
+		ObjectMeta: metav1.ObjectMeta{Name: "stack1", Namespace: "test"},
+	}
+	smartstore := enterprisev1.SmartStoreSpec{
+		VolList:   []enterprisev1.VolumeSpec{{Name: "vol1"}},
+		IndexList: []enterprisev1.IndexSpec{{Name: "index1"}, {Name: "index2"}},
+	}
+
+	setSmartStoreCondition(&cr, &cr.Status.Conditions, &smartstore)
+	checkCondition(t, cr.Status.Conditions, enterprisev1.ConditionSmartStoreConfigured, corev1.ConditionTrue, reasonSmartStoreConfigured)
+	want := "1 volumes and 2 indexes are configured"
+	if got := getCondition(cr.Status.Conditions, enterprisev1.ConditionSmartStoreConfigured).Message; got != want {
+		t.Errorf("setSmartStoreCondition() set message %s; want %s", got, want)
+	}
+
+	setSmartStoreCondition(&cr, &cr.Status.Conditions, &enterprisev1.SmartStoreSpec{})
+	if getCondition(cr.Status.Conditions, enterprisev1.ConditionSmartStoreConfigured) != nil {
+		t.Errorf("setSmartStoreCondition() kept condition without SmartStore configuration")
+	}
+}
+
+func TestSetLicenseCondition(t *testing.T) {
+	c := spltest.NewMockClient()
+	cr := enterprisev1.Standalone{
+		ObjectMeta: metav1.ObjectMeta{Name: "stack1", Namespace: "test"},
+	}
+	conditions := &cr.Status.Conditions
+
+	setLicenseCondition(c, &cr, conditions, cr.Spec.LicenseMasterRef)
+	if len(*conditions) != 0 {
+		t.Errorf("setLicenseCondition() without license master returned %v; want no conditions", *conditions)
+	}
+
+	cr.Spec.LicenseMasterRef.Name = "stack1"
+	setLicenseCondition(c, &cr, conditions, cr.Spec.LicenseMasterRef)
+	checkCondition(t, *conditions, enterprisev1.ConditionLicenseConnected, corev1.ConditionFalse, reasonLicenseMasterNotFound)
+
+	lm := enterprisev1.LicenseMaster{
+		ObjectMeta: metav1.ObjectMeta{Name: "stack1", Namespace: "test"},
+	}
+	lm.Status.Phase = splcommon.PhasePending
+	c.AddObject(&lm)
+	setLicenseCondition(c, &cr, conditions, cr.Spec.LicenseMasterRef)
+	checkCondition(t, *conditions, enterprisev1.ConditionLicenseConnected, corev1.ConditionFalse, reasonLicenseMasterNotReady)
+
+	lm.Status.Phase = splcommon.PhaseReady
+	c.AddObject(&lm)
+	setLicenseCondition(c, &cr, conditions, cr.Spec.LicenseMasterRef)
+	checkCondition(t, *conditions, enterprisev1.ConditionLicenseConnected, corev1.ConditionTrue, reasonLicenseMasterReady)
+
+	cr.Spec.LicenseMasterRef.Name = ""
+	setLicenseCondition(c, &cr, conditions, cr.Spec.LicenseMasterRef)
+	if len(*conditions) != 0 {
+		t.Errorf("setLicenseCondition() kept condition after license master was removed")
+	}
+}
@@ -17,6 +17,9 @@ package enterprise
 import (
 	"context"
 	"fmt"
+	"path"
+	"strings"
+	"time"
 
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
@@ -26,6 +29,7 @@ import (
 	"k8s.io/apimachinery/pkg/util/intstr"
 
 	enterprisev1 "github.com/splunk/splunk-operator/pkg/apis/enterprise/v1"
+	splclient "github.com/splunk/splunk-operator/pkg/splunk/client"
 	splcommon "github.com/splunk/splunk-operator/pkg/splunk/common"
 	splctrl "github.com/splunk/splunk-operator/pkg/splunk/controller"
 	splutil "github.com/splunk/splunk-operator/pkg/splunk/util"
@@ -120,7 +124,7 @@ func getSplunkService(cr splcommon.MetaObject, spec *enterprisev1.CommonSplunkSp
 	service.ObjectMeta.Namespace = cr.GetNamespace()
 	instanceIdentifier := cr.GetName()
 	var partOfIdentifier string
-	if instanceType == SplunkMonitoringConsole {
+	if isImplicitMonitoringConsole(cr, instanceType) {
 		service.ObjectMeta.Name = GetSplunkServiceName(instanceType, cr.GetNamespace(), isHeadless)
 		instanceIdentifier = cr.GetNamespace()
 	}
@@ -165,8 +169,15 @@ func getSplunkService(cr splcommon.MetaObject, spec *enterprisev1.CommonSplunkSp
 	return service
 }
 
-// setVolumeDefaults set properties in Volumes to default values
+// setVolumeDefaults set properties in Volumes and volume storage configurations to default values
 func setVolumeDefaults(spec *enterprisev1.CommonSplunkSpec) {
+	// storage capacities are only used by persistent volume claims
+	if !spec.EtcVolumeStorageConfig.EphemeralStorage && spec.EtcVolumeStorageConfig.StorageCapacity == "" {
+		spec.EtcVolumeStorageConfig.StorageCapacity = splcommon.DefaultEtcVolumeStorageCapacity
+	}
+	if !spec.VarVolumeStorageConfig.EphemeralStorage && spec.VarVolumeStorageConfig.StorageCapacity == "" {
+		spec.VarVolumeStorageConfig.StorageCapacity = splcommon.DefaultVarVolumeStorageCapacity
+	}
 
 	// work-around openapi validation error by ensuring it is not nil
 	if spec.Volumes == nil {
@@ -214,8 +225,9 @@ func validateCommonSplunkSpec(spec *enterprisev1.CommonSplunkSpec) error {
 }
 
 // getSplunkDefaults returns a Kubernetes ConfigMap containing defaults for a Splunk Enterprise resource.
-func getSplunkDefaults(identifier, namespace string, instanceType InstanceType, defaults string) *corev1.ConfigMap {
-	return &corev1.ConfigMap{
+// The defaults rendered from a typed multisite configuration are kept in a separate file, if any.
+func getSplunkDefaults(identifier, namespace string, instanceType InstanceType, defaults, multisiteDefaults string) *corev1.ConfigMap {
+	configMap := &corev1.ConfigMap{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      GetSplunkDefaultsName(identifier, instanceType),
 			Namespace: namespace,
@@ -224,6 +236,10 @@ func getSplunkDefaults(identifier, namespace string, instanceType InstanceType,
 			"default.yml": defaults,
 		},
 	}
+	if multisiteDefaults != "" {
+		configMap.Data["multisite.yml"] = multisiteDefaults
+	}
+	return configMap
 }
 
 // prepareSplunkSmartstoreConfigMap returns a K8 ConfigMap containing Splunk smartstore config in INI format
@@ -256,6 +272,9 @@ func getSplunkPorts(instanceType InstanceType) map[string]int {
 	case SplunkIndexer:
 		result[GetPortName(hecPort, protoHTTP)] = 8088
 		result[GetPortName(s2sPort, protoTCP)] = 9997
+	case SplunkHeavyForwarder:
+		result[GetPortName(hecPort, protoHTTP)] = 8088
+		result[GetPortName(s2sPort, protoTCP)] = 9997
 	}
 
 	return result
@@ -383,6 +402,15 @@ func getSplunkStatefulSet(client splcommon.ControllerClient, cr splcommon.MetaOb
 	selectLabels := getSplunkLabels(cr.GetName(), instanceType, spec.ClusterMasterRef.Name)
 	affinity := splcommon.AppendPodAntiAffinity(&spec.Affinity, cr.GetName(), instanceType.ToString())
 
+	// constrain the pods of a site to the zone it is mapped to
+	zoneLabel, zone, err := getSiteZone(client, cr, instanceType)
+	if err != nil {
+		return nil, err
+	}
+	if zone != "" {
+		affinity = splcommon.AppendNodeAffinity(affinity, zoneLabel, zone)
+	}
+
 	// start with same labels as selector; note that this object gets modified by splcommon.AppendParentMeta()
 	labels := make(map[string]string)
 	for k, v := range selectLabels {
@@ -432,7 +460,7 @@ func getSplunkStatefulSet(client splcommon.ControllerClient, cr splcommon.MetaOb
 	}
 
 	// Add storage volumes
-	err := addStorageVolumes(cr, spec, statefulSet, labels)
+	err = addStorageVolumes(cr, spec, statefulSet, labels)
 	if err != nil {
 		return statefulSet, err
 	}
@@ -465,6 +493,46 @@ func getSplunkStatefulSet(client splcommon.ControllerClient, cr splcommon.MetaOb
 	return statefulSet, nil
 }
 
+// applyRemoteVolumeServiceAccount annotates the service account of the pods with the IAM role of the SmartStore volumes
+// that use authMode iamRole. It returns a warning if the pods have no service account to annotate.
+func applyRemoteVolumeServiceAccount(client splcommon.ControllerClient, spec *enterprisev1.CommonSplunkSpec, namespace string, smartstore *enterprisev1.SmartStoreSpec) (string, error) {
+	usesRole := false
+	roleARN := ""
+	for i := range smartstore.VolList {
+		if !usesRemoteVolumeKeys(&smartstore.VolList[i]) {
+			usesRole = true
+			if smartstore.VolList[i].RoleARN != "" {
+				roleARN = smartstore.VolList[i].RoleARN
+			}
+		}
+	}
+	if !usesRole {
+		return "", nil
+	}
+
+	if spec.ServiceAccount == "" {
+		return "SmartStore volumes use authMode iamRole, but no serviceAccount is configured for the pods", nil
+	}
+	namespacedName := types.NamespacedName{Namespace: namespace, Name: spec.ServiceAccount}
+	serviceAccount, err := splctrl.GetServiceAccount(client, namespacedName)
+	if err != nil {
+		// getSplunkStatefulSet does not use a service account that does not exist
+		return fmt.Sprintf("SmartStore volumes use authMode iamRole, but serviceAccount %s does not exist", spec.ServiceAccount), nil
+	}
+
+	// without a roleArn, the service account is expected to be annotated already
+	if roleARN == "" || serviceAccount.GetAnnotations()[roleARNAnnotation] == roleARN {
+		return "", nil
+	}
+	annotations := serviceAccount.GetAnnotations()
+	if annotations == nil {
+		annotations = make(map[string]string)
+	}
+	annotations[roleARNAnnotation] = roleARN
+	serviceAccount.SetAnnotations(annotations)
+	return "", splutil.UpdateResource(client, serviceAccount)
+}
+
 // getSmartstoreConfigMap returns the smartstore configMap, if it exists and applicable for that instanceType
 func getSmartstoreConfigMap(client splcommon.ControllerClient, cr splcommon.MetaObject, instanceType InstanceType) (*corev1.ConfigMap, bool) {
 	var smartStoreConfigMapName string
@@ -506,7 +574,7 @@ func updateSplunkPodTemplateWithConfig(client splcommon.ControllerClient, podTem
 	}
 
 	// Add custom volumes to splunk containers other than MC(where CR spec volumes are not needed)
-	if spec.Volumes != nil && instanceType != SplunkMonitoringConsole {
+	if spec.Volumes != nil && !isImplicitMonitoringConsole(cr, instanceType) {
 		podTemplateSpec.Spec.Volumes = append(podTemplateSpec.Spec.Volumes, spec.Volumes...)
 		for idx := range podTemplateSpec.Spec.Containers {
 			for v := range spec.Volumes {
@@ -531,7 +599,8 @@ func updateSplunkPodTemplateWithConfig(client splcommon.ControllerClient, podTem
 	configMapVolDefaultMode := int32(corev1.ConfigMapVolumeSourceDefaultMode)
 
 	// add inline defaults to all splunk containers other than MC(where CR spec defaults are not needed)
-	if spec.Defaults != "" && instanceType != SplunkMonitoringConsole {
+	multisiteDefaults := getMultisiteDefaults(cr)
+	if (spec.Defaults != "" || multisiteDefaults != "") && !isImplicitMonitoringConsole(cr, instanceType) {
 		configMapName := GetSplunkDefaultsName(cr.GetName(), instanceType)
 		addSplunkVolumeToTemplate(podTemplateSpec, "mnt-splunk-defaults", "/mnt/splunk-defaults", corev1.VolumeSource{
 			ConfigMap: &corev1.ConfigMapVolumeSource{
@@ -548,7 +617,7 @@ func updateSplunkPodTemplateWithConfig(client splcommon.ControllerClient, podTem
 		// so that any change in the ConfigMap will lead to recycle of the pod.
 		configMapResourceVersion, err := splctrl.GetConfigMapResourceVersion(client, namespacedName)
 		if err == nil {
-			podTemplateSpec.ObjectMeta.Annotations["defaultConfigRev"] = configMapResourceVersion
+			podTemplateSpec.ObjectMeta.Annotations[defaultConfigRev] = configMapResourceVersion
 		} else {
 			scopedLog.Error(err, "Updation of default configMap annotation failed")
 		}
@@ -632,6 +701,10 @@ func updateSplunkPodTemplateWithConfig(client splcommon.ControllerClient, podTem
 	if spec.DefaultsURL != "" {
 		splunkDefaults = fmt.Sprintf("%s,%s", spec.DefaultsURL, splunkDefaults)
 	}
+	// typed multisite config takes precedence over inline defaults, which are loaded first
+	if multisiteDefaults != "" {
+		splunkDefaults = fmt.Sprintf("%s,%s", "/mnt/splunk-defaults/multisite.yml", splunkDefaults)
+	}
 	if spec.Defaults != "" {
 		splunkDefaults = fmt.Sprintf("%s,%s", "/mnt/splunk-defaults/default.yml", splunkDefaults)
 	}
@@ -701,7 +774,8 @@ func updateSplunkPodTemplateWithConfig(client splcommon.ControllerClient, podTem
 		}
 	}
 
-	if clusterMasterURL != "" {
+	// heavy forwarders use the outputs.conf generated by the operator, instead of joining the indexer cluster
+	if clusterMasterURL != "" && instanceType != SplunkHeavyForwarder {
 		extraEnv = append(extraEnv, corev1.EnvVar{
 			Name:  "SPLUNK_CLUSTER_MASTER_URL",
 			Value: clusterMasterURL,
@@ -719,7 +793,7 @@ func updateSplunkPodTemplateWithConfig(client splcommon.ControllerClient, podTem
 	// update each container in pod
 	for idx := range podTemplateSpec.Spec.Containers {
 		//Used pre-defined resource values for MC
-		if instanceType != SplunkMonitoringConsole {
+		if !isImplicitMonitoringConsole(cr, instanceType) {
 			podTemplateSpec.Spec.Containers[idx].Resources = spec.Resources
 		}
 		podTemplateSpec.Spec.Containers[idx].LivenessProbe = livenessProbe
@@ -737,6 +811,19 @@ func isSmartstoreConfigured(smartstore *enterprisev1.SmartStoreSpec) bool {
 	return smartstore.IndexList != nil || smartstore.VolList != nil || smartstore.Defaults.VolName != ""
 }
 
+// getRemoteVolumeProvider returns the remote storage provider of a volume, which defaults to S3
+func getRemoteVolumeProvider(volume *enterprisev1.VolumeSpec) enterprisev1.RemoteStorageProvider {
+	if volume.Provider == "" {
+		return enterprisev1.RemoteStorageProviderS3
+	}
+	return volume.Provider
+}
+
+// usesRemoteVolumeKeys returns true if Splunk Enterprise authenticates to a volume with the keys of its secret
+func usesRemoteVolumeKeys(volume *enterprisev1.VolumeSpec) bool {
+	return volume.AuthMode != enterprisev1.RemoteVolumeAuthModeIAMRole
+}
+
 func checkIfVolumeExists(volumeList []enterprisev1.VolumeSpec, volName string) (int, error) {
 	for i, volume := range volumeList {
 		if volume.Name == volName {
@@ -758,6 +845,10 @@ func AreRemoteVolumeKeysChanged(client splcommon.ControllerClient, cr splcommon.
 
 	volList := smartstore.VolList
 	for _, volume := range volList {
+		if !usesRemoteVolumeKeys(&volume) {
+			continue
+		}
+
 		namespaceScopedSecret, err := splutil.GetSecretByName(client, cr, volume.SecretRef)
 		// Ideally, this should have been detected in Spec validation time
 		if err != nil {
@@ -798,6 +889,7 @@ func ValidateSplunkSmartstoreSpec(smartstore *enterprisev1.SmartStoreSpec) error
 	}
 
 	duplicateChecker := make(map[string]bool)
+	var roleARN string
 
 	volList := smartstore.VolList
 	// Make sure that all the Volumes are provided with the mandatory config values.
@@ -812,16 +904,48 @@ func ValidateSplunkSmartstoreSpec(smartstore *enterprisev1.SmartStoreSpec) error
 			return fmt.Errorf("Volume name is missing for volume at : %d", i)
 		}
 
-		if volume.Endpoint == "" {
-			return fmt.Errorf("Volume Endpoint URI is missing")
+		provider := getRemoteVolumeProvider(&volume)
+		switch provider {
+		case enterprisev1.RemoteStorageProviderS3, enterprisev1.RemoteStorageProviderAzure:
+			if volume.Endpoint == "" {
+				return fmt.Errorf("Volume Endpoint URI is missing")
+			}
+		case enterprisev1.RemoteStorageProviderGCS:
+			// Splunk Enterprise always uses the Google Cloud Storage endpoint
+		default:
+			return fmt.Errorf("Volume: %s, has an unsupported provider: %s. Use s3, gcs or azure", volume.Name, provider)
 		}
 
 		if volume.Path == "" {
 			return fmt.Errorf("Volume Path is missing")
 		}
 
-		if volume.SecretRef == "" {
-			return fmt.Errorf("Volume SecretRef is missing")
+		err = validateS3VolumeSettings(&volume, provider)
+		if err != nil {
+			return err
+		}
+
+		switch volume.AuthMode {
+		case "", enterprisev1.RemoteVolumeAuthModeAccessKey:
+			if volume.SecretRef == "" {
+				return fmt.Errorf("Volume SecretRef is missing")
+			}
+			if volume.RoleARN != "" {
+				return fmt.Errorf("Volume: %s, sets roleArn without authMode iamRole", volume.Name)
+			}
+		case enterprisev1.RemoteVolumeAuthModeIAMRole:
+			if provider != enterprisev1.RemoteStorageProviderS3 {
+				return fmt.Errorf("Volume: %s, uses authMode iamRole, which is only supported by the s3 provider", volume.Name)
+			}
+			// the pods have a single service account, annotated with a single role
+			if volume.RoleARN != "" {
+				if roleARN != "" && roleARN != volume.RoleARN {
+					return fmt.Errorf("Volume: %s, uses roleArn %s, but another volume uses roleArn %s. All the volumes must use the same IAM role", volume.Name, volume.RoleARN, roleARN)
+				}
+				roleARN = volume.RoleARN
+			}
+		default:
+			return fmt.Errorf("Volume: %s, has an unsupported authMode: %s. Use accessKey or iamRole", volume.Name, volume.AuthMode)
 		}
 	}
 
@@ -863,25 +987,334 @@ func ValidateSplunkSmartstoreSpec(smartstore *enterprisev1.SmartStoreSpec) error
 	return nil
 }
 
+// validateS3VolumeSettings checks the settings of a volume that only apply to the S3 provider
+func validateS3VolumeSettings(volume *enterprisev1.VolumeSpec, provider enterprisev1.RemoteStorageProvider) error {
+	caBundle := &volume.CABundle
+	if provider != enterprisev1.RemoteStorageProviderS3 {
+		if volume.Region != "" || volume.SignatureVersion != "" || volume.PathStyle || volume.SSLVerifyServerCert ||
+			*caBundle != (enterprisev1.CABundleSpec{}) || volume.Encryption != "" || volume.KMSKeyID != "" {
+			return fmt.Errorf("Volume: %s, sets S3 settings, which are not supported by the %s provider", volume.Name, provider)
+		}
+		return nil
+	}
+
+	switch volume.SignatureVersion {
+	case "", "v2", "v4":
+	default:
+		return fmt.Errorf("Volume: %s, has an unsupported signatureVersion: %s. Use v2 or v4", volume.Name, volume.SignatureVersion)
+	}
+
+	switch volume.Encryption {
+	case "", "sse-s3":
+	case "sse-kms":
+		if volume.KMSKeyID == "" {
+			return fmt.Errorf("Volume: %s, uses sse-kms encryption without a kmsKeyId", volume.Name)
+		}
+	default:
+		return fmt.Errorf("Volume: %s, has an unsupported encryption: %s. Use sse-s3 or sse-kms", volume.Name, volume.Encryption)
+	}
+	if volume.KMSKeyID != "" && volume.Encryption != "sse-kms" {
+		return fmt.Errorf("Volume: %s, sets kmsKeyId without sse-kms encryption", volume.Name)
+	}
+
+	if caBundle.SecretRef != "" && caBundle.ConfigMapRef != "" {
+		return fmt.Errorf("Volume: %s, has a CA bundle with both a secretRef and a configMapRef", volume.Name)
+	}
+	if caBundle.Key != "" && caBundle.SecretRef == "" && caBundle.ConfigMapRef == "" {
+		return fmt.Errorf("Volume: %s, has a CA bundle key without a secretRef or a configMapRef", volume.Name)
+	}
+
+	return nil
+}
+
+// getRemoteVolumeCAPath returns the path of the CA bundle of a remote volume in the pods, or "" if it has none
+func getRemoteVolumeCAPath(volume *enterprisev1.VolumeSpec) string {
+	caBundle := &volume.CABundle
+	if caBundle.SecretRef == "" && caBundle.ConfigMapRef == "" {
+		return ""
+	}
+	key := caBundle.Key
+	if key == "" {
+		key = defaultCABundleKey
+	}
+	return fmt.Sprintf("%s/%s/%s", remoteVolumeCADir, volume.Name, key)
+}
+
+// addRemoteVolumeCABundles mounts the CA bundles of the SmartStore volumes in the pods, for all the instances that
+// access the volumes
+func addRemoteVolumeCABundles(podTemplateSpec *corev1.PodTemplateSpec, smartstore *enterprisev1.SmartStoreSpec) {
+	defaultMode := int32(corev1.ConfigMapVolumeSourceDefaultMode)
+	for i := range smartstore.VolList {
+		volume := &smartstore.VolList[i]
+		caPath := getRemoteVolumeCAPath(volume)
+		if caPath == "" {
+			continue
+		}
+
+		items := []corev1.KeyToPath{{Key: path.Base(caPath), Path: path.Base(caPath)}}
+		var volumeSource corev1.VolumeSource
+		if volume.CABundle.SecretRef != "" {
+			volumeSource.Secret = &corev1.SecretVolumeSource{
+				SecretName:  volume.CABundle.SecretRef,
+				Items:       items,
+				DefaultMode: &defaultMode,
+			}
+		} else {
+			volumeSource.ConfigMap = &corev1.ConfigMapVolumeSource{
+				LocalObjectReference: corev1.LocalObjectReference{Name: volume.CABundle.ConfigMapRef},
+				Items:                items,
+				DefaultMode:          &defaultMode,
+			}
+		}
+
+		// volume names may not be valid pod volume names
+		addSplunkVolumeToTemplate(podTemplateSpec, fmt.Sprintf("mnt-splunk-ca-%d", i), path.Dir(caPath), volumeSource)
+	}
+}
+
+// getRemoteVolumeGCSCredentialPath returns the path of the service account key of a GCS volume in the pods, or "" if
+// the volume does not use one
+func getRemoteVolumeGCSCredentialPath(volume *enterprisev1.VolumeSpec) string {
+	if getRemoteVolumeProvider(volume) != enterprisev1.RemoteStorageProviderGCS || !usesRemoteVolumeKeys(volume) {
+		return ""
+	}
+	return fmt.Sprintf("%s/%s/%s", remoteVolumeGCSDir, volume.Name, gcsCredentialFile)
+}
+
+// addRemoteVolumeGCSCredentials mounts the service account keys of the GCS volumes from their secrets in the pods, for
+// all the instances that access the volumes
+func addRemoteVolumeGCSCredentials(podTemplateSpec *corev1.PodTemplateSpec, smartstore *enterprisev1.SmartStoreSpec) {
+	defaultMode := int32(corev1.SecretVolumeSourceDefaultMode)
+	for i := range smartstore.VolList {
+		volume := &smartstore.VolList[i]
+		credentialPath := getRemoteVolumeGCSCredentialPath(volume)
+		if credentialPath == "" {
+			continue
+		}
+
+		volumeSource := corev1.VolumeSource{
+			Secret: &corev1.SecretVolumeSource{
+				SecretName:  volume.SecretRef,
+				Items:       []corev1.KeyToPath{{Key: gcsCredentialFile, Path: gcsCredentialFile}},
+				DefaultMode: &defaultMode,
+			},
+		}
+
+		// volume names may not be valid pod volume names
+		addSplunkVolumeToTemplate(podTemplateSpec, fmt.Sprintf("mnt-splunk-gcs-%d", i), path.Dir(credentialPath), volumeSource)
+	}
+}
+
+// getS3VolumeSettings returns the optional settings of an S3 volume in INI format
+func getS3VolumeSettings(volume *enterprisev1.VolumeSpec) string {
+	var settings string
+	if volume.Region != "" {
+		settings = fmt.Sprintf("%sremote.s3.auth_region = %s\n", settings, volume.Region)
+	}
+	if volume.SignatureVersion != "" {
+		settings = fmt.Sprintf("%sremote.s3.signature_version = %s\n", settings, volume.SignatureVersion)
+	}
+	if volume.PathStyle {
+		// version 1 URLs have the bucket in their path
+		settings = fmt.Sprintf("%sremote.s3.url_version = v1\n", settings)
+	}
+	if volume.SSLVerifyServerCert {
+		settings = fmt.Sprintf("%sremote.s3.sslVerifyServerCert = true\n", settings)
+	}
+	if caPath := getRemoteVolumeCAPath(volume); caPath != "" {
+		settings = fmt.Sprintf("%sremote.s3.sslRootCAPath = %s\n", settings, caPath)
+	}
+	if volume.Encryption != "" {
+		settings = fmt.Sprintf("%sremote.s3.encryption = %s\n", settings, volume.Encryption)
+	}
+	if volume.KMSKeyID != "" {
+		settings = fmt.Sprintf("%sremote.s3.kms.key_id = %s\n", settings, volume.KMSKeyID)
+	}
+	return settings
+}
+
+// CheckSmartstoreVolumes runs the preflight check of the remote volumes of a SmartStore configuration, before it is
+// rolled out: a probe object is written under the path of each S3 volume with the keys of its secret, listed, then
+// deleted. It returns the result of each volume, and an error if any of them failed.
+func CheckSmartstoreVolumes(client splcommon.ControllerClient, cr splcommon.MetaObject, smartstore *enterprisev1.SmartStoreSpec) ([]enterprisev1.RemoteVolumeStatus, error) {
+	return checkSmartstoreVolumes(client, cr, smartstore, splclient.NewAWSS3ClientWithConfig)
+}
+
+// checkSmartstoreVolumes runs the preflight check of the remote volumes of a SmartStore configuration with the S3
+// clients returned by newS3Client
+func checkSmartstoreVolumes(client splcommon.ControllerClient, cr splcommon.MetaObject, smartstore *enterprisev1.SmartStoreSpec,
+	newS3Client func(cfg splclient.S3ClientConfig) (splclient.S3Client, error)) ([]enterprisev1.RemoteVolumeStatus, error) {
+	scopedLog := log.WithName("CheckSmartstoreVolumes").WithValues("name", cr.GetName(), "namespace", cr.GetNamespace())
+
+	var statuses []enterprisev1.RemoteVolumeStatus
+	var failures []string
+	for i := range smartstore.VolList {
+		volume := &smartstore.VolList[i]
+		status := enterprisev1.RemoteVolumeStatus{Name: volume.Name, Result: enterprisev1.RemoteVolumeCheckPassed}
+		provider := getRemoteVolumeProvider(volume)
+		if provider != enterprisev1.RemoteStorageProviderS3 {
+			status.Result = enterprisev1.RemoteVolumeCheckSkipped
+			status.Message = fmt.Sprintf("Preflight check is not supported for %s volumes", provider)
+		} else if !usesRemoteVolumeKeys(volume) {
+			// the IAM role is assumed by the Splunk pods, not by the operator
+			status.Result = enterprisev1.RemoteVolumeCheckSkipped
+			status.Message = "Preflight check is not supported for volumes using an IAM role"
+		} else if err := checkS3Volume(client, cr, smartstore, volume, newS3Client); err != nil {
+			scopedLog.Error(err, "Preflight check failed", "volume", volume.Name)
+			status.Result = enterprisev1.RemoteVolumeCheckFailed
+			status.Message = err.Error()
+			failures = append(failures, fmt.Sprintf("%s: %v", volume.Name, err))
+		}
+		statuses = append(statuses, status)
+	}
+
+	if len(failures) > 0 {
+		return statuses, fmt.Errorf("Preflight check of remote volumes failed: %s", strings.Join(failures, "; "))
+	}
+	return statuses, nil
+}
+
+// checkS3Volume writes a probe object under the path of an S3 volume, lists it, then deletes it. The settings of
+// the volume are honored the way Splunk Enterprise does, so that the certificate of the endpoint is only verified
+// with sslVerifyServerCert.
+func checkS3Volume(client splcommon.ControllerClient, cr splcommon.MetaObject, smartstore *enterprisev1.SmartStoreSpec,
+	volume *enterprisev1.VolumeSpec, newS3Client func(cfg splclient.S3ClientConfig) (splclient.S3Client, error)) error {
+	accessKey, secretKey, _, err := GetSmartstoreRemoteVolumeSecrets(*volume, client, cr, smartstore)
+	if err != nil {
+		return err
+	}
+	caBundle, err := getRemoteVolumeCABundle(client, cr, volume)
+	if err != nil {
+		return err
+	}
+
+	// volume path is of the form <bucket>/<prefix>
+	bucket := strings.Split(strings.Trim(volume.Path, "/"), "/")[0]
+	prefix := strings.TrimPrefix(strings.Trim(volume.Path, "/"), bucket)
+
+	cfg := splclient.S3ClientConfig{
+		Endpoint:           volume.Endpoint,
+		Bucket:             bucket,
+		Region:             volume.Region,
+		AccessKey:          accessKey,
+		SecretKey:          secretKey,
+		CABundle:           caBundle,
+		InsecureSkipVerify: !volume.SSLVerifyServerCert,
+		KMSKeyID:           volume.KMSKeyID,
+	}
+	switch volume.Encryption {
+	case "sse-s3":
+		cfg.ServerSideEncryption = "AES256"
+	case "sse-kms":
+		cfg.ServerSideEncryption = "aws:kms"
+	}
+	s3Client, err := newS3Client(cfg)
+	if err != nil {
+		return err
+	}
+
+	key := path.Join(strings.TrimPrefix(prefix, "/"), fmt.Sprintf(remoteVolumeProbeObject, cr.GetNamespace(), cr.GetName()))
+	err = s3Client.PutObject(key, []byte(fmt.Sprintf("%d", time.Now().Unix())))
+	if err != nil {
+		return err
+	}
+
+	objects, err := s3Client.ListObjects(key)
+	if err == nil {
+		err = fmt.Errorf("Probe object %s is missing from the objects listed in bucket %s", key, bucket)
+		for _, obj := range objects {
+			if obj.Key == key {
+				err = nil
+				break
+			}
+		}
+	}
+
+	// always try to clean up the probe object
+	deleteErr := s3Client.DeleteObject(key)
+	if err != nil {
+		return err
+	}
+	return deleteErr
+}
+
+// getRemoteVolumeCABundle returns the CA bundle of a remote volume from its Secret or ConfigMap, if it has one
+func getRemoteVolumeCABundle(client splcommon.ControllerClient, cr splcommon.MetaObject, volume *enterprisev1.VolumeSpec) ([]byte, error) {
+	caPath := getRemoteVolumeCAPath(volume)
+	if caPath == "" {
+		return nil, nil
+	}
+	key := path.Base(caPath)
+
+	var caBundle []byte
+	if volume.CABundle.SecretRef != "" {
+		secret, err := splutil.GetSecretByName(client, cr, volume.CABundle.SecretRef)
+		if err != nil {
+			return nil, err
+		}
+		caBundle = secret.Data[key]
+	} else {
+		configMap, err := splctrl.GetConfigMap(client, types.NamespacedName{Namespace: cr.GetNamespace(), Name: volume.CABundle.ConfigMapRef})
+		if err != nil {
+			return nil, err
+		}
+		caBundle = []byte(configMap.Data[key])
+	}
+
+	if len(caBundle) == 0 {
+		return nil, fmt.Errorf("CA bundle %s is missing", key)
+	}
+	return caBundle, nil
+}
+
 // GetSmartstoreVolumesConfig returns the list of Volumes configuration in INI format
 func GetSmartstoreVolumesConfig(client splcommon.ControllerClient, cr splcommon.MetaObject, smartstore *enterprisev1.SmartStoreSpec, mapData map[string]string) (string, error) {
 	var volumesConf string
 
 	volumes := smartstore.VolList
 	for i := 0; i < len(volumes); i++ {
-		s3AccessKey, s3SecretKey, _, err := GetSmartstoreRemoteVolumeSecrets(volumes[i], client, cr, smartstore)
+		accessKey, secretKey, _, err := GetSmartstoreRemoteVolumeSecrets(volumes[i], client, cr, smartstore)
 		if err != nil {
 			return "", fmt.Errorf("Unable to read the secrets for volume = %s. %s", volumes[i].Name, err)
 		}
 
-		volumesConf = fmt.Sprintf(`%s
+		switch getRemoteVolumeProvider(&volumes[i]) {
+		case enterprisev1.RemoteStorageProviderGCS:
+			volumesConf = fmt.Sprintf(`%s
+[volume:%s]
+storageType = remote
+path = gs://%s
+remote.gs.credential_file = %s
+`, volumesConf, volumes[i].Name, volumes[i].Path, accessKey)
+		case enterprisev1.RemoteStorageProviderAzure:
+			volumesConf = fmt.Sprintf(`%s
+[volume:%s]
+storageType = remote
+path = azure://%s
+remote.azure.access_key = %s
+remote.azure.secret_key = %s
+remote.azure.endpoint = %s
+`, volumesConf, volumes[i].Name, volumes[i].Path, accessKey, secretKey, volumes[i].Endpoint)
+		default:
+			if usesRemoteVolumeKeys(&volumes[i]) {
+				volumesConf = fmt.Sprintf(`%s
 [volume:%s]
 storageType = remote
 path = s3://%s
 remote.s3.access_key = %s
 remote.s3.secret_key = %s
 remote.s3.endpoint = %s
-`, volumesConf, volumes[i].Name, volumes[i].Path, s3AccessKey, s3SecretKey, volumes[i].Endpoint)
+%s`, volumesConf, volumes[i].Name, volumes[i].Path, accessKey, secretKey, volumes[i].Endpoint, getS3VolumeSettings(&volumes[i]))
+			} else {
+				// without keys, Splunk Enterprise uses the credentials of the IAM role of the pod
+				volumesConf = fmt.Sprintf(`%s
+[volume:%s]
+storageType = remote
+path = s3://%s
+remote.s3.endpoint = %s
+%s`, volumesConf, volumes[i].Name, volumes[i].Path, volumes[i].Endpoint, getS3VolumeSettings(&volumes[i]))
+			}
+		}
 	}
 
 	return volumesConf, nil
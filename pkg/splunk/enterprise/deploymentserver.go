@@ -0,0 +1,300 @@
+// Copyright (c) 2018-2021 Splunk Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package enterprise
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	enterprisev1 "github.com/splunk/splunk-operator/pkg/apis/enterprise/v1"
+	splclient "github.com/splunk/splunk-operator/pkg/splunk/client"
+	splcommon "github.com/splunk/splunk-operator/pkg/splunk/common"
+	splctrl "github.com/splunk/splunk-operator/pkg/splunk/controller"
+)
+
+// ApplyDeploymentServer reconciles the state for the Splunk Enterprise deployment server.
+func ApplyDeploymentServer(client splcommon.ControllerClient, recorder record.EventRecorder, cr *enterprisev1.DeploymentServer) (reconcile.Result, error) {
+
+	// unless modified, reconcile for this object will be requeued after 5 seconds
+	result := reconcile.Result{
+		Requeue:      true,
+		RequeueAfter: time.Second * 5,
+	}
+
+	eventPublisher := splcommon.NewEventPublisher(recorder, cr)
+
+	// validate and updates defaults for CR
+	err := validateDeploymentServerSpec(&cr.Spec)
+	if err != nil {
+		cr.Status.Phase = splcommon.PhaseError
+		setErrorCondition(cr, &cr.Status.Conditions, "", reasonInvalidSpec, err)
+		cr.Status.ObservedGeneration = cr.GetGeneration()
+		client.Status().Update(context.TODO(), cr)
+		return result, err
+	}
+
+	// updates status after function completes
+	cr.Status.Phase = splcommon.PhaseError
+	defer func() {
+		setPhaseConditions(cr, &cr.Status.Conditions, cr.Status.Phase)
+		cr.Status.ObservedGeneration = cr.GetGeneration()
+		client.Status().Update(context.TODO(), cr)
+	}()
+
+	// create or update general config resources
+	namespaceScopedSecret, err := ApplySplunkConfig(client, cr, cr.Spec.CommonSplunkSpec, SplunkDeploymentServer)
+	if err != nil {
+		setErrorCondition(cr, &cr.Status.Conditions, enterprisev1.ConditionSecretsSynced, reasonSecretsSyncFailed, err)
+		return result, err
+	}
+	setCondition(cr, &cr.Status.Conditions, enterprisev1.ConditionSecretsSynced, corev1.ConditionTrue, reasonSecretsSynced, "")
+
+	// check if deletion has been requested
+	if cr.ObjectMeta.DeletionTimestamp != nil {
+		err = ApplyMonitoringConsole(client, cr, cr.Spec.CommonSplunkSpec, getDeploymentServerExtraEnv(cr))
+		if err != nil {
+			setErrorCondition(cr, &cr.Status.Conditions, "", reasonMonitoringConsoleFailed, err)
+			return result, err
+		}
+		DeleteOwnerReferencesForResources(client, cr, nil)
+		terminating, err := splctrl.CheckForDeletion(cr, client, eventPublisher)
+		if terminating && err != nil { // don't bother if no error, since it will just be removed immmediately after
+			cr.Status.Phase = splcommon.PhaseTerminating
+			setErrorCondition(cr, &cr.Status.Conditions, "", reasonDeletionFailed, err)
+		} else {
+			result.Requeue = false
+		}
+		return result, err
+	}
+
+	setLicenseCondition(client, cr, &cr.Status.Conditions, cr.Spec.LicenseMasterRef)
+
+	// create or update the serverclass.conf configMap
+	_, err = splctrl.ApplyConfigMap(client, getDeploymentServerServerClassConfigMap(cr))
+	if err != nil {
+		setErrorCondition(cr, &cr.Status.Conditions, "", reasonConfigMapFailed, err)
+		return result, err
+	}
+
+	// create or update a service
+	err = splctrl.ApplyService(client, getSplunkService(cr, &cr.Spec.CommonSplunkSpec, SplunkDeploymentServer, false))
+	if err != nil {
+		setErrorCondition(cr, &cr.Status.Conditions, "", reasonServiceFailed, err)
+		return result, err
+	}
+
+	// create or update statefulset
+	statefulSet, err := getDeploymentServerStatefulSet(client, cr)
+	if err != nil {
+		setErrorCondition(cr, &cr.Status.Conditions, "", reasonStatefulSetFailed, err)
+		return result, err
+	}
+	_, err = applyUpgradeOrder(client, cr, &cr.Status.Conditions, cr.Status.Image, cr.Status.Version, statefulSet, eventPublisher)
+	if err != nil {
+		setErrorCondition(cr, &cr.Status.Conditions, enterprisev1.ConditionImageUpToDate, reasonUpgradeFailed, err)
+		return result, err
+	}
+	err = applySplunkPodDisruptionBudget(client, cr, &cr.Spec.PodDisruptionBudget, getDefaultDisruptionBudget(SplunkDeploymentServer, 1, 0), statefulSet)
+	if err != nil {
+		setErrorCondition(cr, &cr.Status.Conditions, "", reasonDisruptionBudgetFailed, err)
+		return result, err
+	}
+	mgr := splctrl.DefaultStatefulSetPodManager{EventPublisher: eventPublisher}
+	phase, err := mgr.Update(client, statefulSet, 1)
+	if err != nil {
+		setErrorCondition(cr, &cr.Status.Conditions, "", reasonStatefulSetFailed, err)
+		return result, err
+	}
+	cr.Status.Phase = phase
+
+	// no need to requeue if everything is ready
+	if cr.Status.Phase == splcommon.PhaseReady {
+		err = ApplyMonitoringConsole(client, cr, cr.Spec.CommonSplunkSpec, getDeploymentServerExtraEnv(cr))
+		if err != nil {
+			setErrorCondition(cr, &cr.Status.Conditions, "", reasonMonitoringConsoleFailed, err)
+			return result, err
+		}
+
+		dsMgr := deploymentServerPodManager{c: client, cr: cr, secrets: namespaceScopedSecret, newSplunkClient: splclient.NewSplunkClient}
+		err = dsMgr.updateStatus()
+		if err != nil {
+			setErrorCondition(cr, &cr.Status.Conditions, "", reasonDeploymentServerFailed, err)
+			return result, err
+		}
+		err = updateVersion(&cr.Spec.CommonSplunkSpec, cr.Status.Image, &cr.Status.Version, statefulSet, func() *splclient.SplunkClient { return dsMgr.getClient() })
+		if err != nil {
+			setErrorCondition(cr, &cr.Status.Conditions, "", reasonServerInfoFailed, err)
+			return result, err
+		}
+		setRunningImage(cr, &cr.Status.Conditions, &cr.Status.Image, cr.Spec.Image, statefulSet, eventPublisher)
+
+		// keep polling the deployment server for clients phoning home
+		result.RequeueAfter = time.Second * deploymentServerClientsPollInterval
+	}
+	setReconciledCondition(cr, &cr.Status.Conditions)
+	return result, nil
+}
+
+// deploymentServerPodManager is used to query the deployment server pod
+type deploymentServerPodManager struct {
+	c               splcommon.ControllerClient
+	cr              *enterprisev1.DeploymentServer
+	secrets         *corev1.Secret
+	newSplunkClient func(managementURI, username, password string) *splclient.SplunkClient
+}
+
+// updateStatus for deploymentServerPodManager updates the status with the deployment clients that phoned home
+func (mgr *deploymentServerPodManager) updateStatus() error {
+	if mgr.cr.Spec.Mock {
+		return nil
+	}
+
+	clients, err := mgr.getClient().GetDeploymentServerClients()
+	if err != nil {
+		return err
+	}
+
+	mgr.cr.Status.Clients = []enterprisev1.DeploymentClientStatus{}
+	for _, info := range clients {
+		client := enterprisev1.DeploymentClientStatus{
+			Name:              info.ClientName,
+			Hostname:          info.Hostname,
+			IP:                info.IP,
+			LastPhoneHomeTime: info.LastPhoneHomeTime,
+		}
+		for serverClass := range info.ServerClasses {
+			client.ServerClasses = append(client.ServerClasses, serverClass)
+		}
+		sort.Strings(client.ServerClasses)
+		mgr.cr.Status.Clients = append(mgr.cr.Status.Clients, client)
+	}
+	sort.Slice(mgr.cr.Status.Clients, func(i, j int) bool {
+		return mgr.cr.Status.Clients[i].Name < mgr.cr.Status.Clients[j].Name
+	})
+
+	return nil
+}
+
+// getClient for deploymentServerPodManager returns a SplunkClient for the deployment server
+func (mgr *deploymentServerPodManager) getClient() *splclient.SplunkClient {
+	fqdnName := splcommon.GetServiceFQDN(mgr.cr.GetNamespace(), GetSplunkServiceName(SplunkDeploymentServer, mgr.cr.GetName(), false))
+	return mgr.newSplunkClient(fmt.Sprintf("https://%s:8089", fqdnName), "admin", string(mgr.secrets.Data["password"]))
+}
+
+// getDeploymentServerStatefulSet returns a Kubernetes StatefulSet object for a Splunk Enterprise deployment server.
+func getDeploymentServerStatefulSet(client splcommon.ControllerClient, cr *enterprisev1.DeploymentServer) (*appsv1.StatefulSet, error) {
+	ss, err := getSplunkStatefulSet(client, cr, &cr.Spec.CommonSplunkSpec, SplunkDeploymentServer, 1, []corev1.EnvVar{})
+	if err != nil {
+		return nil, err
+	}
+
+	// mount the serverclass.conf configMap, and recycle the pod when it changes
+	configMapName := GetSplunkServerClassConfigMapName(cr.GetName())
+	configMapResourceVersion, err := splctrl.GetConfigMapResourceVersion(client, types.NamespacedName{Namespace: cr.GetNamespace(), Name: configMapName})
+	if err != nil {
+		return nil, err
+	}
+	configMapVolDefaultMode := int32(corev1.ConfigMapVolumeSourceDefaultMode)
+	addSplunkVolumeToTemplate(&ss.Spec.Template, "mnt-splunk-serverclass", "/mnt/splunk-serverclass", corev1.VolumeSource{
+		ConfigMap: &corev1.ConfigMapVolumeSource{
+			LocalObjectReference: corev1.LocalObjectReference{
+				Name: configMapName,
+			},
+			DefaultMode: &configMapVolDefaultMode,
+		},
+	})
+	ss.Spec.Template.ObjectMeta.Annotations[serverClassConfigRev] = configMapResourceVersion
+
+	setupInitContainer(&ss.Spec.Template, cr.Spec.Image, cr.Spec.ImagePullPolicy, commandForDeploymentServerServerClass)
+	if cr.Spec.EtcVolumeStorageConfig.EphemeralStorage {
+		initContainer := &ss.Spec.Template.Spec.InitContainers[len(ss.Spec.Template.Spec.InitContainers)-1]
+		initContainer.VolumeMounts[0].Name = "mnt-splunk-etc"
+	}
+
+	return ss, nil
+}
+
+// getDeploymentServerServerClassConfigMap returns a Kubernetes ConfigMap holding the serverclass.conf of a deployment server.
+func getDeploymentServerServerClassConfigMap(cr *enterprisev1.DeploymentServer) *corev1.ConfigMap {
+	configMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      GetSplunkServerClassConfigMapName(cr.GetName()),
+			Namespace: cr.GetNamespace(),
+		},
+		Data: map[string]string{
+			"serverclass.conf": getServerClassConf(cr.Spec.ServerClasses),
+		},
+	}
+	configMap.SetOwnerReferences(append(configMap.GetOwnerReferences(), splcommon.AsOwner(cr, true)))
+	return configMap
+}
+
+// getServerClassConf returns the serverclass.conf for a list of server classes in INI format
+func getServerClassConf(serverClasses []enterprisev1.ServerClassSpec) string {
+	var sb strings.Builder
+	for _, serverClass := range serverClasses {
+		fmt.Fprintf(&sb, "[serverClass:%s]\n", serverClass.Name)
+		for i, client := range serverClass.Whitelist {
+			fmt.Fprintf(&sb, "whitelist.%d = %s\n", i, client)
+		}
+		for i, client := range serverClass.Blacklist {
+			fmt.Fprintf(&sb, "blacklist.%d = %s\n", i, client)
+		}
+		if serverClass.RestartSplunkd {
+			sb.WriteString("restartSplunkd = true\n")
+		}
+		sb.WriteString("\n")
+
+		for _, app := range serverClass.Apps {
+			fmt.Fprintf(&sb, "[serverClass:%s:app:%s]\n\n", serverClass.Name, app)
+		}
+	}
+	return sb.String()
+}
+
+// validateDeploymentServerSpec checks validity and makes default updates to a DeploymentServerSpec, and returns error if something is wrong.
+func validateDeploymentServerSpec(spec *enterprisev1.DeploymentServerSpec) error {
+	serverClassNames := make(map[string]bool)
+	for _, serverClass := range spec.ServerClasses {
+		if serverClass.Name == "" {
+			return fmt.Errorf("Server class name is required")
+		}
+		if strings.Contains(serverClass.Name, ":") {
+			return fmt.Errorf("Invalid server class name %s", serverClass.Name)
+		}
+		if serverClassNames[serverClass.Name] {
+			return fmt.Errorf("Duplicate server class %s", serverClass.Name)
+		}
+		serverClassNames[serverClass.Name] = true
+
+		for _, app := range serverClass.Apps {
+			if app == "" {
+				return fmt.Errorf("Empty app name in server class %s", serverClass.Name)
+			}
+		}
+	}
+
+	return validateCommonSplunkSpec(&spec.CommonSplunkSpec)
+}
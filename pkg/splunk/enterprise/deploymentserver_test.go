@@ -0,0 +1,235 @@
+// Copyright (c) 2018-2021 Splunk Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package enterprise
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	enterprisev1 "github.com/splunk/splunk-operator/pkg/apis/enterprise/v1"
+	splclient "github.com/splunk/splunk-operator/pkg/splunk/client"
+	splcommon "github.com/splunk/splunk-operator/pkg/splunk/common"
+	splctrl "github.com/splunk/splunk-operator/pkg/splunk/controller"
+	spltest "github.com/splunk/splunk-operator/pkg/splunk/test"
+	splutil "github.com/splunk/splunk-operator/pkg/splunk/util"
+)
+
+func TestApplyDeploymentServer(t *testing.T) {
+	funcCalls := []spltest.MockFuncCall{
+		{MetaName: "*v1.Secret-test-splunk-test-secret"},
+		{MetaName: "*v1.Secret-test-splunk-test-secret"},
+		{MetaName: "*v1.ConfigMap-test-splunk-stack1-deployment-server-serverclass"},
+		{MetaName: "*v1.Service-test-splunk-stack1-deployment-server-service"},
+		{MetaName: "*v1.Secret-test-splunk-test-secret"},
+		{MetaName: "*v1.Secret-test-splunk-stack1-deployment-server-secret-v1"},
+		{MetaName: "*v1.ConfigMap-test-splunk-stack1-deployment-server-serverclass"},
+		{MetaName: "*v1beta1.PodDisruptionBudget-test-splunk-stack1-deployment-server"},
+		{MetaName: "*v1.StatefulSet-test-splunk-stack1-deployment-server"},
+	}
+	labels := map[string]string{
+		"app.kubernetes.io/component":  "versionedSecrets",
+		"app.kubernetes.io/managed-by": "splunk-operator",
+	}
+	listOpts := []client.ListOption{
+		client.InNamespace("test"),
+		client.MatchingLabels(labels),
+	}
+	listmockCall := []spltest.MockFuncCall{
+		{ListOpts: listOpts}}
+
+	createCalls := map[string][]spltest.MockFuncCall{"Get": funcCalls, "Create": {funcCalls[0], funcCalls[2], funcCalls[3], funcCalls[5], funcCalls[8]}, "Update": {funcCalls[0]}, "List": {listmockCall[0]}}
+	updateCalls := map[string][]spltest.MockFuncCall{"Get": funcCalls, "Update": {funcCalls[2], funcCalls[8]}, "List": {listmockCall[0]}}
+	current := enterprisev1.DeploymentServer{
+		TypeMeta: metav1.TypeMeta{
+			Kind: "DeploymentServer",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "stack1",
+			Namespace: "test",
+		},
+	}
+	revised := current.DeepCopy()
+	revised.Spec.Image = "splunk/test"
+	revised.Spec.ServerClasses = []enterprisev1.ServerClassSpec{{Name: "linux", Whitelist: []string{"*"}, Apps: []string{"Splunk_TA_nix"}}}
+	reconcile := func(c *spltest.MockClient, cr interface{}) error {
+		_, err := ApplyDeploymentServer(c, nil, cr.(*enterprisev1.DeploymentServer))
+		return err
+	}
+	spltest.ReconcileTesterWithoutRedundantCheck(t, "TestApplyDeploymentServer", &current, revised, createCalls, updateCalls, reconcile, true)
+
+	// test deletion
+	currentTime := metav1.NewTime(time.Now())
+	revised.ObjectMeta.DeletionTimestamp = &currentTime
+	revised.ObjectMeta.Finalizers = []string{"enterprise.splunk.com/delete-pvc"}
+	deleteFunc := func(cr splcommon.MetaObject, c splcommon.ControllerClient) (bool, error) {
+		_, err := ApplyDeploymentServer(c, nil, cr.(*enterprisev1.DeploymentServer))
+		return true, err
+	}
+	splunkDeletionTester(t, revised, deleteFunc)
+}
+
+func TestGetDeploymentServerStatefulSet(t *testing.T) {
+	cr := enterprisev1.DeploymentServer{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "stack1",
+			Namespace: "test",
+		},
+	}
+
+	c := spltest.NewMockClient()
+	_, err := splutil.ApplyNamespaceScopedSecretObject(c, "test")
+	if err != nil {
+		t.Errorf("Failed to create namespace scoped object")
+	}
+
+	test := func(want string) {
+		f := func() (interface{}, error) {
+			if err := validateDeploymentServerSpec(&cr.Spec); err != nil {
+				t.Errorf("validateDeploymentServerSpec() returned error: %v", err)
+			}
+			return getDeploymentServerStatefulSet(c, &cr)
+		}
+		configTester(t, "getDeploymentServerStatefulSet()", f, want)
+	}
+
+	// the serverclass.conf configMap must exist before the statefulset is created
+	_, err = getDeploymentServerStatefulSet(c, &cr)
+	if err == nil {
+		t.Errorf("getDeploymentServerStatefulSet() returned nil; want error")
+	}
+
+	_, err = splctrl.ApplyConfigMap(c, getDeploymentServerServerClassConfigMap(&cr))
+	if err != nil {
+		t.Errorf("Failed to create serverclass configMap: %v", err)
+	}
+	test(`{"kind":"StatefulSet","apiVersion":"apps/v1","metadata":{"name":"splunk-stack1-deployment-server","namespace":"test","creationTimestamp":null,"ownerReferences":[{"apiVersion":"","kind":"","name":"stack1","uid":"","controller":true}]},"spec":{"replicas":1,"selector":{"matchLabels":{"app.kubernetes.io/component":"deployment-server","app.kubernetes.io/instance":"splunk-stack1-deployment-server","app.kubernetes.io/managed-by":"splunk-operator","app.kubernetes.io/name":"deployment-server","app.kubernetes.io/part-of":"splunk-stack1-deployment-server"}},"template":{"metadata":{"creationTimestamp":null,"labels":{"app.kubernetes.io/component":"deployment-server","app.kubernetes.io/instance":"splunk-stack1-deployment-server","app.kubernetes.io/managed-by":"splunk-operator","app.kubernetes.io/name":"deployment-server","app.kubernetes.io/part-of":"splunk-stack1-deployment-server"},"annotations":{"ServerClassConfigRev":"","traffic.sidecar.istio.io/excludeOutboundPorts":"8089,8191,9997","traffic.sidecar.istio.io/includeInboundPorts":"8000"}},"spec":{"volumes":[{"name":"mnt-splunk-secrets","secret":{"secretName":"splunk-stack1-deployment-server-secret-v1","defaultMode":420}},{"name":"mnt-splunk-serverclass","configMap":{"name":"splunk-stack1-deployment-server-serverclass","defaultMode":420}}],"initContainers":[{"name":"init","image":"splunk/splunk","command":["bash","-c","mkdir -p /opt/splk/etc/apps/splunk-operator/local \u0026\u0026 ln -sfn  /mnt/splunk-serverclass/serverclass.conf /opt/splk/etc/apps/splunk-operator/local/serverclass.conf"],"resources":{"limits":{"cpu":"1","memory":"512Mi"},"requests":{"cpu":"250m","memory":"128Mi"}},"volumeMounts":[{"name":"pvc-etc","mountPath":"/opt/splk/etc"}],"imagePullPolicy":"IfNotPresent"}],"containers":[{"name":"splunk","image":"splunk/splunk","ports":[{"name":"http-splunkweb","containerPort":8000,"protocol":"TCP"},{"name":"https-splunkd","containerPort":8089,"protocol":"TCP"}],"env":[{"name":"SPLUNK_HOME","value":"/opt/splunk"},{"name":"SPLUNK_START_ARGS","value":"--accept-license"},{"name":"SPLUNK_DEFAULTS_URL","value":"/mnt/splunk-secrets/default.yml"},{"name":"SPLUNK_HOME_OWNERSHIP_ENFORCEMENT","value":"false"},{"name":"SPLUNK_ROLE","value":"splunk_deployment_server"},{"name":"SPLUNK_DECLARATIVE_ADMIN_PASSWORD","value":"true"}],"resources":{"limits":{"cpu":"4","memory":"8Gi"},"requests":{"cpu":"100m","memory":"512Mi"}},"volumeMounts":[{"name":"pvc-etc","mountPath":"/opt/splunk/etc"},{"name":"pvc-var","mountPath":"/opt/splunk/var"},{"name":"mnt-splunk-secrets","mountPath":"/mnt/splunk-secrets"},{"name":"mnt-splunk-serverclass","mountPath":"/mnt/splunk-serverclass"}],"livenessProbe":{"exec":{"command":["/sbin/checkstate.sh"]},"initialDelaySeconds":300,"timeoutSeconds":30,"periodSeconds":30},"readinessProbe":{"exec":{"command":["/bin/grep","started","/opt/container_artifact/splunk-container.state"]},"initialDelaySeconds":10,"timeoutSeconds":5,"periodSeconds":5},"imagePullPolicy":"IfNotPresent"}],"securityContext":{"runAsUser":41812,"fsGroup":41812},"affinity":{"podAntiAffinity":{"preferredDuringSchedulingIgnoredDuringExecution":[{"weight":100,"podAffinityTerm":{"labelSelector":{"matchExpressions":[{"key":"app.kubernetes.io/instance","operator":"In","values":["splunk-stack1-deployment-server"]}]},"topologyKey":"kubernetes.io/hostname"}}]}},"schedulerName":"default-scheduler"}},"volumeClaimTemplates":[{"metadata":{"name":"pvc-etc","namespace":"test","creationTimestamp":null,"labels":{"app.kubernetes.io/component":"deployment-server","app.kubernetes.io/instance":"splunk-stack1-deployment-server","app.kubernetes.io/managed-by":"splunk-operator","app.kubernetes.io/name":"deployment-server","app.kubernetes.io/part-of":"splunk-stack1-deployment-server"}},"spec":{"accessModes":["ReadWriteOnce"],"resources":{"requests":{"storage":"10Gi"}}},"status":{}},{"metadata":{"name":"pvc-var","namespace":"test","creationTimestamp":null,"labels":{"app.kubernetes.io/component":"deployment-server","app.kubernetes.io/instance":"splunk-stack1-deployment-server","app.kubernetes.io/managed-by":"splunk-operator","app.kubernetes.io/name":"deployment-server","app.kubernetes.io/part-of":"splunk-stack1-deployment-server"}},"spec":{"accessModes":["ReadWriteOnce"],"resources":{"requests":{"storage":"100Gi"}}},"status":{}}],"serviceName":"splunk-stack1-deployment-server-headless","podManagementPolicy":"Parallel","updateStrategy":{"type":"OnDelete"}},"status":{"replicas":0}}`)
+}
+
+func TestGetServerClassConf(t *testing.T) {
+	serverClasses := []enterprisev1.ServerClassSpec{
+		{
+			Name:           "linux",
+			Whitelist:      []string{"uf-*", "10.0.1.*"},
+			Blacklist:      []string{"uf-test"},
+			Apps:           []string{"Splunk_TA_nix", "outputs"},
+			RestartSplunkd: true,
+		},
+		{
+			Name:      "windows",
+			Whitelist: []string{"win-*"},
+		},
+	}
+	want := `[serverClass:linux]
+whitelist.0 = uf-*
+whitelist.1 = 10.0.1.*
+blacklist.0 = uf-test
+restartSplunkd = true
+
+[serverClass:linux:app:Splunk_TA_nix]
+
+[serverClass:linux:app:outputs]
+
+[serverClass:windows]
+whitelist.0 = win-*
+
+`
+	got := getServerClassConf(serverClasses)
+	if got != want {
+		t.Errorf("getServerClassConf() = %s; want %s", got, want)
+	}
+
+	if got = getServerClassConf(nil); got != "" {
+		t.Errorf("getServerClassConf(nil) = %s; want empty", got)
+	}
+}
+
+func TestDeploymentServerUpdateStatus(t *testing.T) {
+	cr := enterprisev1.DeploymentServer{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "stack1",
+			Namespace: "test",
+		},
+	}
+	secrets := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "stack1-secrets",
+			Namespace: "test",
+		},
+		Data: map[string][]byte{
+			"password": {'1', '2', '3'},
+		},
+	}
+	mockHandlers := []spltest.MockHTTPHandler{
+		{
+			Method: "GET",
+			URL:    "https://splunk-stack1-deployment-server-service.test.svc.cluster.local:8089/services/deployment/server/clients?count=0&output_mode=json",
+			Status: 200,
+			Err:    nil,
+			Body:   `{"entry":[{"name":"b2","content":{"clientName":"uf-2","hostname":"uf-2.example.com","ip":"10.0.0.12","lastPhoneHomeTime":1624300370,"serverClasses":{}}},{"name":"a1","content":{"clientName":"uf-1","hostname":"uf-1.example.com","ip":"10.0.0.11","lastPhoneHomeTime":1624300362,"serverClasses":{"windows":{"stateOnClient":"enabled"},"linux":{"stateOnClient":"enabled"}}}}]}`,
+		},
+	}
+	mockSplunkClient := &spltest.MockHTTPClient{}
+	mockSplunkClient.AddHandlers(mockHandlers...)
+	mgr := &deploymentServerPodManager{
+		cr:      &cr,
+		secrets: secrets,
+		newSplunkClient: func(managementURI, username, password string) *splclient.SplunkClient {
+			c := splclient.NewSplunkClient(managementURI, username, password)
+			c.Client = mockSplunkClient
+			return c
+		},
+	}
+	err := mgr.updateStatus()
+	if err != nil {
+		t.Errorf("updateStatus() returned error: %v", err)
+	}
+	mockSplunkClient.CheckRequests(t, "TestDeploymentServerUpdateStatus")
+
+	want := []enterprisev1.DeploymentClientStatus{
+		{Name: "uf-1", Hostname: "uf-1.example.com", IP: "10.0.0.11", LastPhoneHomeTime: 1624300362, ServerClasses: []string{"linux", "windows"}},
+		{Name: "uf-2", Hostname: "uf-2.example.com", IP: "10.0.0.12", LastPhoneHomeTime: 1624300370},
+	}
+	if !reflect.DeepEqual(cr.Status.Clients, want) {
+		t.Errorf("updateStatus() clients=%v; want %v", cr.Status.Clients, want)
+	}
+}
+
+func TestValidateDeploymentServerSpec(t *testing.T) {
+	test := func(spec enterprisev1.DeploymentServerSpec, wantErr bool) {
+		err := validateDeploymentServerSpec(&spec)
+		if (err != nil) != wantErr {
+			t.Errorf("validateDeploymentServerSpec(%v) returned %v; want error %t", spec, err, wantErr)
+		}
+	}
+
+	spec := enterprisev1.DeploymentServerSpec{}
+	test(spec, false)
+	spec.ServerClasses = []enterprisev1.ServerClassSpec{{Name: "linux", Apps: []string{"Splunk_TA_nix"}}}
+	test(spec, false)
+	spec.ServerClasses = append(spec.ServerClasses, enterprisev1.ServerClassSpec{Name: "linux"})
+	test(spec, true)
+	spec.ServerClasses[1].Name = ""
+	test(spec, true)
+	spec.ServerClasses[1].Name = "linux:app"
+	test(spec, true)
+	spec.ServerClasses[1].Name = "windows"
+	spec.ServerClasses[1].Apps = []string{""}
+	test(spec, true)
+}
@@ -49,6 +49,12 @@ func DeleteSplunkPvc(cr splcommon.MetaObject, c splcommon.ControllerClient) erro
 		components = append(components, "indexer")
 	case "ClusterMaster":
 		components = append(components, "cluster-master")
+	case "HeavyForwarder":
+		components = append(components, "heavy-forwarder")
+	case "DeploymentServer":
+		components = append(components, "deployment-server")
+	case "MonitoringConsole":
+		components = append(components, "monitoring-console")
 	default:
 		scopedLog.Info("Skipping PVC removal")
 		return nil
@@ -44,6 +44,12 @@ func splunkDeletionTester(t *testing.T, cr splcommon.MetaObject, delete func(spl
 		component = "indexer"
 	case "ClusterMaster":
 		component = "cluster-master"
+	case "HeavyForwarder":
+		component = "heavy-forwarder"
+	case "DeploymentServer":
+		component = "deployment-server"
+	case "MonitoringConsole":
+		component = "monitoring-console"
 	}
 
 	labelsA := map[string]string{
@@ -80,7 +86,30 @@ func splunkDeletionTester(t *testing.T, cr splcommon.MetaObject, delete func(spl
 		mockCalls["Update"] = []spltest.MockFuncCall{
 			{MetaName: fmt.Sprintf("*%s.%s-%s-%s", apiVersion.Version, cr.GetObjectKind().GroupVersionKind().Kind, cr.GetNamespace(), cr.GetName())},
 		}
-		if cr.GetObjectKind().GroupVersionKind().Kind != "IndexerCluster" {
+		if cr.GetObjectKind().GroupVersionKind().Kind == "MonitoringConsole" {
+			// a MonitoringConsole does not register with the monitoring console of the namespace
+			mockCalls["Update"] = []spltest.MockFuncCall{
+				{MetaName: "*v1.Secret-test-splunk-test-secret"},
+				{MetaName: "*v1.Secret-test-splunk-test-secret"},
+				{MetaName: fmt.Sprintf("*%s.%s-%s-%s", apiVersion.Version, cr.GetObjectKind().GroupVersionKind().Kind, cr.GetNamespace(), cr.GetName())},
+			}
+			mockCalls["Delete"] = []spltest.MockFuncCall{
+				{MetaName: "*v1.PersistentVolumeClaim-test-splunk-pvc-stack1-var"},
+			}
+			mockCalls["List"] = []spltest.MockFuncCall{
+				{ListOpts: listOptsB},
+			}
+			mockCalls["Create"] = []spltest.MockFuncCall{
+				{MetaName: "*v1.StatefulSet-test-splunk-test-monitoring-console"},
+				{MetaName: "*v1.Secret-test-splunk-test-secret"},
+			}
+			mockCalls["Get"] = []spltest.MockFuncCall{
+				{MetaName: "*v1.StatefulSet-test-splunk-test-monitoring-console"},
+				{MetaName: "*v1.Secret-test-splunk-test-secret"},
+				{MetaName: "*v1.Secret-test-splunk-test-secret"},
+				{MetaName: "*v1.Secret-test-splunk-test-secret"},
+			}
+		} else if cr.GetObjectKind().GroupVersionKind().Kind != "IndexerCluster" {
 			mockCalls["Update"] = []spltest.MockFuncCall{
 				{MetaName: "*v1.Secret-test-splunk-test-secret"},
 				{MetaName: "*v1.StatefulSet-test-splunk-test-monitoring-console"},
@@ -188,6 +217,12 @@ func splunkPVCDeletionTester(t *testing.T, cr splcommon.MetaObject, delete func(
 		component = "indexer"
 	case "ClusterMaster":
 		component = "cluster-master"
+	case "HeavyForwarder":
+		component = "heavy-forwarder"
+	case "DeploymentServer":
+		component = "deployment-server"
+	case "MonitoringConsole":
+		component = "monitoring-console"
 	}
 
 	labels := map[string]string{
@@ -232,6 +267,11 @@ func splunkPVCDeletionTester(t *testing.T, cr splcommon.MetaObject, delete func(
 	}
 	c.CheckCalls(t, "Testsplctrl.CheckForDeletion", mockCalls)
 }
+// checkForDeletionWithoutEvents calls splctrl.CheckForDeletion without recording events
+func checkForDeletionWithoutEvents(cr splcommon.MetaObject, c splcommon.ControllerClient) (bool, error) {
+	return splctrl.CheckForDeletion(cr, c, nil)
+}
+
 func TestDeleteSplunkPvc(t *testing.T) {
 	cr := enterprisev1.IndexerCluster{
 		TypeMeta: metav1.TypeMeta{
@@ -242,18 +282,18 @@ func TestDeleteSplunkPvc(t *testing.T) {
 			Namespace: "test",
 		},
 	}
-	splunkPVCDeletionTester(t, &cr, splctrl.CheckForDeletion)
+	splunkPVCDeletionTester(t, &cr, checkForDeletionWithoutEvents)
 
 	now := time.Now().Add(time.Second * 100)
 	currentTime := metav1.NewTime(now)
 	cr.ObjectMeta.DeletionTimestamp = &currentTime
 	cr.ObjectMeta.Finalizers = []string{"enterprise.splunk.com/delete-pvc"}
-	splunkPVCDeletionTester(t, &cr, splctrl.CheckForDeletion)
+	splunkPVCDeletionTester(t, &cr, checkForDeletionWithoutEvents)
 
 	// try with unrecognized finalizer
 	c := spltest.NewMockClient()
 	cr.ObjectMeta.Finalizers = append(cr.ObjectMeta.Finalizers, "bad-finalizer")
-	deleted, err := splctrl.CheckForDeletion(&cr, c)
+	deleted, err := splctrl.CheckForDeletion(&cr, c, nil)
 	if deleted != false || err == nil {
 		t.Errorf("splctrl.CheckForDeletion() returned %t, %v; want false, (error)", deleted, err)
 	}
@@ -269,18 +309,18 @@ func TestDeleteSplunkClusterMasterPvc(t *testing.T) {
 			Namespace: "test",
 		},
 	}
-	splunkPVCDeletionTester(t, &cr, splctrl.CheckForDeletion)
+	splunkPVCDeletionTester(t, &cr, checkForDeletionWithoutEvents)
 
 	now := time.Now().Add(time.Second * 100)
 	currentTime := metav1.NewTime(now)
 	cr.ObjectMeta.DeletionTimestamp = &currentTime
 	cr.ObjectMeta.Finalizers = []string{"enterprise.splunk.com/delete-pvc"}
-	splunkPVCDeletionTester(t, &cr, splctrl.CheckForDeletion)
+	splunkPVCDeletionTester(t, &cr, checkForDeletionWithoutEvents)
 
 	// try with unrecognized finalizer
 	c := spltest.NewMockClient()
 	cr.ObjectMeta.Finalizers = append(cr.ObjectMeta.Finalizers, "bad-finalizer")
-	deleted, err := splctrl.CheckForDeletion(&cr, c)
+	deleted, err := splctrl.CheckForDeletion(&cr, c, nil)
 	if deleted != false || err == nil {
 		t.Errorf("splctrl.CheckForDeletion() returned %t, %v; want false, (error)", deleted, err)
 	}
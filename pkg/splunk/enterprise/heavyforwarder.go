@@ -0,0 +1,235 @@
+// Copyright (c) 2018-2021 Splunk Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package enterprise
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	enterprisev1 "github.com/splunk/splunk-operator/pkg/apis/enterprise/v1"
+	splclient "github.com/splunk/splunk-operator/pkg/splunk/client"
+	splcommon "github.com/splunk/splunk-operator/pkg/splunk/common"
+	splctrl "github.com/splunk/splunk-operator/pkg/splunk/controller"
+)
+
+// ApplyHeavyForwarder reconciles the StatefulSet for N heavy forwarder instances of Splunk Enterprise.
+func ApplyHeavyForwarder(client splcommon.ControllerClient, recorder record.EventRecorder, cr *enterprisev1.HeavyForwarder) (reconcile.Result, error) {
+
+	// unless modified, reconcile for this object will be requeued after 5 seconds
+	result := reconcile.Result{
+		Requeue:      true,
+		RequeueAfter: time.Second * 5,
+	}
+
+	eventPublisher := splcommon.NewEventPublisher(recorder, cr)
+
+	// validate and updates defaults for CR
+	err := validateHeavyForwarderSpec(cr)
+	if err != nil {
+		cr.Status.Phase = splcommon.PhaseError
+		setErrorCondition(cr, &cr.Status.Conditions, "", reasonInvalidSpec, err)
+		cr.Status.ObservedGeneration = cr.GetGeneration()
+		client.Status().Update(context.TODO(), cr)
+		return result, err
+	}
+
+	// updates status after function completes
+	cr.Status.Phase = splcommon.PhaseError
+	cr.Status.Replicas = cr.Spec.Replicas
+	cr.Status.Selector = fmt.Sprintf("app.kubernetes.io/instance=splunk-%s-heavy-forwarder", cr.GetName())
+	defer func() {
+		setPhaseConditions(cr, &cr.Status.Conditions, cr.Status.Phase)
+		cr.Status.ObservedGeneration = cr.GetGeneration()
+		client.Status().Update(context.TODO(), cr)
+	}()
+
+	// create or update general config resources
+	namespaceScopedSecret, err := ApplySplunkConfig(client, cr, cr.Spec.CommonSplunkSpec, SplunkHeavyForwarder)
+	if err != nil {
+		setErrorCondition(cr, &cr.Status.Conditions, enterprisev1.ConditionSecretsSynced, reasonSecretsSyncFailed, err)
+		return result, err
+	}
+	setCondition(cr, &cr.Status.Conditions, enterprisev1.ConditionSecretsSynced, corev1.ConditionTrue, reasonSecretsSynced, "")
+
+	// check if deletion has been requested
+	if cr.ObjectMeta.DeletionTimestamp != nil {
+		//update monitoring console configMap after custom resource deletion is requested
+		err = ApplyMonitoringConsole(client, cr, cr.Spec.CommonSplunkSpec, getHeavyForwarderExtraEnv(cr, cr.Spec.Replicas))
+		if err != nil {
+			setErrorCondition(cr, &cr.Status.Conditions, "", reasonMonitoringConsoleFailed, err)
+			return result, err
+		}
+
+		DeleteOwnerReferencesForResources(client, cr, nil)
+		terminating, err := splctrl.CheckForDeletion(cr, client, eventPublisher)
+		if terminating && err != nil { // don't bother if no error, since it will just be removed immmediately after
+			cr.Status.Phase = splcommon.PhaseTerminating
+			setErrorCondition(cr, &cr.Status.Conditions, "", reasonDeletionFailed, err)
+		} else {
+			result.Requeue = false
+		}
+		return result, err
+	}
+
+	setLicenseCondition(client, cr, &cr.Status.Conditions, cr.Spec.LicenseMasterRef)
+
+	// create or update a headless service
+	err = splctrl.ApplyService(client, getSplunkService(cr, &cr.Spec.CommonSplunkSpec, SplunkHeavyForwarder, true))
+	if err != nil {
+		setErrorCondition(cr, &cr.Status.Conditions, "", reasonServiceFailed, err)
+		return result, err
+	}
+
+	// create or update a regular service
+	err = splctrl.ApplyService(client, getSplunkService(cr, &cr.Spec.CommonSplunkSpec, SplunkHeavyForwarder, false))
+	if err != nil {
+		setErrorCondition(cr, &cr.Status.Conditions, "", reasonServiceFailed, err)
+		return result, err
+	}
+
+	// create or update statefulset
+	statefulSet, err := getHeavyForwarderStatefulSet(client, cr)
+	if err != nil {
+		setErrorCondition(cr, &cr.Status.Conditions, "", reasonStatefulSetFailed, err)
+		return result, err
+	}
+
+	_, err = applyUpgradeOrder(client, cr, &cr.Status.Conditions, cr.Status.Image, cr.Status.Version, statefulSet, eventPublisher)
+	if err != nil {
+		setErrorCondition(cr, &cr.Status.Conditions, enterprisev1.ConditionImageUpToDate, reasonUpgradeFailed, err)
+		return result, err
+	}
+	err = applySplunkPodDisruptionBudget(client, cr, &cr.Spec.PodDisruptionBudget, getDefaultDisruptionBudget(SplunkHeavyForwarder, cr.Spec.Replicas, 0), statefulSet)
+	if err != nil {
+		setErrorCondition(cr, &cr.Status.Conditions, "", reasonDisruptionBudgetFailed, err)
+		return result, err
+	}
+	mgr := splctrl.DefaultStatefulSetPodManager{EventPublisher: eventPublisher}
+	phase, err := mgr.Update(client, statefulSet, cr.Spec.Replicas)
+	cr.Status.ReadyReplicas = statefulSet.Status.ReadyReplicas
+	if err != nil {
+		setErrorCondition(cr, &cr.Status.Conditions, "", reasonStatefulSetFailed, err)
+		return result, err
+	}
+	cr.Status.Phase = phase
+
+	// no need to requeue if everything is ready
+	if cr.Status.Phase == splcommon.PhaseReady {
+		err = ApplyMonitoringConsole(client, cr, cr.Spec.CommonSplunkSpec, getHeavyForwarderExtraEnv(cr, cr.Spec.Replicas))
+		if err != nil {
+			setErrorCondition(cr, &cr.Status.Conditions, "", reasonMonitoringConsoleFailed, err)
+			return result, err
+		}
+		err = updateVersion(&cr.Spec.CommonSplunkSpec, cr.Status.Image, &cr.Status.Version, statefulSet, func() *splclient.SplunkClient {
+			return getServiceClient(cr, SplunkHeavyForwarder, namespaceScopedSecret)
+		})
+		if err != nil {
+			setErrorCondition(cr, &cr.Status.Conditions, "", reasonServerInfoFailed, err)
+			return result, err
+		}
+		setRunningImage(cr, &cr.Status.Conditions, &cr.Status.Image, cr.Spec.Image, statefulSet, eventPublisher)
+		result.Requeue = false
+	}
+	setReconciledCondition(cr, &cr.Status.Conditions)
+	return result, nil
+}
+
+// getHeavyForwarderStatefulSet returns a Kubernetes StatefulSet object for Splunk Enterprise heavy forwarders.
+func getHeavyForwarderStatefulSet(client splcommon.ControllerClient, cr *enterprisev1.HeavyForwarder) (*appsv1.StatefulSet, error) {
+	// get generic statefulset for Splunk Enterprise objects
+	ss, err := getSplunkStatefulSet(client, cr, &cr.Spec.CommonSplunkSpec, SplunkHeavyForwarder, cr.Spec.Replicas, []corev1.EnvVar{})
+	if err != nil {
+		return nil, err
+	}
+
+	// write outputs.conf before splunkd starts; the indexer discovery key is read from the mounted secrets
+	setupInitContainer(&ss.Spec.Template, cr.Spec.Image, cr.Spec.ImagePullPolicy,
+		fmt.Sprintf(commandForHeavyForwarderOutputs, getHeavyForwarderOutputsConf(cr)))
+	initContainer := &ss.Spec.Template.Spec.InitContainers[len(ss.Spec.Template.Spec.InitContainers)-1]
+	if cr.Spec.EtcVolumeStorageConfig.EphemeralStorage {
+		initContainer.VolumeMounts[0].Name = "mnt-splunk-etc"
+	}
+	initContainer.VolumeMounts = append(initContainer.VolumeMounts, corev1.VolumeMount{Name: "mnt-splunk-secrets", MountPath: "/mnt/splunk-secrets"})
+
+	return ss, nil
+}
+
+// getHeavyForwarderOutputsConf returns the outputs.conf used by heavy forwarders to send data to the indexers,
+// either through indexer discovery on the cluster master, or to the list of indexer targets.
+func getHeavyForwarderOutputsConf(cr *enterprisev1.HeavyForwarder) string {
+	if cr.Spec.ClusterMasterRef.Name != "" {
+		clusterMasterURL := GetSplunkServiceName(SplunkClusterMaster, cr.Spec.ClusterMasterRef.Name, false)
+		return fmt.Sprintf(`[indexer_discovery:%s]
+pass4SymmKey = $(cat /mnt/splunk-secrets/%s)
+master_uri = https://%s:8089
+
+[tcpout:%s]
+indexerDiscovery = %s
+useACK = true
+
+[tcpout]
+defaultGroup = %s
+`, cr.Spec.ClusterMasterRef.Name, splcommon.IdxcSecret, clusterMasterURL,
+			cr.Spec.ClusterMasterRef.Name, cr.Spec.ClusterMasterRef.Name, cr.Spec.ClusterMasterRef.Name)
+	}
+
+	var servers []string
+	for _, target := range cr.Spec.IndexerTargets {
+		if !strings.Contains(target, ":") {
+			target = fmt.Sprintf("%s:9997", target)
+		}
+		servers = append(servers, target)
+	}
+	return fmt.Sprintf(`[tcpout:indexers]
+server = %s
+useACK = true
+
+[tcpout]
+defaultGroup = indexers
+`, strings.Join(servers, ","))
+}
+
+// validateHeavyForwarderSpec checks validity and makes default updates to a HeavyForwarderSpec, and returns error if something is wrong.
+func validateHeavyForwarderSpec(cr *enterprisev1.HeavyForwarder) error {
+	spec := &cr.Spec
+	if spec.Replicas == 0 {
+		spec.Replicas = 1
+	}
+
+	if spec.ClusterMasterRef.Name == "" && len(spec.IndexerTargets) == 0 {
+		return fmt.Errorf("Either clusterMasterRef or indexerTargets is required for a heavy forwarder")
+	}
+	if spec.ClusterMasterRef.Name != "" && len(spec.IndexerTargets) > 0 {
+		return fmt.Errorf("clusterMasterRef and indexerTargets can not be used together for a heavy forwarder")
+	}
+	// the indexer discovery key is read from the idxc_secret of the namespace of the heavy forwarder
+	if spec.ClusterMasterRef.Namespace != "" && spec.ClusterMasterRef.Namespace != cr.GetNamespace() {
+		return fmt.Errorf("clusterMasterRef of a heavy forwarder can not refer to a ClusterMaster in namespace %s, outside of namespace %s", spec.ClusterMasterRef.Namespace, cr.GetNamespace())
+	}
+	for _, target := range spec.IndexerTargets {
+		if target == "" {
+			return fmt.Errorf("Empty indexer target for a heavy forwarder")
+		}
+	}
+
+	return validateCommonSplunkSpec(&spec.CommonSplunkSpec)
+}
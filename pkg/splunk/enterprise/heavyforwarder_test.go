@@ -0,0 +1,180 @@
+// Copyright (c) 2018-2021 Splunk Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package enterprise
+
+import (
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	enterprisev1 "github.com/splunk/splunk-operator/pkg/apis/enterprise/v1"
+	splcommon "github.com/splunk/splunk-operator/pkg/splunk/common"
+	spltest "github.com/splunk/splunk-operator/pkg/splunk/test"
+	splutil "github.com/splunk/splunk-operator/pkg/splunk/util"
+)
+
+func TestApplyHeavyForwarder(t *testing.T) {
+	funcCalls := []spltest.MockFuncCall{
+		{MetaName: "*v1.Secret-test-splunk-test-secret"},
+		{MetaName: "*v1.Secret-test-splunk-test-secret"},
+		{MetaName: "*v1.Service-test-splunk-stack1-heavy-forwarder-headless"},
+		{MetaName: "*v1.Service-test-splunk-stack1-heavy-forwarder-service"},
+		{MetaName: "*v1.Secret-test-splunk-test-secret"},
+		{MetaName: "*v1.Secret-test-splunk-stack1-heavy-forwarder-secret-v1"},
+		{MetaName: "*v1beta1.PodDisruptionBudget-test-splunk-stack1-heavy-forwarder"},
+		{MetaName: "*v1.StatefulSet-test-splunk-stack1-heavy-forwarder"},
+	}
+	labels := map[string]string{
+		"app.kubernetes.io/component":  "versionedSecrets",
+		"app.kubernetes.io/managed-by": "splunk-operator",
+	}
+	listOpts := []client.ListOption{
+		client.InNamespace("test"),
+		client.MatchingLabels(labels),
+	}
+	listmockCall := []spltest.MockFuncCall{
+		{ListOpts: listOpts}}
+
+	createCalls := map[string][]spltest.MockFuncCall{"Get": funcCalls, "Create": {funcCalls[0], funcCalls[2], funcCalls[3], funcCalls[5], funcCalls[7]}, "Update": {funcCalls[0]}, "List": {listmockCall[0]}}
+	updateCalls := map[string][]spltest.MockFuncCall{"Get": funcCalls, "Update": {funcCalls[7]}, "List": {listmockCall[0]}}
+	current := enterprisev1.HeavyForwarder{
+		TypeMeta: metav1.TypeMeta{
+			Kind: "HeavyForwarder",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "stack1",
+			Namespace: "test",
+		},
+		Spec: enterprisev1.HeavyForwarderSpec{
+			IndexerTargets: []string{"idx1.example.com"},
+		},
+	}
+	revised := current.DeepCopy()
+	revised.Spec.Image = "splunk/test"
+	reconcile := func(c *spltest.MockClient, cr interface{}) error {
+		_, err := ApplyHeavyForwarder(c, nil, cr.(*enterprisev1.HeavyForwarder))
+		return err
+	}
+	spltest.ReconcileTesterWithoutRedundantCheck(t, "TestApplyHeavyForwarder", &current, revised, createCalls, updateCalls, reconcile, true)
+
+	// test deletion
+	currentTime := metav1.NewTime(time.Now())
+	revised.ObjectMeta.DeletionTimestamp = &currentTime
+	revised.ObjectMeta.Finalizers = []string{"enterprise.splunk.com/delete-pvc"}
+	deleteFunc := func(cr splcommon.MetaObject, c splcommon.ControllerClient) (bool, error) {
+		_, err := ApplyHeavyForwarder(c, nil, cr.(*enterprisev1.HeavyForwarder))
+		return true, err
+	}
+	splunkDeletionTester(t, revised, deleteFunc)
+}
+
+func TestGetHeavyForwarderStatefulSet(t *testing.T) {
+	cr := enterprisev1.HeavyForwarder{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "stack1",
+			Namespace: "test",
+		},
+	}
+	cr.Spec.ClusterMasterRef.Name = "stack2"
+
+	c := spltest.NewMockClient()
+	_, err := splutil.ApplyNamespaceScopedSecretObject(c, "test")
+	if err != nil {
+		t.Errorf("Failed to create namespace scoped object")
+	}
+
+	test := func(want string) {
+		f := func() (interface{}, error) {
+			if err := validateHeavyForwarderSpec(&cr); err != nil {
+				t.Errorf("validateHeavyForwarderSpec() returned error: %v", err)
+			}
+			return getHeavyForwarderStatefulSet(c, &cr)
+		}
+		configTester(t, "getHeavyForwarderStatefulSet()", f, want)
+	}
+
+	test(`{"kind":"StatefulSet","apiVersion":"apps/v1","metadata":{"name":"splunk-stack1-heavy-forwarder","namespace":"test","creationTimestamp":null,"ownerReferences":[{"apiVersion":"","kind":"","name":"stack1","uid":"","controller":true}]},"spec":{"replicas":1,"selector":{"matchLabels":{"app.kubernetes.io/component":"heavy-forwarder","app.kubernetes.io/instance":"splunk-stack1-heavy-forwarder","app.kubernetes.io/managed-by":"splunk-operator","app.kubernetes.io/name":"heavy-forwarder","app.kubernetes.io/part-of":"splunk-stack1-heavy-forwarder"}},"template":{"metadata":{"creationTimestamp":null,"labels":{"app.kubernetes.io/component":"heavy-forwarder","app.kubernetes.io/instance":"splunk-stack1-heavy-forwarder","app.kubernetes.io/managed-by":"splunk-operator","app.kubernetes.io/name":"heavy-forwarder","app.kubernetes.io/part-of":"splunk-stack1-heavy-forwarder"},"annotations":{"traffic.sidecar.istio.io/excludeOutboundPorts":"8089,8191,9997","traffic.sidecar.istio.io/includeInboundPorts":"8000,8088"}},"spec":{"volumes":[{"name":"mnt-splunk-secrets","secret":{"secretName":"splunk-stack1-heavy-forwarder-secret-v1","defaultMode":420}}],"initContainers":[{"name":"init","image":"splunk/splunk","command":["bash","-c","mkdir -p /opt/splk/etc/apps/splunk-operator/local \u0026\u0026 cat \u003e /opt/splk/etc/apps/splunk-operator/local/outputs.conf \u003c\u003cEOF\n[indexer_discovery:stack2]\npass4SymmKey = $(cat /mnt/splunk-secrets/idxc_secret)\nmaster_uri = https://splunk-stack2-cluster-master-service:8089\n\n[tcpout:stack2]\nindexerDiscovery = stack2\nuseACK = true\n\n[tcpout]\ndefaultGroup = stack2\nEOF"],"resources":{"limits":{"cpu":"1","memory":"512Mi"},"requests":{"cpu":"250m","memory":"128Mi"}},"volumeMounts":[{"name":"pvc-etc","mountPath":"/opt/splk/etc"},{"name":"mnt-splunk-secrets","mountPath":"/mnt/splunk-secrets"}],"imagePullPolicy":"IfNotPresent"}],"containers":[{"name":"splunk","image":"splunk/splunk","ports":[{"name":"http-splunkweb","containerPort":8000,"protocol":"TCP"},{"name":"http-hec","containerPort":8088,"protocol":"TCP"},{"name":"https-splunkd","containerPort":8089,"protocol":"TCP"},{"name":"tcp-s2s","containerPort":9997,"protocol":"TCP"}],"env":[{"name":"SPLUNK_HOME","value":"/opt/splunk"},{"name":"SPLUNK_START_ARGS","value":"--accept-license"},{"name":"SPLUNK_DEFAULTS_URL","value":"/mnt/splunk-secrets/default.yml"},{"name":"SPLUNK_HOME_OWNERSHIP_ENFORCEMENT","value":"false"},{"name":"SPLUNK_ROLE","value":"splunk_heavy_forwarder"},{"name":"SPLUNK_DECLARATIVE_ADMIN_PASSWORD","value":"true"}],"resources":{"limits":{"cpu":"4","memory":"8Gi"},"requests":{"cpu":"100m","memory":"512Mi"}},"volumeMounts":[{"name":"pvc-etc","mountPath":"/opt/splunk/etc"},{"name":"pvc-var","mountPath":"/opt/splunk/var"},{"name":"mnt-splunk-secrets","mountPath":"/mnt/splunk-secrets"}],"livenessProbe":{"exec":{"command":["/sbin/checkstate.sh"]},"initialDelaySeconds":300,"timeoutSeconds":30,"periodSeconds":30},"readinessProbe":{"exec":{"command":["/bin/grep","started","/opt/container_artifact/splunk-container.state"]},"initialDelaySeconds":10,"timeoutSeconds":5,"periodSeconds":5},"imagePullPolicy":"IfNotPresent"}],"securityContext":{"runAsUser":41812,"fsGroup":41812},"affinity":{"podAntiAffinity":{"preferredDuringSchedulingIgnoredDuringExecution":[{"weight":100,"podAffinityTerm":{"labelSelector":{"matchExpressions":[{"key":"app.kubernetes.io/instance","operator":"In","values":["splunk-stack1-heavy-forwarder"]}]},"topologyKey":"kubernetes.io/hostname"}}]}},"schedulerName":"default-scheduler"}},"volumeClaimTemplates":[{"metadata":{"name":"pvc-etc","namespace":"test","creationTimestamp":null,"labels":{"app.kubernetes.io/component":"heavy-forwarder","app.kubernetes.io/instance":"splunk-stack1-heavy-forwarder","app.kubernetes.io/managed-by":"splunk-operator","app.kubernetes.io/name":"heavy-forwarder","app.kubernetes.io/part-of":"splunk-stack1-heavy-forwarder"}},"spec":{"accessModes":["ReadWriteOnce"],"resources":{"requests":{"storage":"10Gi"}}},"status":{}},{"metadata":{"name":"pvc-var","namespace":"test","creationTimestamp":null,"labels":{"app.kubernetes.io/component":"heavy-forwarder","app.kubernetes.io/instance":"splunk-stack1-heavy-forwarder","app.kubernetes.io/managed-by":"splunk-operator","app.kubernetes.io/name":"heavy-forwarder","app.kubernetes.io/part-of":"splunk-stack1-heavy-forwarder"}},"spec":{"accessModes":["ReadWriteOnce"],"resources":{"requests":{"storage":"100Gi"}}},"status":{}}],"serviceName":"splunk-stack1-heavy-forwarder-headless","podManagementPolicy":"Parallel","updateStrategy":{"type":"OnDelete"}},"status":{"replicas":0}}`)
+}
+
+func TestGetHeavyForwarderOutputsConf(t *testing.T) {
+	cr := enterprisev1.HeavyForwarder{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "stack1",
+			Namespace: "test",
+		},
+	}
+	test := func(want string) {
+		got := getHeavyForwarderOutputsConf(&cr)
+		if got != want {
+			t.Errorf("getHeavyForwarderOutputsConf() = %s; want %s", got, want)
+		}
+	}
+
+	cr.Spec.ClusterMasterRef.Name = "stack2"
+	test(`[indexer_discovery:stack2]
+pass4SymmKey = $(cat /mnt/splunk-secrets/idxc_secret)
+master_uri = https://splunk-stack2-cluster-master-service:8089
+
+[tcpout:stack2]
+indexerDiscovery = stack2
+useACK = true
+
+[tcpout]
+defaultGroup = stack2
+`)
+
+	cr.Spec.ClusterMasterRef = corev1.ObjectReference{}
+	cr.Spec.IndexerTargets = []string{"idx1.example.com", "idx2.example.com:9998"}
+	test(`[tcpout:indexers]
+server = idx1.example.com:9997,idx2.example.com:9998
+useACK = true
+
+[tcpout]
+defaultGroup = indexers
+`)
+}
+
+func TestValidateHeavyForwarderSpec(t *testing.T) {
+	test := func(spec enterprisev1.HeavyForwarderSpec, wantErr bool) {
+		cr := enterprisev1.HeavyForwarder{
+			ObjectMeta: metav1.ObjectMeta{Name: "stack1", Namespace: "test"},
+			Spec:       spec,
+		}
+		err := validateHeavyForwarderSpec(&cr)
+		if (err != nil) != wantErr {
+			t.Errorf("validateHeavyForwarderSpec(%v) returned %v; want error %t", spec, err, wantErr)
+		}
+	}
+
+	spec := enterprisev1.HeavyForwarderSpec{}
+	test(spec, true)
+	spec.IndexerTargets = []string{"idx1.example.com"}
+	test(spec, false)
+	spec.ClusterMasterRef.Name = "stack2"
+	test(spec, true)
+	spec.IndexerTargets = nil
+	test(spec, false)
+	spec.ClusterMasterRef.Namespace = "test"
+	test(spec, false)
+	// the indexer discovery key of a cluster master in another namespace is not available
+	spec.ClusterMasterRef.Namespace = "idxc"
+	test(spec, true)
+	spec.ClusterMasterRef = corev1.ObjectReference{}
+	spec.IndexerTargets = []string{""}
+	test(spec, true)
+}
@@ -15,16 +15,22 @@
 package enterprise
 
 import (
+	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/json"
 	"errors"
 	"fmt"
-	"regexp"
-	"strconv"
+	"path"
+	"sort"
 	"time"
 
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/types"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	"github.com/go-logr/logr"
@@ -32,11 +38,12 @@ import (
 	splclient "github.com/splunk/splunk-operator/pkg/splunk/client"
 	splcommon "github.com/splunk/splunk-operator/pkg/splunk/common"
 	splctrl "github.com/splunk/splunk-operator/pkg/splunk/controller"
+	splmetrics "github.com/splunk/splunk-operator/pkg/splunk/metrics"
 	splutil "github.com/splunk/splunk-operator/pkg/splunk/util"
 )
 
 // ApplyIndexerCluster reconciles the state of a Splunk Enterprise indexer cluster.
-func ApplyIndexerCluster(client splcommon.ControllerClient, cr *enterprisev1.IndexerCluster) (reconcile.Result, error) {
+func ApplyIndexerCluster(client splcommon.ControllerClient, recorder record.EventRecorder, cr *enterprisev1.IndexerCluster) (reconcile.Result, error) {
 
 	// unless modified, reconcile for this object will be requeued after 5 seconds
 	result := reconcile.Result{
@@ -45,9 +52,15 @@ func ApplyIndexerCluster(client splcommon.ControllerClient, cr *enterprisev1.Ind
 	}
 	scopedLog := log.WithName("ApplyIndexerCluster").WithValues("name", cr.GetName(), "namespace", cr.GetNamespace())
 
+	eventPublisher := splcommon.NewEventPublisher(recorder, cr)
+
 	// validate and updates defaults for CR
 	err := validateIndexerClusterSpec(cr)
 	if err != nil {
+		cr.Status.Phase = splcommon.PhaseError
+		setErrorCondition(cr, &cr.Status.Conditions, "", reasonInvalidSpec, err)
+		cr.Status.ObservedGeneration = cr.GetGeneration()
+		client.Status().Update(context.TODO(), cr)
 		return result, err
 	}
 
@@ -66,6 +79,8 @@ func ApplyIndexerCluster(client splcommon.ControllerClient, cr *enterprisev1.Ind
 		cr.Status.IdxcPasswordChangedSecrets = make(map[string]bool)
 	}
 	defer func() {
+		setPhaseConditions(cr, &cr.Status.Conditions, cr.Status.Phase)
+		cr.Status.ObservedGeneration = cr.GetGeneration()
 		err = client.Status().Update(context.TODO(), cr)
 		if err != nil {
 			scopedLog.Error(err, "Status update failed")
@@ -75,8 +90,10 @@ func ApplyIndexerCluster(client splcommon.ControllerClient, cr *enterprisev1.Ind
 	// create or update general config resources
 	namespaceScopedSecret, err := ApplySplunkConfig(client, cr, cr.Spec.CommonSplunkSpec, SplunkIndexer)
 	if err != nil {
+		setErrorCondition(cr, &cr.Status.Conditions, enterprisev1.ConditionSecretsSynced, reasonSecretsSyncFailed, err)
 		return result, err
 	}
+	setCondition(cr, &cr.Status.Conditions, enterprisev1.ConditionSecretsSynced, corev1.ConditionTrue, reasonSecretsSynced, "")
 
 	namespacedName := types.NamespacedName{
 		Namespace: cr.GetNamespace(),
@@ -89,11 +106,12 @@ func ApplyIndexerCluster(client splcommon.ControllerClient, cr *enterprisev1.Ind
 	} else {
 		cr.Status.ClusterMasterPhase = splcommon.PhaseError
 	}
-	mgr := indexerClusterPodManager{log: scopedLog, cr: cr, secrets: namespaceScopedSecret, newSplunkClient: splclient.NewSplunkClient}
+	mgr := indexerClusterPodManager{c: client, log: scopedLog, cr: cr, secrets: namespaceScopedSecret, newSplunkClient: splclient.NewSplunkClient, eventPublisher: eventPublisher}
 	// Check if we have configured enough number(<= RF) of replicas
 	if mgr.cr.Status.ClusterMasterPhase == splcommon.PhaseReady {
 		err = mgr.verifyRFPeers(client)
 		if err != nil {
+			setErrorCondition(cr, &cr.Status.Conditions, "", reasonClusterMasterUnreachable, err)
 			return result, err
 		}
 	}
@@ -101,35 +119,87 @@ func ApplyIndexerCluster(client splcommon.ControllerClient, cr *enterprisev1.Ind
 	// check if deletion has been requested
 	if cr.ObjectMeta.DeletionTimestamp != nil {
 		DeleteOwnerReferencesForResources(client, cr, nil)
-		terminating, err := splctrl.CheckForDeletion(cr, client)
+		terminating, err := splctrl.CheckForDeletion(cr, client, eventPublisher)
 		if terminating && err != nil { // don't bother if no error, since it will just be removed immmediately after
 			cr.Status.Phase = splcommon.PhaseTerminating
 			cr.Status.ClusterMasterPhase = splcommon.PhaseTerminating
+			setErrorCondition(cr, &cr.Status.Conditions, "", reasonDeletionFailed, err)
 		} else {
 			result.Requeue = false
 		}
 		return result, err
 	}
+
+	// check that the site of the indexers matches the multisite configuration of the cluster master
+	if mgr.cr.Status.ClusterMasterPhase == splcommon.PhaseReady {
+		err = mgr.verifyMultisite(masterIdxCluster)
+		if err != nil {
+			setErrorCondition(cr, &cr.Status.Conditions, "", reasonMultisiteMismatch, err)
+			return result, err
+		}
+	}
+
+	// indexers use the license master of their cluster master
+	setLicenseCondition(client, cr, &cr.Status.Conditions, masterIdxCluster.Spec.LicenseMasterRef)
+
 	// create or update a headless service for indexer cluster
 	err = splctrl.ApplyService(client, getSplunkService(cr, &cr.Spec.CommonSplunkSpec, SplunkIndexer, true))
 	if err != nil {
+		setErrorCondition(cr, &cr.Status.Conditions, "", reasonServiceFailed, err)
 		return result, err
 	}
 
 	// create or update a regular service for indexer cluster (ingestion)
 	err = splctrl.ApplyService(client, getSplunkService(cr, &cr.Spec.CommonSplunkSpec, SplunkIndexer, false))
 	if err != nil {
+		setErrorCondition(cr, &cr.Status.Conditions, "", reasonServiceFailed, err)
 		return result, err
 	}
 
+	// the indexers access the SmartStore volumes of their cluster master with the IAM role of their own service account
+	warning, err := applyRemoteVolumeServiceAccount(client, &cr.Spec.CommonSplunkSpec, cr.GetNamespace(), &masterIdxCluster.Spec.SmartStore)
+	if err != nil {
+		setErrorCondition(cr, &cr.Status.Conditions, "", reasonServiceAccountFailed, err)
+		return result, err
+	}
+	if warning != "" {
+		eventPublisher.Warning(splcommon.EventReasonServiceAccountMissing, "%s", warning)
+	}
+
 	// create or update statefulset for the indexers
 	statefulSet, err := getIndexerStatefulSet(client, cr)
 	if err != nil {
+		setErrorCondition(cr, &cr.Status.Conditions, "", reasonStatefulSetFailed, err)
 		return result, err
 	}
 
+	// the master apps bundle refers to the CA bundles and GCS service account keys of the SmartStore volumes of the cluster master
+	addRemoteVolumeCABundles(&statefulSet.Spec.Template, &masterIdxCluster.Spec.SmartStore)
+	addRemoteVolumeGCSCredentials(&statefulSet.Spec.Template, &masterIdxCluster.Spec.SmartStore)
+
+	// upgrade the indexers after the cluster master and the search heads, with the cluster master in maintenance mode
+	upgrading, err := applyUpgradeOrder(client, cr, &cr.Status.Conditions, cr.Status.Image, cr.Status.Version, statefulSet, eventPublisher)
+	if err != nil {
+		setErrorCondition(cr, &cr.Status.Conditions, enterprisev1.ConditionImageUpToDate, reasonUpgradeFailed, err)
+		return result, err
+	}
+	if upgrading && cr.Status.ClusterMasterPhase == splcommon.PhaseReady && !cr.Status.MaintenanceMode {
+		err = mgr.setMaintenanceMode(true)
+		if err != nil {
+			setErrorCondition(cr, &cr.Status.Conditions, "", reasonClusterMasterUnreachable, err)
+			return result, err
+		}
+		eventPublisher.Normal(splcommon.EventReasonMaintenanceMode, "Enabled maintenance mode on cluster master %s to upgrade the indexers", cr.Spec.ClusterMasterRef.Name)
+	}
+
+	err = applySplunkPodDisruptionBudget(client, cr, &cr.Spec.PodDisruptionBudget, getDefaultDisruptionBudget(SplunkIndexer, cr.Spec.Replicas, masterIdxCluster.Spec.Multisite.SiteReplicationFactor.Origin), statefulSet)
+	if err != nil {
+		setErrorCondition(cr, &cr.Status.Conditions, "", reasonDisruptionBudgetFailed, err)
+		return result, err
+	}
 	phase, err := mgr.Update(client, statefulSet, cr.Spec.Replicas)
 	if err != nil {
+		setErrorCondition(cr, &cr.Status.Conditions, "", reasonStatefulSetFailed, err)
 		return result, err
 	}
 	cr.Status.Phase = phase
@@ -138,15 +208,41 @@ func ApplyIndexerCluster(client splcommon.ControllerClient, cr *enterprisev1.Ind
 	if cr.Status.Phase == splcommon.PhaseReady {
 		err = ApplyMonitoringConsole(client, cr, cr.Spec.CommonSplunkSpec, getIndexerExtraEnv(cr, cr.Spec.Replicas))
 		if err != nil {
+			setErrorCondition(cr, &cr.Status.Conditions, "", reasonMonitoringConsoleFailed, err)
 			return result, err
 		}
 		if len(cr.Status.IndexerSecretChanged) > 0 {
 			// Disable maintenance mode
-			err = SetClusterMaintenanceMode(client, cr, false, false)
+			err = mgr.setMaintenanceMode(false)
+			if err != nil {
+				setErrorCondition(cr, &cr.Status.Conditions, "", reasonClusterMasterUnreachable, err)
+				return result, err
+			}
+			eventPublisher.Normal(splcommon.EventReasonMaintenanceMode, "Disabled maintenance mode on cluster master %s after changing idxc_secret", cr.Spec.ClusterMasterRef.Name)
+		}
+
+		// leave maintenance mode once the indexers are upgraded, unless other indexer clusters of the cluster master are still upgrading
+		if isUpgradeComplete(cr.Status.Image, statefulSet) && cr.Status.MaintenanceMode {
+			upgrading, err := getUpgradingIndexerClusters(client, cr)
 			if err != nil {
+				setErrorCondition(cr, &cr.Status.Conditions, enterprisev1.ConditionImageUpToDate, reasonUpgradeFailed, err)
 				return result, err
 			}
+			if len(upgrading) == 0 {
+				err = mgr.setMaintenanceMode(false)
+				if err != nil {
+					setErrorCondition(cr, &cr.Status.Conditions, "", reasonClusterMasterUnreachable, err)
+					return result, err
+				}
+				eventPublisher.Normal(splcommon.EventReasonMaintenanceMode, "Disabled maintenance mode on cluster master %s after upgrading the indexers", cr.Spec.ClusterMasterRef.Name)
+			}
 		}
+		err = updateVersion(&cr.Spec.CommonSplunkSpec, cr.Status.Image, &cr.Status.Version, statefulSet, func() *splclient.SplunkClient { return mgr.getClient(0) })
+		if err != nil {
+			setErrorCondition(cr, &cr.Status.Conditions, "", reasonServerInfoFailed, err)
+			return result, err
+		}
+		setRunningImage(cr, &cr.Status.Conditions, &cr.Status.Image, cr.Spec.Image, statefulSet, eventPublisher)
 
 		// Reset idxc secret changed and namespace secret revision
 		cr.Status.IndexerSecretChanged = []bool{}
@@ -159,10 +255,12 @@ func ApplyIndexerCluster(client splcommon.ControllerClient, cr *enterprisev1.Ind
 		namespacedName = types.NamespacedName{Namespace: cr.GetNamespace(), Name: GetSplunkStatefulsetName(SplunkClusterMaster, cr.Spec.ClusterMasterRef.Name)}
 		err = splctrl.SetStatefulSetOwnerRef(client, cr, namespacedName)
 		if err != nil {
+			setErrorCondition(cr, &cr.Status.Conditions, "", reasonStatefulSetFailed, err)
 			result.Requeue = true
 			return result, err
 		}
 	}
+	setReconciledCondition(cr, &cr.Status.Conditions)
 	return result, nil
 }
 
@@ -173,48 +271,11 @@ type indexerClusterPodManager struct {
 	cr              *enterprisev1.IndexerCluster
 	secrets         *corev1.Secret
 	newSplunkClient func(managementURI, username, password string) *splclient.SplunkClient
-}
-
-// SetClusterMaintenanceMode enables/disables cluster maintenance mode
-func SetClusterMaintenanceMode(c splcommon.ControllerClient, cr *enterprisev1.IndexerCluster, enable bool, mock bool) error {
-	// Retrieve admin password from Pod
-	var masterIdxcName string
-	if len(cr.Spec.ClusterMasterRef.Name) > 0 {
-		masterIdxcName = cr.Spec.ClusterMasterRef.Name
-	} else {
-		return errors.New("Empty cluster master reference")
-	}
-	cmPodName := fmt.Sprintf("splunk-%s-cluster-master-0", masterIdxcName)
-	adminPwd, err := splutil.GetSpecificSecretTokenFromPod(c, cmPodName, cr.GetNamespace(), "password")
-	if err != nil {
-		return err
-	}
-
-	var command string
-	if enable {
-		command = fmt.Sprintf("/opt/splunk/bin/splunk enable maintenance-mode --answer-yes -auth admin:%s", adminPwd)
-	} else {
-		command = fmt.Sprintf("/opt/splunk/bin/splunk disable maintenance-mode --answer-yes -auth admin:%s", adminPwd)
-	}
-	_, _, err = splutil.PodExecCommand(c, cmPodName, cr.GetNamespace(), []string{"/bin/sh"}, command, false, false)
-	if err != nil {
-		if !mock {
-			return err
-		}
-	}
-
-	// Set cluster master maintenance mode
-	if enable {
-		cr.Status.MaintenanceMode = true
-	} else {
-		cr.Status.MaintenanceMode = false
-	}
-
-	return nil
+	eventPublisher  *splcommon.EventPublisher
 }
 
 // ApplyIdxcSecret checks if any of the indexer's have a different idxc_secret from namespace scoped secret and changes it
-func ApplyIdxcSecret(mgr *indexerClusterPodManager, replicas int32, mock bool) error {
+func ApplyIdxcSecret(mgr *indexerClusterPodManager, replicas int32) error {
 	var indIdxcSecret string
 	// Get namespace scoped secret
 	namespaceSecret, err := splutil.ApplyNamespaceScopedSecretObject(mgr.c, mgr.cr.GetNamespace())
@@ -222,7 +283,7 @@ func ApplyIdxcSecret(mgr *indexerClusterPodManager, replicas int32, mock bool) e
 		return err
 	}
 
-	scopedLog := log.WithName("ApplyIdxcSecret").WithValues("Desired replicas", replicas, "IdxcSecretChanged", mgr.cr.Status.IndexerSecretChanged, "NamespaceSecretResourceVersion", mgr.cr.Status.NamespaceSecretResourceVersion, "mock", mock)
+	scopedLog := log.WithName("ApplyIdxcSecret").WithValues("Desired replicas", replicas, "IdxcSecretChanged", mgr.cr.Status.IndexerSecretChanged, "NamespaceSecretResourceVersion", mgr.cr.Status.NamespaceSecretResourceVersion)
 
 	// If namespace scoped secret revision is the same ignore
 	if len(mgr.cr.Status.NamespaceSecretResourceVersion) == 0 {
@@ -263,11 +324,12 @@ func ApplyIdxcSecret(mgr *indexerClusterPodManager, replicas int32, mock bool) e
 
 			// Enable maintenance mode
 			if len(mgr.cr.Status.IndexerSecretChanged) == 0 && !mgr.cr.Status.MaintenanceMode {
-				err = SetClusterMaintenanceMode(mgr.c, mgr.cr, true, mock)
+				err = mgr.setMaintenanceMode(true)
 				if err != nil {
 					return err
 				}
 				scopedLog.Info("Set Cm in maintenance mode")
+				mgr.eventPublisher.Normal(splcommon.EventReasonMaintenanceMode, "Enabled maintenance mode on cluster master %s to change idxc_secret", mgr.cr.Spec.ClusterMasterRef.Name)
 			}
 
 			// If idxc secret already changed, ignore
@@ -293,6 +355,7 @@ func ApplyIdxcSecret(mgr *indexerClusterPodManager, replicas int32, mock bool) e
 				return err
 			}
 			scopedLog.Info("Restarted splunk")
+			mgr.eventPublisher.Normal(splcommon.EventReasonSecretChanged, "Changed idxc_secret on %s", indexerPodName)
 
 			// Keep a track of all the secrets on pods to change their idxc secret below
 			mgr.cr.Status.IdxcPasswordChangedSecrets[podSecret.GetName()] = true
@@ -357,6 +420,10 @@ func (mgr *indexerClusterPodManager) Update(c splcommon.ControllerClient, statef
 	}
 	// update statefulset, if necessary
 	if mgr.cr.Status.ClusterMasterPhase == splcommon.PhaseReady {
+		err = mgr.applyRollingRestart(statefulSet)
+		if err != nil {
+			return splcommon.PhaseError, err
+		}
 		_, err = splctrl.ApplyStatefulSet(mgr.c, statefulSet)
 		if err != nil {
 			return splcommon.PhaseError, err
@@ -366,7 +433,7 @@ func (mgr *indexerClusterPodManager) Update(c splcommon.ControllerClient, statef
 	}
 
 	// Check if a recycle of idxc pods is necessary(due to idxc_secret mismatch with CM)
-	err = ApplyIdxcSecret(mgr, desiredReplicas, false)
+	err = ApplyIdxcSecret(mgr, desiredReplicas)
 	if err != nil {
 		return splcommon.PhaseError, err
 	}
@@ -378,8 +445,294 @@ func (mgr *indexerClusterPodManager) Update(c splcommon.ControllerClient, statef
 		return splcommon.PhasePending, nil
 	}
 
+	// pods are not recycled while the cluster master restarts the peers
+	if mgr.cr.Status.RollingRestart.InProgress {
+		mgr.log.Info("Waiting for the rolling restart of the peers to complete", "pending", mgr.cr.Status.RollingRestart.Pending)
+		return splcommon.PhaseUpdating, nil
+	}
+
 	// manage scaling and updates
-	return splctrl.UpdateStatefulSetPods(c, statefulSet, mgr, desiredReplicas)
+	err = mgr.startRecycleMaintenanceMode(statefulSet, desiredReplicas)
+	if err != nil {
+		return splcommon.PhaseError, err
+	}
+	phase, err := splctrl.UpdateStatefulSetPods(c, mgr.eventPublisher, statefulSet, mgr, desiredReplicas)
+	if err != nil || phase != splcommon.PhaseReady {
+		return phase, err
+	}
+	err = mgr.finishRecycleMaintenanceMode()
+	if err != nil {
+		return splcommon.PhaseError, err
+	}
+	return phase, nil
+}
+
+// startRecycleMaintenanceMode puts the cluster master in maintenance mode before more than one peer is recycled, so that
+// it does not fix up the buckets of each peer while it restarts. An indexer cluster joins the maintenance mode started
+// by another indexer cluster of the cluster master to recycle its peers, but not one enabled to upgrade the indexers,
+// to change the idxc_secret or by hand. Scale downs are left out, since decommissioning waits for bucket fixups.
+func (mgr *indexerClusterPodManager) startRecycleMaintenanceMode(statefulSet *appsv1.StatefulSet, desiredReplicas int32) error {
+	replicas := statefulSet.Status.Replicas
+	if mgr.cr.Status.RecycleMaintenanceMode || replicas < 2 || replicas > desiredReplicas || statefulSet.Status.UpdatedReplicas >= replicas {
+		return nil
+	}
+
+	if mgr.cr.Status.MaintenanceMode {
+		recycling, err := getRecyclingIndexerClusters(mgr.c, mgr.cr)
+		if err != nil || len(recycling) == 0 {
+			return err
+		}
+	} else {
+		err := mgr.setMaintenanceMode(true)
+		if err != nil {
+			return err
+		}
+		mgr.eventPublisher.Normal(splcommon.EventReasonMaintenanceMode, "Enabled maintenance mode on cluster master %s to recycle the indexers", mgr.cr.Spec.ClusterMasterRef.Name)
+	}
+	mgr.cr.Status.RecycleMaintenanceMode = true
+	return nil
+}
+
+// finishRecycleMaintenanceMode leaves the maintenance mode started to recycle the peers once they are all updated,
+// unless other indexer clusters of the cluster master are still recycling theirs
+func (mgr *indexerClusterPodManager) finishRecycleMaintenanceMode() error {
+	if !mgr.cr.Status.RecycleMaintenanceMode {
+		return nil
+	}
+	recycling, err := getRecyclingIndexerClusters(mgr.c, mgr.cr)
+	if err != nil {
+		return err
+	}
+	if len(recycling) == 0 && mgr.cr.Status.MaintenanceMode {
+		err = mgr.setMaintenanceMode(false)
+		if err != nil {
+			return err
+		}
+		mgr.eventPublisher.Normal(splcommon.EventReasonMaintenanceMode, "Disabled maintenance mode on cluster master %s after recycling the indexers", mgr.cr.Spec.ClusterMasterRef.Name)
+	}
+	mgr.cr.Status.RecycleMaintenanceMode = false
+	return nil
+}
+
+// getRecyclingIndexerClusters returns the names of the other indexer clusters of the same cluster master that keep it
+// in maintenance mode to recycle their peers
+func getRecyclingIndexerClusters(c splcommon.ControllerClient, cr *enterprisev1.IndexerCluster) ([]string, error) {
+	var list enterprisev1.IndexerClusterList
+	err := c.List(context.TODO(), &list, client.InNamespace(cr.GetNamespace()))
+	if err != nil {
+		return nil, fmt.Errorf("Unable to list indexer clusters: %v", err)
+	}
+	var recycling []string
+	for _, idxc := range list.Items {
+		if idxc.GetName() != cr.GetName() && idxc.Spec.ClusterMasterRef.Name == cr.Spec.ClusterMasterRef.Name && idxc.Status.RecycleMaintenanceMode {
+			recycling = append(recycling, idxc.GetName())
+		}
+	}
+	return recycling, nil
+}
+
+// getSharedMasterIndexerClusters returns the names of the other indexer clusters of the cluster master of an indexer cluster
+func getSharedMasterIndexerClusters(c splcommon.ControllerClient, cr *enterprisev1.IndexerCluster) ([]string, error) {
+	var list enterprisev1.IndexerClusterList
+	err := c.List(context.TODO(), &list, client.InNamespace(cr.GetNamespace()))
+	if err != nil {
+		return nil, fmt.Errorf("Unable to list indexer clusters: %v", err)
+	}
+	var shared []string
+	for _, idxc := range list.Items {
+		if idxc.GetName() != cr.GetName() && idxc.Spec.ClusterMasterRef.Name == cr.Spec.ClusterMasterRef.Name {
+			shared = append(shared, idxc.GetName())
+		}
+	}
+	return shared, nil
+}
+
+// applyRollingRestart applies a change of the defaults ConfigMap with a searchable rolling restart of the peers by the
+// cluster master, instead of recycling the pods. splunk-ansible only renders the defaults when a container starts, so
+// this is limited to changes of the values of the splunk.conf settings in etc/system/local, which are updated on each
+// peer before the restart. When such a change of the defaults revision is the only change of the pod template, the
+// current revision is kept in the revised StatefulSet so that its pods are left as is. The cluster master restarts all
+// of its peers, so the pods are recycled when it has other indexer clusters.
+func (mgr *indexerClusterPodManager) applyRollingRestart(statefulSet *appsv1.StatefulSet) error {
+	annotations := statefulSet.Spec.Template.ObjectMeta.Annotations
+	revisedRev := annotations[defaultConfigRev]
+	if revisedRev == "" {
+		return nil
+	}
+
+	namespacedName := types.NamespacedName{Namespace: mgr.cr.GetNamespace(), Name: GetSplunkDefaultsName(mgr.cr.GetName(), SplunkIndexer)}
+	defaults, err := splctrl.GetConfigMap(mgr.c, namespacedName)
+	if err != nil {
+		return err
+	}
+	checksum, confSettings, err := getRestartableDefaults(defaults.Data)
+	if err != nil {
+		return err
+	}
+	restartStatus := &mgr.cr.Status.RollingRestart
+
+	namespacedName = types.NamespacedName{Namespace: statefulSet.GetNamespace(), Name: statefulSet.GetName()}
+	current, err := splctrl.GetStatefulSetByName(mgr.c, namespacedName)
+	if err != nil {
+		// the StatefulSet is created with the defaults
+		restartStatus.DefaultsChecksum = checksum
+		return nil
+	}
+	currentRev := current.Spec.Template.ObjectMeta.Annotations[defaultConfigRev]
+	if currentRev == "" || currentRev == revisedRev {
+		if restartStatus.DefaultsChecksum == "" {
+			restartStatus.DefaultsChecksum = checksum
+		}
+		return nil
+	}
+
+	// any other change of the defaults or of the pod template needs its pods to be recycled, which also picks up the defaults
+	annotations[defaultConfigRev] = currentRev
+	if checksum == "" || checksum != restartStatus.DefaultsChecksum ||
+		splctrl.MergePodUpdates(current.Spec.Template.DeepCopy(), statefulSet.Spec.Template.DeepCopy(), current.GetName()) {
+		annotations[defaultConfigRev] = revisedRev
+		restartStatus.DefaultsChecksum = checksum
+		return nil
+	}
+
+	// a new revision waits for the rolling restart in progress to complete
+	if restartStatus.ConfigRev == revisedRev || restartStatus.InProgress {
+		return nil
+	}
+
+	// the cluster master restarts all of its peers, including those of other indexer clusters, so these recycle their own pods
+	shared, err := getSharedMasterIndexerClusters(mgr.c, mgr.cr)
+	if err != nil {
+		return err
+	}
+	if len(shared) > 0 {
+		mgr.log.Info("Recycling the peers since the cluster master has other indexer clusters", "indexerClusters", shared)
+		annotations[defaultConfigRev] = revisedRev
+		restartStatus.DefaultsChecksum = checksum
+		return nil
+	}
+
+	// the settings are written where splunk-ansible would, so that the peers read them when they restart
+	for n := int32(0); n < current.Status.Replicas; n++ {
+		c := mgr.getClient(n)
+		for _, stanza := range confSettings {
+			err = c.UpdateConfStanza(stanza.file, stanza.stanza, stanza.settings)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	mgr.log.Info("Starting searchable rolling restart of the peers", "defaultConfigRev", revisedRev)
+	err = mgr.getClusterMasterClient().RestartClusterPeers(true)
+	if err != nil {
+		return err
+	}
+	*restartStatus = enterprisev1.IndexerClusterRestartStatus{
+		ConfigRev:        revisedRev,
+		InProgress:       true,
+		StartTime:        time.Now().Unix(),
+		DefaultsChecksum: checksum,
+	}
+	mgr.eventPublisher.Normal(splcommon.EventReasonRollingRestart, "Started searchable rolling restart of the peers to apply defaults revision %s", revisedRev)
+	return nil
+}
+
+// confStanzaSettings holds the settings of a stanza of a .conf file in etc/system/local
+type confStanzaSettings struct {
+	file     string
+	stanza   string
+	settings map[string]string
+}
+
+// getRestartableDefaults returns the splunk.conf settings of the default.yml of a defaults ConfigMap, with a checksum
+// of the ConfigMap data without the values of these settings, which changes when the pods must be recreated to apply
+// the defaults. The checksum is empty if any of the settings is written out of etc/system/local.
+func getRestartableDefaults(data map[string]string) (string, []confStanzaSettings, error) {
+	raw, err := utilyaml.ToJSON([]byte(data["default.yml"]))
+	if err != nil {
+		return "", nil, err
+	}
+	var defaults map[string]interface{}
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber()
+	err = decoder.Decode(&defaults)
+	if err != nil {
+		return "", nil, err
+	}
+
+	var confSettings []confStanzaSettings
+	splunk, _ := defaults["splunk"].(map[string]interface{})
+	if conf, ok := splunk["conf"]; ok {
+		entries, ok := conf.([]interface{})
+		if !ok {
+			return "", nil, nil
+		}
+		for _, entry := range entries {
+			entry, _ := entry.(map[string]interface{})
+			file, _ := entry["key"].(string)
+			value, _ := entry["value"].(map[string]interface{})
+			directory, _ := value["directory"].(string)
+			content, _ := value["content"].(map[string]interface{})
+			if file == "" || (directory != "" && path.Clean(directory) != splunkSystemLocalDir) {
+				return "", nil, nil
+			}
+
+			stanzas := make([]string, 0, len(content))
+			for stanza := range content {
+				stanzas = append(stanzas, stanza)
+			}
+			sort.Strings(stanzas)
+			for _, stanza := range stanzas {
+				settings, ok := content[stanza].(map[string]interface{})
+				if !ok {
+					return "", nil, nil
+				}
+				values := make(map[string]string, len(settings))
+				for key, value := range settings {
+					values[key] = fmt.Sprint(value)
+					settings[key] = ""
+				}
+				confSettings = append(confSettings, confStanzaSettings{file: file, stanza: stanza, settings: values})
+			}
+		}
+	}
+
+	// the settings of the defaults are cleared, while the other files of the ConfigMap are kept as is
+	layout := make(map[string]interface{}, len(data))
+	for key, value := range data {
+		layout[key] = value
+	}
+	layout["default.yml"] = defaults
+	raw, err = json.Marshal(layout)
+	if err != nil {
+		return "", nil, err
+	}
+	return fmt.Sprintf("%x", sha256.Sum256(raw)), confSettings, nil
+}
+
+// updateRollingRestartStatus updates the status of the rolling restart in progress from the cluster master
+func (mgr *indexerClusterPodManager) updateRollingRestartStatus(c *splclient.SplunkClient) error {
+	status, err := c.GetClusterRestartStatus()
+	if err != nil {
+		return err
+	}
+	restartStatus := &mgr.cr.Status.RollingRestart
+	progress := status.RestartProgress
+	restartStatus.Done = progress.Done
+	restartStatus.Pending = append(append([]string{}, progress.InProgress...), progress.ToBeRestarted...)
+	restartStatus.Failed = progress.Failed
+	if status.RollingRestartOrUpgrade {
+		return nil
+	}
+
+	restartStatus.InProgress = false
+	if len(restartStatus.Failed) > 0 {
+		mgr.eventPublisher.Warning(splcommon.EventReasonRollingRestart, "Rolling restart of the peers completed, but failed to restart %v", restartStatus.Failed)
+	} else {
+		mgr.eventPublisher.Normal(splcommon.EventReasonRollingRestart, "Completed searchable rolling restart of the peers")
+	}
+	return nil
 }
 
 // PrepareScaleDown for indexerClusterPodManager prepares indexer pod to be removed via scale down event; it returns true when ready
@@ -419,7 +772,12 @@ func (mgr *indexerClusterPodManager) decommission(n int32, enforceCounts bool) (
 	case "Up":
 		mgr.log.Info("Decommissioning indexer cluster peer", "peerName", peerName, "enforceCounts", enforceCounts)
 		c := mgr.getClient(n)
-		return false, c.DecommissionIndexerClusterPeer(enforceCounts)
+		err := c.DecommissionIndexerClusterPeer(enforceCounts)
+		if err == nil {
+			splmetrics.StartDecommission(mgr.cr.GetNamespace(), peerName)
+			mgr.eventPublisher.Normal(splcommon.EventReasonDecommissionStarted, "Decommissioning indexer cluster peer %s (enforceCounts=%t)", peerName, enforceCounts)
+		}
+		return false, err
 
 	case "Decommissioning":
 		mgr.log.Info("Waiting for decommission to complete", "peerName", peerName)
@@ -431,10 +789,14 @@ func (mgr *indexerClusterPodManager) decommission(n int32, enforceCounts bool) (
 
 	case "GracefulShutdown":
 		mgr.log.Info("Decommission complete", "peerName", peerName, "Status", mgr.cr.Status.Peers[n].Status)
+		splmetrics.FinishDecommission(mgr.cr.GetNamespace(), mgr.cr.GetName(), peerName)
+		mgr.eventPublisher.Normal(splcommon.EventReasonDecommissionComplete, "Indexer cluster peer %s is decommissioned with status %s", peerName, mgr.cr.Status.Peers[n].Status)
 		return true, nil
 
 	case "Down":
 		mgr.log.Info("Decommission complete", "peerName", peerName, "Status", mgr.cr.Status.Peers[n].Status)
+		splmetrics.FinishDecommission(mgr.cr.GetNamespace(), mgr.cr.GetName(), peerName)
+		mgr.eventPublisher.Normal(splcommon.EventReasonDecommissionComplete, "Indexer cluster peer %s is decommissioned with status %s", peerName, mgr.cr.Status.Peers[n].Status)
 		return true, nil
 
 	case "": // this can happen after the peer has been removed from the indexer cluster
@@ -491,12 +853,17 @@ func (mgr *indexerClusterPodManager) getClusterMasterClient() *splclient.SplunkC
 	return mgr.newSplunkClient(fmt.Sprintf("https://%s:8089", fqdnName), "admin", adminPwd)
 }
 
-// getSiteRepFactorOriginCount gets the origin count of the site_replication_factor
-func getSiteRepFactorOriginCount(siteRepFactor string) int32 {
-	re := regexp.MustCompile(".*origin:(?P<rf>.*),.*")
-	match := re.FindStringSubmatch(siteRepFactor)
-	siteRF, _ := strconv.Atoi(match[1])
-	return int32(siteRF)
+// setMaintenanceMode enables or disables maintenance mode on the cluster master
+func (mgr *indexerClusterPodManager) setMaintenanceMode(enable bool) error {
+	if mgr.cr.Spec.ClusterMasterRef.Name == "" {
+		return errors.New(splcommon.EmptyClusterMasterRef)
+	}
+	err := mgr.getClusterMasterClient().SetMaintenanceMode(enable)
+	if err != nil {
+		return err
+	}
+	mgr.cr.Status.MaintenanceMode = enable
+	return nil
 }
 
 // verifyRFPeers verifies the number of peers specified in the replicas section
@@ -505,17 +872,9 @@ func (mgr *indexerClusterPodManager) verifyRFPeers(c splcommon.ControllerClient)
 	if mgr.c == nil {
 		mgr.c = c
 	}
-	cm := mgr.getClusterMasterClient()
-	clusterInfo, err := cm.GetClusterInfo(false)
+	replicationFactor, err := mgr.getReplicationFactor()
 	if err != nil {
-		return fmt.Errorf("Could not get cluster info from cluster master")
-	}
-	var replicationFactor int32
-	// if it is a multisite indexer cluster, check site_replication_factor
-	if clusterInfo.MultiSite == "true" {
-		replicationFactor = getSiteRepFactorOriginCount(clusterInfo.SiteReplicationFactor)
-	} else { // for single site, check replication factor
-		replicationFactor = clusterInfo.ReplicationFactor
+		return err
 	}
 
 	if mgr.cr.Spec.Replicas < replicationFactor {
@@ -525,6 +884,26 @@ func (mgr *indexerClusterPodManager) verifyRFPeers(c splcommon.ControllerClient)
 	return nil
 }
 
+// getReplicationFactor returns the number of peers required by the replication factor of the cluster master
+func (mgr *indexerClusterPodManager) getReplicationFactor() (int32, error) {
+	cm := mgr.getClusterMasterClient()
+	clusterInfo, err := cm.GetClusterInfo(false)
+	if err != nil {
+		return 0, fmt.Errorf("Could not get cluster info from cluster master")
+	}
+
+	// if it is a multisite indexer cluster, check site_replication_factor
+	if clusterInfo.MultiSite == "true" {
+		siteReplicationFactor, err := parseSiteFactor(clusterInfo.SiteReplicationFactor)
+		if err != nil {
+			return 0, err
+		}
+		return siteReplicationFactor.Origin, nil
+	}
+	// for single site, check replication factor
+	return clusterInfo.ReplicationFactor, nil
+}
+
 // updateStatus for indexerClusterPodManager uses the REST API to update the status for an IndexerCluster custom resource
 func (mgr *indexerClusterPodManager) updateStatus(statefulSet *appsv1.StatefulSet) error {
 	mgr.cr.Status.ReadyReplicas = statefulSet.Status.ReadyReplicas
@@ -548,6 +927,14 @@ func (mgr *indexerClusterPodManager) updateStatus(statefulSet *appsv1.StatefulSe
 	mgr.cr.Status.ServiceReady = clusterInfo.ServiceReady
 	mgr.cr.Status.MaintenanceMode = clusterInfo.MaintenanceMode
 
+	// track the rolling restart of the peers, if any
+	if mgr.cr.Status.RollingRestart.InProgress {
+		err = mgr.updateRollingRestartStatus(c)
+		if err != nil {
+			return err
+		}
+	}
+
 	// get peer information from cluster master
 	peers, err := c.GetClusterMasterPeers()
 	if err != nil {
@@ -16,6 +16,7 @@ package enterprise
 
 import (
 	"fmt"
+	"reflect"
 	"strings"
 	"testing"
 	"time"
@@ -24,6 +25,7 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	enterprisev1 "github.com/splunk/splunk-operator/pkg/apis/enterprise/v1"
@@ -43,6 +45,7 @@ func TestApplyIndexerCluster(t *testing.T) {
 		{MetaName: "*v1.Secret-test-splunk-test-secret"},
 		{MetaName: "*v1.Secret-test-splunk-stack1-indexer-secret-v1"},
 		{MetaName: "*v1.ClusterMaster-test-master1"},
+		{MetaName: "*v1beta1.PodDisruptionBudget-test-splunk-stack1-indexer"},
 		{MetaName: "*v1.Secret-test-splunk-test-secret"},
 	}
 	labels := map[string]string{
@@ -55,7 +58,7 @@ func TestApplyIndexerCluster(t *testing.T) {
 	}
 	listmockCall := []spltest.MockFuncCall{
 		{ListOpts: listOpts}}
-	createCalls := map[string][]spltest.MockFuncCall{"Get": funcCalls, "Create": {funcCalls[0], funcCalls[3], funcCalls[4], funcCalls[6]}, "Update": {funcCalls[0]}, "List": {listmockCall[0]}}
+	createCalls := map[string][]spltest.MockFuncCall{"Get": funcCalls, "Create": {funcCalls[0], funcCalls[3], funcCalls[4], funcCalls[6], funcCalls[8]}, "Update": {funcCalls[0]}, "List": {listmockCall[0]}}
 	updateCalls := map[string][]spltest.MockFuncCall{"Get": funcCalls, "List": {listmockCall[0]}}
 
 	current := enterprisev1.IndexerCluster{
@@ -81,7 +84,7 @@ func TestApplyIndexerCluster(t *testing.T) {
 	revised := current.DeepCopy()
 	revised.Spec.Image = "splunk/test"
 	reconcile := func(c *spltest.MockClient, cr interface{}) error {
-		_, err := ApplyIndexerCluster(c, cr.(*enterprisev1.IndexerCluster))
+		_, err := ApplyIndexerCluster(c, nil, cr.(*enterprisev1.IndexerCluster))
 		return err
 	}
 	spltest.ReconcileTesterWithoutRedundantCheck(t, "TestApplyIndexerCluster", &current, revised, createCalls, updateCalls, reconcile, true)
@@ -91,7 +94,7 @@ func TestApplyIndexerCluster(t *testing.T) {
 	revised.ObjectMeta.DeletionTimestamp = &currentTime
 	revised.ObjectMeta.Finalizers = []string{"enterprise.splunk.com/delete-pvc"}
 	deleteFunc := func(cr splcommon.MetaObject, c splcommon.ControllerClient) (bool, error) {
-		_, err := ApplyIndexerCluster(c, cr.(*enterprisev1.IndexerCluster))
+		_, err := ApplyIndexerCluster(c, nil, cr.(*enterprisev1.IndexerCluster))
 		return true, err
 	}
 	splunkDeletionTester(t, revised, deleteFunc)
@@ -403,6 +406,207 @@ func TestInvalidPeerStatusInScaleDown(t *testing.T) {
 	}
 }
 
+func TestIndexerClusterDecommissionEvents(t *testing.T) {
+	mockHandlers := []spltest.MockHTTPHandler{
+		{
+			Method: "POST",
+			URL:    "https://splunk-stack1-indexer-0.splunk-stack1-indexer-headless.test.svc.cluster.local:8089/services/cluster/slave/control/control/decommission?enforce_counts=1",
+			Status: 200,
+			Err:    nil,
+			Body:   ``,
+		},
+	}
+	method := "indexerClusterPodManager.decommission(Events)"
+	mockSplunkClient := &spltest.MockHTTPClient{}
+	mockSplunkClient.AddHandlers(mockHandlers...)
+	mgr := getIndexerClusterPodManager(method, mockHandlers, mockSplunkClient, 1)
+	mgr.c = spltest.NewMockClient()
+	recorder := record.NewFakeRecorder(10)
+	mgr.eventPublisher = splcommon.NewEventPublisher(recorder, mgr.cr)
+
+	test := func(status string, wantComplete bool, want string) {
+		mgr.cr.Status.Peers = []enterprisev1.IndexerClusterMemberStatus{{ID: "D39B1729", Status: status}}
+		complete, err := mgr.decommission(0, true)
+		if err != nil || complete != wantComplete {
+			t.Errorf("%s returned %t, %v for peer status %s; want %t, nil", method, complete, err, status, wantComplete)
+		}
+		if len(recorder.Events) != 1 {
+			t.Errorf("%s recorded %d events for peer status %s; want %q", method, len(recorder.Events), status, want)
+			return
+		}
+		if got := <-recorder.Events; got != want {
+			t.Errorf("%s recorded %q for peer status %s; want %q", method, got, status, want)
+		}
+	}
+
+	test("Up", false, "Normal DecommissionStarted Decommissioning indexer cluster peer splunk-stack1-indexer-0 (enforceCounts=true)")
+	test("Down", true, "Normal DecommissionComplete Indexer cluster peer splunk-stack1-indexer-0 is decommissioned with status Down")
+	mockSplunkClient.CheckRequests(t, method)
+}
+
+func TestIndexerClusterRollingRestart(t *testing.T) {
+	method := "indexerClusterPodManager.applyRollingRestart"
+	mockSplunkClient := &spltest.MockHTTPClient{}
+	mockSplunkClient.AddHandlers(spltest.MockHTTPHandler{
+		Method: "POST",
+		URL:    "https://splunk-stack1-indexer-0.splunk-stack1-indexer-headless.test.svc.cluster.local:8089/servicesNS/nobody/system/configs/conf-indexes/main",
+		Status: 200,
+		Err:    nil,
+		Body:   ``,
+	}, spltest.MockHTTPHandler{
+		Method: "POST",
+		URL:    "https://splunk-master1-cluster-master-service.test.svc.cluster.local:8089/services/cluster/master/control/control/restart",
+		Status: 200,
+		Err:    nil,
+		Body:   ``,
+	})
+	mgr := getIndexerClusterPodManager(method, nil, mockSplunkClient, 1)
+	c := spltest.NewMockClient()
+	c.ListObj = &enterprisev1.IndexerClusterList{}
+	mgr.c = c
+	recorder := record.NewFakeRecorder(10)
+	mgr.eventPublisher = splcommon.NewEventPublisher(recorder, mgr.cr)
+
+	newDefaults := func(settings string) string {
+		return fmt.Sprintf(`splunk:
+  conf:
+    - key: indexes
+      value:
+        directory: /opt/splunk/etc/system/local
+        content:
+          main:
+            %s
+`, settings)
+	}
+	defaults := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "splunk-stack1-indexer-defaults", Namespace: "test"},
+		Data:       map[string]string{"default.yml": newDefaults("maxDataSize: auto")},
+	}
+	c.AddObject(defaults)
+	newStatefulSet := func(rev, image string) *appsv1.StatefulSet {
+		statefulSet := newUpgradeTestStatefulSet(image)
+		statefulSet.ObjectMeta = metav1.ObjectMeta{Name: "splunk-stack1-indexer", Namespace: "test"}
+		statefulSet.Spec.Template.ObjectMeta.Annotations = map[string]string{defaultConfigRev: rev}
+		statefulSet.Status.Replicas = 1
+		return statefulSet
+	}
+	c.AddObject(newStatefulSet("1", newTestImage))
+
+	// other changes of the pod template recycle the pods, which picks up the defaults
+	statefulSet := newStatefulSet("2", "splunk/splunk:8.2.1")
+	err := mgr.applyRollingRestart(statefulSet)
+	if err != nil || statefulSet.Spec.Template.ObjectMeta.Annotations[defaultConfigRev] != "2" || mgr.cr.Status.RollingRestart.InProgress {
+		t.Errorf("%s started a rolling restart along with other changes: %v", method, err)
+	}
+	checksum := mgr.cr.Status.RollingRestart.DefaultsChecksum
+	if checksum == "" {
+		t.Errorf("%s did not record the checksum of the defaults", method)
+	}
+
+	// the peers of another indexer cluster of the cluster master would be restarted as well, so the pods are recycled
+	defaults.Data["default.yml"] = newDefaults("maxDataSize: auto_high_volume")
+	site2 := enterprisev1.IndexerCluster{ObjectMeta: metav1.ObjectMeta{Name: "site2", Namespace: "test"}}
+	site2.Spec.ClusterMasterRef.Name = mgr.cr.Spec.ClusterMasterRef.Name
+	c.ListObj = &enterprisev1.IndexerClusterList{Items: []enterprisev1.IndexerCluster{site2}}
+	statefulSet = newStatefulSet("2", newTestImage)
+	err = mgr.applyRollingRestart(statefulSet)
+	if err != nil || statefulSet.Spec.Template.ObjectMeta.Annotations[defaultConfigRev] != "2" || mgr.cr.Status.RollingRestart.InProgress {
+		t.Errorf("%s started a rolling restart of the peers of another indexer cluster: %v", method, err)
+	}
+	if mgr.cr.Status.RollingRestart.DefaultsChecksum != checksum {
+		t.Errorf("%s changed the checksum of the defaults for new values of the settings", method)
+	}
+	c.ListObj = &enterprisev1.IndexerClusterList{}
+
+	// a change of the values of the splunk.conf settings only is written to the peers and applied by a single rolling restart
+	for i := 0; i < 2; i++ {
+		statefulSet = newStatefulSet("2", newTestImage)
+		err = mgr.applyRollingRestart(statefulSet)
+		if err != nil {
+			t.Errorf("%s returned error: %v", method, err)
+		}
+		if rev := statefulSet.Spec.Template.ObjectMeta.Annotations[defaultConfigRev]; rev != "1" {
+			t.Errorf("%s set defaults revision %s; want 1", method, rev)
+		}
+	}
+	restartStatus := mgr.cr.Status.RollingRestart
+	if restartStatus.ConfigRev != "2" || !restartStatus.InProgress || restartStatus.StartTime == 0 || restartStatus.DefaultsChecksum != checksum {
+		t.Errorf("%s set rolling restart status %v", method, restartStatus)
+	}
+	if len(recorder.Events) != 1 {
+		t.Errorf("%s recorded %d events; want 1 RollingRestart event", method, len(recorder.Events))
+	}
+	mockSplunkClient.CheckRequests(t, method)
+
+	// splunk-ansible only applies the other changes of the defaults when the pods are recreated
+	for _, settings := range []string{"maxDataSize: auto\n            maxTotalDataSizeMB: 1000", "maxDataSize: auto\n  hec_disabled: 1"} {
+		defaults.Data["default.yml"] = newDefaults(settings)
+		statefulSet = newStatefulSet("3", newTestImage)
+		err = mgr.applyRollingRestart(statefulSet)
+		if err != nil || statefulSet.Spec.Template.ObjectMeta.Annotations[defaultConfigRev] != "3" {
+			t.Errorf("%s did not recycle the pods for defaults %q: %v", method, defaults.Data["default.yml"], err)
+		}
+		if got := mgr.cr.Status.RollingRestart.DefaultsChecksum; got == checksum {
+			t.Errorf("%s kept the checksum of the defaults for %q", method, defaults.Data["default.yml"])
+		}
+		checksum = mgr.cr.Status.RollingRestart.DefaultsChecksum
+	}
+	mockSplunkClient.CheckRequests(t, method)
+
+	// the progress of the rolling restart is tracked until the cluster master completes it
+	method = "indexerClusterPodManager.updateRollingRestartStatus"
+	test := func(body string, wantInProgress bool, wantPending []string) {
+		mockSplunkClient := &spltest.MockHTTPClient{}
+		mockSplunkClient.AddHandlers(spltest.MockHTTPHandler{
+			Method: "GET",
+			URL:    "https://splunk-master1-cluster-master-service.test.svc.cluster.local:8089/services/cluster/master/status?count=0&output_mode=json",
+			Status: 200,
+			Err:    nil,
+			Body:   body,
+		})
+		cmClient := splclient.NewSplunkClient("https://splunk-master1-cluster-master-service.test.svc.cluster.local:8089", "admin", "123")
+		cmClient.Client = mockSplunkClient
+		err := mgr.updateRollingRestartStatus(cmClient)
+		if err != nil {
+			t.Errorf("%s returned error: %v", method, err)
+		}
+		restartStatus := mgr.cr.Status.RollingRestart
+		if restartStatus.InProgress != wantInProgress || !reflect.DeepEqual(restartStatus.Pending, wantPending) {
+			t.Errorf("%s set rolling restart status %v; want inProgress %t and pending %v", method, restartStatus, wantInProgress, wantPending)
+		}
+		mockSplunkClient.CheckRequests(t, method)
+	}
+	test(`{"entry":[{"name":"master","content":{"restart_progress":{"done":["splunk-stack1-indexer-2"],"failed":[],"in_progress":["splunk-stack1-indexer-1"],"to_be_restarted":["splunk-stack1-indexer-0"]},"rolling_restart_or_upgrade":true,"searchable_rolling":true}}]}`,
+		true, []string{"splunk-stack1-indexer-1", "splunk-stack1-indexer-0"})
+	test(`{"entry":[{"name":"master","content":{"restart_progress":{"done":["splunk-stack1-indexer-2","splunk-stack1-indexer-1","splunk-stack1-indexer-0"],"failed":[],"in_progress":[],"to_be_restarted":[]},"rolling_restart_or_upgrade":false,"searchable_rolling":false}}]}`,
+		false, []string{})
+	<-recorder.Events
+	if got, want := <-recorder.Events, "Normal RollingRestart Completed searchable rolling restart of the peers"; got != want {
+		t.Errorf("%s recorded %q; want %q", method, got, want)
+	}
+}
+
+func TestGetRestartableDefaults(t *testing.T) {
+	test := func(defaults string, wantRestartable bool, want []confStanzaSettings) {
+		checksum, settings, err := getRestartableDefaults(map[string]string{"default.yml": defaults})
+		if err != nil {
+			t.Errorf("getRestartableDefaults(%q) returned error: %v", defaults, err)
+		}
+		if (checksum != "") != wantRestartable || !reflect.DeepEqual(settings, want) {
+			t.Errorf("getRestartableDefaults(%q) = %q, %v; want restartable %t and %v", defaults, checksum, settings, wantRestartable, want)
+		}
+	}
+
+	test("", true, nil)
+	test("splunk:\n  conf:\n    - key: server\n      value:\n        content:\n          general:\n            parallelIngestionPipelines: 2\n          sslConfig:\n            sslVerifyServerCert: true\n",
+		true, []confStanzaSettings{
+			{file: "server", stanza: "general", settings: map[string]string{"parallelIngestionPipelines": "2"}},
+			{file: "server", stanza: "sslConfig", settings: map[string]string{"sslVerifyServerCert": "true"}},
+		})
+	test("splunk:\n  conf:\n    - key: inputs\n      value:\n        directory: /opt/splunk/etc/apps/search/local\n        content:\n          splunktcp://9997:\n            disabled: 0\n",
+		false, nil)
+}
+
 func TestInvalidPeerInFinishRecycle(t *testing.T) {
 	var replicas int32 = 1
 	statefulSet := &appsv1.StatefulSet{
@@ -617,109 +821,131 @@ func TestIndexerClusterPodManager(t *testing.T) {
 }
 
 func TestSetClusterMaintenanceMode(t *testing.T) {
-	var initObjectList []runtime.Object
-
-	c := spltest.NewMockClient()
-
-	// Get namespace scoped secret
-	_, err := splutil.ApplyNamespaceScopedSecretObject(c, "test")
-	if err != nil {
-		t.Errorf("Apply namespace scoped secret failed")
-	}
-
-	// Create pod
-	pod := &corev1.Pod{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      "splunk-stack1-cluster-master-0",
-			Namespace: "test",
-			Labels: map[string]string{
-				"controller-revision-hash": "v0",
-			},
-		},
-		Spec: corev1.PodSpec{
-			Containers: []corev1.Container{
-				{
-					VolumeMounts: []corev1.VolumeMount{
-						{
-							MountPath: "/mnt/splunk-secrets",
-							Name:      "mnt-splunk-secrets",
-						},
-					},
-				},
-			},
-			Volumes: []corev1.Volume{
-				{
-					Name: "mnt-splunk-secrets",
-					VolumeSource: corev1.VolumeSource{
-						Secret: &corev1.SecretVolumeSource{
-							SecretName: "stack1-secrets",
-						},
-					},
-				},
-			},
-		},
-	}
-	initObjectList = append(initObjectList, pod)
-
-	secrets := &corev1.Secret{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      "stack1-secrets",
-			Namespace: "test",
-		},
-		Data: map[string][]byte{
-			"password": {'1', '2', '3'},
-		},
-	}
-	initObjectList = append(initObjectList, secrets)
-
-	c.AddObjects(initObjectList)
-
-	cr := enterprisev1.IndexerCluster{
-		TypeMeta: metav1.TypeMeta{
-			Kind: "IndexerCluster",
-		},
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      "stack1",
-			Namespace: "test",
+	method := "indexerClusterPodManager.setMaintenanceMode"
+	mockHandlers := []spltest.MockHTTPHandler{
+		{
+			Method: "POST",
+			URL:    "https://splunk-master1-cluster-master-service.test.svc.cluster.local:8089/services/cluster/master/control/default/maintenance",
+			Status: 200,
+			Err:    nil,
+			Body:   ``,
 		},
 	}
+	mockSplunkClient := &spltest.MockHTTPClient{}
+	mockSplunkClient.AddHandlers(mockHandlers...)
+	mgr := getIndexerClusterPodManager(method, mockHandlers, mockSplunkClient, 1)
+	mgr.c = spltest.NewMockClient()
 
-	cr.Spec.ClusterMasterRef.Name = cr.GetName()
 	// Enable CM maintenance mode
-	err = SetClusterMaintenanceMode(c, &cr, true, true)
+	err := mgr.setMaintenanceMode(true)
 	if err != nil {
 		t.Errorf("Couldn't enable cm maintenance mode %s", err.Error())
 	}
-
-	if cr.Status.MaintenanceMode != true {
-		t.Errorf("Couldn't enable cm maintenance mode %s", err.Error())
+	if mgr.cr.Status.MaintenanceMode != true {
+		t.Errorf("Couldn't enable cm maintenance mode")
 	}
+	mockSplunkClient.CheckRequests(t, method)
 
 	// Disable CM maintenance mode
-	err = SetClusterMaintenanceMode(c, &cr, false, true)
+	err = mgr.setMaintenanceMode(false)
 	if err != nil {
-		t.Errorf("Couldn't enable cm maintenance mode %s", err.Error())
+		t.Errorf("Couldn't disable cm maintenance mode %s", err.Error())
 	}
-
-	if cr.Status.MaintenanceMode != false {
-		t.Errorf("Couldn't enable cm maintenance mode %s", err.Error())
+	if mgr.cr.Status.MaintenanceMode != false {
+		t.Errorf("Couldn't disable cm maintenance mode")
 	}
 
-	// Disable CM maintenance mode
-	cr.Spec.ClusterMasterRef.Name = "random"
-	err = SetClusterMaintenanceMode(c, &cr, false, true)
-	if err.Error() != splcommon.PodNotFoundError {
-		t.Errorf("Couldn't enable cm maintenance mode %s", err.Error())
+	// Unreachable cluster master
+	mgr.cr.Status.MaintenanceMode = true
+	mgr.cr.Spec.ClusterMasterRef.Name = "random"
+	err = mgr.setMaintenanceMode(false)
+	if err == nil || mgr.cr.Status.MaintenanceMode != true {
+		t.Errorf("Couldn't detect unreachable cluster master")
 	}
 
 	// Empty clusterMaster reference
-	cr.Spec.ClusterMasterRef.Name = ""
-	err = SetClusterMaintenanceMode(c, &cr, false, true)
+	mgr.cr.Spec.ClusterMasterRef.Name = ""
+	err = mgr.setMaintenanceMode(false)
 	if err.Error() != splcommon.EmptyClusterMasterRef {
 		t.Errorf("Couldn't detect empty Cluster Master reference %s", err.Error())
 	}
 }
 
+func TestRecycleMaintenanceMode(t *testing.T) {
+	method := "indexerClusterPodManager.startRecycleMaintenanceMode"
+	handler := spltest.MockHTTPHandler{
+		Method: "POST",
+		URL:    "https://splunk-master1-cluster-master-service.test.svc.cluster.local:8089/services/cluster/master/control/default/maintenance",
+		Status: 200,
+		Err:    nil,
+		Body:   ``,
+	}
+	mockSplunkClient := &spltest.MockHTTPClient{}
+	mockSplunkClient.AddHandlers(handler, handler)
+	mgr := getIndexerClusterPodManager(method, nil, mockSplunkClient, 3)
+	c := spltest.NewMockClient()
+	mgr.c = c
+	recorder := record.NewFakeRecorder(10)
+	mgr.eventPublisher = splcommon.NewEventPublisher(recorder, mgr.cr)
+
+	site2 := enterprisev1.IndexerCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "site2", Namespace: "test"},
+	}
+	site2.Spec.ClusterMasterRef.Name = "master1"
+	site2.Status.RecycleMaintenanceMode = true
+	c.ListObj = &enterprisev1.IndexerClusterList{}
+
+	start := func(replicas, updatedReplicas, desiredReplicas int32, wantMaintenanceMode bool) {
+		statefulSet := &appsv1.StatefulSet{
+			Status: appsv1.StatefulSetStatus{Replicas: replicas, UpdatedReplicas: updatedReplicas},
+		}
+		err := mgr.startRecycleMaintenanceMode(statefulSet, desiredReplicas)
+		if err != nil {
+			t.Errorf("%s returned error: %v", method, err)
+		}
+		if mgr.cr.Status.RecycleMaintenanceMode != wantMaintenanceMode {
+			t.Errorf("%s set RecycleMaintenanceMode=%t for %d/%d updated replicas; want %t", method, mgr.cr.Status.RecycleMaintenanceMode, updatedReplicas, replicas, wantMaintenanceMode)
+		}
+	}
+
+	// no maintenance mode for a single peer, a scale down or updated peers
+	start(1, 0, 1, false)
+	start(3, 0, 2, false)
+	start(3, 3, 3, false)
+
+	// a maintenance mode enabled for other reasons is left alone
+	mgr.cr.Status.MaintenanceMode = true
+	start(3, 1, 3, false)
+
+	// a maintenance mode started by another indexer cluster to recycle its peers is joined
+	c.ListObj = &enterprisev1.IndexerClusterList{Items: []enterprisev1.IndexerCluster{site2}}
+	start(3, 1, 3, true)
+	err := mgr.finishRecycleMaintenanceMode()
+	if err != nil || mgr.cr.Status.RecycleMaintenanceMode || !mgr.cr.Status.MaintenanceMode {
+		t.Errorf("indexerClusterPodManager.finishRecycleMaintenanceMode() left maintenance mode while site2 recycles its peers: %v", err)
+	}
+	if len(recorder.Events) != 0 {
+		t.Errorf("%s recorded %d events while joining a maintenance mode", method, len(recorder.Events))
+	}
+
+	// the maintenance mode is started before recycling the peers, and left once they are all updated
+	mgr.cr.Status.MaintenanceMode = false
+	c.ListObj = &enterprisev1.IndexerClusterList{}
+	start(3, 1, 3, true)
+	if !mgr.cr.Status.MaintenanceMode {
+		t.Errorf("%s did not enable maintenance mode", method)
+	}
+	start(3, 2, 3, true)
+	err = mgr.finishRecycleMaintenanceMode()
+	if err != nil || mgr.cr.Status.RecycleMaintenanceMode || mgr.cr.Status.MaintenanceMode {
+		t.Errorf("indexerClusterPodManager.finishRecycleMaintenanceMode() did not leave maintenance mode: %v", err)
+	}
+	if len(recorder.Events) != 2 {
+		t.Errorf("%s recorded %d events; want 2 MaintenanceMode events", method, len(recorder.Events))
+	}
+	mockSplunkClient.CheckRequests(t, method)
+}
+
 func TestApplyIdxcSecret(t *testing.T) {
 	method := "ApplyIdxcSecret"
 	scopedLog := log.WithName(method)
@@ -816,6 +1042,12 @@ func TestApplyIdxcSecret(t *testing.T) {
 	c.AddObjects(initObjectList)
 
 	mockHandlers := []spltest.MockHTTPHandler{
+		{
+			Method: "POST",
+			URL:    "https://splunk-stack1-cluster-master-service.test.svc.cluster.local:8089/services/cluster/master/control/default/maintenance",
+			Status: 200,
+			Err:    nil,
+		},
 		{
 			Method: "POST",
 			URL:    fmt.Sprintf("https://splunk-stack1-indexer-0.splunk-stack1-indexer-headless.test.svc.cluster.local:8089/services/cluster/config/config?secret=%s", string(nsSecret.Data[splcommon.IdxcSecret])),
@@ -856,14 +1088,14 @@ func TestApplyIdxcSecret(t *testing.T) {
 	}
 
 	// Set resource version to that of NS secret
-	err = ApplyIdxcSecret(mgr, 1, true)
+	err = ApplyIdxcSecret(mgr, 1)
 	if err != nil {
 		t.Errorf("Couldn't apply idxc secret %s", err.Error())
 	}
 
 	// Change resource version
 	mgr.cr.Status.NamespaceSecretResourceVersion = "0"
-	err = ApplyIdxcSecret(mgr, 1, true)
+	err = ApplyIdxcSecret(mgr, 1)
 	if err != nil {
 		t.Errorf("Couldn't apply idxc secret %s", err.Error())
 	}
@@ -875,7 +1107,7 @@ func TestApplyIdxcSecret(t *testing.T) {
 	if err != nil {
 		t.Errorf("Couldn't update resource")
 	}
-	err = ApplyIdxcSecret(mgr, 1, true)
+	err = ApplyIdxcSecret(mgr, 1)
 	if err != nil {
 		t.Errorf("Couldn't apply idxc secret %s", err.Error())
 	}
@@ -887,7 +1119,7 @@ func TestApplyIdxcSecret(t *testing.T) {
 		t.Errorf("Couldn't update resource")
 	}
 	// Test set again
-	err = ApplyIdxcSecret(mgr, 1, true)
+	err = ApplyIdxcSecret(mgr, 1)
 	if err != nil {
 		t.Errorf("Couldn't apply idxc secret %s", err.Error())
 	}
@@ -903,7 +1135,7 @@ func TestApplyIdxcSecret(t *testing.T) {
 	mgr.cr.Spec.ClusterMasterRef.Name = ""
 	mgr.cr.Status.MaintenanceMode = false
 	mgr.cr.Status.IndexerSecretChanged = []bool{}
-	err = ApplyIdxcSecret(mgr, 1, true)
+	err = ApplyIdxcSecret(mgr, 1)
 	if err.Error() != splcommon.EmptyClusterMasterRef {
 		t.Errorf("Couldn't apply idxc secret %s", err.Error())
 	}
@@ -924,7 +1156,7 @@ func TestApplyIdxcSecret(t *testing.T) {
 		t.Errorf("Couldn't update resource")
 	}
 
-	err = ApplyIdxcSecret(mgr, 1, true)
+	err = ApplyIdxcSecret(mgr, 1)
 	if err.Error() != fmt.Sprintf(splcommon.SecretTokenNotRetrievable, splcommon.IdxcSecret) {
 		t.Errorf("Couldn't recognize missing idxc secret %s", err.Error())
 	}
@@ -937,7 +1169,7 @@ func TestApplyIdxcSecret(t *testing.T) {
 		t.Errorf("Couldn't update resource")
 	}
 
-	err = ApplyIdxcSecret(mgr, 1, true)
+	err = ApplyIdxcSecret(mgr, 1)
 	if err != nil {
 		t.Errorf("Couldn't apply idxc secret %s", err.Error())
 	}
@@ -949,7 +1181,7 @@ func TestApplyIdxcSecret(t *testing.T) {
 		t.Errorf("Couldn't update resource")
 	}
 
-	err = ApplyIdxcSecret(mgr, 1, true)
+	err = ApplyIdxcSecret(mgr, 1)
 	if err.Error() != fmt.Sprintf(splcommon.PodSecretNotFoundError, podName) {
 		t.Errorf("Couldn't recognize missing secret from Pod, error: %s", err.Error())
 	}
@@ -980,19 +1212,19 @@ func TestInvalidIndexerClusterSpec(t *testing.T) {
 	cm.Status.Phase = splcommon.PhaseReady
 	// Empty ClusterMasterRef should return an error
 	cr.Spec.ClusterMasterRef.Name = ""
-	if _, err := ApplyIndexerCluster(c, &cr); err == nil {
+	if _, err := ApplyIndexerCluster(c, nil, &cr); err == nil {
 		t.Errorf("ApplyIndxerCluster() should have returned error")
 	}
 
 	cr.Spec.ClusterMasterRef.Name = "master1"
 	// verifyRFPeers should return err here
-	if _, err := ApplyIndexerCluster(c, &cr); err == nil {
+	if _, err := ApplyIndexerCluster(c, nil, &cr); err == nil {
 		t.Errorf("ApplyIndxerCluster() should have returned error")
 	}
 
 	cm.Status.Phase = splcommon.PhaseError
 	cr.Spec.CommonSplunkSpec.EtcVolumeStorageConfig.StorageCapacity = "-abcd"
-	if _, err := ApplyIndexerCluster(c, &cr); err == nil {
+	if _, err := ApplyIndexerCluster(c, nil, &cr); err == nil {
 		t.Errorf("ApplyIndxerCluster() should have returned error")
 	}
 }
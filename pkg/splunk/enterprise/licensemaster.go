@@ -20,15 +20,17 @@ import (
 
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	enterprisev1 "github.com/splunk/splunk-operator/pkg/apis/enterprise/v1"
+	splclient "github.com/splunk/splunk-operator/pkg/splunk/client"
 	splcommon "github.com/splunk/splunk-operator/pkg/splunk/common"
 	splctrl "github.com/splunk/splunk-operator/pkg/splunk/controller"
 )
 
 // ApplyLicenseMaster reconciles the state for the Splunk Enterprise license master.
-func ApplyLicenseMaster(client splcommon.ControllerClient, cr *enterprisev1.LicenseMaster) (reconcile.Result, error) {
+func ApplyLicenseMaster(client splcommon.ControllerClient, recorder record.EventRecorder, cr *enterprisev1.LicenseMaster) (reconcile.Result, error) {
 
 	// unless modified, reconcile for this object will be requeued after 5 seconds
 	result := reconcile.Result{
@@ -36,34 +38,46 @@ func ApplyLicenseMaster(client splcommon.ControllerClient, cr *enterprisev1.Lice
 		RequeueAfter: time.Second * 5,
 	}
 
+	eventPublisher := splcommon.NewEventPublisher(recorder, cr)
+
 	// validate and updates defaults for CR
 	err := validateLicenseMasterSpec(&cr.Spec)
 	if err != nil {
+		cr.Status.Phase = splcommon.PhaseError
+		setErrorCondition(cr, &cr.Status.Conditions, "", reasonInvalidSpec, err)
+		cr.Status.ObservedGeneration = cr.GetGeneration()
+		client.Status().Update(context.TODO(), cr)
 		return result, err
 	}
 
 	// updates status after function completes
 	cr.Status.Phase = splcommon.PhaseError
 	defer func() {
+		setPhaseConditions(cr, &cr.Status.Conditions, cr.Status.Phase)
+		cr.Status.ObservedGeneration = cr.GetGeneration()
 		client.Status().Update(context.TODO(), cr)
 	}()
 
 	// create or update general config resources
-	_, err = ApplySplunkConfig(client, cr, cr.Spec.CommonSplunkSpec, SplunkLicenseMaster)
+	namespaceScopedSecret, err := ApplySplunkConfig(client, cr, cr.Spec.CommonSplunkSpec, SplunkLicenseMaster)
 	if err != nil {
+		setErrorCondition(cr, &cr.Status.Conditions, enterprisev1.ConditionSecretsSynced, reasonSecretsSyncFailed, err)
 		return result, err
 	}
+	setCondition(cr, &cr.Status.Conditions, enterprisev1.ConditionSecretsSynced, corev1.ConditionTrue, reasonSecretsSynced, "")
 
 	// check if deletion has been requested
 	if cr.ObjectMeta.DeletionTimestamp != nil {
 		err = ApplyMonitoringConsole(client, cr, cr.Spec.CommonSplunkSpec, getLicenseMasterURL(cr, &cr.Spec.CommonSplunkSpec))
 		if err != nil {
+			setErrorCondition(cr, &cr.Status.Conditions, "", reasonMonitoringConsoleFailed, err)
 			return result, err
 		}
 		DeleteOwnerReferencesForResources(client, cr, nil)
-		terminating, err := splctrl.CheckForDeletion(cr, client)
+		terminating, err := splctrl.CheckForDeletion(cr, client, eventPublisher)
 		if terminating && err != nil { // don't bother if no error, since it will just be removed immmediately after
 			cr.Status.Phase = splcommon.PhaseTerminating
+			setErrorCondition(cr, &cr.Status.Conditions, "", reasonDeletionFailed, err)
 		} else {
 			result.Requeue = false
 		}
@@ -73,17 +87,30 @@ func ApplyLicenseMaster(client splcommon.ControllerClient, cr *enterprisev1.Lice
 	// create or update a service
 	err = splctrl.ApplyService(client, getSplunkService(cr, &cr.Spec.CommonSplunkSpec, SplunkLicenseMaster, false))
 	if err != nil {
+		setErrorCondition(cr, &cr.Status.Conditions, "", reasonServiceFailed, err)
 		return result, err
 	}
 
 	// create or update statefulset
 	statefulSet, err := getLicenseMasterStatefulSet(client, cr)
 	if err != nil {
+		setErrorCondition(cr, &cr.Status.Conditions, "", reasonStatefulSetFailed, err)
+		return result, err
+	}
+	_, err = applyUpgradeOrder(client, cr, &cr.Status.Conditions, cr.Status.Image, cr.Status.Version, statefulSet, eventPublisher)
+	if err != nil {
+		setErrorCondition(cr, &cr.Status.Conditions, enterprisev1.ConditionImageUpToDate, reasonUpgradeFailed, err)
 		return result, err
 	}
-	mgr := splctrl.DefaultStatefulSetPodManager{}
+	err = applySplunkPodDisruptionBudget(client, cr, &cr.Spec.PodDisruptionBudget, getDefaultDisruptionBudget(SplunkLicenseMaster, 1, 0), statefulSet)
+	if err != nil {
+		setErrorCondition(cr, &cr.Status.Conditions, "", reasonDisruptionBudgetFailed, err)
+		return result, err
+	}
+	mgr := splctrl.DefaultStatefulSetPodManager{EventPublisher: eventPublisher}
 	phase, err := mgr.Update(client, statefulSet, 1)
 	if err != nil {
+		setErrorCondition(cr, &cr.Status.Conditions, "", reasonStatefulSetFailed, err)
 		return result, err
 	}
 	cr.Status.Phase = phase
@@ -92,10 +119,20 @@ func ApplyLicenseMaster(client splcommon.ControllerClient, cr *enterprisev1.Lice
 	if cr.Status.Phase == splcommon.PhaseReady {
 		err = ApplyMonitoringConsole(client, cr, cr.Spec.CommonSplunkSpec, getLicenseMasterURL(cr, &cr.Spec.CommonSplunkSpec))
 		if err != nil {
+			setErrorCondition(cr, &cr.Status.Conditions, "", reasonMonitoringConsoleFailed, err)
+			return result, err
+		}
+		err = updateVersion(&cr.Spec.CommonSplunkSpec, cr.Status.Image, &cr.Status.Version, statefulSet, func() *splclient.SplunkClient {
+			return getServiceClient(cr, SplunkLicenseMaster, namespaceScopedSecret)
+		})
+		if err != nil {
+			setErrorCondition(cr, &cr.Status.Conditions, "", reasonServerInfoFailed, err)
 			return result, err
 		}
+		setRunningImage(cr, &cr.Status.Conditions, &cr.Status.Image, cr.Spec.Image, statefulSet, eventPublisher)
 		result.Requeue = false
 	}
+	setReconciledCondition(cr, &cr.Status.Conditions)
 	return result, nil
 }
 
@@ -15,11 +15,13 @@
 package enterprise
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"reflect"
 	"sort"
 	"strings"
+	"time"
 
 	enterprisev1 "github.com/splunk/splunk-operator/pkg/apis/enterprise/v1"
 	splclient "github.com/splunk/splunk-operator/pkg/splunk/client"
@@ -31,10 +33,17 @@ import (
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 )
 
 // ApplyMonitoringConsole creates the statefulset for monitoring console statefulset of Splunk Enterprise.
 func ApplyMonitoringConsole(client splcommon.ControllerClient, cr splcommon.MetaObject, spec enterprisev1.CommonSplunkSpec, extraEnv []corev1.EnvVar) error {
+	// resources referring to a MonitoringConsole are only registered with it, the namespace monitoring console is not used
+	if spec.MonitoringConsoleRef.Name != "" {
+		return registerWithMonitoringConsole(client, cr, spec, extraEnv)
+	}
+
 	secrets, err := splutil.GetLatestVersionedSecret(client, cr, cr.GetNamespace(), GetSplunkStatefulsetName(SplunkMonitoringConsole, cr.GetNamespace()))
 	if err != nil {
 		return err
@@ -254,11 +263,17 @@ func getMonitoringConsoleStatefulSet(client splcommon.ControllerClient, cr splco
 
 //ApplyMonitoringConsoleEnvConfigMap creates or updates a Kubernetes ConfigMap for extra env for monitoring console pod
 func ApplyMonitoringConsoleEnvConfigMap(client splcommon.ControllerClient, namespace string, crName string, newURLs []corev1.EnvVar, addNewURLs bool) (*corev1.ConfigMap, error) {
+	return applyMonitoringConsoleEnvConfigMap(client, namespace, namespace, crName, newURLs, addNewURLs)
+}
+
+// applyMonitoringConsoleEnvConfigMap creates or updates the ConfigMap for extra env for the monitoring console pod
+// with the given identifier, which is the namespace for the monitoring console the operator creates for it
+func applyMonitoringConsoleEnvConfigMap(client splcommon.ControllerClient, namespace string, identifier string, crName string, newURLs []corev1.EnvVar, addNewURLs bool) (*corev1.ConfigMap, error) {
 
 	var current corev1.ConfigMap
 	current.Data = make(map[string]string)
 
-	configMap := GetSplunkMonitoringconsoleConfigMapName(namespace, SplunkMonitoringConsole)
+	configMap := GetSplunkMonitoringconsoleConfigMapName(identifier, SplunkMonitoringConsole)
 	namespacedName := types.NamespacedName{Namespace: namespace, Name: configMap}
 	err := client.Get(context.TODO(), namespacedName, &current)
 
@@ -381,3 +396,272 @@ func DeleteURLsConfigMap(revised *corev1.ConfigMap, crName string, newURLs []cor
 		}
 	}
 }
+
+// monitoringConsolePeerRoles maps the env variables used to register peers with a monitoring console to their role
+var monitoringConsolePeerRoles = map[string]string{
+	"SPLUNK_STANDALONE_URL":          "indexer",
+	"SPLUNK_INDEXER_URL":             "indexer",
+	"SPLUNK_SEARCH_HEAD_URL":         "search_head",
+	"SPLUNK_SEARCH_HEAD_CAPTAIN_URL": "search_head",
+	"SPLUNK_CLUSTER_MASTER_URL":      "cluster_master",
+	"SPLUNK_LICENSE_MASTER_URL":      "license_master",
+	"SPLUNK_DEPLOYER_URL":            "shc_deployer",
+	"SPLUNK_DEPLOYMENT_SERVER_URL":   "deployment_server",
+	"SPLUNK_HEAVY_FORWARDER_URL":     "heavy_forwarder",
+}
+
+// isImplicitMonitoringConsole returns true if the monitoring console is the one the operator creates for the namespace of cr,
+// as opposed to a monitoring console managed by a MonitoringConsole custom resource
+func isImplicitMonitoringConsole(cr splcommon.MetaObject, instanceType InstanceType) bool {
+	return instanceType == SplunkMonitoringConsole && cr.GetObjectKind().GroupVersionKind().Kind != "MonitoringConsole"
+}
+
+// registerWithMonitoringConsole adds (or removes, when deletion has been requested) the instances of cr to the peers
+// of the MonitoringConsole custom resource it refers to
+func registerWithMonitoringConsole(client splcommon.ControllerClient, cr splcommon.MetaObject, spec enterprisev1.CommonSplunkSpec, extraEnv []corev1.EnvVar) error {
+	addNewURLs := cr.GetObjectMeta().GetDeletionTimestamp() == nil
+	mcName := spec.MonitoringConsoleRef.Name
+	mcNamespace := spec.MonitoringConsoleRef.Namespace
+	if mcNamespace == "" {
+		mcNamespace = cr.GetNamespace()
+	}
+
+	// instances are still removed on deletion, even if they can no longer be reached
+	if addNewURLs && mcNamespace != cr.GetNamespace() {
+		err := checkMonitoringConsolePassword(client, cr.GetNamespace(), mcNamespace)
+		if err != nil {
+			return err
+		}
+	}
+
+	//For IndexerCluster custom resource click "Apply changes" on MC and return
+	if cr.GetObjectKind().GroupVersionKind().Kind == "IndexerCluster" {
+		secrets, err := splutil.GetNamespaceScopedSecret(client, mcNamespace)
+		if err != nil {
+			return err
+		}
+		fqdnName := splcommon.GetServiceFQDN(mcNamespace, GetSplunkServiceName(SplunkMonitoringConsole, mcName, false))
+		c := splclient.NewSplunkClient(fmt.Sprintf("https://%s:8089", fqdnName), "admin", string(secrets.Data["password"]))
+		return c.AutomateMCApplyChanges(spec.Mock)
+	}
+
+	//get cluster info from cluster master
+	if cr.GetObjectKind().GroupVersionKind().Kind == "ClusterMaster" && !spec.Mock {
+		secrets, err := splutil.GetNamespaceScopedSecret(client, cr.GetNamespace())
+		if err != nil {
+			return err
+		}
+		mgr := monitoringConsolePodManager{cr: &cr, spec: &spec, secrets: secrets, newSplunkClient: splclient.NewSplunkClient}
+		clusterInfo, err := mgr.getClusterMasterClient(cr).GetClusterInfo(spec.Mock)
+		if err != nil {
+			return err
+		}
+		if clusterInfo.MultiSite == "true" {
+			clusterMasterURL := splcommon.GetServiceFQDN(cr.GetNamespace(), GetSplunkServiceName(SplunkClusterMaster, cr.GetName(), false))
+			extraEnv = append(extraEnv, corev1.EnvVar{Name: "SPLUNK_SITE", Value: "site0"}, corev1.EnvVar{Name: "SPLUNK_MULTISITE_MASTER", Value: clusterMasterURL})
+		}
+	}
+
+	_, err := applyMonitoringConsoleEnvConfigMap(client, mcNamespace, mcName, cr.GetName(), extraEnv, addNewURLs)
+	return err
+}
+
+// ApplyMonitoringConsoleCR reconciles the state of a Splunk Enterprise monitoring console managed by a MonitoringConsole custom resource.
+func ApplyMonitoringConsoleCR(client splcommon.ControllerClient, recorder record.EventRecorder, cr *enterprisev1.MonitoringConsole) (reconcile.Result, error) {
+
+	// unless modified, reconcile for this object will be requeued after 5 seconds
+	result := reconcile.Result{
+		Requeue:      true,
+		RequeueAfter: time.Second * 5,
+	}
+
+	eventPublisher := splcommon.NewEventPublisher(recorder, cr)
+
+	// validate and updates defaults for CR
+	err := validateMonitoringConsoleSpec(cr)
+	if err != nil {
+		cr.Status.Phase = splcommon.PhaseError
+		setErrorCondition(cr, &cr.Status.Conditions, "", reasonInvalidSpec, err)
+		cr.Status.ObservedGeneration = cr.GetGeneration()
+		client.Status().Update(context.TODO(), cr)
+		return result, err
+	}
+
+	// updates status after function completes
+	cr.Status.Phase = splcommon.PhaseError
+	defer func() {
+		setPhaseConditions(cr, &cr.Status.Conditions, cr.Status.Phase)
+		cr.Status.ObservedGeneration = cr.GetGeneration()
+		client.Status().Update(context.TODO(), cr)
+	}()
+
+	// create or update general config resources
+	namespaceScopedSecret, err := ApplySplunkConfig(client, cr, cr.Spec.CommonSplunkSpec, SplunkMonitoringConsole)
+	if err != nil {
+		setErrorCondition(cr, &cr.Status.Conditions, enterprisev1.ConditionSecretsSynced, reasonSecretsSyncFailed, err)
+		return result, err
+	}
+	setCondition(cr, &cr.Status.Conditions, enterprisev1.ConditionSecretsSynced, corev1.ConditionTrue, reasonSecretsSynced, "")
+
+	// check if deletion has been requested
+	if cr.ObjectMeta.DeletionTimestamp != nil {
+		DeleteOwnerReferencesForResources(client, cr, nil)
+		terminating, err := splctrl.CheckForDeletion(cr, client, eventPublisher)
+		if terminating && err != nil { // don't bother if no error, since it will just be removed immmediately after
+			cr.Status.Phase = splcommon.PhaseTerminating
+			setErrorCondition(cr, &cr.Status.Conditions, "", reasonDeletionFailed, err)
+		} else {
+			result.Requeue = false
+		}
+		return result, err
+	}
+
+	setLicenseCondition(client, cr, &cr.Status.Conditions, cr.Spec.LicenseMasterRef)
+
+	// create the configMap holding the peers, which is updated by the resources referring to this monitoring console
+	configMap, err := applyMonitoringConsoleEnvConfigMap(client, cr.GetNamespace(), cr.GetName(), cr.GetName(), []corev1.EnvVar{}, true)
+	if err != nil {
+		setErrorCondition(cr, &cr.Status.Conditions, "", reasonConfigMapFailed, err)
+		return result, err
+	}
+	err = splctrl.SetConfigMapOwnerRef(client, cr, types.NamespacedName{Namespace: cr.GetNamespace(), Name: configMap.GetName()})
+	if err != nil {
+		setErrorCondition(cr, &cr.Status.Conditions, "", reasonConfigMapFailed, err)
+		return result, err
+	}
+	cr.Status.Peers = getMonitoringConsolePeers(configMap)
+
+	// create or update a headless service
+	err = splctrl.ApplyService(client, getSplunkService(cr, &cr.Spec.CommonSplunkSpec, SplunkMonitoringConsole, true))
+	if err != nil {
+		setErrorCondition(cr, &cr.Status.Conditions, "", reasonServiceFailed, err)
+		return result, err
+	}
+
+	// create or update a regular service
+	err = splctrl.ApplyService(client, getSplunkService(cr, &cr.Spec.CommonSplunkSpec, SplunkMonitoringConsole, false))
+	if err != nil {
+		setErrorCondition(cr, &cr.Status.Conditions, "", reasonServiceFailed, err)
+		return result, err
+	}
+
+	// create or update statefulset
+	statefulSet, err := getMonitoringConsoleCRStatefulSet(client, cr)
+	if err != nil {
+		setErrorCondition(cr, &cr.Status.Conditions, "", reasonStatefulSetFailed, err)
+		return result, err
+	}
+	_, err = applyUpgradeOrder(client, cr, &cr.Status.Conditions, cr.Status.Image, cr.Status.Version, statefulSet, eventPublisher)
+	if err != nil {
+		setErrorCondition(cr, &cr.Status.Conditions, enterprisev1.ConditionImageUpToDate, reasonUpgradeFailed, err)
+		return result, err
+	}
+	err = applySplunkPodDisruptionBudget(client, cr, &cr.Spec.PodDisruptionBudget, getDefaultDisruptionBudget(SplunkMonitoringConsole, 1, 0), statefulSet)
+	if err != nil {
+		setErrorCondition(cr, &cr.Status.Conditions, "", reasonDisruptionBudgetFailed, err)
+		return result, err
+	}
+	mgr := splctrl.DefaultStatefulSetPodManager{EventPublisher: eventPublisher}
+	phase, err := mgr.Update(client, statefulSet, 1)
+	if err != nil {
+		setErrorCondition(cr, &cr.Status.Conditions, "", reasonStatefulSetFailed, err)
+		return result, err
+	}
+	cr.Status.Phase = phase
+
+	// no need to requeue if everything is ready
+	if cr.Status.Phase == splcommon.PhaseReady {
+		err = updateVersion(&cr.Spec.CommonSplunkSpec, cr.Status.Image, &cr.Status.Version, statefulSet, func() *splclient.SplunkClient { return getServiceClient(cr, SplunkMonitoringConsole, namespaceScopedSecret) })
+		if err != nil {
+			setErrorCondition(cr, &cr.Status.Conditions, "", reasonServerInfoFailed, err)
+			return result, err
+		}
+		setRunningImage(cr, &cr.Status.Conditions, &cr.Status.Image, cr.Spec.Image, statefulSet, eventPublisher)
+		result.Requeue = false
+	}
+	setReconciledCondition(cr, &cr.Status.Conditions)
+	return result, nil
+}
+
+// getMonitoringConsoleCRStatefulSet returns a Kubernetes StatefulSet object for a MonitoringConsole custom resource.
+func getMonitoringConsoleCRStatefulSet(client splcommon.ControllerClient, cr *enterprisev1.MonitoringConsole) (*appsv1.StatefulSet, error) {
+	ss, err := getSplunkStatefulSet(client, cr, &cr.Spec.CommonSplunkSpec, SplunkMonitoringConsole, 1, []corev1.EnvVar{})
+	if err != nil {
+		return nil, err
+	}
+
+	// peers are passed to the monitoring console through the env variables of its configMap
+	configMapName := GetSplunkMonitoringconsoleConfigMapName(cr.GetName(), SplunkMonitoringConsole)
+	monitoringConsoleConfigMap, err := splctrl.GetConfigMap(client, types.NamespacedName{Namespace: cr.GetNamespace(), Name: configMapName})
+	if err != nil {
+		return nil, err
+	}
+	ss.Spec.Template.Spec.Containers[0].EnvFrom = []corev1.EnvFromSource{
+		{
+			ConfigMapRef: &corev1.ConfigMapEnvSource{
+				LocalObjectReference: corev1.LocalObjectReference{
+					Name: configMapName,
+				},
+			},
+		},
+	}
+	ss.Spec.Template.ObjectMeta.Annotations[monitoringConsoleConfigRev] = monitoringConsoleConfigMap.ResourceVersion
+
+	return ss, nil
+}
+
+// getMonitoringConsolePeers returns the peers registered in the env configMap of a monitoring console, with their role
+func getMonitoringConsolePeers(configMap *corev1.ConfigMap) []enterprisev1.MonitoringConsolePeerStatus {
+	peers := []enterprisev1.MonitoringConsolePeerStatus{}
+	found := make(map[enterprisev1.MonitoringConsolePeerStatus]bool)
+	for envName, urls := range configMap.Data {
+		role, ok := monitoringConsolePeerRoles[envName]
+		if !ok {
+			continue
+		}
+		for _, url := range strings.Split(urls, ",") {
+			peer := enterprisev1.MonitoringConsolePeerStatus{Name: url, Role: role}
+			if url != "" && !found[peer] {
+				found[peer] = true
+				peers = append(peers, peer)
+			}
+		}
+	}
+	sort.Slice(peers, func(i, j int) bool {
+		if peers[i].Name == peers[j].Name {
+			return peers[i].Role < peers[j].Role
+		}
+		return peers[i].Name < peers[j].Name
+	})
+	return peers
+}
+
+// validateMonitoringConsoleSpec checks validity and makes default updates to a MonitoringConsoleSpec, and returns error if something is wrong.
+func validateMonitoringConsoleSpec(cr *enterprisev1.MonitoringConsole) error {
+	// the monitoring console the operator creates for a namespace is named after the namespace
+	if cr.GetName() == cr.GetNamespace() {
+		return fmt.Errorf("MonitoringConsole can not be named after its namespace %s, which is the name of the monitoring console of the namespace", cr.GetNamespace())
+	}
+	if cr.Spec.MonitoringConsoleRef.Name != "" {
+		return fmt.Errorf("monitoringConsoleRef can not be used by a monitoring console")
+	}
+	return validateCommonSplunkSpec(&cr.Spec.CommonSplunkSpec)
+}
+
+// checkMonitoringConsolePassword returns an error unless the namespace scoped secrets of namespace and of the namespace
+// of a MonitoringConsole have the same admin password, which the monitoring console uses to reach its peers
+func checkMonitoringConsolePassword(client splcommon.ControllerClient, namespace, mcNamespace string) error {
+	secrets, err := splutil.GetNamespaceScopedSecret(client, namespace)
+	if err != nil {
+		return err
+	}
+	mcSecrets, err := splutil.GetNamespaceScopedSecret(client, mcNamespace)
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(secrets.Data["password"], mcSecrets.Data["password"]) {
+		return fmt.Errorf("monitoringConsoleRef refers to a MonitoringConsole in namespace %s, whose secret %s does not have the admin password of secret %s",
+			mcNamespace, splcommon.GetNamespaceScopedSecretName(mcNamespace), splcommon.GetNamespaceScopedSecretName(namespace))
+	}
+	return nil
+}
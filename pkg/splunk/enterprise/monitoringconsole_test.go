@@ -16,14 +16,19 @@ package enterprise
 
 import (
 	"fmt"
+	"reflect"
 	"testing"
+	"time"
 
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	enterprisev1 "github.com/splunk/splunk-operator/pkg/apis/enterprise/v1"
+	splcommon "github.com/splunk/splunk-operator/pkg/splunk/common"
 	splctrl "github.com/splunk/splunk-operator/pkg/splunk/controller"
 	spltest "github.com/splunk/splunk-operator/pkg/splunk/test"
 	splutil "github.com/splunk/splunk-operator/pkg/splunk/util"
@@ -250,3 +255,226 @@ func TestGetMonitoringConsoleStatefulSet(t *testing.T) {
 
 	test(`{"kind":"StatefulSet","apiVersion":"apps/v1","metadata":{"name":"splunk-test-monitoring-console","namespace":"test","creationTimestamp":null},"spec":{"replicas":1,"selector":{"matchLabels":{"app.kubernetes.io/component":"monitoring-console","app.kubernetes.io/instance":"splunk-test-monitoring-console","app.kubernetes.io/managed-by":"splunk-operator","app.kubernetes.io/name":"monitoring-console","app.kubernetes.io/part-of":"splunk-test-monitoring-console"}},"template":{"metadata":{"creationTimestamp":null,"labels":{"app.kubernetes.io/component":"monitoring-console","app.kubernetes.io/instance":"splunk-test-monitoring-console","app.kubernetes.io/managed-by":"splunk-operator","app.kubernetes.io/name":"monitoring-console","app.kubernetes.io/part-of":"splunk-test-monitoring-console"},"annotations":{"monitoringConsoleConfigRev":"","traffic.sidecar.istio.io/excludeOutboundPorts":"8089,8191,9997","traffic.sidecar.istio.io/includeInboundPorts":"8000,8088"}},"spec":{"volumes":[{"name":"mnt-splunk-etc","emptyDir":{}},{"name":"mnt-splunk-var","emptyDir":{}},{"name":"mnt-splunk-secrets","secret":{"secretName":"splunk-test-secret","defaultMode":420}}],"containers":[{"name":"splunk","image":"splunk/splunk","ports":[{"name":"http-splunkweb","containerPort":8000,"protocol":"TCP"},{"name":"http-hec","containerPort":8088,"protocol":"TCP"},{"name":"https-splunkd","containerPort":8089,"protocol":"TCP"},{"name":"tcp-s2s","containerPort":9997,"protocol":"TCP"}],"envFrom":[{"configMapRef":{"name":"splunk-test-monitoring-console"}}],"env":[{"name":"SPLUNK_HOME","value":"/opt/splunk"},{"name":"SPLUNK_START_ARGS","value":"--accept-license"},{"name":"SPLUNK_DEFAULTS_URL","value":"/mnt/splunk-secrets/default.yml"},{"name":"SPLUNK_HOME_OWNERSHIP_ENFORCEMENT","value":"false"},{"name":"SPLUNK_ROLE","value":"splunk_monitor"},{"name":"SPLUNK_DECLARATIVE_ADMIN_PASSWORD","value":"true"},{"name":"SPLUNK_CLUSTER_MASTER_URL","value":"splunk-stack1-cluster-master-service"}],"resources":{"limits":{"cpu":"4","memory":"8Gi"},"requests":{"cpu":"100m","memory":"512Mi"}},"volumeMounts":[{"name":"mnt-splunk-etc","mountPath":"/opt/splunk/etc"},{"name":"mnt-splunk-var","mountPath":"/opt/splunk/var"},{"name":"mnt-splunk-secrets","mountPath":"/mnt/splunk-secrets"}],"livenessProbe":{"exec":{"command":["/sbin/checkstate.sh"]},"initialDelaySeconds":300,"timeoutSeconds":30,"periodSeconds":30},"readinessProbe":{"exec":{"command":["/bin/grep","started","/opt/container_artifact/splunk-container.state"]},"initialDelaySeconds":10,"timeoutSeconds":5,"periodSeconds":5},"imagePullPolicy":"IfNotPresent"}],"securityContext":{"runAsUser":41812,"fsGroup":41812},"affinity":{"podAntiAffinity":{"preferredDuringSchedulingIgnoredDuringExecution":[{"weight":100,"podAffinityTerm":{"labelSelector":{"matchExpressions":[{"key":"app.kubernetes.io/instance","operator":"In","values":["splunk-test-monitoring-console"]}]},"topologyKey":"kubernetes.io/hostname"}}]}},"schedulerName":"default-scheduler"}},"serviceName":"splunk-test-monitoring-console-headless","podManagementPolicy":"Parallel","updateStrategy":{"type":"OnDelete"}},"status":{"replicas":0}}`)
 }
+
+func TestApplyMonitoringConsoleWithRef(t *testing.T) {
+	standaloneCR := enterprisev1.Standalone{
+		TypeMeta: metav1.TypeMeta{
+			Kind: "Standalone",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "stack1",
+			Namespace: "test",
+		},
+		Spec: enterprisev1.StandaloneSpec{
+			Replicas: 2,
+			CommonSplunkSpec: enterprisev1.CommonSplunkSpec{
+				MonitoringConsoleRef: corev1.ObjectReference{
+					Name:      "mc1",
+					Namespace: "monitoring",
+				},
+			},
+		},
+	}
+
+	// a monitoring console in another namespace reaches the instances with the admin password of its namespace
+	c := spltest.NewMockClient()
+	addSecret := func(namespace, password string) {
+		c.AddObject(&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: splcommon.GetNamespaceScopedSecretName(namespace), Namespace: namespace},
+			Data:       map[string][]byte{"password": []byte(password)},
+		})
+	}
+	addSecret("test", "helloworld")
+	addSecret("monitoring", "changeme")
+	err := ApplyMonitoringConsole(c, &standaloneCR, standaloneCR.Spec.CommonSplunkSpec, getStandaloneExtraEnv(&standaloneCR, standaloneCR.Spec.Replicas))
+	if err == nil {
+		t.Errorf("ApplyMonitoringConsole() returned nil; want error for a monitoring console with another admin password")
+	}
+	if _, err := splctrl.GetConfigMap(c, types.NamespacedName{Namespace: "monitoring", Name: "splunk-mc1-monitoring-console"}); err == nil {
+		t.Errorf("ApplyMonitoringConsole() registered the instances with a monitoring console that can not reach them")
+	}
+
+	// only the configMap of the referenced monitoring console is updated
+	addSecret("monitoring", "helloworld")
+	c.Calls = make(map[string][]spltest.MockFuncCall)
+	err = ApplyMonitoringConsole(c, &standaloneCR, standaloneCR.Spec.CommonSplunkSpec, getStandaloneExtraEnv(&standaloneCR, standaloneCR.Spec.Replicas))
+	if err != nil {
+		t.Errorf("ApplyMonitoringConsole() returned error: %v", err)
+	}
+	c.CheckCalls(t, "TestApplyMonitoringConsoleWithRef", map[string][]spltest.MockFuncCall{
+		"Get": {
+			{MetaName: "*v1.Secret-test-splunk-test-secret"},
+			{MetaName: "*v1.Secret-monitoring-splunk-monitoring-secret"},
+			{MetaName: "*v1.ConfigMap-monitoring-splunk-mc1-monitoring-console"},
+		},
+		"Create": {{MetaName: "*v1.ConfigMap-monitoring-splunk-mc1-monitoring-console"}},
+	})
+	configMap, err := splctrl.GetConfigMap(c, types.NamespacedName{Namespace: "monitoring", Name: "splunk-mc1-monitoring-console"})
+	if err != nil {
+		t.Errorf("Failed to get monitoring console configMap: %v", err)
+	}
+	want := "splunk-stack1-standalone-0.splunk-stack1-standalone-headless.test.svc.cluster.local,splunk-stack1-standalone-1.splunk-stack1-standalone-headless.test.svc.cluster.local"
+	if configMap.Data["SPLUNK_STANDALONE_URL"] != want {
+		t.Errorf("SPLUNK_STANDALONE_URL=%s; want %s", configMap.Data["SPLUNK_STANDALONE_URL"], want)
+	}
+
+	// deregister when deletion is requested, even if the admin passwords no longer match
+	addSecret("monitoring", "changeme")
+	currentTime := metav1.NewTime(time.Now())
+	standaloneCR.ObjectMeta.DeletionTimestamp = &currentTime
+	err = ApplyMonitoringConsole(c, &standaloneCR, standaloneCR.Spec.CommonSplunkSpec, getStandaloneExtraEnv(&standaloneCR, standaloneCR.Spec.Replicas))
+	if err != nil {
+		t.Errorf("ApplyMonitoringConsole() returned error: %v", err)
+	}
+	configMap, _ = splctrl.GetConfigMap(c, types.NamespacedName{Namespace: "monitoring", Name: "splunk-mc1-monitoring-console"})
+	if _, ok := configMap.Data["SPLUNK_STANDALONE_URL"]; ok {
+		t.Errorf("SPLUNK_STANDALONE_URL=%s; want none", configMap.Data["SPLUNK_STANDALONE_URL"])
+	}
+}
+
+func TestApplyMonitoringConsoleCR(t *testing.T) {
+	funcCalls := []spltest.MockFuncCall{
+		{MetaName: "*v1.Secret-test-splunk-test-secret"},
+		{MetaName: "*v1.Secret-test-splunk-test-secret"},
+		{MetaName: "*v1.ConfigMap-test-splunk-stack1-monitoring-console"},
+		{MetaName: "*v1.ConfigMap-test-splunk-stack1-monitoring-console"},
+		{MetaName: "*v1.Service-test-splunk-stack1-monitoring-console-headless"},
+		{MetaName: "*v1.Service-test-splunk-stack1-monitoring-console-service"},
+		{MetaName: "*v1.Secret-test-splunk-test-secret"},
+		{MetaName: "*v1.Secret-test-splunk-stack1-monitoring-console-secret-v1"},
+		{MetaName: "*v1.ConfigMap-test-splunk-stack1-monitoring-console"},
+		{MetaName: "*v1beta1.PodDisruptionBudget-test-splunk-stack1-monitoring-console"},
+		{MetaName: "*v1.StatefulSet-test-splunk-stack1-monitoring-console"},
+	}
+	labels := map[string]string{
+		"app.kubernetes.io/component":  "versionedSecrets",
+		"app.kubernetes.io/managed-by": "splunk-operator",
+	}
+	listOpts := []client.ListOption{
+		client.InNamespace("test"),
+		client.MatchingLabels(labels),
+	}
+	listmockCall := []spltest.MockFuncCall{
+		{ListOpts: listOpts}}
+
+	createCalls := map[string][]spltest.MockFuncCall{"Get": funcCalls, "Create": {funcCalls[0], funcCalls[2], funcCalls[4], funcCalls[5], funcCalls[7], funcCalls[10]}, "Update": {funcCalls[0], funcCalls[2]}, "List": {listmockCall[0]}}
+	updateCalls := map[string][]spltest.MockFuncCall{"Get": funcCalls, "Update": {funcCalls[10]}, "List": {listmockCall[0]}}
+	current := enterprisev1.MonitoringConsole{
+		TypeMeta: metav1.TypeMeta{
+			Kind: "MonitoringConsole",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "stack1",
+			Namespace: "test",
+		},
+	}
+	revised := current.DeepCopy()
+	revised.Spec.Image = "splunk/test"
+	reconcile := func(c *spltest.MockClient, cr interface{}) error {
+		_, err := ApplyMonitoringConsoleCR(c, nil, cr.(*enterprisev1.MonitoringConsole))
+		return err
+	}
+	spltest.ReconcileTesterWithoutRedundantCheck(t, "TestApplyMonitoringConsoleCR", &current, revised, createCalls, updateCalls, reconcile, true)
+
+	// test deletion
+	currentTime := metav1.NewTime(time.Now())
+	revised.ObjectMeta.DeletionTimestamp = &currentTime
+	revised.ObjectMeta.Finalizers = []string{"enterprise.splunk.com/delete-pvc"}
+	deleteFunc := func(cr splcommon.MetaObject, c splcommon.ControllerClient) (bool, error) {
+		_, err := ApplyMonitoringConsoleCR(c, nil, cr.(*enterprisev1.MonitoringConsole))
+		return true, err
+	}
+	splunkDeletionTester(t, revised, deleteFunc)
+}
+
+func TestGetMonitoringConsoleCRStatefulSet(t *testing.T) {
+	cr := enterprisev1.MonitoringConsole{
+		TypeMeta: metav1.TypeMeta{
+			Kind: "MonitoringConsole",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "stack1",
+			Namespace: "test",
+		},
+	}
+	cr.Spec.Resources = corev1.ResourceRequirements{
+		Requests: corev1.ResourceList{
+			corev1.ResourceCPU:    resource.MustParse("1"),
+			corev1.ResourceMemory: resource.MustParse("2Gi"),
+		},
+	}
+	cr.Spec.EtcVolumeStorageConfig.StorageClassName = "gp2"
+	cr.Spec.VarVolumeStorageConfig.StorageCapacity = "50Gi"
+
+	c := spltest.NewMockClient()
+	_, err := splutil.ApplyNamespaceScopedSecretObject(c, "test")
+	if err != nil {
+		t.Errorf("Failed to create namespace scoped object")
+	}
+
+	test := func(want string) {
+		f := func() (interface{}, error) {
+			if err := validateMonitoringConsoleSpec(&cr); err != nil {
+				t.Errorf("validateMonitoringConsoleSpec() returned error: %v", err)
+			}
+			return getMonitoringConsoleCRStatefulSet(c, &cr)
+		}
+		configTester(t, "getMonitoringConsoleCRStatefulSet()", f, want)
+	}
+
+	// the env configMap must exist before the statefulset is created
+	_, err = getMonitoringConsoleCRStatefulSet(c, &cr)
+	if err == nil {
+		t.Errorf("getMonitoringConsoleCRStatefulSet() returned nil; want error")
+	}
+
+	c.AddObject(&corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "splunk-stack1-monitoring-console",
+			Namespace: "test",
+		},
+	})
+	test(`{"kind":"StatefulSet","apiVersion":"apps/v1","metadata":{"name":"splunk-stack1-monitoring-console","namespace":"test","creationTimestamp":null,"ownerReferences":[{"apiVersion":"","kind":"MonitoringConsole","name":"stack1","uid":"","controller":true}]},"spec":{"replicas":1,"selector":{"matchLabels":{"app.kubernetes.io/component":"monitoring-console","app.kubernetes.io/instance":"splunk-stack1-monitoring-console","app.kubernetes.io/managed-by":"splunk-operator","app.kubernetes.io/name":"monitoring-console","app.kubernetes.io/part-of":"splunk-stack1-monitoring-console"}},"template":{"metadata":{"creationTimestamp":null,"labels":{"app.kubernetes.io/component":"monitoring-console","app.kubernetes.io/instance":"splunk-stack1-monitoring-console","app.kubernetes.io/managed-by":"splunk-operator","app.kubernetes.io/name":"monitoring-console","app.kubernetes.io/part-of":"splunk-stack1-monitoring-console"},"annotations":{"monitoringConsoleConfigRev":"","traffic.sidecar.istio.io/excludeOutboundPorts":"8089,8191,9997","traffic.sidecar.istio.io/includeInboundPorts":"8000,8088"}},"spec":{"volumes":[{"name":"mnt-splunk-secrets","secret":{"secretName":"splunk-stack1-monitoring-console-secret-v1","defaultMode":420}}],"containers":[{"name":"splunk","image":"splunk/splunk","ports":[{"name":"http-splunkweb","containerPort":8000,"protocol":"TCP"},{"name":"http-hec","containerPort":8088,"protocol":"TCP"},{"name":"https-splunkd","containerPort":8089,"protocol":"TCP"},{"name":"tcp-s2s","containerPort":9997,"protocol":"TCP"}],"envFrom":[{"configMapRef":{"name":"splunk-stack1-monitoring-console"}}],"env":[{"name":"SPLUNK_HOME","value":"/opt/splunk"},{"name":"SPLUNK_START_ARGS","value":"--accept-license"},{"name":"SPLUNK_DEFAULTS_URL","value":"/mnt/splunk-secrets/default.yml"},{"name":"SPLUNK_HOME_OWNERSHIP_ENFORCEMENT","value":"false"},{"name":"SPLUNK_ROLE","value":"splunk_monitor"},{"name":"SPLUNK_DECLARATIVE_ADMIN_PASSWORD","value":"true"}],"resources":{"limits":{"cpu":"4","memory":"8Gi"},"requests":{"cpu":"1","memory":"2Gi"}},"volumeMounts":[{"name":"pvc-etc","mountPath":"/opt/splunk/etc"},{"name":"pvc-var","mountPath":"/opt/splunk/var"},{"name":"mnt-splunk-secrets","mountPath":"/mnt/splunk-secrets"}],"livenessProbe":{"exec":{"command":["/sbin/checkstate.sh"]},"initialDelaySeconds":300,"timeoutSeconds":30,"periodSeconds":30},"readinessProbe":{"exec":{"command":["/bin/grep","started","/opt/container_artifact/splunk-container.state"]},"initialDelaySeconds":10,"timeoutSeconds":5,"periodSeconds":5},"imagePullPolicy":"IfNotPresent"}],"securityContext":{"runAsUser":41812,"fsGroup":41812},"affinity":{"podAntiAffinity":{"preferredDuringSchedulingIgnoredDuringExecution":[{"weight":100,"podAffinityTerm":{"labelSelector":{"matchExpressions":[{"key":"app.kubernetes.io/instance","operator":"In","values":["splunk-stack1-monitoring-console"]}]},"topologyKey":"kubernetes.io/hostname"}}]}},"schedulerName":"default-scheduler"}},"volumeClaimTemplates":[{"metadata":{"name":"pvc-etc","namespace":"test","creationTimestamp":null,"labels":{"app.kubernetes.io/component":"monitoring-console","app.kubernetes.io/instance":"splunk-stack1-monitoring-console","app.kubernetes.io/managed-by":"splunk-operator","app.kubernetes.io/name":"monitoring-console","app.kubernetes.io/part-of":"splunk-stack1-monitoring-console"}},"spec":{"accessModes":["ReadWriteOnce"],"resources":{"requests":{"storage":"10Gi"}},"storageClassName":"gp2"},"status":{}},{"metadata":{"name":"pvc-var","namespace":"test","creationTimestamp":null,"labels":{"app.kubernetes.io/component":"monitoring-console","app.kubernetes.io/instance":"splunk-stack1-monitoring-console","app.kubernetes.io/managed-by":"splunk-operator","app.kubernetes.io/name":"monitoring-console","app.kubernetes.io/part-of":"splunk-stack1-monitoring-console"}},"spec":{"accessModes":["ReadWriteOnce"],"resources":{"requests":{"storage":"50Gi"}}},"status":{}}],"serviceName":"splunk-stack1-monitoring-console-headless","podManagementPolicy":"Parallel","updateStrategy":{"type":"OnDelete"}},"status":{"replicas":0}}`)
+}
+
+func TestGetMonitoringConsolePeers(t *testing.T) {
+	configMap := corev1.ConfigMap{
+		Data: map[string]string{
+			"SPLUNK_SEARCH_HEAD_URL":         "splunk-stack1-search-head-0,splunk-stack1-search-head-1",
+			"SPLUNK_SEARCH_HEAD_CAPTAIN_URL": "splunk-stack1-search-head-0",
+			"SPLUNK_CLUSTER_MASTER_URL":      "splunk-stack1-cluster-master-service",
+			"SPLUNK_STANDALONE_URL":          "",
+			"SPLUNK_SITE":                    "site0",
+		},
+	}
+	want := []enterprisev1.MonitoringConsolePeerStatus{
+		{Name: "splunk-stack1-cluster-master-service", Role: "cluster_master"},
+		{Name: "splunk-stack1-search-head-0", Role: "search_head"},
+		{Name: "splunk-stack1-search-head-1", Role: "search_head"},
+	}
+	got := getMonitoringConsolePeers(&configMap)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("getMonitoringConsolePeers()=%v; want %v", got, want)
+	}
+}
+
+func TestValidateMonitoringConsoleSpec(t *testing.T) {
+	cr := enterprisev1.MonitoringConsole{
+		ObjectMeta: metav1.ObjectMeta{Name: "mc1", Namespace: "test"},
+	}
+	if err := validateMonitoringConsoleSpec(&cr); err != nil {
+		t.Errorf("validateMonitoringConsoleSpec() returned error: %v", err)
+	}
+
+	cr.Spec.MonitoringConsoleRef.Name = "mc1"
+	if err := validateMonitoringConsoleSpec(&cr); err == nil {
+		t.Errorf("validateMonitoringConsoleSpec() returned nil; want error")
+	}
+
+	// the name of the monitoring console of the namespace is taken
+	cr.Spec.MonitoringConsoleRef.Name = ""
+	cr.ObjectMeta.Name = "test"
+	if err := validateMonitoringConsoleSpec(&cr); err == nil {
+		t.Errorf("validateMonitoringConsoleSpec() returned nil; want error for a MonitoringConsole named after its namespace")
+	}
+}
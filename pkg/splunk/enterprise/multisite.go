@@ -0,0 +1,276 @@
+// Copyright (c) 2018-2021 Splunk Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package enterprise
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	enterprisev1 "github.com/splunk/splunk-operator/pkg/apis/enterprise/v1"
+	splcommon "github.com/splunk/splunk-operator/pkg/splunk/common"
+)
+
+// defaultZoneLabel is the node label used to map sites to Kubernetes zones, unless another one is specified
+const defaultZoneLabel = "topology.kubernetes.io/zone"
+
+// isMultisiteConfigured returns true if any field of a MultisiteSpec is set
+func isMultisiteConfigured(multisite *enterprisev1.MultisiteSpec) bool {
+	return len(multisite.Sites) > 0 || multisite.Site != "" ||
+		multisite.SiteReplicationFactor != enterprisev1.SiteFactorSpec{} ||
+		multisite.SiteSearchFactor != enterprisev1.SiteFactorSpec{} ||
+		multisite.ZoneLabel != "" || len(multisite.SiteZones) > 0
+}
+
+// validateMultisiteSpec checks validity and makes default updates to a MultisiteSpec, and returns error if something is wrong.
+func validateMultisiteSpec(multisite *enterprisev1.MultisiteSpec) error {
+	if !isMultisiteConfigured(multisite) {
+		return nil
+	}
+
+	if len(multisite.Sites) == 0 {
+		return fmt.Errorf("Multisite configuration requires a list of sites")
+	}
+	sites := make(map[string]bool)
+	for _, site := range multisite.Sites {
+		if site == "" || strings.ContainsAny(site, ", ") {
+			return fmt.Errorf("Multisite site name \"%s\" is invalid", site)
+		}
+		if sites[site] {
+			return fmt.Errorf("Multisite site %s is listed more than once", site)
+		}
+		sites[site] = true
+	}
+
+	if multisite.Site == "" {
+		multisite.Site = multisite.Sites[0]
+	} else if !sites[multisite.Site] {
+		return fmt.Errorf("Multisite site %s of the cluster master is not one of the sites %s", multisite.Site, strings.Join(multisite.Sites, ","))
+	}
+
+	err := validateSiteFactor("siteReplicationFactor", &multisite.SiteReplicationFactor)
+	if err != nil {
+		return err
+	}
+	err = validateSiteFactor("siteSearchFactor", &multisite.SiteSearchFactor)
+	if err != nil {
+		return err
+	}
+
+	rf, sf := multisite.SiteReplicationFactor, multisite.SiteSearchFactor
+	if rf.Total > 0 && sf.Total > rf.Total {
+		return fmt.Errorf("Multisite siteSearchFactor total (%d) cannot be greater than siteReplicationFactor total (%d)", sf.Total, rf.Total)
+	}
+
+	for site, zone := range multisite.SiteZones {
+		if !sites[site] {
+			return fmt.Errorf("Multisite siteZones site %s is not one of the sites %s", site, strings.Join(multisite.Sites, ","))
+		}
+		if zone == "" {
+			return fmt.Errorf("Multisite siteZones zone of site %s cannot be empty", site)
+		}
+	}
+	if len(multisite.SiteZones) > 0 && multisite.ZoneLabel == "" {
+		multisite.ZoneLabel = defaultZoneLabel
+	}
+	return nil
+}
+
+// validateSiteFactor returns an error if the origin of a site replication or search factor is greater than its total
+func validateSiteFactor(field string, factor *enterprisev1.SiteFactorSpec) error {
+	if factor.Origin < 0 || factor.Total < 0 {
+		return fmt.Errorf("Multisite %s cannot be negative", field)
+	}
+	if factor.Total > 0 && factor.Origin > factor.Total {
+		return fmt.Errorf("Multisite %s origin (%d) cannot be greater than its total (%d)", field, factor.Origin, factor.Total)
+	}
+	return nil
+}
+
+// parseSiteFactor parses a site replication or search factor reported by a cluster master, such as "{ origin:2, total:3 }".
+// Explicit site values, such as "site1:1", are ignored.
+func parseSiteFactor(siteFactor string) (enterprisev1.SiteFactorSpec, error) {
+	var factor enterprisev1.SiteFactorSpec
+	found := false
+	for _, field := range strings.Split(strings.Trim(siteFactor, "{} "), ",") {
+		parts := strings.SplitN(strings.TrimSpace(field), ":", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		value, err := strconv.Atoi(strings.TrimSpace(parts[1]))
+		if err != nil {
+			return factor, fmt.Errorf("Invalid site factor \"%s\": %v", siteFactor, err)
+		}
+		switch strings.TrimSpace(parts[0]) {
+		case "origin":
+			factor.Origin = int32(value)
+			found = true
+		case "total":
+			factor.Total = int32(value)
+		}
+	}
+	if !found {
+		return factor, fmt.Errorf("Invalid site factor \"%s\": origin is missing", siteFactor)
+	}
+	return factor, nil
+}
+
+// getMultisiteDefaults returns the splunk-ansible defaults rendered from the typed multisite configuration
+// of a ClusterMaster, IndexerCluster or SearchHeadCluster, or an empty string if it has none.
+func getMultisiteDefaults(cr splcommon.MetaObject) string {
+	var lines []string
+	switch cr := cr.(type) {
+	case *enterprisev1.ClusterMaster:
+		multisite := &cr.Spec.Multisite
+		if len(multisite.Sites) == 0 {
+			return ""
+		}
+		lines = append(lines,
+			"multisite_master: localhost",
+			fmt.Sprintf("all_sites: %s", strings.Join(multisite.Sites, ",")),
+			fmt.Sprintf("site: %s", multisite.Site))
+		lines = append(lines, getSiteFactorDefaults("multisite_replication_factor", multisite.SiteReplicationFactor)...)
+		lines = append(lines, getSiteFactorDefaults("multisite_search_factor", multisite.SiteSearchFactor)...)
+	case *enterprisev1.IndexerCluster:
+		if cr.Spec.Site == "" {
+			return ""
+		}
+		lines = append(lines,
+			fmt.Sprintf("multisite_master: %s", GetSplunkServiceName(SplunkClusterMaster, cr.Spec.ClusterMasterRef.Name, false)),
+			fmt.Sprintf("site: %s", cr.Spec.Site))
+	case *enterprisev1.SearchHeadCluster:
+		if cr.Spec.Site == "" || cr.Spec.ClusterMasterRef.Name == "" {
+			return ""
+		}
+		lines = append(lines,
+			fmt.Sprintf("multisite_master: %s", GetSplunkServiceName(SplunkClusterMaster, cr.Spec.ClusterMasterRef.Name, false)),
+			fmt.Sprintf("site: %s", cr.Spec.Site))
+	default:
+		return ""
+	}
+	return fmt.Sprintf("splunk:\n  %s\n", strings.Join(lines, "\n  "))
+}
+
+// getSiteFactorDefaults returns the splunk-ansible defaults for the fields of a site factor that are set
+func getSiteFactorDefaults(prefix string, factor enterprisev1.SiteFactorSpec) []string {
+	var lines []string
+	if factor.Origin > 0 {
+		lines = append(lines, fmt.Sprintf("%s_origin: %d", prefix, factor.Origin))
+	}
+	if factor.Total > 0 {
+		lines = append(lines, fmt.Sprintf("%s_total: %d", prefix, factor.Total))
+	}
+	return lines
+}
+
+// getSiteZone returns the node label and the zone that the site of a cluster master, indexer cluster
+// or search heads is mapped to by the multisite configuration of the cluster master, if any.
+func getSiteZone(client splcommon.ControllerClient, cr splcommon.MetaObject, instanceType InstanceType) (string, string, error) {
+	var site string
+	var clusterMasterRef corev1.ObjectReference
+	switch cr := cr.(type) {
+	case *enterprisev1.ClusterMaster:
+		zoneLabel, zone := getZone(&cr.Spec.Multisite, cr.Spec.Multisite.Site)
+		return zoneLabel, zone, nil
+	case *enterprisev1.IndexerCluster:
+		site, clusterMasterRef = cr.Spec.Site, cr.Spec.ClusterMasterRef
+	case *enterprisev1.SearchHeadCluster:
+		// the deployer does not search, so it is not constrained to the zone of the search heads
+		if instanceType != SplunkSearchHead {
+			return "", "", nil
+		}
+		site, clusterMasterRef = cr.Spec.Site, cr.Spec.ClusterMasterRef
+	}
+	if site == "" || clusterMasterRef.Name == "" {
+		return "", "", nil
+	}
+
+	namespacedName := types.NamespacedName{Namespace: clusterMasterRef.Namespace, Name: clusterMasterRef.Name}
+	if namespacedName.Namespace == "" {
+		namespacedName.Namespace = cr.GetNamespace()
+	}
+	var cm enterprisev1.ClusterMaster
+	err := client.Get(context.TODO(), namespacedName, &cm)
+	if err != nil {
+		return "", "", fmt.Errorf("Unable to get cluster master %s to map site %s to a zone: %v", namespacedName.Name, site, err)
+	}
+	zoneLabel, zone := getZone(&cm.Spec.Multisite, site)
+	return zoneLabel, zone, nil
+}
+
+// getZone returns the node label and the zone that a site is mapped to by a MultisiteSpec, if any
+func getZone(multisite *enterprisev1.MultisiteSpec, site string) (string, string) {
+	zoneLabel := multisite.ZoneLabel
+	if zoneLabel == "" {
+		zoneLabel = defaultZoneLabel
+	}
+	return zoneLabel, multisite.SiteZones[site]
+}
+
+// verifyMultisite returns an error if the site of the indexer cluster does not match the multisite
+// configuration of its cluster master, as defined by its spec and as reported by its REST API.
+func (mgr *indexerClusterPodManager) verifyMultisite(cm *enterprisev1.ClusterMaster) error {
+	site := mgr.cr.Spec.Site
+	multisite := &cm.Spec.Multisite
+	if site == "" && len(multisite.Sites) == 0 {
+		return nil
+	}
+
+	if site != "" && len(multisite.Sites) > 0 {
+		found := false
+		for _, s := range multisite.Sites {
+			found = found || s == site
+		}
+		if !found {
+			return fmt.Errorf("IndexerCluster site %s is not one of the sites %s of cluster master %s", site, strings.Join(multisite.Sites, ","), cm.GetName())
+		}
+	}
+
+	clusterInfo, err := mgr.getClusterMasterClient().GetClusterInfo(false)
+	if err != nil {
+		return fmt.Errorf("could not get cluster info from cluster master: %w", err)
+	}
+	if clusterInfo.MultiSite != "true" {
+		if site != "" {
+			return fmt.Errorf("IndexerCluster site %s requires a multisite cluster master; cluster master %s is not multisite", site, cm.GetName())
+		}
+		return fmt.Errorf("Cluster master %s has a multisite configuration but is not multisite", cm.GetName())
+	}
+
+	err = verifySiteFactor("site_replication_factor", clusterInfo.SiteReplicationFactor, multisite.SiteReplicationFactor, cm.GetName())
+	if err != nil {
+		return err
+	}
+	return verifySiteFactor("site_search_factor", clusterInfo.SiteSearchFactor, multisite.SiteSearchFactor, cm.GetName())
+}
+
+// verifySiteFactor returns an error if a site factor reported by a cluster master differs from the fields set in its spec
+func verifySiteFactor(name, reported string, want enterprisev1.SiteFactorSpec, cmName string) error {
+	if want == (enterprisev1.SiteFactorSpec{}) {
+		return nil
+	}
+	got, err := parseSiteFactor(reported)
+	if err != nil {
+		return err
+	}
+	if (want.Origin > 0 && got.Origin != want.Origin) || (want.Total > 0 && got.Total != want.Total) {
+		return fmt.Errorf("Cluster master %s has %s origin:%d,total:%d; want origin:%d,total:%d", cmName, name, got.Origin, got.Total, want.Origin, want.Total)
+	}
+	return nil
+}
@@ -0,0 +1,267 @@
+// Copyright (c) 2018-2021 Splunk Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package enterprise
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	enterprisev1 "github.com/splunk/splunk-operator/pkg/apis/enterprise/v1"
+	splcommon "github.com/splunk/splunk-operator/pkg/splunk/common"
+	spltest "github.com/splunk/splunk-operator/pkg/splunk/test"
+)
+
+func TestValidateMultisiteSpec(t *testing.T) {
+	test := func(multisite enterprisev1.MultisiteSpec, wantError string) {
+		err := validateMultisiteSpec(&multisite)
+		if (err == nil && wantError != "") || (err != nil && err.Error() != wantError) {
+			t.Errorf("validateMultisiteSpec(%v) returned %v; want %s", multisite, err, wantError)
+		}
+	}
+
+	test(enterprisev1.MultisiteSpec{}, "")
+	test(enterprisev1.MultisiteSpec{Sites: []string{"site1", "site2"}, Site: "site2"}, "")
+	test(enterprisev1.MultisiteSpec{Site: "site1"}, "Multisite configuration requires a list of sites")
+	test(enterprisev1.MultisiteSpec{Sites: []string{"site1", ""}}, "Multisite site name \"\" is invalid")
+	test(enterprisev1.MultisiteSpec{Sites: []string{"site1,site2"}}, "Multisite site name \"site1,site2\" is invalid")
+	test(enterprisev1.MultisiteSpec{Sites: []string{"site1", "site1"}}, "Multisite site site1 is listed more than once")
+	test(enterprisev1.MultisiteSpec{Sites: []string{"site1", "site2"}, Site: "site3"}, "Multisite site site3 of the cluster master is not one of the sites site1,site2")
+	test(enterprisev1.MultisiteSpec{
+		Sites:                 []string{"site1", "site2"},
+		SiteReplicationFactor: enterprisev1.SiteFactorSpec{Origin: 3, Total: 2},
+	}, "Multisite siteReplicationFactor origin (3) cannot be greater than its total (2)")
+	test(enterprisev1.MultisiteSpec{
+		Sites:                 []string{"site1", "site2"},
+		SiteReplicationFactor: enterprisev1.SiteFactorSpec{Origin: 1, Total: 2},
+		SiteSearchFactor:      enterprisev1.SiteFactorSpec{Origin: 1, Total: 3},
+	}, "Multisite siteSearchFactor total (3) cannot be greater than siteReplicationFactor total (2)")
+
+	test(enterprisev1.MultisiteSpec{
+		Sites:     []string{"site1", "site2"},
+		SiteZones: map[string]string{"site3": "zone-1c"},
+	}, "Multisite siteZones site site3 is not one of the sites site1,site2")
+	test(enterprisev1.MultisiteSpec{
+		Sites:     []string{"site1", "site2"},
+		SiteZones: map[string]string{"site1": ""},
+	}, "Multisite siteZones zone of site site1 cannot be empty")
+
+	// the site of the cluster master defaults to the first site, and zones to the standard zone label
+	multisite := enterprisev1.MultisiteSpec{
+		Sites:     []string{"site1", "site2"},
+		SiteZones: map[string]string{"site1": "zone-1a"},
+	}
+	validateMultisiteSpec(&multisite)
+	if multisite.Site != "site1" || multisite.ZoneLabel != "topology.kubernetes.io/zone" {
+		t.Errorf("validateMultisiteSpec() set site %s and zone label %s; want site1 and topology.kubernetes.io/zone", multisite.Site, multisite.ZoneLabel)
+	}
+}
+
+func TestParseSiteFactor(t *testing.T) {
+	test := func(siteFactor string, want enterprisev1.SiteFactorSpec, wantError bool) {
+		got, err := parseSiteFactor(siteFactor)
+		if (err != nil) != wantError {
+			t.Errorf("parseSiteFactor(%s) returned error %v; want error %t", siteFactor, err, wantError)
+		}
+		if err == nil && got != want {
+			t.Errorf("parseSiteFactor(%s) returned %v; want %v", siteFactor, got, want)
+		}
+	}
+
+	test("{ origin:2, total:3 }", enterprisev1.SiteFactorSpec{Origin: 2, Total: 3}, false)
+	test("origin:1,site1:1,total:2", enterprisev1.SiteFactorSpec{Origin: 1, Total: 2}, false)
+	test("total:2", enterprisev1.SiteFactorSpec{}, true)
+	test("origin:two,total:3", enterprisev1.SiteFactorSpec{}, true)
+	test("", enterprisev1.SiteFactorSpec{}, true)
+}
+
+func TestGetMultisiteDefaults(t *testing.T) {
+	cm := enterprisev1.ClusterMaster{
+		ObjectMeta: metav1.ObjectMeta{Name: "master1", Namespace: "test"},
+	}
+	if got := getMultisiteDefaults(&cm); got != "" {
+		t.Errorf("getMultisiteDefaults() without multisite configuration returned %s; want empty", got)
+	}
+
+	cm.Spec.Multisite = enterprisev1.MultisiteSpec{
+		Sites:                 []string{"site1", "site2", "site3"},
+		Site:                  "site1",
+		SiteReplicationFactor: enterprisev1.SiteFactorSpec{Origin: 1, Total: 2},
+		SiteSearchFactor:      enterprisev1.SiteFactorSpec{Origin: 1},
+	}
+	want := `splunk:
+  multisite_master: localhost
+  all_sites: site1,site2,site3
+  site: site1
+  multisite_replication_factor_origin: 1
+  multisite_replication_factor_total: 2
+  multisite_search_factor_origin: 1
+`
+	if got := getMultisiteDefaults(&cm); got != want {
+		t.Errorf("getMultisiteDefaults() returned %s; want %s", got, want)
+	}
+
+	idxc := enterprisev1.IndexerCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "site2", Namespace: "test"},
+	}
+	idxc.Spec.ClusterMasterRef.Name = "master1"
+	if got := getMultisiteDefaults(&idxc); got != "" {
+		t.Errorf("getMultisiteDefaults() without site returned %s; want empty", got)
+	}
+	idxc.Spec.Site = "site2"
+	want = `splunk:
+  multisite_master: splunk-master1-cluster-master-service
+  site: site2
+`
+	if got := getMultisiteDefaults(&idxc); got != want {
+		t.Errorf("getMultisiteDefaults() returned %s; want %s", got, want)
+	}
+
+	// typed multisite config is loaded after inline defaults
+	c := spltest.NewMockClient()
+	c.AddObject(&cm)
+	idxc.Spec.Defaults = "defaults-yaml"
+	ss, err := getIndexerStatefulSet(c, &idxc)
+	if err != nil {
+		t.Errorf("getIndexerStatefulSet() returned error %v", err)
+	}
+	wantDefaultsURL := "/mnt/splunk-defaults/default.yml,/mnt/splunk-defaults/multisite.yml,/mnt/splunk-secrets/default.yml"
+	for _, env := range ss.Spec.Template.Spec.Containers[0].Env {
+		if env.Name == "SPLUNK_DEFAULTS_URL" && env.Value != wantDefaultsURL {
+			t.Errorf("getIndexerStatefulSet() set SPLUNK_DEFAULTS_URL %s; want %s", env.Value, wantDefaultsURL)
+		}
+	}
+
+	configMap := getSplunkDefaults(idxc.GetName(), idxc.GetNamespace(), SplunkIndexer, idxc.Spec.Defaults, getMultisiteDefaults(&idxc))
+	if configMap.Data["default.yml"] != "defaults-yaml" || configMap.Data["multisite.yml"] != want {
+		t.Errorf("getSplunkDefaults() returned %v; want default.yml and multisite.yml", configMap.Data)
+	}
+}
+
+func TestVerifyMultisite(t *testing.T) {
+	cm := enterprisev1.ClusterMaster{
+		ObjectMeta: metav1.ObjectMeta{Name: "master1", Namespace: "test"},
+	}
+	clusterInfoURL := "https://splunk-master1-cluster-master-service.test.svc.cluster.local:8089/services/cluster/config?count=0&output_mode=json"
+	singlesite := `{"entry":[{"content":{"multisite":"false","replication_factor":3}}]}`
+	multisite := `{"entry":[{"content":{"multisite":"true","site_replication_factor":"{ origin:1, total:2 }","site_search_factor":"{ origin:1, total:2 }"}}]}`
+
+	test := func(site string, body string, wantError string) {
+		mockSplunkClient := &spltest.MockHTTPClient{}
+		if body != "" {
+			mockSplunkClient.AddHandlers(spltest.MockHTTPHandler{Method: "GET", URL: clusterInfoURL, Status: 200, Body: body})
+		}
+		mgr := getIndexerClusterPodManager("verifyMultisite", nil, mockSplunkClient, 1)
+		mgr.c = spltest.NewMockClient()
+		mgr.cr.Spec.Site = site
+		err := mgr.verifyMultisite(&cm)
+		if (err == nil && wantError != "") || (err != nil && err.Error() != wantError) {
+			t.Errorf("verifyMultisite() with site %s returned %v; want %s", site, err, wantError)
+		}
+		mockSplunkClient.CheckRequests(t, "verifyMultisite")
+	}
+
+	// nothing is checked without any multisite configuration
+	test("", "", "")
+	test("site1", singlesite, "IndexerCluster site site1 requires a multisite cluster master; cluster master master1 is not multisite")
+	test("site1", multisite, "")
+
+	cm.Spec.Multisite.Sites = []string{"site1", "site2"}
+	test("site3", "", "IndexerCluster site site3 is not one of the sites site1,site2 of cluster master master1")
+	test("", singlesite, "Cluster master master1 has a multisite configuration but is not multisite")
+
+	cm.Spec.Multisite.SiteReplicationFactor = enterprisev1.SiteFactorSpec{Origin: 1, Total: 2}
+	test("site2", multisite, "")
+	cm.Spec.Multisite.SiteSearchFactor = enterprisev1.SiteFactorSpec{Total: 1}
+	test("site2", multisite, "Cluster master master1 has site_search_factor origin:1,total:2; want origin:0,total:1")
+
+	// errors of the cluster master are kept for diagnosis
+	mockSplunkClient := &spltest.MockHTTPClient{}
+	mockSplunkClient.AddHandlers(spltest.MockHTTPHandler{Method: "GET", URL: clusterInfoURL, Status: 503, Body: ""})
+	mgr := getIndexerClusterPodManager("verifyMultisite", nil, mockSplunkClient, 1)
+	mgr.c = spltest.NewMockClient()
+	mgr.cr.Spec.Site = "site2"
+	err := mgr.verifyMultisite(&cm)
+	if err == nil || errors.Unwrap(err) == nil || !strings.HasPrefix(err.Error(), "could not get cluster info from cluster master: ") {
+		t.Errorf("verifyMultisite() returned %v; want the wrapped error of the cluster master", err)
+	}
+}
+
+func TestGetSiteZone(t *testing.T) {
+	c := spltest.NewMockClient()
+	cm := enterprisev1.ClusterMaster{
+		ObjectMeta: metav1.ObjectMeta{Name: "master1", Namespace: "test"},
+		Spec: enterprisev1.ClusterMasterSpec{
+			Multisite: enterprisev1.MultisiteSpec{
+				Sites:     []string{"site1", "site2"},
+				Site:      "site1",
+				ZoneLabel: "failure-domain.beta.kubernetes.io/zone",
+				SiteZones: map[string]string{"site1": "zone-1a", "site2": "zone-1b"},
+			},
+		},
+	}
+
+	test := func(cr splcommon.MetaObject, instanceType InstanceType, wantLabel, wantZone string, wantError bool) {
+		zoneLabel, zone, err := getSiteZone(c, cr, instanceType)
+		if (err != nil) != wantError {
+			t.Errorf("getSiteZone(%s, %s) returned error %v; want error %t", cr.GetName(), instanceType, err, wantError)
+		}
+		if zone != wantZone || (wantZone != "" && zoneLabel != wantLabel) {
+			t.Errorf("getSiteZone(%s, %s) returned %s=%s; want %s=%s", cr.GetName(), instanceType, zoneLabel, zone, wantLabel, wantZone)
+		}
+	}
+
+	test(&cm, SplunkClusterMaster, "failure-domain.beta.kubernetes.io/zone", "zone-1a", false)
+
+	idxc := enterprisev1.IndexerCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "site2", Namespace: "test"},
+	}
+	idxc.Spec.ClusterMasterRef.Name = "master1"
+	test(&idxc, SplunkIndexer, "", "", false)
+	idxc.Spec.Site = "site2"
+	test(&idxc, SplunkIndexer, "", "", true)
+	c.AddObject(&cm)
+	test(&idxc, SplunkIndexer, "failure-domain.beta.kubernetes.io/zone", "zone-1b", false)
+
+	shc := enterprisev1.SearchHeadCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "shc", Namespace: "test"},
+	}
+	shc.Spec.ClusterMasterRef.Name = "master1"
+	shc.Spec.Site = "site1"
+	test(&shc, SplunkSearchHead, "failure-domain.beta.kubernetes.io/zone", "zone-1a", false)
+	test(&shc, SplunkDeployer, "", "", false)
+	shc.Spec.Site = "site0"
+	test(&shc, SplunkSearchHead, "", "", false)
+
+	// the zone is required by the pods of the site
+	ss, err := getIndexerStatefulSet(c, &idxc)
+	if err != nil {
+		t.Errorf("getIndexerStatefulSet() returned error %v", err)
+	}
+	want := []corev1.NodeSelectorTerm{{
+		MatchExpressions: []corev1.NodeSelectorRequirement{{
+			Key:      "failure-domain.beta.kubernetes.io/zone",
+			Operator: corev1.NodeSelectorOpIn,
+			Values:   []string{"zone-1b"},
+		}},
+	}}
+	got := ss.Spec.Template.Spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms
+	if splcommon.CompareByMarshall(got, want) {
+		t.Errorf("getIndexerStatefulSet() set node selector terms %v; want %v", got, want)
+	}
+}
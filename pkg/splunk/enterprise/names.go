@@ -41,6 +41,9 @@ const (
 	// identifier
 	smartstoreTemplateStr = "splunk-%s-%s-smartstore"
 
+	// identifier
+	serverClassTemplateStr = "splunk-%s-%s-serverclass"
+
 	// default docker image used for Splunk instances
 	defaultSplunkImage = "splunk/splunk"
 
@@ -50,12 +53,42 @@ const (
 	// identifier used for S3 secret key
 	s3SecretKey = "s3_secret_key"
 
+	// identifier used for the GCS service account key, in JSON
+	gcsCredentialFile = "gcs_credential_file"
+
+	// identifier used for Azure storage account name
+	azureAccountName = "azure_sa_name"
+
+	// identifier used for Azure storage account key
+	azureAccountKey = "azure_sa_secret_key"
+
+	// annotation of a service account with the IAM role assumed by its pods, for IAM roles for service accounts
+	roleARNAnnotation = "eks.amazonaws.com/role-arn"
+
+	// directory of the pods where the CA bundles of the remote volumes are mounted
+	remoteVolumeCADir = "/mnt/splunk-operator/ca"
+
+	// directory of the pods where the GCS service account keys of the remote volumes are mounted
+	remoteVolumeGCSDir = "/mnt/splunk-gcs"
+
+	// default key of a CA bundle in a Secret or ConfigMap
+	defaultCABundleKey = "ca.crt"
+
+	// object written under the path of a remote volume by its preflight check, for a namespace and custom resource name
+	remoteVolumeProbeObject = ".splunk-operator-preflight-%s-%s"
+
 	//identifier for monitoring console configMap revision
 	monitoringConsoleConfigRev = "monitoringConsoleConfigRev"
 
+	// identifier to track the defaults config rev. on Pod
+	defaultConfigRev = "defaultConfigRev"
+
 	// identifier to track the smartstore config rev. on Pod
 	smartStoreConfigRev = "SmartStoreConfigRev"
 
+	// identifier to track the serverclass config rev. on Pod
+	serverClassConfigRev = "ServerClassConfigRev"
+
 	// command merger
 	commandMerger = " && "
 
@@ -65,9 +98,54 @@ const (
 	// command for init container on a CM
 	commandForCMSmartstore = "mkdir -p /opt/splk/etc/master-apps/splunk-operator/local && ln -sfn  /mnt/splunk-operator/local/indexes.conf /opt/splk/etc/master-apps/splunk-operator/local/indexes.conf && ln -sfn  /mnt/splunk-operator/local/server.conf /opt/splk/etc/master-apps/splunk-operator/local/server.conf"
 
+	// directory of the app holding the outputs.conf generated for heavy forwarders, as mounted on the init container
+	heavyForwarderOutputsDir = "/opt/splk/etc/apps/splunk-operator/local"
+
+	// command for init container on a heavy forwarder, which writes the outputs.conf given as argument
+	commandForHeavyForwarderOutputs = "mkdir -p " + heavyForwarderOutputsDir + " && cat > " + heavyForwarderOutputsDir + "/outputs.conf <<EOF\n%sEOF"
+
+	// command for init container on a deployment server
+	commandForDeploymentServerServerClass = "mkdir -p /opt/splk/etc/apps/splunk-operator/local && ln -sfn  /mnt/splunk-serverclass/serverclass.conf /opt/splk/etc/apps/splunk-operator/local/serverclass.conf"
+
+	// time in seconds between polls of the deployment clients that phoned home to a deployment server
+	deploymentServerClientsPollInterval = 60
+
 	//smartstoreconfigToken used to track if the config is reflecting on Pod or not
 	configToken = "conftoken"
 
+	// app deployment scope: install the apps on the instance itself
+	appScopeLocal = "local"
+
+	// app deployment scope: push the apps to the indexer cluster peers or search head cluster members
+	appScopeCluster = "cluster"
+
+	// app deployment scope: install the apps on the cluster master or deployer, then push them to the cluster
+	appScopeClusterWithPreConfig = "clusterWithPreConfig"
+
+	// directory holding apps installed on a Splunk instance
+	appsLocalDir = "/opt/splunk/etc/apps"
+
+	// directory holding apps pushed by a cluster master to the indexer cluster peers
+	appsMasterDir = "/opt/splunk/etc/master-apps"
+
+	// directory holding apps pushed by a deployer to the search head cluster members
+	appsShClusterDir = "/opt/splunk/etc/shcluster/apps"
+
+	// default interval in seconds between checks of the app repository
+	defaultAppsRepoPollInterval = 3600
+
+	// default time in seconds that a search head cluster member waits for its active searches to complete
+	defaultSearchDrainTimeout = 600
+
+	// directory where splunk-ansible writes the splunk.conf settings of the defaults, unless they set another one
+	splunkSystemLocalDir = "/opt/splunk/etc/system/local"
+
+	// time in seconds to wait for another search head cluster member to be elected captain before the transfer is requested again
+	captaincyTransferTimeout = 300
+
+	// app verify target used for the search head cluster members
+	verifyTargetSearchHead = "searchhead"
+
 	// port names and templates and protocols
 	portNameTemplateStr = "%s-%s"
 
@@ -124,6 +202,11 @@ func GetSplunkSmartstoreConfigMapName(identifier string, crKind string) string {
 	return fmt.Sprintf(smartstoreTemplateStr, identifier, strings.ToLower(crKind))
 }
 
+// GetSplunkServerClassConfigMapName uses a template to name a Kubernetes ConfigMap for the serverclass.conf of a deployment server.
+func GetSplunkServerClassConfigMapName(identifier string) string {
+	return fmt.Sprintf(serverClassTemplateStr, identifier, SplunkDeploymentServer)
+}
+
 // GetSplunkStatefulsetUrls returns a list of fully qualified domain names for all pods within a Splunk StatefulSet.
 func GetSplunkStatefulsetUrls(namespace string, instanceType InstanceType, identifier string, replicas int32, hostnameOnly bool) string {
 	urls := make([]string, replicas)
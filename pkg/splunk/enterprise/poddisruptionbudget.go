@@ -0,0 +1,106 @@
+// Copyright (c) 2018-2021 Splunk Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package enterprise
+
+import (
+	appsv1 "k8s.io/api/apps/v1"
+	policyv1beta1 "k8s.io/api/policy/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	enterprisev1 "github.com/splunk/splunk-operator/pkg/apis/enterprise/v1"
+	splcommon "github.com/splunk/splunk-operator/pkg/splunk/common"
+	splctrl "github.com/splunk/splunk-operator/pkg/splunk/controller"
+)
+
+// getDefaultDisruptionBudget returns the default PodDisruptionBudget of the pods of an instance type. Indexers are evicted
+// one at a time, or up to one less than the origin site replication factor of a multisite indexer cluster, so that each
+// site keeps a copy of its own buckets. Search heads keep a majority of members to elect a captain. Single instances,
+// such as the cluster master, the license master or the deployer, have none.
+func getDefaultDisruptionBudget(instanceType InstanceType, replicas, siteReplicationFactor int32) enterprisev1.PodDisruptionBudgetSpec {
+	switch instanceType {
+	case SplunkIndexer:
+		maxUnavailable := intstr.FromInt(1)
+		if siteReplicationFactor > 1 {
+			maxUnavailable = intstr.FromInt(int(siteReplicationFactor - 1))
+		}
+		return enterprisev1.PodDisruptionBudgetSpec{MaxUnavailable: &maxUnavailable}
+	case SplunkSearchHead:
+		minAvailable := intstr.FromInt(int(replicas/2 + 1))
+		return enterprisev1.PodDisruptionBudgetSpec{MinAvailable: &minAvailable}
+	case SplunkStandalone, SplunkHeavyForwarder:
+		if replicas > 1 {
+			maxUnavailable := intstr.FromInt(1)
+			return enterprisev1.PodDisruptionBudgetSpec{MaxUnavailable: &maxUnavailable}
+		}
+	}
+	return enterprisev1.PodDisruptionBudgetSpec{Disabled: true}
+}
+
+// getSplunkPodDisruptionBudget returns a Kubernetes PodDisruptionBudget object for the pods of a StatefulSet, using the
+// budget of the spec if it sets one and the default budget otherwise. It returns nil if the pods should have none.
+func getSplunkPodDisruptionBudget(cr splcommon.MetaObject, spec *enterprisev1.PodDisruptionBudgetSpec, defaults enterprisev1.PodDisruptionBudgetSpec, statefulSet *appsv1.StatefulSet) *policyv1beta1.PodDisruptionBudget {
+	budget := *spec
+	if budget.MinAvailable == nil && budget.MaxUnavailable == nil {
+		budget.MinAvailable, budget.MaxUnavailable = defaults.MinAvailable, defaults.MaxUnavailable
+		budget.Disabled = budget.Disabled || defaults.Disabled
+	}
+	if budget.Disabled {
+		return nil
+	}
+
+	pdb := &policyv1beta1.PodDisruptionBudget{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "PodDisruptionBudget",
+			APIVersion: "policy/v1beta1",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        statefulSet.GetName(),
+			Namespace:   statefulSet.GetNamespace(),
+			Labels:      make(map[string]string),
+			Annotations: make(map[string]string),
+		},
+		Spec: policyv1beta1.PodDisruptionBudgetSpec{
+			Selector: statefulSet.Spec.Selector.DeepCopy(),
+		},
+	}
+
+	// the PodDisruptionBudget cannot set both minAvailable and maxUnavailable
+	if budget.MinAvailable != nil {
+		pdb.Spec.MinAvailable = budget.MinAvailable
+	} else {
+		pdb.Spec.MaxUnavailable = budget.MaxUnavailable
+	}
+
+	// append same labels as selector, and labels and annotations from parent
+	for k, v := range statefulSet.Spec.Selector.MatchLabels {
+		pdb.ObjectMeta.Labels[k] = v
+	}
+	splcommon.AppendParentMeta(pdb.GetObjectMeta(), cr.GetObjectMeta())
+
+	pdb.SetOwnerReferences(append(pdb.GetOwnerReferences(), splcommon.AsOwner(cr, true)))
+	return pdb
+}
+
+// applySplunkPodDisruptionBudget creates or updates the PodDisruptionBudget of the pods of a StatefulSet,
+// or deletes it if they should have none
+func applySplunkPodDisruptionBudget(client splcommon.ControllerClient, cr splcommon.MetaObject, spec *enterprisev1.PodDisruptionBudgetSpec, defaults enterprisev1.PodDisruptionBudgetSpec, statefulSet *appsv1.StatefulSet) error {
+	pdb := getSplunkPodDisruptionBudget(cr, spec, defaults, statefulSet)
+	if pdb == nil {
+		return splctrl.DeletePodDisruptionBudget(client, cr, types.NamespacedName{Namespace: statefulSet.GetNamespace(), Name: statefulSet.GetName()})
+	}
+	return splctrl.ApplyPodDisruptionBudget(client, pdb)
+}
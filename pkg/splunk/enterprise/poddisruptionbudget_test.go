@@ -0,0 +1,114 @@
+// Copyright (c) 2018-2021 Splunk Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package enterprise
+
+import (
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	enterprisev1 "github.com/splunk/splunk-operator/pkg/apis/enterprise/v1"
+)
+
+func TestGetDefaultDisruptionBudget(t *testing.T) {
+	test := func(instanceType InstanceType, replicas, siteReplicationFactor int32, want string) {
+		budget := getDefaultDisruptionBudget(instanceType, replicas, siteReplicationFactor)
+		got := "disabled"
+		if budget.MinAvailable != nil {
+			got = "minAvailable=" + budget.MinAvailable.String()
+		} else if budget.MaxUnavailable != nil {
+			got = "maxUnavailable=" + budget.MaxUnavailable.String()
+		}
+		if budget.Disabled != (want == "disabled") || got != want {
+			t.Errorf("getDefaultDisruptionBudget(%s, %d, %d) returned %s; want %s", instanceType, replicas, siteReplicationFactor, got, want)
+		}
+	}
+
+	test(SplunkIndexer, 3, 0, "maxUnavailable=1")
+	test(SplunkIndexer, 6, 1, "maxUnavailable=1")
+	test(SplunkIndexer, 6, 3, "maxUnavailable=2")
+	test(SplunkSearchHead, 3, 0, "minAvailable=2")
+	test(SplunkSearchHead, 4, 0, "minAvailable=3")
+	test(SplunkStandalone, 1, 0, "disabled")
+	test(SplunkStandalone, 3, 0, "maxUnavailable=1")
+	test(SplunkHeavyForwarder, 2, 0, "maxUnavailable=1")
+	test(SplunkClusterMaster, 1, 0, "disabled")
+	test(SplunkLicenseMaster, 1, 0, "disabled")
+	test(SplunkDeployer, 1, 0, "disabled")
+}
+
+func TestGetSplunkPodDisruptionBudget(t *testing.T) {
+	cr := enterprisev1.IndexerCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "stack1",
+			Namespace: "test",
+		},
+	}
+	selector := map[string]string{
+		"app.kubernetes.io/component": "indexer",
+		"app.kubernetes.io/instance":  "splunk-stack1-indexer",
+	}
+	statefulSet := &appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "splunk-stack1-indexer", Namespace: "test"},
+		Spec: appsv1.StatefulSetSpec{
+			Selector: &metav1.LabelSelector{MatchLabels: selector},
+		},
+	}
+	defaults := getDefaultDisruptionBudget(SplunkIndexer, 3, 0)
+
+	pdb := getSplunkPodDisruptionBudget(&cr, &cr.Spec.PodDisruptionBudget, defaults, statefulSet)
+	if pdb == nil {
+		t.Fatalf("getSplunkPodDisruptionBudget() returned nil for the default budget")
+	}
+	if pdb.GetName() != "splunk-stack1-indexer" || pdb.GetNamespace() != "test" {
+		t.Errorf("getSplunkPodDisruptionBudget() returned %s/%s", pdb.GetNamespace(), pdb.GetName())
+	}
+	if pdb.Spec.MinAvailable != nil || pdb.Spec.MaxUnavailable.String() != "1" {
+		t.Errorf("getSplunkPodDisruptionBudget() returned minAvailable=%v, maxUnavailable=%v; want maxUnavailable=1", pdb.Spec.MinAvailable, pdb.Spec.MaxUnavailable)
+	}
+	for k, v := range selector {
+		if pdb.Spec.Selector.MatchLabels[k] != v || pdb.GetLabels()[k] != v {
+			t.Errorf("getSplunkPodDisruptionBudget() did not select or label %s=%s", k, v)
+		}
+	}
+	if len(pdb.GetOwnerReferences()) != 1 || pdb.GetOwnerReferences()[0].Name != "stack1" {
+		t.Errorf("getSplunkPodDisruptionBudget() returned owners %v", pdb.GetOwnerReferences())
+	}
+
+	// minAvailable takes precedence over maxUnavailable
+	minAvailable := intstr.FromString("50%")
+	maxUnavailable := intstr.FromInt(2)
+	cr.Spec.PodDisruptionBudget.MinAvailable = &minAvailable
+	cr.Spec.PodDisruptionBudget.MaxUnavailable = &maxUnavailable
+	pdb = getSplunkPodDisruptionBudget(&cr, &cr.Spec.PodDisruptionBudget, defaults, statefulSet)
+	if pdb.Spec.MinAvailable.String() != "50%" || pdb.Spec.MaxUnavailable != nil {
+		t.Errorf("getSplunkPodDisruptionBudget() returned minAvailable=%v, maxUnavailable=%v; want minAvailable=50%%", pdb.Spec.MinAvailable, pdb.Spec.MaxUnavailable)
+	}
+
+	// a budget can be set for single instances, and disabled for any
+	single := getDefaultDisruptionBudget(SplunkClusterMaster, 1, 0)
+	if pdb = getSplunkPodDisruptionBudget(&cr, &cr.Spec.PodDisruptionBudget, single, statefulSet); pdb == nil {
+		t.Errorf("getSplunkPodDisruptionBudget() returned nil for a budget set in the spec")
+	}
+	if pdb = getSplunkPodDisruptionBudget(&cr, &enterprisev1.PodDisruptionBudgetSpec{}, single, statefulSet); pdb != nil {
+		t.Errorf("getSplunkPodDisruptionBudget() returned a budget for a single instance")
+	}
+	cr.Spec.PodDisruptionBudget.Disabled = true
+	if pdb = getSplunkPodDisruptionBudget(&cr, &cr.Spec.PodDisruptionBudget, defaults, statefulSet); pdb != nil {
+		t.Errorf("getSplunkPodDisruptionBudget() returned a disabled budget")
+	}
+}
@@ -22,6 +22,8 @@ import (
 	"github.com/go-logr/logr"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	enterprisev1 "github.com/splunk/splunk-operator/pkg/apis/enterprise/v1"
@@ -32,7 +34,7 @@ import (
 )
 
 // ApplySearchHeadCluster reconciles the state for a Splunk Enterprise search head cluster.
-func ApplySearchHeadCluster(client splcommon.ControllerClient, cr *enterprisev1.SearchHeadCluster) (reconcile.Result, error) {
+func ApplySearchHeadCluster(client splcommon.ControllerClient, recorder record.EventRecorder, cr *enterprisev1.SearchHeadCluster) (reconcile.Result, error) {
 	// unless modified, reconcile for this object will be requeued after 5 seconds
 	result := reconcile.Result{
 		Requeue:      true,
@@ -40,9 +42,15 @@ func ApplySearchHeadCluster(client splcommon.ControllerClient, cr *enterprisev1.
 	}
 	scopedLog := log.WithName("ApplySearchHeadCluster").WithValues("name", cr.GetName(), "namespace", cr.GetNamespace())
 
+	eventPublisher := splcommon.NewEventPublisher(recorder, cr)
+
 	// validate and updates defaults for CR
 	err := validateSearchHeadClusterSpec(&cr.Spec)
 	if err != nil {
+		cr.Status.Phase = splcommon.PhaseError
+		setErrorCondition(cr, &cr.Status.Conditions, "", reasonInvalidSpec, err)
+		cr.Status.ObservedGeneration = cr.GetGeneration()
+		client.Status().Update(context.TODO(), cr)
 		return result, err
 	}
 
@@ -51,6 +59,7 @@ func ApplySearchHeadCluster(client splcommon.ControllerClient, cr *enterprisev1.
 	cr.Status.DeployerPhase = splcommon.PhaseError
 	cr.Status.Replicas = cr.Spec.Replicas
 	cr.Status.Selector = fmt.Sprintf("app.kubernetes.io/instance=splunk-%s-search-head", cr.GetName())
+	cr.Status.SearchDrainTimeout = cr.Spec.SearchDrainTimeout
 	if cr.Status.Members == nil {
 		cr.Status.Members = []enterprisev1.SearchHeadClusterMemberStatus{}
 	}
@@ -65,6 +74,8 @@ func ApplySearchHeadCluster(client splcommon.ControllerClient, cr *enterprisev1.
 		cr.Status.AdminPasswordChangedSecrets = make(map[string]bool)
 	}
 	defer func() {
+		setPhaseConditions(cr, &cr.Status.Conditions, cr.Status.Phase)
+		cr.Status.ObservedGeneration = cr.GetGeneration()
 		err = client.Status().Update(context.TODO(), cr)
 		if err != nil {
 			scopedLog.Error(err, "Status update failed")
@@ -74,52 +85,74 @@ func ApplySearchHeadCluster(client splcommon.ControllerClient, cr *enterprisev1.
 	// create or update general config resources
 	namespaceScopedSecret, err := ApplySplunkConfig(client, cr, cr.Spec.CommonSplunkSpec, SplunkSearchHead)
 	if err != nil {
+		setErrorCondition(cr, &cr.Status.Conditions, enterprisev1.ConditionSecretsSynced, reasonSecretsSyncFailed, err)
 		return result, err
 	}
+	setCondition(cr, &cr.Status.Conditions, enterprisev1.ConditionSecretsSynced, corev1.ConditionTrue, reasonSecretsSynced, "")
 
 	// check if deletion has been requested
 	if cr.ObjectMeta.DeletionTimestamp != nil {
 		err = ApplyMonitoringConsole(client, cr, cr.Spec.CommonSplunkSpec, getSearchHeadEnv(cr))
 		DeleteOwnerReferencesForResources(client, cr, nil)
 		if err != nil {
+			setErrorCondition(cr, &cr.Status.Conditions, "", reasonMonitoringConsoleFailed, err)
 			return result, err
 		}
-		terminating, err := splctrl.CheckForDeletion(cr, client)
+		terminating, err := splctrl.CheckForDeletion(cr, client, eventPublisher)
 		if terminating && err != nil { // don't bother if no error, since it will just be removed immmediately after
 			cr.Status.Phase = splcommon.PhaseTerminating
 			cr.Status.DeployerPhase = splcommon.PhaseTerminating
+			setErrorCondition(cr, &cr.Status.Conditions, "", reasonDeletionFailed, err)
 		} else {
 			result.Requeue = false
 		}
 		return result, err
 	}
 
+	setLicenseCondition(client, cr, &cr.Status.Conditions, cr.Spec.LicenseMasterRef)
+
 	// create or update a headless search head cluster service
 	err = splctrl.ApplyService(client, getSplunkService(cr, &cr.Spec.CommonSplunkSpec, SplunkSearchHead, true))
 	if err != nil {
+		setErrorCondition(cr, &cr.Status.Conditions, "", reasonServiceFailed, err)
 		return result, err
 	}
 
 	// create or update a regular search head cluster service
 	err = splctrl.ApplyService(client, getSplunkService(cr, &cr.Spec.CommonSplunkSpec, SplunkSearchHead, false))
 	if err != nil {
+		setErrorCondition(cr, &cr.Status.Conditions, "", reasonServiceFailed, err)
 		return result, err
 	}
 
 	// create or update a deployer service
 	err = splctrl.ApplyService(client, getSplunkService(cr, &cr.Spec.CommonSplunkSpec, SplunkDeployer, false))
 	if err != nil {
+		setErrorCondition(cr, &cr.Status.Conditions, "", reasonServiceFailed, err)
 		return result, err
 	}
 
 	// create or update statefulset for the deployer
 	statefulSet, err := getDeployerStatefulSet(client, cr)
 	if err != nil {
+		setErrorCondition(cr, &cr.Status.Conditions, "", reasonStatefulSetFailed, err)
+		return result, err
+	}
+	// upgrade the deployer and the search heads after the license master and the cluster master
+	_, err = applyUpgradeOrder(client, cr, &cr.Status.Conditions, cr.Status.Image, cr.Status.Version, statefulSet, eventPublisher)
+	if err != nil {
+		setErrorCondition(cr, &cr.Status.Conditions, enterprisev1.ConditionImageUpToDate, reasonUpgradeFailed, err)
 		return result, err
 	}
-	deployerManager := splctrl.DefaultStatefulSetPodManager{}
+	err = applySplunkPodDisruptionBudget(client, cr, &cr.Spec.DeployerPodDisruptionBudget, getDefaultDisruptionBudget(SplunkDeployer, 1, 0), statefulSet)
+	if err != nil {
+		setErrorCondition(cr, &cr.Status.Conditions, "", reasonDisruptionBudgetFailed, err)
+		return result, err
+	}
+	deployerManager := splctrl.DefaultStatefulSetPodManager{EventPublisher: eventPublisher}
 	phase, err := deployerManager.Update(client, statefulSet, 1)
 	if err != nil {
+		setErrorCondition(cr, &cr.Status.Conditions, "", reasonStatefulSetFailed, err)
 		return result, err
 	}
 	cr.Status.DeployerPhase = phase
@@ -127,11 +160,23 @@ func ApplySearchHeadCluster(client splcommon.ControllerClient, cr *enterprisev1.
 	// create or update statefulset for the search heads
 	statefulSet, err = getSearchHeadStatefulSet(client, cr)
 	if err != nil {
+		setErrorCondition(cr, &cr.Status.Conditions, "", reasonStatefulSetFailed, err)
 		return result, err
 	}
-	mgr := searchHeadClusterPodManager{c: client, log: scopedLog, cr: cr, secrets: namespaceScopedSecret, newSplunkClient: splclient.NewSplunkClient}
+	_, err = applyUpgradeOrder(client, cr, &cr.Status.Conditions, cr.Status.Image, cr.Status.Version, statefulSet, eventPublisher)
+	if err != nil {
+		setErrorCondition(cr, &cr.Status.Conditions, enterprisev1.ConditionImageUpToDate, reasonUpgradeFailed, err)
+		return result, err
+	}
+	err = applySplunkPodDisruptionBudget(client, cr, &cr.Spec.PodDisruptionBudget, getDefaultDisruptionBudget(SplunkSearchHead, cr.Spec.Replicas, 0), statefulSet)
+	if err != nil {
+		setErrorCondition(cr, &cr.Status.Conditions, "", reasonDisruptionBudgetFailed, err)
+		return result, err
+	}
+	mgr := searchHeadClusterPodManager{c: client, log: scopedLog, cr: cr, secrets: namespaceScopedSecret, newSplunkClient: splclient.NewSplunkClient, eventPublisher: eventPublisher}
 	phase, err = mgr.Update(client, statefulSet, cr.Spec.Replicas)
 	if err != nil {
+		setErrorCondition(cr, &cr.Status.Conditions, "", reasonStatefulSetFailed, err)
 		return result, err
 	}
 	cr.Status.Phase = phase
@@ -140,9 +185,24 @@ func ApplySearchHeadCluster(client splcommon.ControllerClient, cr *enterprisev1.
 	if cr.Status.Phase == splcommon.PhaseReady {
 		err = ApplyMonitoringConsole(client, cr, cr.Spec.CommonSplunkSpec, getSearchHeadEnv(cr))
 		if err != nil {
+			setErrorCondition(cr, &cr.Status.Conditions, "", reasonMonitoringConsoleFailed, err)
+			return result, err
+		}
+		err = updateVersion(&cr.Spec.CommonSplunkSpec, cr.Status.Image, &cr.Status.Version, statefulSet, func() *splclient.SplunkClient { return mgr.getClient(0) })
+		if err != nil {
+			setErrorCondition(cr, &cr.Status.Conditions, "", reasonServerInfoFailed, err)
+			return result, err
+		}
+		setRunningImage(cr, &cr.Status.Conditions, &cr.Status.Image, cr.Spec.Image, statefulSet, eventPublisher)
+
+		// install apps from the app repository on the deployer, and push the cluster scoped apps to the members
+		err = ApplyAppFramework(client, cr, &cr.Spec.CommonSplunkSpec, &cr.Status.AppContext, SplunkDeployer, 1, namespaceScopedSecret)
+		if err != nil {
+			setErrorCondition(cr, &cr.Status.Conditions, "", reasonAppFrameworkFailed, err)
 			return result, err
 		}
 		result.Requeue = false
+		setAppFrameworkRequeue(&result, &cr.Spec.AppFrameworkConfig, &cr.Status.AppContext)
 
 		// Reset secrets related status structs
 		cr.Status.ShcSecretChanged = []bool{}
@@ -150,6 +210,7 @@ func ApplySearchHeadCluster(client splcommon.ControllerClient, cr *enterprisev1.
 		cr.Status.AdminPasswordChangedSecrets = make(map[string]bool)
 		cr.Status.NamespaceSecretResourceVersion = namespaceScopedSecret.ObjectMeta.ResourceVersion
 	}
+	setReconciledCondition(cr, &cr.Status.Conditions)
 	return result, nil
 }
 
@@ -160,6 +221,7 @@ type searchHeadClusterPodManager struct {
 	cr              *enterprisev1.SearchHeadCluster
 	secrets         *corev1.Secret
 	newSplunkClient func(managementURI, username, password string) *splclient.SplunkClient
+	eventPublisher  *splcommon.EventPublisher
 }
 
 // ApplyShcSecret checks if any of the search heads have a different shc_secret from namespace scoped secret and changes it
@@ -236,6 +298,7 @@ func ApplyShcSecret(mgr *searchHeadClusterPodManager, replicas int32, mock bool)
 				return err
 			}
 			scopedLog.Info("Restarted Splunk")
+			mgr.eventPublisher.Normal(splcommon.EventReasonSecretChanged, "Changed shc_secret on %s", shPodName)
 
 			// Set the shc_secret changed flag to true
 			if i < int32(len(mgr.cr.Status.ShcSecretChanged)) {
@@ -272,6 +335,7 @@ func ApplyShcSecret(mgr *searchHeadClusterPodManager, replicas int32, mock bool)
 				return err
 			}
 			scopedLog.Info("Restarted Splunk")
+			mgr.eventPublisher.Normal(splcommon.EventReasonSecretChanged, "Changed admin password on %s", shPodName)
 
 			// Set the adminSecretChanged changed flag to true
 			if i < int32(len(mgr.cr.Status.AdminSecretChanged)) {
@@ -344,7 +408,7 @@ func (mgr *searchHeadClusterPodManager) Update(c splcommon.ControllerClient, sta
 	}
 
 	// manage scaling and updates
-	return splctrl.UpdateStatefulSetPods(mgr.c, statefulSet, mgr, desiredReplicas)
+	return splctrl.UpdateStatefulSetPods(mgr.c, mgr.eventPublisher, statefulSet, mgr, desiredReplicas)
 }
 
 // PrepareScaleDown for searchHeadClusterPodManager prepares search head pod to be removed via scale down event; it returns true when ready
@@ -374,20 +438,41 @@ func (mgr *searchHeadClusterPodManager) PrepareRecycle(n int32) (bool, error) {
 
 	switch mgr.cr.Status.Members[n].Status {
 	case "Up":
+		// Hand the captaincy over before detaining the captain, which is recycled last
+		if memberName == mgr.cr.Status.Captain {
+			transferred, err := mgr.transferCaptaincy(n)
+			if err != nil || transferred {
+				return false, err
+			}
+		}
+
 		// Detain search head
 		mgr.log.Info("Detaining search head cluster member", "memberName", memberName)
 		c := mgr.getClient(n)
 		return false, c.SetSearchHeadDetention(true)
 
 	case "ManualDetention":
-		// Wait until active searches have drained
-		searchesComplete := mgr.cr.Status.Members[n].ActiveHistoricalSearchCount+mgr.cr.Status.Members[n].ActiveRealtimeSearchCount == 0
-		if searchesComplete {
+		// Wait until active searches have drained, or until the search drain timeout expires
+		member := &mgr.cr.Status.Members[n]
+		activeSearches := member.ActiveHistoricalSearchCount + member.ActiveRealtimeSearchCount
+		if activeSearches == 0 {
 			mgr.log.Info("Detention complete", "memberName", memberName)
-		} else {
-			mgr.log.Info("Waiting for active searches to complete", "memberName", memberName)
+			return true, nil
+		}
+		if member.DrainStartTime == 0 {
+			member.DrainStartTime = time.Now().Unix()
 		}
-		return searchesComplete, nil
+		timeout := getSearchDrainTimeout(&mgr.cr.Spec)
+		if time.Now().Unix()-member.DrainStartTime < timeout {
+			mgr.log.Info("Waiting for active searches to complete", "memberName", memberName, "activeSearches", activeSearches)
+			return false, nil
+		}
+		mgr.log.Info("Search drain timeout expired", "memberName", memberName, "activeSearches", activeSearches)
+		mgr.eventPublisher.Warning(splcommon.EventReasonSearchDrainTimeout, "Killing %d active searches on %s after waiting %d seconds for them to complete",
+			activeSearches, memberName, timeout)
+		mgr.cr.Status.KilledSearches += int64(activeSearches)
+		member.DrainStartTime = 0
+		return true, nil
 
 	case "": // this can happen after the member has already been recycled and we're just waiting for state to update
 		mgr.log.Info("Member has empty Status", "memberName", memberName)
@@ -398,6 +483,81 @@ func (mgr *searchHeadClusterPodManager) PrepareRecycle(n int32) (bool, error) {
 	return false, fmt.Errorf("Status=%s", mgr.cr.Status.Members[n].Status)
 }
 
+// RecycleLast for searchHeadClusterPodManager returns the ordinal of the captain, so that it is recycled after all the
+// other members and only one election is held during an update
+func (mgr *searchHeadClusterPodManager) RecycleLast() int32 {
+	for n, member := range mgr.cr.Status.Members {
+		if member.Name != "" && member.Name == mgr.cr.Status.Captain {
+			return int32(n)
+		}
+	}
+	return -1
+}
+
+// transferCaptaincy for searchHeadClusterPodManager transfers the captaincy from member n to another member that is up,
+// then waits until another member is elected; it returns false if there is no such member. The transfer is requested
+// again if no other member was elected within captaincyTransferTimeout.
+func (mgr *searchHeadClusterPodManager) transferCaptaincy(n int32) (bool, error) {
+	transfer := &mgr.cr.Status.CaptaincyTransfer
+	if transfer.Captain == mgr.cr.Status.Captain && time.Now().Unix()-transfer.StartTime < captaincyTransferTimeout {
+		mgr.log.Info("Waiting for search head cluster captaincy transfer", "from", transfer.Captain, "to", transfer.Target)
+		return true, nil
+	}
+
+	target := mgr.getCaptaincyTransferTarget(n)
+	if target < 0 {
+		mgr.log.Info("No search head cluster member to transfer captaincy to", "memberName", mgr.cr.Status.Captain)
+		return false, nil
+	}
+	targetName := GetSplunkStatefulsetPodName(SplunkSearchHead, mgr.cr.GetName(), target)
+	mgr.log.Info("Transferring search head cluster captaincy", "from", mgr.cr.Status.Captain, "to", targetName)
+	c := mgr.getClient(n)
+	err := c.TransferCaptaincy(mgr.getMemberURI(target))
+	if err != nil {
+		return false, err
+	}
+	*transfer = enterprisev1.CaptaincyTransferInfo{
+		Captain:   mgr.cr.Status.Captain,
+		Target:    targetName,
+		StartTime: time.Now().Unix(),
+	}
+	return true, nil
+}
+
+// getCaptaincyTransferTarget for searchHeadClusterPodManager returns the ordinal of the member that the captaincy of
+// member n is transferred to, or -1 if no other member is up. Members already running the update revision of the
+// StatefulSet are preferred, so that the new captain is not recycled by the same update.
+func (mgr *searchHeadClusterPodManager) getCaptaincyTransferTarget(n int32) int32 {
+	updateRevision := ""
+	namespacedName := types.NamespacedName{Namespace: mgr.cr.GetNamespace(), Name: GetSplunkStatefulsetName(SplunkSearchHead, mgr.cr.GetName())}
+	statefulSet, err := splctrl.GetStatefulSetByName(mgr.c, namespacedName)
+	if err == nil {
+		updateRevision = statefulSet.Status.UpdateRevision
+	} else {
+		mgr.log.Error(err, "Unable to get the update revision of the search heads")
+	}
+
+	target := int32(-1)
+	for i, member := range mgr.cr.Status.Members {
+		if int32(i) == n || member.Status != "Up" {
+			continue
+		}
+		if target < 0 {
+			target = int32(i)
+		}
+		if updateRevision == "" {
+			break
+		}
+		var pod corev1.Pod
+		namespacedName.Name = GetSplunkStatefulsetPodName(SplunkSearchHead, mgr.cr.GetName(), int32(i))
+		err = mgr.c.Get(context.TODO(), namespacedName, &pod)
+		if err == nil && pod.GetLabels()["controller-revision-hash"] == updateRevision {
+			return int32(i)
+		}
+	}
+	return target
+}
+
 // FinishRecycle for searchHeadClusterPodManager completes recycle event for search head pod; it returns true when complete
 func (mgr *searchHeadClusterPodManager) FinishRecycle(n int32) (bool, error) {
 	memberName := GetSplunkStatefulsetPodName(SplunkSearchHead, mgr.cr.GetName(), n)
@@ -425,17 +585,21 @@ func (mgr *searchHeadClusterPodManager) getClient(n int32) *splclient.SplunkClie
 	// Get Pod Name
 	memberName := GetSplunkStatefulsetPodName(SplunkSearchHead, mgr.cr.GetName(), n)
 
-	// Get Fully Qualified Domain Name
-	fqdnName := splcommon.GetServiceFQDN(mgr.cr.GetNamespace(),
-		fmt.Sprintf("%s.%s", memberName, GetSplunkServiceName(SplunkSearchHead, mgr.cr.GetName(), true)))
-
 	// Retrieve admin password from Pod
 	adminPwd, err := splutil.GetSpecificSecretTokenFromPod(mgr.c, memberName, mgr.cr.GetNamespace(), "password")
 	if err != nil {
 		scopedLog.Error(err, "Couldn't retrieve the admin password from Pod")
 	}
 
-	return mgr.newSplunkClient(fmt.Sprintf("https://%s:8089", fqdnName), "admin", adminPwd)
+	return mgr.newSplunkClient(mgr.getMemberURI(n), "admin", adminPwd)
+}
+
+// getMemberURI for searchHeadClusterPodManager returns the management URI of the member n
+func (mgr *searchHeadClusterPodManager) getMemberURI(n int32) string {
+	memberName := GetSplunkStatefulsetPodName(SplunkSearchHead, mgr.cr.GetName(), n)
+	fqdnName := splcommon.GetServiceFQDN(mgr.cr.GetNamespace(),
+		fmt.Sprintf("%s.%s", memberName, GetSplunkServiceName(SplunkSearchHead, mgr.cr.GetName(), true)))
+	return fmt.Sprintf("https://%s:8089", fqdnName)
 }
 
 // updateStatus for searchHeadClusterPodManager uses the REST API to update the status for a SearcHead custom resource
@@ -479,6 +643,10 @@ func (mgr *searchHeadClusterPodManager) updateStatus(statefulSet *appsv1.Statefu
 		}
 
 		if n < int32(len(mgr.cr.Status.Members)) {
+			// keep waiting for the searches of a detained member from when its drain started
+			if memberStatus.Status != "Up" && mgr.cr.Status.Members[n].Name == memberName {
+				memberStatus.DrainStartTime = mgr.cr.Status.Members[n].DrainStartTime
+			}
 			mgr.cr.Status.Members[n] = memberStatus
 		} else {
 			mgr.cr.Status.Members = append(mgr.cr.Status.Members, memberStatus)
@@ -490,6 +658,13 @@ func (mgr *searchHeadClusterPodManager) updateStatus(statefulSet *appsv1.Statefu
 		mgr.cr.Status.Members = mgr.cr.Status.Members[:statefulSet.Status.Replicas]
 	}
 
+	// a captaincy transfer is complete once another member is elected
+	transfer := &mgr.cr.Status.CaptaincyTransfer
+	if transfer.Captain != "" && mgr.cr.Status.Captain != "" && mgr.cr.Status.Captain != transfer.Captain {
+		mgr.eventPublisher.Normal(splcommon.EventReasonCaptaincyTransfer, "Transferred captaincy from %s to %s", transfer.Captain, mgr.cr.Status.Captain)
+		*transfer = enterprisev1.CaptaincyTransferInfo{}
+	}
+
 	return nil
 }
 
@@ -513,10 +688,28 @@ func getDeployerStatefulSet(client splcommon.ControllerClient, cr *enterprisev1.
 	return getSplunkStatefulSet(client, cr, &cr.Spec.CommonSplunkSpec, SplunkDeployer, 1, getSearchHeadExtraEnv(cr, cr.Spec.Replicas))
 }
 
+// getSearchDrainTimeout returns the time in seconds that a detained search head waits for its active searches to complete
+func getSearchDrainTimeout(spec *enterprisev1.SearchHeadClusterSpec) int64 {
+	if spec.SearchDrainTimeout <= 0 {
+		return defaultSearchDrainTimeout
+	}
+	return spec.SearchDrainTimeout
+}
+
 // validateSearchHeadClusterSpec checks validity and makes default updates to a SearchHeadClusterSpec, and returns error if something is wrong.
 func validateSearchHeadClusterSpec(spec *enterprisev1.SearchHeadClusterSpec) error {
 	if spec.Replicas < 3 {
 		spec.Replicas = 3
 	}
+
+	if spec.SearchDrainTimeout <= 0 {
+		spec.SearchDrainTimeout = defaultSearchDrainTimeout
+	}
+
+	err := ValidateAppFrameworkSpec(&spec.AppFrameworkConfig, SplunkDeployer)
+	if err != nil {
+		return err
+	}
+
 	return validateCommonSplunkSpec(&spec.CommonSplunkSpec)
 }
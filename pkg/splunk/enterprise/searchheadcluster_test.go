@@ -24,6 +24,7 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	enterprisev1 "github.com/splunk/splunk-operator/pkg/apis/enterprise/v1"
@@ -42,9 +43,11 @@ func TestApplySearchHeadCluster(t *testing.T) {
 		{MetaName: "*v1.Service-test-splunk-stack1-deployer-service"},
 		{MetaName: "*v1.Secret-test-splunk-test-secret"},
 		{MetaName: "*v1.Secret-test-splunk-stack1-deployer-secret-v1"},
+		{MetaName: "*v1beta1.PodDisruptionBudget-test-splunk-stack1-deployer"},
 		{MetaName: "*v1.StatefulSet-test-splunk-stack1-deployer"},
 		{MetaName: "*v1.Secret-test-splunk-test-secret"},
 		{MetaName: "*v1.Secret-test-splunk-stack1-search-head-secret-v1"},
+		{MetaName: "*v1beta1.PodDisruptionBudget-test-splunk-stack1-search-head"},
 		{MetaName: "*v1.StatefulSet-test-splunk-stack1-search-head"},
 		{MetaName: "*v1.Secret-test-splunk-test-secret"},
 	}
@@ -59,8 +62,8 @@ func TestApplySearchHeadCluster(t *testing.T) {
 	listmockCall := []spltest.MockFuncCall{
 		{ListOpts: listOpts}}
 
-	createCalls := map[string][]spltest.MockFuncCall{"Get": funcCalls, "Create": {funcCalls[0], funcCalls[2], funcCalls[3], funcCalls[4], funcCalls[6], funcCalls[7], funcCalls[9], funcCalls[10]}, "Update": {funcCalls[0]}, "List": {listmockCall[0], listmockCall[0]}}
-	updateCalls := map[string][]spltest.MockFuncCall{"Get": {funcCalls[0], funcCalls[1], funcCalls[2], funcCalls[3], funcCalls[4], funcCalls[5], funcCalls[6], funcCalls[7], funcCalls[8], funcCalls[9], funcCalls[10], funcCalls[11]}, "Update": {funcCalls[7], funcCalls[10]}, "List": {listmockCall[0], listmockCall[0]}}
+	createCalls := map[string][]spltest.MockFuncCall{"Get": funcCalls, "Create": {funcCalls[0], funcCalls[2], funcCalls[3], funcCalls[4], funcCalls[6], funcCalls[8], funcCalls[10], funcCalls[11], funcCalls[12]}, "Update": {funcCalls[0]}, "List": {listmockCall[0], listmockCall[0]}}
+	updateCalls := map[string][]spltest.MockFuncCall{"Get": {funcCalls[0], funcCalls[1], funcCalls[2], funcCalls[3], funcCalls[4], funcCalls[5], funcCalls[6], funcCalls[7], funcCalls[8], funcCalls[9], funcCalls[10], funcCalls[11], funcCalls[12], funcCalls[13]}, "Update": {funcCalls[8], funcCalls[12]}, "List": {listmockCall[0], listmockCall[0]}}
 	statefulSet := enterprisev1.SearchHeadCluster{
 		TypeMeta: metav1.TypeMeta{
 			Kind: "SearchHeadCluster",
@@ -79,7 +82,7 @@ func TestApplySearchHeadCluster(t *testing.T) {
 	revised := statefulSet.DeepCopy()
 	revised.Spec.Image = "splunk/test"
 	reconcile := func(c *spltest.MockClient, cr interface{}) error {
-		_, err := ApplySearchHeadCluster(c, cr.(*enterprisev1.SearchHeadCluster))
+		_, err := ApplySearchHeadCluster(c, nil, cr.(*enterprisev1.SearchHeadCluster))
 		return err
 	}
 	spltest.ReconcileTesterWithoutRedundantCheck(t, "TestApplySearchHeadCluster", &statefulSet, revised, createCalls, updateCalls, reconcile, true)
@@ -89,7 +92,7 @@ func TestApplySearchHeadCluster(t *testing.T) {
 	revised.ObjectMeta.DeletionTimestamp = &currentTime
 	revised.ObjectMeta.Finalizers = []string{"enterprise.splunk.com/delete-pvc"}
 	deleteFunc := func(cr splcommon.MetaObject, c splcommon.ControllerClient) (bool, error) {
-		_, err := ApplySearchHeadCluster(c, cr.(*enterprisev1.SearchHeadCluster))
+		_, err := ApplySearchHeadCluster(c, nil, cr.(*enterprisev1.SearchHeadCluster))
 		return true, err
 	}
 	splunkDeletionTester(t, revised, deleteFunc)
@@ -312,6 +315,187 @@ func TestSearchHeadClusterPodManager(t *testing.T) {
 	searchHeadClusterPodManagerTester(t, method, mockHandlers, 1, splcommon.PhaseScalingDown, statefulSet, wantCalls, nil, statefulSet, pod, pvcList[0], pvcList[1])
 }
 
+func TestSearchHeadClusterRecycleCaptain(t *testing.T) {
+	cr := enterprisev1.SearchHeadCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "stack1",
+			Namespace: "test",
+		},
+	}
+	cr.Status.Captain = "splunk-stack1-search-head-1"
+	client := spltest.NewMockClient()
+	for n, revision := range []string{"v1", "v1", "v2"} {
+		cr.Status.Members = append(cr.Status.Members, enterprisev1.SearchHeadClusterMemberStatus{
+			Name:   fmt.Sprintf("splunk-stack1-search-head-%d", n),
+			Status: "Up",
+		})
+		client.AddObject(&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      fmt.Sprintf("splunk-stack1-search-head-%d", n),
+				Namespace: "test",
+				Labels:    map[string]string{"controller-revision-hash": revision},
+			},
+		})
+	}
+	statefulSet := &appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "splunk-stack1-search-head",
+			Namespace: "test",
+		},
+		Status: appsv1.StatefulSetStatus{
+			Replicas:       3,
+			ReadyReplicas:  3,
+			UpdateRevision: "v2",
+		},
+	}
+	client.AddObject(statefulSet)
+	recorder := record.NewFakeRecorder(10)
+	mockSplunkClient := &spltest.MockHTTPClient{}
+	mgr := &searchHeadClusterPodManager{
+		c:              client,
+		log:            log.WithName("TestSearchHeadClusterRecycleCaptain"),
+		cr:             &cr,
+		eventPublisher: splcommon.NewEventPublisher(recorder, &cr),
+		newSplunkClient: func(managementURI, username, password string) *splclient.SplunkClient {
+			c := splclient.NewSplunkClient(managementURI, username, password)
+			c.Client = mockSplunkClient
+			return c
+		},
+	}
+	transferHandler := spltest.MockHTTPHandler{
+		Method: "POST",
+		URL:    "https://splunk-stack1-search-head-1.splunk-stack1-search-head-headless.test.svc.cluster.local:8089/services/shcluster/member/control/control/transfer_captaincy?mgmt_uri=https%3A%2F%2Fsplunk-stack1-search-head-2.splunk-stack1-search-head-headless.test.svc.cluster.local%3A8089",
+		Status: 200,
+	}
+	prepareRecycle := func(method string, n int32, handlers ...spltest.MockHTTPHandler) {
+		mockSplunkClient = &spltest.MockHTTPClient{}
+		mockSplunkClient.AddHandlers(handlers...)
+		ready, err := mgr.PrepareRecycle(n)
+		if err != nil || ready {
+			t.Errorf("%s: PrepareRecycle() returned %t, %v; want false, nil", method, ready, err)
+		}
+		mockSplunkClient.CheckRequests(t, method)
+	}
+
+	if last := mgr.RecycleLast(); last != 1 {
+		t.Errorf("RecycleLast() returned %d; want 1", last)
+	}
+
+	// the captaincy is transferred to a member running the update revision before the captain is detained
+	prepareRecycle("TestSearchHeadClusterRecycleCaptain(transfer)", 1, transferHandler)
+	want := enterprisev1.CaptaincyTransferInfo{Captain: "splunk-stack1-search-head-1", Target: "splunk-stack1-search-head-2"}
+	if got := cr.Status.CaptaincyTransfer; got.Captain != want.Captain || got.Target != want.Target || got.StartTime == 0 {
+		t.Errorf("PrepareRecycle() recorded captaincy transfer %v; want %v", got, want)
+	}
+
+	// the transfer is not requested again while the election is pending, unless it times out
+	prepareRecycle("TestSearchHeadClusterRecycleCaptain(pending)", 1)
+	cr.Status.CaptaincyTransfer.StartTime = time.Now().Unix() - captaincyTransferTimeout - 1
+	prepareRecycle("TestSearchHeadClusterRecycleCaptain(timeout)", 1, transferHandler)
+
+	// the transfer is complete once another member is elected
+	mockSplunkClient = &spltest.MockHTTPClient{}
+	for n := 0; n < 3; n++ {
+		mockSplunkClient.AddHandlers(spltest.MockHTTPHandler{
+			Method: "GET",
+			URL:    fmt.Sprintf("https://splunk-stack1-search-head-%d.splunk-stack1-search-head-headless.test.svc.cluster.local:8089/services/shcluster/member/info?count=0&output_mode=json", n),
+			Status: 200,
+			Body:   `{"entry":[{"content":{"status":"Up"}}]}`,
+		})
+		if n == 0 {
+			mockSplunkClient.AddHandlers(spltest.MockHTTPHandler{
+				Method: "GET",
+				URL:    "https://splunk-stack1-search-head-0.splunk-stack1-search-head-headless.test.svc.cluster.local:8089/services/shcluster/captain/info?count=0&output_mode=json",
+				Status: 200,
+				Body:   `{"entry":[{"content":{"label":"splunk-stack1-search-head-2","service_ready_flag":true,"initialized_flag":true}}]}`,
+			})
+		}
+	}
+	if err := mgr.updateStatus(statefulSet); err != nil {
+		t.Errorf("updateStatus() returned error: %v", err)
+	}
+	mockSplunkClient.CheckRequests(t, "TestSearchHeadClusterRecycleCaptain(elected)")
+	if cr.Status.Captain != "splunk-stack1-search-head-2" || cr.Status.CaptaincyTransfer != (enterprisev1.CaptaincyTransferInfo{}) {
+		t.Errorf("updateStatus() left captain %s and captaincy transfer %v; want splunk-stack1-search-head-2 and no transfer", cr.Status.Captain, cr.Status.CaptaincyTransfer)
+	}
+	if len(recorder.Events) != 1 {
+		t.Errorf("updateStatus() recorded %d events; want 1 CaptaincyTransfer event", len(recorder.Events))
+	}
+
+	// the former captain is detained once another member is elected
+	if last := mgr.RecycleLast(); last != 2 {
+		t.Errorf("RecycleLast() returned %d; want 2", last)
+	}
+	prepareRecycle("TestSearchHeadClusterRecycleCaptain(detain)", 1, spltest.MockHTTPHandler{
+		Method: "POST",
+		URL:    "https://splunk-stack1-search-head-1.splunk-stack1-search-head-headless.test.svc.cluster.local:8089/services/shcluster/member/control/control/set_manual_detention?manual_detention=on",
+		Status: 200,
+	})
+
+	// a captain with no other member up is detained
+	cr.Status.Captain = "splunk-stack1-search-head-0"
+	cr.Status.Members[1].Status = "ManualDetention"
+	cr.Status.Members[2].Status = "Down"
+	prepareRecycle("TestSearchHeadClusterRecycleCaptain(no member)", 0, spltest.MockHTTPHandler{
+		Method: "POST",
+		URL:    "https://splunk-stack1-search-head-0.splunk-stack1-search-head-headless.test.svc.cluster.local:8089/services/shcluster/member/control/control/set_manual_detention?manual_detention=on",
+		Status: 200,
+	})
+}
+
+func TestSearchHeadClusterDrainTimeout(t *testing.T) {
+	cr := enterprisev1.SearchHeadCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "stack1",
+			Namespace: "test",
+		},
+	}
+	cr.Status.Members = []enterprisev1.SearchHeadClusterMemberStatus{{
+		Name:                        "splunk-stack1-search-head-0",
+		Status:                      "ManualDetention",
+		ActiveHistoricalSearchCount: 2,
+		ActiveRealtimeSearchCount:   1,
+	}}
+	recorder := record.NewFakeRecorder(10)
+	mgr := &searchHeadClusterPodManager{
+		log:            log.WithName("TestSearchHeadClusterDrainTimeout"),
+		cr:             &cr,
+		eventPublisher: splcommon.NewEventPublisher(recorder, &cr),
+	}
+	test := func(wantReady bool, wantKilled int64) {
+		ready, err := mgr.PrepareRecycle(0)
+		if err != nil || ready != wantReady {
+			t.Errorf("PrepareRecycle() returned %t, %v; want %t, nil", ready, err, wantReady)
+		}
+		if cr.Status.KilledSearches != wantKilled {
+			t.Errorf("PrepareRecycle() recorded %d killed searches; want %d", cr.Status.KilledSearches, wantKilled)
+		}
+	}
+
+	// the drain starts when the member is first seen in detention with active searches
+	test(false, 0)
+	if cr.Status.Members[0].DrainStartTime == 0 {
+		t.Errorf("PrepareRecycle() did not record the start of the drain")
+	}
+
+	// the active searches are killed once the drain timeout expires
+	cr.Status.Members[0].DrainStartTime = time.Now().Unix() - defaultSearchDrainTimeout - 1
+	test(true, 3)
+	if len(recorder.Events) != 1 {
+		t.Errorf("PrepareRecycle() recorded %d events; want 1 SearchDrainTimeout event", len(recorder.Events))
+	}
+
+	cr.Spec.SearchDrainTimeout = 3600
+	cr.Status.Members[0].DrainStartTime = time.Now().Unix() - defaultSearchDrainTimeout - 1
+	test(false, 3)
+
+	// nothing is killed once the searches have drained
+	cr.Status.Members[0].ActiveHistoricalSearchCount = 0
+	cr.Status.Members[0].ActiveRealtimeSearchCount = 0
+	cr.Status.Members[0].DrainStartTime = time.Now().Unix() - 3601
+	test(true, 3)
+}
+
 func TestApplyShcSecret(t *testing.T) {
 	method := "ApplyShcSecret"
 	scopedLog := log.WithName(method)
@@ -22,15 +22,24 @@ import (
 
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	enterprisev1 "github.com/splunk/splunk-operator/pkg/apis/enterprise/v1"
+	splclient "github.com/splunk/splunk-operator/pkg/splunk/client"
 	splcommon "github.com/splunk/splunk-operator/pkg/splunk/common"
 	splctrl "github.com/splunk/splunk-operator/pkg/splunk/controller"
 )
 
 // ApplyStandalone reconciles the StatefulSet for N standalone instances of Splunk Enterprise.
-func ApplyStandalone(client splcommon.ControllerClient, cr *enterprisev1.Standalone) (reconcile.Result, error) {
+func ApplyStandalone(client splcommon.ControllerClient, recorder record.EventRecorder, cr *enterprisev1.Standalone) (reconcile.Result, error) {
+	return applyStandalone(client, recorder, cr, splclient.NewAWSS3ClientWithConfig)
+}
+
+// applyStandalone reconciles the state of a Splunk Enterprise standalone instance, running the preflight check of the
+// SmartStore volumes with the S3 clients returned by newS3Client
+func applyStandalone(client splcommon.ControllerClient, recorder record.EventRecorder, cr *enterprisev1.Standalone,
+	newS3Client func(cfg splclient.S3ClientConfig) (splclient.S3Client, error)) (reconcile.Result, error) {
 
 	// unless modified, reconcile for this object will be requeued after 5 seconds
 	result := reconcile.Result{
@@ -42,82 +51,131 @@ func ApplyStandalone(client splcommon.ControllerClient, cr *enterprisev1.Standal
 		cr.Status.ResourceRevMap = make(map[string]string)
 	}
 
+	eventPublisher := splcommon.NewEventPublisher(recorder, cr)
+
 	// validate and updates defaults for CR
 	err := validateStandaloneSpec(&cr.Spec)
 	if err != nil {
+		cr.Status.Phase = splcommon.PhaseError
+		setErrorCondition(cr, &cr.Status.Conditions, "", reasonInvalidSpec, err)
+		cr.Status.ObservedGeneration = cr.GetGeneration()
+		client.Status().Update(context.TODO(), cr)
 		return result, err
 	}
 
 	// updates status after function completes
 	cr.Status.Phase = splcommon.PhaseError
 	cr.Status.Replicas = cr.Spec.Replicas
+	defer func() {
+		setPhaseConditions(cr, &cr.Status.Conditions, cr.Status.Phase)
+		cr.Status.ObservedGeneration = cr.GetGeneration()
+		client.Status().Update(context.TODO(), cr)
+	}()
 
 	if !reflect.DeepEqual(cr.Status.SmartStore, cr.Spec.SmartStore) ||
 		AreRemoteVolumeKeysChanged(client, cr, SplunkStandalone, &cr.Spec.SmartStore, cr.Status.ResourceRevMap, &err) {
 
 		if err != nil {
+			setErrorCondition(cr, &cr.Status.Conditions, enterprisev1.ConditionSmartStoreConfigured, reasonSmartStoreFailed, err)
 			return result, err
 		}
 
-		_, _, err := ApplySmartstoreConfigMap(client, cr, &cr.Spec.SmartStore)
+		// the remote volumes must be reachable with their credentials before the configuration is rolled out
+		volumes, err := checkSmartstoreVolumes(client, cr, &cr.Spec.SmartStore, newS3Client)
+		cr.Status.SmartStoreVolumes = volumes
 		if err != nil {
+			setErrorCondition(cr, &cr.Status.Conditions, enterprisev1.ConditionSmartStoreConfigured, reasonSmartStorePreflight, err)
+			return result, err
+		}
+
+		_, _, err = ApplySmartstoreConfigMap(client, cr, &cr.Spec.SmartStore)
+		if err != nil {
+			setErrorCondition(cr, &cr.Status.Conditions, enterprisev1.ConditionSmartStoreConfigured, reasonSmartStoreFailed, err)
 			return result, err
 		}
 
 		cr.Status.SmartStore = cr.Spec.SmartStore
+		setSmartStoreCondition(cr, &cr.Status.Conditions, &cr.Spec.SmartStore)
 	}
 
 	cr.Status.Selector = fmt.Sprintf("app.kubernetes.io/instance=splunk-%s-standalone", cr.GetName())
-	defer func() {
-		client.Status().Update(context.TODO(), cr)
-	}()
 
 	// create or update general config resources
-	_, err = ApplySplunkConfig(client, cr, cr.Spec.CommonSplunkSpec, SplunkStandalone)
+	namespaceScopedSecret, err := ApplySplunkConfig(client, cr, cr.Spec.CommonSplunkSpec, SplunkStandalone)
 	if err != nil {
+		setErrorCondition(cr, &cr.Status.Conditions, enterprisev1.ConditionSecretsSynced, reasonSecretsSyncFailed, err)
 		return result, err
 	}
+	setCondition(cr, &cr.Status.Conditions, enterprisev1.ConditionSecretsSynced, corev1.ConditionTrue, reasonSecretsSynced, "")
 
 	// check if deletion has been requested
 	if cr.ObjectMeta.DeletionTimestamp != nil {
 		//update monitoring console configMap after custom resource deletion is requested
 		err = ApplyMonitoringConsole(client, cr, cr.Spec.CommonSplunkSpec, getStandaloneExtraEnv(cr, cr.Spec.Replicas))
 		if err != nil {
+			setErrorCondition(cr, &cr.Status.Conditions, "", reasonMonitoringConsoleFailed, err)
 			return result, err
 		}
 
 		DeleteOwnerReferencesForResources(client, cr, &cr.Spec.SmartStore)
-		terminating, err := splctrl.CheckForDeletion(cr, client)
+		terminating, err := splctrl.CheckForDeletion(cr, client, eventPublisher)
 		if terminating && err != nil { // don't bother if no error, since it will just be removed immmediately after
 			cr.Status.Phase = splcommon.PhaseTerminating
+			setErrorCondition(cr, &cr.Status.Conditions, "", reasonDeletionFailed, err)
 		} else {
 			result.Requeue = false
 		}
 		return result, err
 	}
 
+	setLicenseCondition(client, cr, &cr.Status.Conditions, cr.Spec.LicenseMasterRef)
+
 	// create or update a headless service
 	err = splctrl.ApplyService(client, getSplunkService(cr, &cr.Spec.CommonSplunkSpec, SplunkStandalone, true))
 	if err != nil {
+		setErrorCondition(cr, &cr.Status.Conditions, "", reasonServiceFailed, err)
 		return result, err
 	}
 
 	// create or update a regular service
 	err = splctrl.ApplyService(client, getSplunkService(cr, &cr.Spec.CommonSplunkSpec, SplunkStandalone, false))
 	if err != nil {
+		setErrorCondition(cr, &cr.Status.Conditions, "", reasonServiceFailed, err)
 		return result, err
 	}
 
+	// annotate the service account of the pods with the IAM role of the remote volumes, before they are created
+	warning, err := applyRemoteVolumeServiceAccount(client, &cr.Spec.CommonSplunkSpec, cr.GetNamespace(), &cr.Spec.SmartStore)
+	if err != nil {
+		setErrorCondition(cr, &cr.Status.Conditions, "", reasonServiceAccountFailed, err)
+		return result, err
+	}
+	if warning != "" {
+		eventPublisher.Warning(splcommon.EventReasonServiceAccountMissing, "%s", warning)
+	}
+
 	// create or update statefulset
 	statefulSet, err := getStandaloneStatefulSet(client, cr)
 	if err != nil {
+		setErrorCondition(cr, &cr.Status.Conditions, "", reasonStatefulSetFailed, err)
 		return result, err
 	}
 
-	mgr := splctrl.DefaultStatefulSetPodManager{}
+	_, err = applyUpgradeOrder(client, cr, &cr.Status.Conditions, cr.Status.Image, cr.Status.Version, statefulSet, eventPublisher)
+	if err != nil {
+		setErrorCondition(cr, &cr.Status.Conditions, enterprisev1.ConditionImageUpToDate, reasonUpgradeFailed, err)
+		return result, err
+	}
+	err = applySplunkPodDisruptionBudget(client, cr, &cr.Spec.PodDisruptionBudget, getDefaultDisruptionBudget(SplunkStandalone, cr.Spec.Replicas, 0), statefulSet)
+	if err != nil {
+		setErrorCondition(cr, &cr.Status.Conditions, "", reasonDisruptionBudgetFailed, err)
+		return result, err
+	}
+	mgr := splctrl.DefaultStatefulSetPodManager{EventPublisher: eventPublisher}
 	phase, err := mgr.Update(client, statefulSet, cr.Spec.Replicas)
 	cr.Status.ReadyReplicas = statefulSet.Status.ReadyReplicas
 	if err != nil {
+		setErrorCondition(cr, &cr.Status.Conditions, "", reasonStatefulSetFailed, err)
 		return result, err
 	}
 	cr.Status.Phase = phase
@@ -126,10 +184,26 @@ func ApplyStandalone(client splcommon.ControllerClient, cr *enterprisev1.Standal
 	if cr.Status.Phase == splcommon.PhaseReady {
 		err = ApplyMonitoringConsole(client, cr, cr.Spec.CommonSplunkSpec, getStandaloneExtraEnv(cr, cr.Spec.Replicas))
 		if err != nil {
+			setErrorCondition(cr, &cr.Status.Conditions, "", reasonMonitoringConsoleFailed, err)
+			return result, err
+		}
+		err = updateVersion(&cr.Spec.CommonSplunkSpec, cr.Status.Image, &cr.Status.Version, statefulSet, func() *splclient.SplunkClient { return getServiceClient(cr, SplunkStandalone, namespaceScopedSecret) })
+		if err != nil {
+			setErrorCondition(cr, &cr.Status.Conditions, "", reasonServerInfoFailed, err)
+			return result, err
+		}
+		setRunningImage(cr, &cr.Status.Conditions, &cr.Status.Image, cr.Spec.Image, statefulSet, eventPublisher)
+
+		// install apps from the app repository
+		err = ApplyAppFramework(client, cr, &cr.Spec.CommonSplunkSpec, &cr.Status.AppContext, SplunkStandalone, cr.Spec.Replicas, namespaceScopedSecret)
+		if err != nil {
+			setErrorCondition(cr, &cr.Status.Conditions, "", reasonAppFrameworkFailed, err)
 			return result, err
 		}
 		result.Requeue = false
+		setAppFrameworkRequeue(&result, &cr.Spec.AppFrameworkConfig, &cr.Status.AppContext)
 	}
+	setReconciledCondition(cr, &cr.Status.Conditions)
 	return result, nil
 }
 
@@ -146,6 +220,8 @@ func getStandaloneStatefulSet(client splcommon.ControllerClient, cr *enterprisev
 	if needToSetupSplunkOperatorApp {
 		setupInitContainer(&ss.Spec.Template, cr.Spec.Image, cr.Spec.ImagePullPolicy, commandForStandaloneSmartstore)
 	}
+	addRemoteVolumeCABundles(&ss.Spec.Template, &cr.Spec.SmartStore)
+	addRemoteVolumeGCSCredentials(&ss.Spec.Template, &cr.Spec.SmartStore)
 
 	return ss, nil
 }
@@ -161,5 +237,10 @@ func validateStandaloneSpec(spec *enterprisev1.StandaloneSpec) error {
 		return err
 	}
 
+	err = ValidateAppFrameworkSpec(&spec.AppFrameworkConfig, SplunkStandalone)
+	if err != nil {
+		return err
+	}
+
 	return validateCommonSplunkSpec(&spec.CommonSplunkSpec)
 }
@@ -15,11 +15,13 @@
 package enterprise
 
 import (
+	"errors"
 	"testing"
 	"time"
 
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	enterprisev1 "github.com/splunk/splunk-operator/pkg/apis/enterprise/v1"
@@ -39,6 +41,7 @@ func TestApplyStandalone(t *testing.T) {
 		{MetaName: "*v1.Secret-test-splunk-stack1-standalone-secret-v1"},
 		{MetaName: "*v1.ConfigMap-test-splunk-stack1-standalone-smartstore"},
 		{MetaName: "*v1.ConfigMap-test-splunk-stack1-standalone-smartstore"},
+		{MetaName: "*v1beta1.PodDisruptionBudget-test-splunk-stack1-standalone"},
 		{MetaName: "*v1.StatefulSet-test-splunk-stack1-standalone"},
 	}
 	labels := map[string]string{
@@ -52,8 +55,8 @@ func TestApplyStandalone(t *testing.T) {
 	listmockCall := []spltest.MockFuncCall{
 		{ListOpts: listOpts}}
 
-	createCalls := map[string][]spltest.MockFuncCall{"Get": funcCalls, "Create": {funcCalls[0], funcCalls[2], funcCalls[3], funcCalls[5], funcCalls[8]}, "Update": {funcCalls[0]}, "List": {listmockCall[0]}}
-	updateCalls := map[string][]spltest.MockFuncCall{"Get": {funcCalls[0], funcCalls[1], funcCalls[2], funcCalls[3], funcCalls[4], funcCalls[5], funcCalls[6], funcCalls[7], funcCalls[8]}, "Update": {funcCalls[8]}, "List": {listmockCall[0]}}
+	createCalls := map[string][]spltest.MockFuncCall{"Get": funcCalls, "Create": {funcCalls[0], funcCalls[2], funcCalls[3], funcCalls[5], funcCalls[9]}, "Update": {funcCalls[0]}, "List": {listmockCall[0]}}
+	updateCalls := map[string][]spltest.MockFuncCall{"Get": {funcCalls[0], funcCalls[1], funcCalls[2], funcCalls[3], funcCalls[4], funcCalls[5], funcCalls[6], funcCalls[7], funcCalls[8], funcCalls[9]}, "Update": {funcCalls[9]}, "List": {listmockCall[0]}}
 	current := enterprisev1.Standalone{
 		TypeMeta: metav1.TypeMeta{
 			Kind: "Standalone",
@@ -66,7 +69,7 @@ func TestApplyStandalone(t *testing.T) {
 	revised := current.DeepCopy()
 	revised.Spec.Image = "splunk/test"
 	reconcile := func(c *spltest.MockClient, cr interface{}) error {
-		_, err := ApplyStandalone(c, cr.(*enterprisev1.Standalone))
+		_, err := ApplyStandalone(c, nil, cr.(*enterprisev1.Standalone))
 		return err
 	}
 	spltest.ReconcileTesterWithoutRedundantCheck(t, "TestApplyStandalone", &current, revised, createCalls, updateCalls, reconcile, true)
@@ -76,7 +79,7 @@ func TestApplyStandalone(t *testing.T) {
 	revised.ObjectMeta.DeletionTimestamp = &currentTime
 	revised.ObjectMeta.Finalizers = []string{"enterprise.splunk.com/delete-pvc"}
 	deleteFunc := func(cr splcommon.MetaObject, c splcommon.ControllerClient) (bool, error) {
-		_, err := ApplyStandalone(c, cr.(*enterprisev1.Standalone))
+		_, err := ApplyStandalone(c, nil, cr.(*enterprisev1.Standalone))
 		return true, err
 	}
 	splunkDeletionTester(t, revised, deleteFunc)
@@ -84,6 +87,8 @@ func TestApplyStandalone(t *testing.T) {
 
 func TestApplyStandaloneWithSmartstore(t *testing.T) {
 	funcCalls := []spltest.MockFuncCall{
+		{MetaName: "*v1.Secret-test-splunk-test-secret"},
+		{MetaName: "*v1.Secret-test-splunk-test-secret"},
 		{MetaName: "*v1.Secret-test-splunk-test-secret"},
 		{MetaName: "*v1.Secret-test-splunk-test-secret"},
 		{MetaName: "*v1.ConfigMap-test-splunk-stack1-standalone-smartstore"},
@@ -96,6 +101,7 @@ func TestApplyStandaloneWithSmartstore(t *testing.T) {
 		{MetaName: "*v1.Secret-test-splunk-stack1-standalone-secret-v1"},
 		{MetaName: "*v1.ConfigMap-test-splunk-stack1-standalone-smartstore"},
 		{MetaName: "*v1.ConfigMap-test-splunk-stack1-standalone-smartstore"},
+		{MetaName: "*v1beta1.PodDisruptionBudget-test-splunk-stack1-standalone"},
 		{MetaName: "*v1.StatefulSet-test-splunk-stack1-standalone"},
 	}
 	labels := map[string]string{
@@ -109,8 +115,8 @@ func TestApplyStandaloneWithSmartstore(t *testing.T) {
 	listmockCall := []spltest.MockFuncCall{
 		{ListOpts: listOpts}}
 
-	createCalls := map[string][]spltest.MockFuncCall{"Get": funcCalls, "Create": {funcCalls[2], funcCalls[6], funcCalls[7], funcCalls[9], funcCalls[12]}, "Update": {funcCalls[0]}, "List": {listmockCall[0]}}
-	updateCalls := map[string][]spltest.MockFuncCall{"Get": {funcCalls[0], funcCalls[1], funcCalls[2], funcCalls[3], funcCalls[4], funcCalls[5], funcCalls[6], funcCalls[7], funcCalls[8], funcCalls[9], funcCalls[10], funcCalls[11], funcCalls[12]}, "Update": {funcCalls[11], funcCalls[12]}, "List": {listmockCall[0]}}
+	createCalls := map[string][]spltest.MockFuncCall{"Get": funcCalls, "Create": {funcCalls[4], funcCalls[8], funcCalls[9], funcCalls[11], funcCalls[15]}, "Update": {funcCalls[0]}, "List": {listmockCall[0]}}
+	updateCalls := map[string][]spltest.MockFuncCall{"Get": {funcCalls[0], funcCalls[1], funcCalls[2], funcCalls[3], funcCalls[4], funcCalls[5], funcCalls[6], funcCalls[7], funcCalls[8], funcCalls[9], funcCalls[10], funcCalls[11], funcCalls[12], funcCalls[13], funcCalls[14], funcCalls[15]}, "Update": {funcCalls[13], funcCalls[15]}, "List": {listmockCall[0]}}
 
 	current := enterprisev1.Standalone{
 		TypeMeta: metav1.TypeMeta{
@@ -147,7 +153,7 @@ func TestApplyStandaloneWithSmartstore(t *testing.T) {
 	client := spltest.NewMockClient()
 
 	// Without S3 keys, ApplyStandalone should fail
-	_, err := ApplyStandalone(client, &current)
+	_, err := applyStandalone(client, nil, &current, newMockS3Client(&mockS3Client{}))
 	if err == nil {
 		t.Errorf("ApplyStandalone should fail without S3 secrets configured")
 	}
@@ -168,7 +174,7 @@ func TestApplyStandaloneWithSmartstore(t *testing.T) {
 	revised := current.DeepCopy()
 	revised.Spec.Image = "splunk/test"
 	reconcile := func(c *spltest.MockClient, cr interface{}) error {
-		_, err := ApplyStandalone(c, cr.(*enterprisev1.Standalone))
+		_, err := applyStandalone(c, nil, cr.(*enterprisev1.Standalone), newMockS3Client(&mockS3Client{}))
 		return err
 	}
 	spltest.ReconcileTesterWithoutRedundantCheck(t, "TestApplyStandaloneWithSmartstore", &current, revised, createCalls, updateCalls, reconcile, true, secret)
@@ -282,7 +288,7 @@ func TestApplyStandaloneSmartstoreKeyChangeDetection(t *testing.T) {
 		t.Errorf(err.Error())
 	}
 
-	_, err = ApplyStandalone(client, &current)
+	_, err = applyStandalone(client, nil, &current, newMockS3Client(&mockS3Client{}))
 	if err != nil {
 		t.Errorf("ApplyStandalone should not fail with full configuration")
 	}
@@ -302,3 +308,67 @@ func TestApplyStandaloneSmartstoreKeyChangeDetection(t *testing.T) {
 		t.Errorf("Key change was not detected %v", err)
 	}
 }
+
+func TestApplyStandaloneSmartstorePreflight(t *testing.T) {
+	current := enterprisev1.Standalone{
+		TypeMeta: metav1.TypeMeta{
+			Kind: "Standalone",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "stack1",
+			Namespace: "test",
+		},
+		Spec: enterprisev1.StandaloneSpec{
+			Replicas: 1,
+			SmartStore: enterprisev1.SmartStoreSpec{
+				VolList: []enterprisev1.VolumeSpec{
+					{Name: "msos_s2s3_vol", Endpoint: "https://s3-eu-west-2.amazonaws.com", Path: "testbucket-rs-london", SecretRef: "splunk-test-secret"},
+				},
+				IndexList: []enterprisev1.IndexSpec{
+					{Name: "salesdata1", RemotePath: "remotepath1",
+						IndexAndGlobalCommonSpec: enterprisev1.IndexAndGlobalCommonSpec{
+							VolName: "msos_s2s3_vol"},
+					},
+				},
+			},
+		},
+	}
+	client := spltest.NewMockClient()
+	secret, err := splutil.ApplyNamespaceScopedSecretObject(client, "test")
+	if err != nil {
+		t.Errorf(err.Error())
+	}
+	secret.Data[s3AccessKey] = []byte("abcdJDckRkxhMEdmSk5FekFRRzBFOXV6bGNldzJSWE9IenhVUy80aa")
+	secret.Data[s3SecretKey] = []byte("g4NVp0a29PTzlPdGczWk1vekVUcVBSa0o4NkhBWWMvR1NadDV4YVEy")
+	_, err = splctrl.ApplySecret(client, secret)
+	if err != nil {
+		t.Errorf(err.Error())
+	}
+	namespacedName := types.NamespacedName{Namespace: "test", Name: GetSplunkSmartstoreConfigMapName("stack1", "Standalone")}
+
+	// the configuration is not rolled out while the volume does not accept the probe object
+	s3Client := &mockS3Client{putErr: errors.New("AccessDenied")}
+	if _, err = applyStandalone(client, nil, &current, newMockS3Client(s3Client)); err == nil {
+		t.Errorf("ApplyStandalone should fail when the preflight check of the remote volumes fails")
+	}
+	if _, err = splctrl.GetConfigMap(client, namespacedName); err == nil {
+		t.Errorf("ApplyStandalone created the smartstore configMap although the preflight check failed")
+	}
+	checkCondition(t, current.Status.Conditions, enterprisev1.ConditionSmartStoreConfigured, corev1.ConditionFalse, reasonSmartStorePreflight)
+	if len(current.Status.SmartStoreVolumes) != 1 || current.Status.SmartStoreVolumes[0].Result != enterprisev1.RemoteVolumeCheckFailed {
+		t.Errorf("ApplyStandalone did not report the failed volume: %v", current.Status.SmartStoreVolumes)
+	}
+
+	// once the probe object is accepted, the configuration is rolled out
+	s3Client.putErr = nil
+	if _, err = applyStandalone(client, nil, &current, newMockS3Client(s3Client)); err != nil {
+		t.Errorf("ApplyStandalone should not fail once the preflight check passes: %v", err)
+	}
+	if _, err = splctrl.GetConfigMap(client, namespacedName); err != nil {
+		t.Errorf("ApplyStandalone did not create the smartstore configMap: %v", err)
+	}
+	checkCondition(t, current.Status.Conditions, enterprisev1.ConditionSmartStoreConfigured, corev1.ConditionTrue, reasonSmartStoreConfigured)
+	if len(current.Status.SmartStoreVolumes) != 1 || current.Status.SmartStoreVolumes[0].Result != enterprisev1.RemoteVolumeCheckPassed {
+		t.Errorf("ApplyStandalone did not report the checked volume: %v", current.Status.SmartStoreVolumes)
+	}
+}
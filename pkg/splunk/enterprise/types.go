@@ -38,6 +38,12 @@ const (
 
 	// SplunkMonitoringConsole is a single instance of Splunk monitor for mc
 	SplunkMonitoringConsole InstanceType = "monitoring-console"
+
+	// SplunkHeavyForwarder is an instance that parses and forwards data to indexers
+	SplunkHeavyForwarder InstanceType = "heavy-forwarder"
+
+	// SplunkDeploymentServer is an instance that distributes apps to groups of deployment clients
+	SplunkDeploymentServer InstanceType = "deployment-server"
 )
 
 // ToString returns a string for a given InstanceType
@@ -63,6 +69,10 @@ func (instanceType InstanceType) ToRole() string {
 		role = "splunk_license_master"
 	case SplunkMonitoringConsole:
 		role = "splunk_monitor"
+	case SplunkHeavyForwarder:
+		role = "splunk_heavy_forwarder"
+	case SplunkDeploymentServer:
+		role = "splunk_deployment_server"
 	}
 	return role
 }
@@ -85,6 +95,10 @@ func (instanceType InstanceType) ToKind() string {
 		kind = "license-master"
 	case SplunkMonitoringConsole:
 		kind = "monitoring-console"
+	case SplunkHeavyForwarder:
+		kind = "heavy-forwarder"
+	case SplunkDeploymentServer:
+		kind = "deployment-server"
 	}
 	return kind
 }
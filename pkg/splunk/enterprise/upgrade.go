@@ -0,0 +1,221 @@
+// Copyright (c) 2018-2021 Splunk Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package enterprise
+
+import (
+	"context"
+	"fmt"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	enterprisev1 "github.com/splunk/splunk-operator/pkg/apis/enterprise/v1"
+	splcommon "github.com/splunk/splunk-operator/pkg/splunk/common"
+)
+
+// upstreamResource is a custom resource whose pods must be upgraded before those of the custom resources using it
+type upstreamResource struct {
+	kind    string
+	name    string
+	image   string
+	running string
+	phase   splcommon.Phase
+}
+
+// holds returns true if an upstream resource is being upgraded to image, and its pods are not all ready on it yet
+func (u *upstreamResource) holds(image string) bool {
+	return u.image == image && (u.running != image || u.phase != splcommon.PhaseReady)
+}
+
+// getUpstreamResources returns the custom resources that must be ready on a new image before cr is upgraded to it,
+// following the order required by Splunk: LicenseMaster, ClusterMaster, SearchHeadCluster and then IndexerCluster.
+func getUpstreamResources(c splcommon.ControllerClient, cr splcommon.MetaObject) ([]upstreamResource, error) {
+	var upstream []upstreamResource
+	var err error
+	switch cr := cr.(type) {
+	case *enterprisev1.ClusterMaster:
+		upstream, err = appendLicenseMaster(c, cr, cr.Spec.LicenseMasterRef, upstream)
+	case *enterprisev1.SearchHeadCluster:
+		upstream, err = appendLicenseMaster(c, cr, cr.Spec.LicenseMasterRef, upstream)
+		if err == nil {
+			upstream, err = appendClusterMaster(c, cr, cr.Spec.ClusterMasterRef, upstream)
+		}
+	case *enterprisev1.IndexerCluster:
+		// the license master is upstream of the cluster master
+		upstream, err = appendClusterMaster(c, cr, cr.Spec.ClusterMasterRef, upstream)
+		if err == nil {
+			upstream, err = appendSearchHeadClusters(c, cr, upstream)
+		}
+	}
+	return upstream, err
+}
+
+// appendLicenseMaster appends the license master referenced by a custom resource to its upstream resources
+func appendLicenseMaster(c splcommon.ControllerClient, cr splcommon.MetaObject, ref corev1.ObjectReference, upstream []upstreamResource) ([]upstreamResource, error) {
+	if ref.Name == "" {
+		return upstream, nil
+	}
+	var lm enterprisev1.LicenseMaster
+	err := c.Get(context.TODO(), getReferenceName(cr, ref), &lm)
+	if k8serrors.IsNotFound(err) {
+		return upstream, nil
+	} else if err != nil {
+		return upstream, fmt.Errorf("Unable to get license master %s: %v", ref.Name, err)
+	}
+	return append(upstream, upstreamResource{kind: "LicenseMaster", name: lm.GetName(),
+		image: GetSplunkImage(lm.Spec.Image), running: lm.Status.Image, phase: lm.Status.Phase}), nil
+}
+
+// appendClusterMaster appends the cluster master referenced by a custom resource to its upstream resources
+func appendClusterMaster(c splcommon.ControllerClient, cr splcommon.MetaObject, ref corev1.ObjectReference, upstream []upstreamResource) ([]upstreamResource, error) {
+	if ref.Name == "" {
+		return upstream, nil
+	}
+	var cm enterprisev1.ClusterMaster
+	err := c.Get(context.TODO(), getReferenceName(cr, ref), &cm)
+	if k8serrors.IsNotFound(err) {
+		return upstream, nil
+	} else if err != nil {
+		return upstream, fmt.Errorf("Unable to get cluster master %s: %v", ref.Name, err)
+	}
+	return append(upstream, upstreamResource{kind: "ClusterMaster", name: cm.GetName(),
+		image: GetSplunkImage(cm.Spec.Image), running: cm.Status.Image, phase: cm.Status.Phase}), nil
+}
+
+// appendSearchHeadClusters appends the search head clusters that search an indexer cluster to its upstream resources
+func appendSearchHeadClusters(c splcommon.ControllerClient, cr *enterprisev1.IndexerCluster, upstream []upstreamResource) ([]upstreamResource, error) {
+	var list enterprisev1.SearchHeadClusterList
+	err := c.List(context.TODO(), &list, client.InNamespace(cr.GetNamespace()))
+	if err != nil {
+		return upstream, fmt.Errorf("Unable to list search head clusters: %v", err)
+	}
+	for _, shc := range list.Items {
+		if shc.Spec.ClusterMasterRef.Name != cr.Spec.ClusterMasterRef.Name || getReferenceName(&shc, shc.Spec.ClusterMasterRef).Namespace != cr.GetNamespace() {
+			continue
+		}
+		upstream = append(upstream, upstreamResource{kind: "SearchHeadCluster", name: shc.GetName(),
+			image: GetSplunkImage(shc.Spec.Image), running: shc.Status.Image, phase: shc.Status.Phase})
+	}
+	return upstream, nil
+}
+
+// getReferenceName returns the namespaced name of a custom resource referenced by cr, which defaults to its namespace
+func getReferenceName(cr splcommon.MetaObject, ref corev1.ObjectReference) types.NamespacedName {
+	namespacedName := types.NamespacedName{Namespace: ref.Namespace, Name: ref.Name}
+	if namespacedName.Namespace == "" {
+		namespacedName.Namespace = cr.GetNamespace()
+	}
+	return namespacedName
+}
+
+// applyUpgradeOrder holds the image upgrade of a StatefulSet of cr until its upstream resources are ready on the new image:
+// while one of them is being upgraded to the same image, the pods keep running the image recorded in the status of cr.
+// Upgrades refused by checkImageUpgrade are never rolled out. It reports the progress of the upgrade in the ImageUpToDate
+// condition, and returns true while the new image is rolled out.
+func applyUpgradeOrder(c splcommon.ControllerClient, cr splcommon.MetaObject, conditions *[]enterprisev1.Condition, running, runningVersion string, statefulSet *appsv1.StatefulSet, eventPublisher *splcommon.EventPublisher) (bool, error) {
+	image := statefulSet.Spec.Template.Spec.Containers[0].Image
+	if running == "" || running == image {
+		return false, nil
+	}
+
+	refusal, err := checkImageUpgrade(c, cr, running, runningVersion, image)
+	if err != nil {
+		return false, err
+	}
+	if refusal != "" {
+		setStatefulSetImage(statefulSet, image, running)
+		if current := getCondition(*conditions, enterprisev1.ConditionImageUpToDate); current == nil || current.Message != refusal {
+			eventPublisher.Warning(splcommon.EventReasonUpgradeRefused, "%s", refusal)
+		}
+		setCondition(cr, conditions, enterprisev1.ConditionImageUpToDate, corev1.ConditionFalse, reasonUpgradeRefused, refusal)
+		return false, nil
+	}
+
+	upstream, err := getUpstreamResources(c, cr)
+	if err != nil {
+		return false, err
+	}
+	for _, u := range upstream {
+		if u.holds(image) {
+			setStatefulSetImage(statefulSet, image, running)
+			setCondition(cr, conditions, enterprisev1.ConditionImageUpToDate, corev1.ConditionFalse, reasonUpgradeWaiting,
+				fmt.Sprintf("Waiting for %s %s to be ready on image %s", u.kind, u.name, image))
+			return false, nil
+		}
+	}
+
+	if current := getCondition(*conditions, enterprisev1.ConditionImageUpToDate); current == nil || current.Reason != reasonUpgradeInProgress {
+		eventPublisher.Normal(splcommon.EventReasonUpgradeStarted, "Upgrading from image %s to %s", running, image)
+	}
+	setCondition(cr, conditions, enterprisev1.ConditionImageUpToDate, corev1.ConditionFalse, reasonUpgradeInProgress,
+		fmt.Sprintf("Upgrading from image %s to %s", running, image))
+	return true, nil
+}
+
+// setStatefulSetImage replaces an image used by the containers of a StatefulSet with another one
+func setStatefulSetImage(statefulSet *appsv1.StatefulSet, image, replacement string) {
+	podSpec := &statefulSet.Spec.Template.Spec
+	for i := range podSpec.InitContainers {
+		if podSpec.InitContainers[i].Image == image {
+			podSpec.InitContainers[i].Image = replacement
+		}
+	}
+	for i := range podSpec.Containers {
+		if podSpec.Containers[i].Image == image {
+			podSpec.Containers[i].Image = replacement
+		}
+	}
+}
+
+// isUpgradeComplete returns true if the ready pods of a StatefulSet were upgraded from the image recorded in the status of its custom resource
+func isUpgradeComplete(running string, statefulSet *appsv1.StatefulSet) bool {
+	return running != "" && running != statefulSet.Spec.Template.Spec.Containers[0].Image
+}
+
+// setRunningImage records in the status of cr the image run by the pods of a StatefulSet once they are all ready,
+// unless its upgrade to the image of the spec is held by applyUpgradeOrder.
+func setRunningImage(cr splcommon.MetaObject, conditions *[]enterprisev1.Condition, running *string, specImage string, statefulSet *appsv1.StatefulSet, eventPublisher *splcommon.EventPublisher) {
+	image := statefulSet.Spec.Template.Spec.Containers[0].Image
+	if image != specImage {
+		return
+	}
+	if isUpgradeComplete(*running, statefulSet) {
+		eventPublisher.Normal(splcommon.EventReasonUpgradeComplete, "Upgraded from image %s to %s", *running, image)
+	}
+	*running = image
+	setCondition(cr, conditions, enterprisev1.ConditionImageUpToDate, corev1.ConditionTrue, reasonImageUpToDate, fmt.Sprintf("Running image %s", image))
+}
+
+// getUpgradingIndexerClusters returns the names of the other indexer clusters of the same cluster master that are being upgraded
+func getUpgradingIndexerClusters(c splcommon.ControllerClient, cr *enterprisev1.IndexerCluster) ([]string, error) {
+	var list enterprisev1.IndexerClusterList
+	err := c.List(context.TODO(), &list, client.InNamespace(cr.GetNamespace()))
+	if err != nil {
+		return nil, fmt.Errorf("Unable to list indexer clusters: %v", err)
+	}
+	var upgrading []string
+	for _, idxc := range list.Items {
+		if idxc.GetName() == cr.GetName() || idxc.Spec.ClusterMasterRef.Name != cr.Spec.ClusterMasterRef.Name {
+			continue
+		}
+		if idxc.Status.Image != "" && idxc.Status.Image != GetSplunkImage(idxc.Spec.Image) {
+			upgrading = append(upgrading, idxc.GetName())
+		}
+	}
+	return upgrading, nil
+}
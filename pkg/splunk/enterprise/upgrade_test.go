@@ -0,0 +1,204 @@
+// Copyright (c) 2018-2021 Splunk Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package enterprise
+
+import (
+	"reflect"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+
+	enterprisev1 "github.com/splunk/splunk-operator/pkg/apis/enterprise/v1"
+	splcommon "github.com/splunk/splunk-operator/pkg/splunk/common"
+	spltest "github.com/splunk/splunk-operator/pkg/splunk/test"
+)
+
+const (
+	oldTestImage = "splunk/splunk:8.1.0"
+	newTestImage = "splunk/splunk:8.2.0"
+)
+
+func newUpgradeTestStatefulSet(image string) *appsv1.StatefulSet {
+	return &appsv1.StatefulSet{
+		Spec: appsv1.StatefulSetSpec{
+			Template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{
+					InitContainers: []corev1.Container{{Name: "init", Image: image}},
+					Containers:     []corev1.Container{{Name: "splunk", Image: image}},
+				},
+			},
+		},
+	}
+}
+
+func TestApplyUpgradeOrder(t *testing.T) {
+	c := spltest.NewMockClient()
+	lm := enterprisev1.LicenseMaster{
+		ObjectMeta: metav1.ObjectMeta{Name: "stack1", Namespace: "test"},
+	}
+	lm.Spec.Image = newTestImage
+	lm.Status.Image = newTestImage
+	lm.Status.Phase = splcommon.PhaseReady
+	cm := enterprisev1.ClusterMaster{
+		ObjectMeta: metav1.ObjectMeta{Name: "master1", Namespace: "test"},
+	}
+	cm.Spec.Image = newTestImage
+	cm.Spec.LicenseMasterRef.Name = "stack1"
+	cm.Status.Image = oldTestImage
+	cm.Status.Phase = splcommon.PhaseUpdating
+	c.AddObject(&lm)
+	c.AddObject(&cm)
+
+	shc := enterprisev1.SearchHeadCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "stack1", Namespace: "test"},
+	}
+	shc.Spec.Image = newTestImage
+	shc.Spec.ClusterMasterRef.Name = "master1"
+	shc.Status.Image = oldTestImage
+	recorder := record.NewFakeRecorder(10)
+	eventPublisher := splcommon.NewEventPublisher(recorder, &shc)
+
+	test := func(cr splcommon.MetaObject, conditions *[]enterprisev1.Condition, running string, wantUpgrading bool, wantImage, wantReason string) {
+		statefulSet := newUpgradeTestStatefulSet(newTestImage)
+		upgrading, err := applyUpgradeOrder(c, cr, conditions, running, "", statefulSet, eventPublisher)
+		if err != nil {
+			t.Errorf("applyUpgradeOrder() returned error: %v", err)
+		}
+		if upgrading != wantUpgrading {
+			t.Errorf("applyUpgradeOrder() returned %t; want %t", upgrading, wantUpgrading)
+		}
+		podSpec := statefulSet.Spec.Template.Spec
+		if podSpec.Containers[0].Image != wantImage || podSpec.InitContainers[0].Image != wantImage {
+			t.Errorf("applyUpgradeOrder() set images %s and %s; want %s", podSpec.Containers[0].Image, podSpec.InitContainers[0].Image, wantImage)
+		}
+		if wantReason == "" {
+			if getCondition(*conditions, enterprisev1.ConditionImageUpToDate) != nil {
+				t.Errorf("applyUpgradeOrder() set condition %s; want none", enterprisev1.ConditionImageUpToDate)
+			}
+		} else {
+			checkCondition(t, *conditions, enterprisev1.ConditionImageUpToDate, corev1.ConditionFalse, wantReason)
+		}
+	}
+
+	// nothing is held for new resources, or once they run the new image
+	test(&shc, &shc.Status.Conditions, "", false, newTestImage, "")
+	test(&shc, &shc.Status.Conditions, newTestImage, false, newTestImage, "")
+
+	// the search heads wait for the cluster master to be ready on the new image
+	test(&shc, &shc.Status.Conditions, oldTestImage, false, oldTestImage, reasonUpgradeWaiting)
+	if len(recorder.Events) != 0 {
+		t.Errorf("applyUpgradeOrder() recorded an event while waiting")
+	}
+
+	// a cluster master upgraded to another image does not hold the search heads
+	cm.Spec.Image = "splunk/splunk:8.2.1"
+	c.AddObject(&cm)
+	test(&shc, &shc.Status.Conditions, oldTestImage, true, newTestImage, reasonUpgradeInProgress)
+
+	// the search heads are upgraded once the cluster master is ready on the new image
+	cm.Spec.Image = newTestImage
+	cm.Status.Image = newTestImage
+	cm.Status.Phase = splcommon.PhaseReady
+	c.AddObject(&cm)
+	test(&shc, &shc.Status.Conditions, oldTestImage, true, newTestImage, reasonUpgradeInProgress)
+	if len(recorder.Events) != 1 {
+		t.Errorf("applyUpgradeOrder() recorded %d events; want 1 UpgradeStarted event", len(recorder.Events))
+	}
+
+	// the indexers wait for the search heads that search them
+	idxc := enterprisev1.IndexerCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "stack1", Namespace: "test"},
+	}
+	idxc.Spec.Image = newTestImage
+	idxc.Spec.ClusterMasterRef.Name = "master1"
+	c.ListObj = &enterprisev1.SearchHeadClusterList{Items: []enterprisev1.SearchHeadCluster{shc}}
+	test(&idxc, &idxc.Status.Conditions, oldTestImage, false, oldTestImage, reasonUpgradeWaiting)
+	if condition := getCondition(idxc.Status.Conditions, enterprisev1.ConditionImageUpToDate); condition.Message != "Waiting for SearchHeadCluster stack1 to be ready on image "+newTestImage {
+		t.Errorf("applyUpgradeOrder() set message \"%s\"", condition.Message)
+	}
+
+	shc.Status.Image = newTestImage
+	shc.Status.Phase = splcommon.PhaseReady
+	c.ListObj = &enterprisev1.SearchHeadClusterList{Items: []enterprisev1.SearchHeadCluster{shc}}
+	test(&idxc, &idxc.Status.Conditions, oldTestImage, true, newTestImage, reasonUpgradeInProgress)
+}
+
+func TestSetRunningImage(t *testing.T) {
+	cr := enterprisev1.LicenseMaster{
+		ObjectMeta: metav1.ObjectMeta{Name: "stack1", Namespace: "test"},
+	}
+	cr.Spec.Image = newTestImage
+	cr.Status.Image = oldTestImage
+	recorder := record.NewFakeRecorder(10)
+	eventPublisher := splcommon.NewEventPublisher(recorder, &cr)
+
+	// nothing is recorded while the upgrade is held
+	statefulSet := newUpgradeTestStatefulSet(oldTestImage)
+	if isUpgradeComplete(cr.Status.Image, statefulSet) {
+		t.Errorf("isUpgradeComplete() returned true for a held upgrade")
+	}
+	setRunningImage(&cr, &cr.Status.Conditions, &cr.Status.Image, cr.Spec.Image, statefulSet, eventPublisher)
+	if cr.Status.Image != oldTestImage || len(cr.Status.Conditions) != 0 {
+		t.Errorf("setRunningImage() recorded image %s and conditions %v for a held upgrade", cr.Status.Image, cr.Status.Conditions)
+	}
+
+	statefulSet = newUpgradeTestStatefulSet(newTestImage)
+	if !isUpgradeComplete(cr.Status.Image, statefulSet) {
+		t.Errorf("isUpgradeComplete() returned false for a rolled out upgrade")
+	}
+	setRunningImage(&cr, &cr.Status.Conditions, &cr.Status.Image, cr.Spec.Image, statefulSet, eventPublisher)
+	if cr.Status.Image != newTestImage {
+		t.Errorf("setRunningImage() recorded image %s; want %s", cr.Status.Image, newTestImage)
+	}
+	checkCondition(t, cr.Status.Conditions, enterprisev1.ConditionImageUpToDate, corev1.ConditionTrue, reasonImageUpToDate)
+	if len(recorder.Events) != 1 {
+		t.Errorf("setRunningImage() recorded %d events; want 1 UpgradeComplete event", len(recorder.Events))
+	}
+	if isUpgradeComplete(cr.Status.Image, statefulSet) {
+		t.Errorf("isUpgradeComplete() returned true after the image was recorded")
+	}
+}
+
+func TestGetUpgradingIndexerClusters(t *testing.T) {
+	newIndexerCluster := func(name, master, image, running string) enterprisev1.IndexerCluster {
+		cr := enterprisev1.IndexerCluster{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "test"},
+		}
+		cr.Spec.ClusterMasterRef.Name = master
+		cr.Spec.Image = image
+		cr.Status.Image = running
+		return cr
+	}
+	cr := newIndexerCluster("site1", "master1", newTestImage, oldTestImage)
+	c := spltest.NewMockClient()
+	c.ListObj = &enterprisev1.IndexerClusterList{Items: []enterprisev1.IndexerCluster{
+		cr,
+		newIndexerCluster("site2", "master1", newTestImage, oldTestImage),
+		newIndexerCluster("site3", "master1", newTestImage, newTestImage),
+		newIndexerCluster("site4", "master1", newTestImage, ""),
+		newIndexerCluster("other", "master2", newTestImage, oldTestImage),
+	}}
+
+	upgrading, err := getUpgradingIndexerClusters(c, &cr)
+	if err != nil {
+		t.Errorf("getUpgradingIndexerClusters() returned error: %v", err)
+	}
+	if want := []string{"site2"}; !reflect.DeepEqual(upgrading, want) {
+		t.Errorf("getUpgradingIndexerClusters() returned %v; want %v", upgrading, want)
+	}
+}
@@ -48,9 +48,10 @@ func ApplySplunkConfig(client splcommon.ControllerClient, cr splcommon.MetaObjec
 		return nil, err
 	}
 
-	// create splunk defaults (for inline config)
-	if spec.Defaults != "" {
-		defaultsMap := getSplunkDefaults(cr.GetName(), cr.GetNamespace(), instanceType, spec.Defaults)
+	// create splunk defaults (for inline config and typed multisite config)
+	multisiteDefaults := getMultisiteDefaults(cr)
+	if spec.Defaults != "" || multisiteDefaults != "" {
+		defaultsMap := getSplunkDefaults(cr.GetName(), cr.GetNamespace(), instanceType, spec.Defaults, multisiteDefaults)
 		defaultsMap.SetOwnerReferences(append(defaultsMap.GetOwnerReferences(), splcommon.AsOwner(cr, true)))
 		_, err = splctrl.ApplyConfigMap(client, defaultsMap)
 		if err != nil {
@@ -91,6 +92,26 @@ func getStandaloneExtraEnv(cr splcommon.MetaObject, replicas int32) []corev1.Env
 	}
 }
 
+// getHeavyForwarderExtraEnv returns extra environment variables used by monitoring console
+func getHeavyForwarderExtraEnv(cr splcommon.MetaObject, replicas int32) []corev1.EnvVar {
+	return []corev1.EnvVar{
+		{
+			Name:  "SPLUNK_HEAVY_FORWARDER_URL",
+			Value: GetSplunkStatefulsetUrls(cr.GetNamespace(), SplunkHeavyForwarder, cr.GetName(), replicas, false),
+		},
+	}
+}
+
+// getDeploymentServerExtraEnv returns extra environment variables used by monitoring console
+func getDeploymentServerExtraEnv(cr splcommon.MetaObject) []corev1.EnvVar {
+	return []corev1.EnvVar{
+		{
+			Name:  "SPLUNK_DEPLOYMENT_SERVER_URL",
+			Value: GetSplunkStatefulsetUrls(cr.GetNamespace(), SplunkDeploymentServer, cr.GetName(), 1, false),
+		},
+	}
+}
+
 // getLicenseMasterURL returns URL of license master
 func getLicenseMasterURL(cr splcommon.MetaObject, spec *enterprisev1.CommonSplunkSpec) []corev1.EnvVar {
 	if spec.LicenseMasterRef.Name != "" {
@@ -139,18 +160,42 @@ func getSearchHeadExtraEnv(cr splcommon.MetaObject, replicas int32) []corev1.Env
 	}
 }
 
-// GetSmartstoreRemoteVolumeSecrets is used to retrieve S3 access key and secrete keys.
+// GetSmartstoreRemoteVolumeSecrets is used to retrieve the keys of a remote volume: the S3 access key and secret key,
+// the Azure storage account name and key, or the path of the GCS service account key in the pods with an empty secret key.
+// Volumes that authenticate with an IAM role have no keys.
 func GetSmartstoreRemoteVolumeSecrets(volume enterprisev1.VolumeSpec, client splcommon.ControllerClient, cr splcommon.MetaObject, smartstore *enterprisev1.SmartStoreSpec) (string, string, string, error) {
+	if !usesRemoteVolumeKeys(&volume) {
+		return "", "", "", nil
+	}
+
 	namespaceScopedSecret, err := splutil.GetSecretByName(client, cr, volume.SecretRef)
 	if err != nil {
 		return "", "", "", err
 	}
 
+	splutil.SetSecretOwnerRef(client, volume.SecretRef, cr)
+
+	switch getRemoteVolumeProvider(&volume) {
+	case enterprisev1.RemoteStorageProviderGCS:
+		// the key is mounted from the secret by addRemoteVolumeGCSCredentials
+		if len(namespaceScopedSecret.Data[gcsCredentialFile]) == 0 {
+			return "", "", "", fmt.Errorf("GCS credential file is missing")
+		}
+		return getRemoteVolumeGCSCredentialPath(&volume), "", namespaceScopedSecret.ResourceVersion, nil
+	case enterprisev1.RemoteStorageProviderAzure:
+		accountName := string(namespaceScopedSecret.Data[azureAccountName])
+		accountKey := string(namespaceScopedSecret.Data[azureAccountKey])
+		if accountName == "" {
+			return "", "", "", fmt.Errorf("Azure storage account name is missing")
+		} else if accountKey == "" {
+			return "", "", "", fmt.Errorf("Azure storage account key is missing")
+		}
+		return accountName, accountKey, namespaceScopedSecret.ResourceVersion, nil
+	}
+
 	accessKey := string(namespaceScopedSecret.Data[s3AccessKey])
 	secretKey := string(namespaceScopedSecret.Data[s3SecretKey])
 
-	splutil.SetSecretOwnerRef(client, volume.SecretRef, cr)
-
 	if accessKey == "" {
 		return "", "", "", fmt.Errorf("S3 Access Key is missing")
 	} else if secretKey == "" {
@@ -282,6 +327,9 @@ func DeleteOwnerReferencesForS3SecretObjects(client splcommon.ControllerClient,
 
 	volList := smartstore.VolList
 	for _, volume := range volList {
+		if !usesRemoteVolumeKeys(&volume) {
+			continue
+		}
 		_, err = splutil.RemoveSecretOwnerRef(client, volume.SecretRef, cr)
 		if err == nil {
 			scopedLog.Info("Success", "Removed references for Secret Object %s", volume.SecretRef)
@@ -330,4 +330,37 @@ func TestGetSmartstoreRemoteVolumeSecrets(t *testing.T) {
 	if accessKey == "" || secretKey == "" || err != nil {
 		t.Errorf("Missing S3 Keys / Error not expected, when the Secret object with the S3 specific keys are present")
 	}
+
+	// Azure volumes use the storage account name and key
+	volume := cr.Spec.SmartStore.VolList[0]
+	volume.Provider = enterprisev1.RemoteStorageProviderAzure
+	_, _, _, err = GetSmartstoreRemoteVolumeSecrets(volume, client, &cr, &cr.Spec.SmartStore)
+	if err == nil {
+		t.Errorf("Missing Azure storage account name should return an error")
+	}
+
+	secret.Data[azureAccountName] = []byte("splunkstorage")
+	_, _, _, err = GetSmartstoreRemoteVolumeSecrets(volume, client, &cr, &cr.Spec.SmartStore)
+	if err == nil {
+		t.Errorf("Missing Azure storage account key should return an error")
+	}
+
+	secret.Data[azureAccountKey] = []byte("Eby8vdM02xNOcqFlqUwJPLlmEtlCDXJ1OUzFT50uSRZ6IFsuFq2UVErCz4I6tq/K1SZFPTOtr/KBHBeksoGMGw==")
+	accessKey, secretKey, _, err = GetSmartstoreRemoteVolumeSecrets(volume, client, &cr, &cr.Spec.SmartStore)
+	if accessKey != "splunkstorage" || secretKey == "" || err != nil {
+		t.Errorf("Azure storage account keys expected, when the Secret object with the Azure specific keys are present")
+	}
+
+	// GCS volumes use the service account key mounted from the secret
+	volume.Provider = enterprisev1.RemoteStorageProviderGCS
+	_, _, _, err = GetSmartstoreRemoteVolumeSecrets(volume, client, &cr, &cr.Spec.SmartStore)
+	if err == nil {
+		t.Errorf("Missing GCS credential file should return an error")
+	}
+
+	secret.Data[gcsCredentialFile] = []byte(`{"type": "service_account"}`)
+	accessKey, secretKey, _, err = GetSmartstoreRemoteVolumeSecrets(volume, client, &cr, &cr.Spec.SmartStore)
+	if accessKey != "/mnt/splunk-gcs/"+volume.Name+"/gcs_credential_file" || secretKey != "" || err != nil {
+		t.Errorf("GCS credential file expected, when the Secret object with the GCS specific keys are present")
+	}
 }
@@ -0,0 +1,205 @@
+// Copyright (c) 2018-2021 Splunk Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package enterprise
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+
+	enterprisev1 "github.com/splunk/splunk-operator/pkg/apis/enterprise/v1"
+	splcommon "github.com/splunk/splunk-operator/pkg/splunk/common"
+)
+
+// SetCustomResourceDefaults applies the defaults of a Splunk Enterprise custom resource that its reconciler
+// would otherwise apply in memory on every reconcile, and returns an error if the custom resource is invalid.
+func SetCustomResourceDefaults(cr splcommon.MetaObject) error {
+	switch cr := cr.(type) {
+	case *enterprisev1.Standalone:
+		return validateStandaloneSpec(&cr.Spec)
+	case *enterprisev1.LicenseMaster:
+		return validateLicenseMasterSpec(&cr.Spec)
+	case *enterprisev1.SearchHeadCluster:
+		return validateSearchHeadClusterSpec(&cr.Spec)
+	case *enterprisev1.ClusterMaster:
+		return validateClusterMasterSpec(cr)
+	case *enterprisev1.IndexerCluster:
+		return validateIndexerClusterSpec(cr)
+	case *enterprisev1.HeavyForwarder:
+		return validateHeavyForwarderSpec(cr)
+	case *enterprisev1.DeploymentServer:
+		return validateDeploymentServerSpec(&cr.Spec)
+	case *enterprisev1.MonitoringConsole:
+		return validateMonitoringConsoleSpec(cr)
+	}
+	return fmt.Errorf("Invalid custom resource kind %s", cr.GetObjectKind().GroupVersionKind().Kind)
+}
+
+// ValidateCustomResource runs the same checks on a Splunk Enterprise custom resource that its
+// reconciler runs before applying it, and returns an error if the custom resource is invalid.
+// The custom resources it refers to are read with c, while checks that depend on Splunk are left
+// to the reconciler. Note that the custom resource is updated with default values.
+func ValidateCustomResource(c splcommon.ControllerClient, cr splcommon.MetaObject) error {
+	err := SetCustomResourceDefaults(cr)
+	if err != nil {
+		return err
+	}
+
+	if idxc, ok := cr.(*enterprisev1.IndexerCluster); ok {
+		err = validateIndexerClusterReplicas(c, idxc)
+		if err != nil {
+			return err
+		}
+	}
+
+	// storage capacities are otherwise only parsed when the StatefulSet is built
+	spec, err := getCommonSplunkSpec(cr)
+	if err != nil {
+		return err
+	}
+	for _, volumeType := range []string{splcommon.EtcVolumeStorage, splcommon.VarVolumeStorage} {
+		_, err = getSplunkVolumeClaims(cr, spec, nil, volumeType)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ValidateCustomResourceUpdate returns an error if an update of a Splunk Enterprise custom resource
+// changes fields that cannot be applied to its existing StatefulSet.
+func ValidateCustomResourceUpdate(old, cr splcommon.MetaObject) error {
+	oldSpec, err := getCommonSplunkSpec(old)
+	if err != nil {
+		return err
+	}
+	spec, err := getCommonSplunkSpec(cr)
+	if err != nil {
+		return err
+	}
+
+	err = validateStorageClassSpecUpdate("etcVolumeStorageConfig", &oldSpec.EtcVolumeStorageConfig, &spec.EtcVolumeStorageConfig, splcommon.DefaultEtcVolumeStorageCapacity)
+	if err != nil {
+		return err
+	}
+	return validateStorageClassSpecUpdate("varVolumeStorageConfig", &oldSpec.VarVolumeStorageConfig, &spec.VarVolumeStorageConfig, splcommon.DefaultVarVolumeStorageCapacity)
+}
+
+// validateStorageClassSpecUpdate returns an error if the persistent volume claims described by a StorageClassSpec would change
+func validateStorageClassSpecUpdate(field string, old, spec *enterprisev1.StorageClassSpec, defaultCapacity string) error {
+	if old.EphemeralStorage != spec.EphemeralStorage {
+		return fmt.Errorf("%s.ephemeralStorage cannot be changed from %t to %t", field, old.EphemeralStorage, spec.EphemeralStorage)
+	}
+
+	// persistent volume claims are not used with ephemeral storage
+	if spec.EphemeralStorage {
+		return nil
+	}
+
+	if old.StorageClassName != spec.StorageClassName {
+		return fmt.Errorf("%s.storageClassName cannot be changed from \"%s\" to \"%s\"", field, old.StorageClassName, spec.StorageClassName)
+	}
+
+	// an invalid capacity was never applied, so any change to it is allowed
+	oldCapacity, err := splcommon.ParseResourceQuantity(old.StorageCapacity, defaultCapacity)
+	if err != nil {
+		return nil
+	}
+	capacity, err := splcommon.ParseResourceQuantity(spec.StorageCapacity, defaultCapacity)
+	if err != nil {
+		return fmt.Errorf("%s.storageCapacity: %s", field, err)
+	}
+	if oldCapacity.Cmp(capacity) != 0 {
+		return fmt.Errorf("%s.storageCapacity cannot be changed from %s to %s", field, oldCapacity.String(), capacity.String())
+	}
+	return nil
+}
+
+// validateIndexerClusterReplicas returns an error if an IndexerCluster has fewer replicas than the replication factor
+// set in the spec of its ClusterMaster. The check is skipped while the cluster master does not exist, or when its
+// replication factor is left to Splunk.
+func validateIndexerClusterReplicas(c splcommon.ControllerClient, cr *enterprisev1.IndexerCluster) error {
+	if cr.Spec.ClusterMasterRef.Name == "" {
+		return nil
+	}
+	var cm enterprisev1.ClusterMaster
+	err := c.Get(context.TODO(), getReferenceName(cr, cr.Spec.ClusterMasterRef), &cm)
+	if k8serrors.IsNotFound(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	replicationFactor, err := getClusterMasterReplicationFactor(&cm)
+	if err != nil {
+		return err
+	}
+	if cr.Spec.Replicas < replicationFactor {
+		return fmt.Errorf("IndexerCluster replicas (%d) cannot be less than the replication factor (%d) of cluster master %s", cr.Spec.Replicas, replicationFactor, cm.GetName())
+	}
+	return nil
+}
+
+// getClusterMasterReplicationFactor returns the number of peers required by the replication factor set in the spec of
+// a cluster master, or 0 if it is left to Splunk: the siteReplicationFactor origin of a multisite cluster master, which
+// is the number of copies kept by each site, or the idxc.replication_factor of its defaults
+func getClusterMasterReplicationFactor(cm *enterprisev1.ClusterMaster) (int32, error) {
+	if isMultisiteConfigured(&cm.Spec.Multisite) {
+		return cm.Spec.Multisite.SiteReplicationFactor.Origin, nil
+	}
+
+	raw, err := utilyaml.ToJSON([]byte(cm.Spec.Defaults))
+	if err != nil {
+		return 0, fmt.Errorf("Invalid defaults of cluster master %s: %v", cm.GetName(), err)
+	}
+	var defaults struct {
+		Splunk struct {
+			Idxc struct {
+				ReplicationFactor int32 `json:"replication_factor"`
+			} `json:"idxc"`
+		} `json:"splunk"`
+	}
+	err = json.Unmarshal(raw, &defaults)
+	if err != nil {
+		return 0, fmt.Errorf("Invalid defaults of cluster master %s: %v", cm.GetName(), err)
+	}
+	return defaults.Splunk.Idxc.ReplicationFactor, nil
+}
+
+// getCommonSplunkSpec returns the CommonSplunkSpec of a Splunk Enterprise custom resource
+func getCommonSplunkSpec(cr splcommon.MetaObject) (*enterprisev1.CommonSplunkSpec, error) {
+	switch cr := cr.(type) {
+	case *enterprisev1.Standalone:
+		return &cr.Spec.CommonSplunkSpec, nil
+	case *enterprisev1.LicenseMaster:
+		return &cr.Spec.CommonSplunkSpec, nil
+	case *enterprisev1.SearchHeadCluster:
+		return &cr.Spec.CommonSplunkSpec, nil
+	case *enterprisev1.ClusterMaster:
+		return &cr.Spec.CommonSplunkSpec, nil
+	case *enterprisev1.IndexerCluster:
+		return &cr.Spec.CommonSplunkSpec, nil
+	case *enterprisev1.HeavyForwarder:
+		return &cr.Spec.CommonSplunkSpec, nil
+	case *enterprisev1.DeploymentServer:
+		return &cr.Spec.CommonSplunkSpec, nil
+	case *enterprisev1.MonitoringConsole:
+		return &cr.Spec.CommonSplunkSpec, nil
+	}
+	return nil, fmt.Errorf("Invalid custom resource kind %s", cr.GetObjectKind().GroupVersionKind().Kind)
+}
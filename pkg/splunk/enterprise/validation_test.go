@@ -0,0 +1,191 @@
+// Copyright (c) 2018-2021 Splunk Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package enterprise
+
+import (
+	"encoding/json"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	enterprisev1 "github.com/splunk/splunk-operator/pkg/apis/enterprise/v1"
+	splcommon "github.com/splunk/splunk-operator/pkg/splunk/common"
+	spltest "github.com/splunk/splunk-operator/pkg/splunk/test"
+)
+
+func TestSetCustomResourceDefaults(t *testing.T) {
+	cr := enterprisev1.ClusterMaster{
+		TypeMeta:   metav1.TypeMeta{Kind: "ClusterMaster"},
+		ObjectMeta: metav1.ObjectMeta{Name: "master1", Namespace: "test"},
+	}
+	cr.Spec.EtcVolumeStorageConfig.EphemeralStorage = true
+	if err := SetCustomResourceDefaults(&cr); err != nil {
+		t.Fatalf("SetCustomResourceDefaults() returned %v; want nil", err)
+	}
+	if cr.Spec.EtcVolumeStorageConfig.StorageCapacity != "" || cr.Spec.VarVolumeStorageConfig.StorageCapacity != splcommon.DefaultVarVolumeStorageCapacity {
+		t.Errorf("SetCustomResourceDefaults() set storage capacities %s and %s; want none for ephemeral etc storage and %s for var storage",
+			cr.Spec.EtcVolumeStorageConfig.StorageCapacity, cr.Spec.VarVolumeStorageConfig.StorageCapacity, splcommon.DefaultVarVolumeStorageCapacity)
+	}
+	if cr.Spec.ImagePullPolicy != "IfNotPresent" || cr.Spec.Resources.Limits.Memory().String() != "8Gi" {
+		t.Errorf("SetCustomResourceDefaults() set imagePullPolicy %s and memory limit %s; want IfNotPresent and 8Gi", cr.Spec.ImagePullPolicy, cr.Spec.Resources.Limits.Memory().String())
+	}
+
+	// reconciling a custom resource that already has its defaults does not change it
+	defaulted, _ := json.Marshal(&cr)
+	if err := validateClusterMasterSpec(&cr); err != nil {
+		t.Fatalf("validateClusterMasterSpec() returned %v; want nil", err)
+	}
+	reconciled, _ := json.Marshal(&cr)
+	if string(reconciled) != string(defaulted) {
+		t.Errorf("validateClusterMasterSpec() changed defaulted custom resource:\ngot  %s\nwant %s", reconciled, defaulted)
+	}
+
+	if err := SetCustomResourceDefaults(&corev1.Pod{}); err == nil {
+		t.Errorf("SetCustomResourceDefaults() returned nil; want error for Pod")
+	}
+}
+
+func TestValidateCustomResource(t *testing.T) {
+	c := spltest.NewMockClient()
+	c.NotFoundError = k8serrors.NewNotFound(schema.GroupResource{Group: "enterprise.splunk.com", Resource: "clustermasters"}, "master1")
+	test := func(cr splcommon.MetaObject, wantErr bool) {
+		err := ValidateCustomResource(c, cr)
+		if (err != nil) != wantErr {
+			t.Errorf("ValidateCustomResource(%s) returned %v; want error %t", cr.GetObjectKind().GroupVersionKind().Kind, err, wantErr)
+		}
+	}
+
+	standalone := enterprisev1.Standalone{
+		TypeMeta:   metav1.TypeMeta{Kind: "Standalone"},
+		ObjectMeta: metav1.ObjectMeta{Name: "stack1", Namespace: "test"},
+	}
+	test(&standalone, false)
+
+	standalone.Spec.ImagePullPolicy = "Sometimes"
+	test(&standalone, true)
+
+	standalone.Spec.ImagePullPolicy = ""
+	standalone.Spec.SmartStore = enterprisev1.SmartStoreSpec{
+		VolList: []enterprisev1.VolumeSpec{
+			{Name: "msos_s2s3_vol", Endpoint: "https://s3-eu-west-2.amazonaws.com", Path: "testbucket-rs-london", SecretRef: "s3-secret"},
+		},
+		IndexList: []enterprisev1.IndexSpec{
+			{Name: "salesdata1", RemotePath: "remotepath1",
+				IndexAndGlobalCommonSpec: enterprisev1.IndexAndGlobalCommonSpec{
+					VolName: "missing_vol"},
+			},
+		},
+	}
+	test(&standalone, true)
+
+	standalone.Spec.SmartStore = enterprisev1.SmartStoreSpec{}
+	standalone.Spec.VarVolumeStorageConfig.StorageCapacity = "lots"
+	test(&standalone, true)
+
+	idxc := enterprisev1.IndexerCluster{
+		TypeMeta:   metav1.TypeMeta{Kind: "IndexerCluster"},
+		ObjectMeta: metav1.ObjectMeta{Name: "stack1", Namespace: "test"},
+	}
+	test(&idxc, true)
+
+	idxc.Spec.ClusterMasterRef.Name = "master1"
+	test(&idxc, false)
+
+	shc := enterprisev1.SearchHeadCluster{
+		TypeMeta:   metav1.TypeMeta{Kind: "SearchHeadCluster"},
+		ObjectMeta: metav1.ObjectMeta{Name: "stack1", Namespace: "test"},
+	}
+	shc.Spec.ImagePullPolicy = "Sometimes"
+	test(&shc, true)
+
+	test(&corev1.Pod{TypeMeta: metav1.TypeMeta{Kind: "Pod"}}, true)
+}
+
+func TestValidateCustomResourceUpdate(t *testing.T) {
+	test := func(old, cr enterprisev1.StorageClassSpec, wantErr bool) {
+		oldCR := enterprisev1.ClusterMaster{TypeMeta: metav1.TypeMeta{Kind: "ClusterMaster"}}
+		oldCR.Spec.EtcVolumeStorageConfig = old
+		oldCR.Spec.VarVolumeStorageConfig = old
+		newCR := enterprisev1.ClusterMaster{TypeMeta: metav1.TypeMeta{Kind: "ClusterMaster"}}
+		newCR.Spec.EtcVolumeStorageConfig = old
+		newCR.Spec.VarVolumeStorageConfig = cr
+		err := ValidateCustomResourceUpdate(&oldCR, &newCR)
+		if (err != nil) != wantErr {
+			t.Errorf("ValidateCustomResourceUpdate(%v, %v) returned %v; want error %t", old, cr, err, wantErr)
+		}
+	}
+
+	test(enterprisev1.StorageClassSpec{}, enterprisev1.StorageClassSpec{}, false)
+	test(enterprisev1.StorageClassSpec{}, enterprisev1.StorageClassSpec{StorageCapacity: splcommon.DefaultVarVolumeStorageCapacity}, false)
+	test(enterprisev1.StorageClassSpec{StorageCapacity: "100Gi"}, enterprisev1.StorageClassSpec{StorageCapacity: "102400Mi"}, false)
+	test(enterprisev1.StorageClassSpec{EphemeralStorage: true, StorageClassName: "gp2"}, enterprisev1.StorageClassSpec{EphemeralStorage: true}, false)
+	test(enterprisev1.StorageClassSpec{StorageCapacity: "lots"}, enterprisev1.StorageClassSpec{StorageCapacity: "50Gi"}, false)
+	test(enterprisev1.StorageClassSpec{}, enterprisev1.StorageClassSpec{StorageCapacity: "50Gi"}, true)
+	test(enterprisev1.StorageClassSpec{}, enterprisev1.StorageClassSpec{StorageClassName: "gp2"}, true)
+	test(enterprisev1.StorageClassSpec{}, enterprisev1.StorageClassSpec{EphemeralStorage: true}, true)
+	test(enterprisev1.StorageClassSpec{EphemeralStorage: true}, enterprisev1.StorageClassSpec{}, true)
+
+	err := ValidateCustomResourceUpdate(&corev1.Pod{}, &corev1.Pod{})
+	if err == nil {
+		t.Errorf("ValidateCustomResourceUpdate() returned nil; want error for Pod")
+	}
+}
+
+func TestValidateIndexerClusterReplicas(t *testing.T) {
+	c := spltest.NewMockClient()
+	c.NotFoundError = k8serrors.NewNotFound(schema.GroupResource{Group: "enterprise.splunk.com", Resource: "clustermasters"}, "master1")
+	cm := enterprisev1.ClusterMaster{
+		TypeMeta:   metav1.TypeMeta{Kind: "ClusterMaster"},
+		ObjectMeta: metav1.ObjectMeta{Name: "master1", Namespace: "test"},
+	}
+	idxc := enterprisev1.IndexerCluster{
+		TypeMeta:   metav1.TypeMeta{Kind: "IndexerCluster"},
+		ObjectMeta: metav1.ObjectMeta{Name: "stack1", Namespace: "test"},
+	}
+	idxc.Spec.ClusterMasterRef.Name = "master1"
+	idxc.Spec.Replicas = 2
+	test := func(wantErr bool) {
+		err := validateIndexerClusterReplicas(c, &idxc)
+		if (err != nil) != wantErr {
+			t.Errorf("validateIndexerClusterReplicas() with %d replicas returned %v; want error %t", idxc.Spec.Replicas, err, wantErr)
+		}
+	}
+
+	// the replication factor is not checked until the cluster master exists, or when it is left to Splunk
+	test(false)
+	c.AddObject(&cm)
+	test(false)
+
+	// replication factor of the defaults of a single site cluster master
+	cm.Spec.Defaults = "splunk:\n  idxc:\n    replication_factor: 3\n"
+	test(true)
+	idxc.Spec.Replicas = 3
+	test(false)
+
+	// each site keeps the origin copies of a multisite cluster master
+	cm.Spec.Multisite = enterprisev1.MultisiteSpec{Sites: []string{"site1", "site2"}, SiteReplicationFactor: enterprisev1.SiteFactorSpec{Origin: 4, Total: 6}}
+	idxc.Spec.Site = "site1"
+	test(true)
+	idxc.Spec.Replicas = 4
+	test(false)
+
+	// other errors are reported
+	cm.Spec.Multisite = enterprisev1.MultisiteSpec{}
+	cm.Spec.Defaults = "splunk: ["
+	test(true)
+}
@@ -0,0 +1,161 @@
+// Copyright (c) 2018-2021 Splunk Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package enterprise
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+
+	enterprisev1 "github.com/splunk/splunk-operator/pkg/apis/enterprise/v1"
+	splclient "github.com/splunk/splunk-operator/pkg/splunk/client"
+	splcommon "github.com/splunk/splunk-operator/pkg/splunk/common"
+)
+
+// skipVersionCheckAnnotation disables the version checks of image upgrades for a custom resource when set to "true",
+// for example to use an image whose tag is not a Splunk Enterprise version
+const skipVersionCheckAnnotation = "enterprise.splunk.com/skip-version-check"
+
+// splunkVersion is a Splunk Enterprise version, such as 8.2.0
+type splunkVersion struct {
+	major, minor, patch int
+}
+
+// String returns the version in its usual form
+func (v splunkVersion) String() string {
+	return fmt.Sprintf("%d.%d.%d", v.major, v.minor, v.patch)
+}
+
+// less returns true if the version is older than another one
+func (v splunkVersion) less(other splunkVersion) bool {
+	if v.major != other.major {
+		return v.major < other.major
+	}
+	if v.minor != other.minor {
+		return v.minor < other.minor
+	}
+	return v.patch < other.patch
+}
+
+// parseSplunkVersion parses a Splunk Enterprise version, such as 8.2.0, 8.1 or 8.1.2.1.
+// Any suffix following a dash, such as in 8.2.0-a1, is ignored.
+func parseSplunkVersion(version string) (splunkVersion, error) {
+	var v splunkVersion
+	fields := strings.Split(strings.SplitN(version, "-", 2)[0], ".")
+	if len(fields) < 2 {
+		return v, fmt.Errorf("\"%s\" is not a Splunk Enterprise version", version)
+	}
+	numbers := []*int{&v.major, &v.minor, &v.patch}
+	for i, field := range fields {
+		n, err := strconv.Atoi(field)
+		if err != nil || n < 0 {
+			return v, fmt.Errorf("\"%s\" is not a Splunk Enterprise version", version)
+		}
+		if i < len(numbers) {
+			*numbers[i] = n
+		}
+	}
+	return v, nil
+}
+
+// getImageVersion returns the Splunk Enterprise version of an image, from its tag
+func getImageVersion(image string) (splunkVersion, error) {
+	name := strings.SplitN(image, "@", 2)[0]
+	tag := ""
+	if i := strings.LastIndex(name, ":"); i > strings.LastIndex(name, "/") {
+		tag = name[i+1:]
+	}
+	if tag == "" {
+		return splunkVersion{}, fmt.Errorf("image %s has no tag", image)
+	}
+	version, err := parseSplunkVersion(tag)
+	if err != nil {
+		return version, fmt.Errorf("tag of image %s: %v", image, err)
+	}
+	return version, nil
+}
+
+// checkImageUpgrade is the preflight check of an upgrade of cr from image running, which reported version runningVersion
+// if known, to a new image. It returns why the upgrade is refused, or an empty string if the upgrade is supported:
+// the tag of the new image must be a version, no major version can be skipped, and the indexer peers and search heads
+// cannot be newer than their cluster master.
+func checkImageUpgrade(c splcommon.ControllerClient, cr splcommon.MetaObject, running, runningVersion, image string) (string, error) {
+	if cr.GetAnnotations()[skipVersionCheckAnnotation] == "true" {
+		return "", nil
+	}
+
+	version, err := getImageVersion(image)
+	if err != nil {
+		return fmt.Sprintf("Unable to check the upgrade to image %s: %v; set annotation %s to \"true\" to use it", image, err, skipVersionCheckAnnotation), nil
+	}
+
+	current, err := parseSplunkVersion(runningVersion)
+	if err != nil {
+		current, err = getImageVersion(running)
+	}
+	if err == nil && version.major > current.major+1 {
+		return fmt.Sprintf("Upgrading from version %s to %s skips major version %d, which is not supported", current, version, current.major+1), nil
+	}
+
+	var clusterMasterRef corev1.ObjectReference
+	switch cr := cr.(type) {
+	case *enterprisev1.IndexerCluster:
+		clusterMasterRef = cr.Spec.ClusterMasterRef
+	case *enterprisev1.SearchHeadCluster:
+		clusterMasterRef = cr.Spec.ClusterMasterRef
+	}
+	if clusterMasterRef.Name == "" {
+		return "", nil
+	}
+	var cm enterprisev1.ClusterMaster
+	err = c.Get(context.TODO(), getReferenceName(cr, clusterMasterRef), &cm)
+	if err != nil {
+		return "", fmt.Errorf("Unable to get cluster master %s: %v", clusterMasterRef.Name, err)
+	}
+
+	// the cluster master is upgraded first, so the version of its spec is the one it runs once the peers are upgraded
+	cmVersion, err := getImageVersion(GetSplunkImage(cm.Spec.Image))
+	if err != nil {
+		cmVersion, err = parseSplunkVersion(cm.Status.Version)
+	}
+	if err == nil && cmVersion.less(version) {
+		return fmt.Sprintf("Image %s has version %s, which is newer than version %s of cluster master %s", image, version, cmVersion, cm.GetName()), nil
+	}
+	return "", nil
+}
+
+// updateVersion records the Splunk Enterprise version run by the pods of a StatefulSet, if it is unknown or if they
+// were upgraded from the image recorded in the status of their custom resource. getClient returns a client for one of them.
+func updateVersion(spec *enterprisev1.CommonSplunkSpec, running string, version *string, statefulSet *appsv1.StatefulSet, getClient func() *splclient.SplunkClient) error {
+	if spec.Mock || (*version != "" && !isUpgradeComplete(running, statefulSet)) {
+		return nil
+	}
+	info, err := getClient().GetServerInfo()
+	if err != nil {
+		return fmt.Errorf("Unable to get the Splunk Enterprise version: %v", err)
+	}
+	*version = info.Version
+	return nil
+}
+
+// getServiceClient returns a SplunkClient for the regular service of a custom resource
+func getServiceClient(cr splcommon.MetaObject, instanceType InstanceType, secret *corev1.Secret) *splclient.SplunkClient {
+	fqdnName := splcommon.GetServiceFQDN(cr.GetNamespace(), GetSplunkServiceName(instanceType, cr.GetName(), false))
+	return splclient.NewSplunkClient(fmt.Sprintf("https://%s:8089", fqdnName), "admin", string(secret.Data["password"]))
+}
@@ -0,0 +1,192 @@
+// Copyright (c) 2018-2021 Splunk Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package enterprise
+
+import (
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+
+	enterprisev1 "github.com/splunk/splunk-operator/pkg/apis/enterprise/v1"
+	splclient "github.com/splunk/splunk-operator/pkg/splunk/client"
+	splcommon "github.com/splunk/splunk-operator/pkg/splunk/common"
+	spltest "github.com/splunk/splunk-operator/pkg/splunk/test"
+)
+
+func TestGetImageVersion(t *testing.T) {
+	test := func(image string, want splunkVersion, wantErr bool) {
+		got, err := getImageVersion(image)
+		if wantErr {
+			if err == nil {
+				t.Errorf("getImageVersion(\"%s\") returned %s; want error", image, got)
+			}
+			return
+		}
+		if err != nil {
+			t.Errorf("getImageVersion(\"%s\") returned error: %v", image, err)
+		} else if got != want {
+			t.Errorf("getImageVersion(\"%s\") returned %s; want %s", image, got, want)
+		}
+	}
+
+	test("splunk/splunk:8.2.0", splunkVersion{8, 2, 0}, false)
+	test("splunk/splunk:8.1", splunkVersion{8, 1, 0}, false)
+	test("splunk/splunk:8.1.2.1", splunkVersion{8, 1, 2}, false)
+	test("splunk/splunk:8.2.0-a1", splunkVersion{8, 2, 0}, false)
+	test("registry.example.com:5000/splunk/splunk:7.3.4", splunkVersion{7, 3, 4}, false)
+	test("splunk/splunk:8.2.0@sha256:0123456789abcdef", splunkVersion{8, 2, 0}, false)
+	test("splunk/splunk:latest", splunkVersion{}, true)
+	test("splunk/splunk:edge", splunkVersion{}, true)
+	test("splunk/splunk", splunkVersion{}, true)
+	test("registry.example.com:5000/splunk/splunk", splunkVersion{}, true)
+	test("splunk/splunk@sha256:0123456789abcdef", splunkVersion{}, true)
+	test("splunk/splunk:8", splunkVersion{}, true)
+}
+
+func TestSplunkVersionLess(t *testing.T) {
+	versions := []splunkVersion{{7, 3, 9}, {8, 0, 0}, {8, 1, 2}, {8, 2, 0}, {8, 2, 1}}
+	for i := range versions {
+		for j := range versions {
+			if got := versions[i].less(versions[j]); got != (i < j) {
+				t.Errorf("%s.less(%s) returned %t", versions[i], versions[j], got)
+			}
+		}
+	}
+}
+
+func TestCheckImageUpgrade(t *testing.T) {
+	c := spltest.NewMockClient()
+	cm := enterprisev1.ClusterMaster{
+		ObjectMeta: metav1.ObjectMeta{Name: "master1", Namespace: "test"},
+	}
+	cm.Spec.Image = newTestImage
+	c.AddObject(&cm)
+	idxc := enterprisev1.IndexerCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "stack1", Namespace: "test"},
+	}
+	idxc.Spec.ClusterMasterRef.Name = "master1"
+
+	test := func(cr splcommon.MetaObject, running, runningVersion, image, wantRefusal string) {
+		refusal, err := checkImageUpgrade(c, cr, running, runningVersion, image)
+		if err != nil {
+			t.Errorf("checkImageUpgrade(%s, %s) returned error: %v", running, image, err)
+		}
+		if !strings.HasPrefix(refusal, wantRefusal) || (wantRefusal == "") != (refusal == "") {
+			t.Errorf("checkImageUpgrade(%s, %s) refused \"%s\"; want \"%s\"", running, image, refusal, wantRefusal)
+		}
+	}
+
+	test(&idxc, oldTestImage, "", newTestImage, "")
+	test(&idxc, oldTestImage, "", "splunk/splunk:8.1.2", "")
+	test(&idxc, oldTestImage, "", "splunk/splunk:latest", "Unable to check the upgrade to image splunk/splunk:latest")
+	test(&idxc, oldTestImage, "", "splunk/splunk:8.2.1", "Image splunk/splunk:8.2.1 has version 8.2.1, which is newer than version 8.2.0 of cluster master master1")
+
+	// the version reported by Splunk Enterprise is preferred to the tag of the running image
+	lm := enterprisev1.LicenseMaster{
+		ObjectMeta: metav1.ObjectMeta{Name: "stack1", Namespace: "test"},
+	}
+	test(&lm, "splunk/splunk:7.3.4", "", "splunk/splunk:9.0.0", "Upgrading from version 7.3.4 to 9.0.0 skips major version 8")
+	test(&lm, "splunk/splunk:edge", "7.3.4", "splunk/splunk:9.0.0", "Upgrading from version 7.3.4 to 9.0.0 skips major version 8")
+	test(&lm, "splunk/splunk:edge", "", "splunk/splunk:9.0.0", "")
+	test(&lm, "splunk/splunk:8.2.0", "", "splunk/splunk:9.0.0", "")
+
+	// the cluster master falls back to the version it reported when its image has no version
+	cm.Spec.Image = "splunk/splunk:edge"
+	cm.Status.Version = "8.1.0"
+	c.AddObject(&cm)
+	test(&idxc, oldTestImage, "", newTestImage, "Image splunk/splunk:8.2.0 has version 8.2.0, which is newer than version 8.1.0 of cluster master master1")
+
+	// all the checks are skipped with the annotation
+	idxc.ObjectMeta.Annotations = map[string]string{skipVersionCheckAnnotation: "true"}
+	test(&idxc, oldTestImage, "", newTestImage, "")
+	test(&idxc, oldTestImage, "", "splunk/splunk:latest", "")
+}
+
+func TestApplyUpgradeOrderRefused(t *testing.T) {
+	c := spltest.NewMockClient()
+	cr := enterprisev1.Standalone{
+		ObjectMeta: metav1.ObjectMeta{Name: "stack1", Namespace: "test"},
+	}
+	cr.Status.Image = oldTestImage
+	recorder := record.NewFakeRecorder(10)
+	eventPublisher := splcommon.NewEventPublisher(recorder, &cr)
+
+	for i := 0; i < 2; i++ {
+		statefulSet := newUpgradeTestStatefulSet("splunk/splunk:latest")
+		upgrading, err := applyUpgradeOrder(c, &cr, &cr.Status.Conditions, cr.Status.Image, "8.1.0", statefulSet, eventPublisher)
+		if err != nil {
+			t.Errorf("applyUpgradeOrder() returned error: %v", err)
+		}
+		if upgrading {
+			t.Errorf("applyUpgradeOrder() returned true for a refused upgrade")
+		}
+		if image := statefulSet.Spec.Template.Spec.Containers[0].Image; image != oldTestImage {
+			t.Errorf("applyUpgradeOrder() set image %s; want %s", image, oldTestImage)
+		}
+		checkCondition(t, cr.Status.Conditions, enterprisev1.ConditionImageUpToDate, corev1.ConditionFalse, reasonUpgradeRefused)
+	}
+	if len(recorder.Events) != 1 {
+		t.Errorf("applyUpgradeOrder() recorded %d events; want 1 UpgradeRefused event", len(recorder.Events))
+	}
+}
+
+func TestUpdateVersion(t *testing.T) {
+	spec := enterprisev1.CommonSplunkSpec{}
+	mockSplunkClient := &spltest.MockHTTPClient{}
+	mockSplunkClient.AddHandlers(spltest.MockHTTPHandler{
+		Method: "GET",
+		URL:    "https://splunk-stack1-standalone-service.test.svc.cluster.local:8089/services/server/info?count=0&output_mode=json",
+		Status: 200,
+		Body:   `{"entry":[{"name":"server-info","content":{"serverName":"splunk-stack1-standalone-0","guid":"2A1C5A5B-7C4D-4D43-8E0A-6C1D0B1C3F3E","version":"8.2.0","build":"e053ef3c985f","product_type":"enterprise","server_roles":["indexer","license_master"],"startup_time":1624300362}}]}`,
+	})
+	calls := 0
+	getClient := func() *splclient.SplunkClient {
+		calls++
+		c := splclient.NewSplunkClient("https://splunk-stack1-standalone-service.test.svc.cluster.local:8089", "admin", "123")
+		c.Client = mockSplunkClient
+		return c
+	}
+
+	// the version is not requested again until an upgrade completes
+	version := ""
+	err := updateVersion(&spec, newTestImage, &version, newUpgradeTestStatefulSet(newTestImage), getClient)
+	if err != nil {
+		t.Errorf("updateVersion() returned error: %v", err)
+	}
+	if version != "8.2.0" {
+		t.Errorf("updateVersion() recorded version \"%s\"; want \"8.2.0\"", version)
+	}
+	mockSplunkClient.CheckRequests(t, "TestUpdateVersion")
+
+	version = "8.1.0"
+	err = updateVersion(&spec, newTestImage, &version, newUpgradeTestStatefulSet(newTestImage), getClient)
+	if err != nil || version != "8.1.0" || calls != 1 {
+		t.Errorf("updateVersion() requested the version of an unchanged image")
+	}
+	err = updateVersion(&spec, oldTestImage, &version, newUpgradeTestStatefulSet(newTestImage), getClient)
+	if err != nil || version != "8.2.0" || calls != 2 {
+		t.Errorf("updateVersion() did not record the version of an upgraded image")
+	}
+
+	spec.Mock = true
+	version = ""
+	err = updateVersion(&spec, oldTestImage, &version, newUpgradeTestStatefulSet(newTestImage), getClient)
+	if err != nil || version != "" || calls != 2 {
+		t.Errorf("updateVersion() requested the version with a mock spec")
+	}
+}
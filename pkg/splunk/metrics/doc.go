@@ -0,0 +1,20 @@
+// Copyright (c) 2018-2021 Splunk Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+/*
+Package metrics defines the Prometheus metrics exported by the Splunk Operator.
+The metrics are registered with the controller-runtime registry, which is served by the manager's metrics endpoint.
+This package has no dependencies outside of the standard go and prometheus libraries, and controller-runtime.
+*/
+package metrics
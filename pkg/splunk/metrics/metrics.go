@@ -0,0 +1,181 @@
+// Copyright (c) 2018-2021 Splunk Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metrics
+
+import (
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+// metricNamespace prefixes the names of all metrics exported by the operator
+const metricNamespace = "splunk_operator"
+
+var (
+	// reconcileDuration is the time taken to reconcile custom resources
+	reconcileDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: metricNamespace,
+		Name:      "reconcile_duration_seconds",
+		Help:      "Time taken to reconcile a custom resource, by kind",
+		Buckets:   []float64{0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60, 120},
+	}, []string{"kind"})
+
+	// reconcileErrors counts the reconciles of custom resources that returned an error
+	reconcileErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricNamespace,
+		Name:      "reconcile_errors_total",
+		Help:      "Number of reconciles of a custom resource that returned an error, by kind",
+	}, []string{"kind"})
+
+	// customResourcePhase is set to 1 for the current phase of each custom resource
+	customResourcePhase = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: metricNamespace,
+		Name:      "custom_resource_phase",
+		Help:      "Current phase of a custom resource; the series for its current phase has the value 1",
+	}, []string{"kind", "namespace", "name", "phase"})
+
+	// podsRecycled counts the pods deleted to pick up a new StatefulSet revision
+	podsRecycled = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricNamespace,
+		Name:      "pods_recycled_total",
+		Help:      "Number of pods deleted to pick up a new revision of their StatefulSet",
+	}, []string{"namespace", "statefulset"})
+
+	// bundlePushes counts the attempts to push the master apps bundle of a cluster master
+	bundlePushes = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricNamespace,
+		Name:      "bundle_pushes_total",
+		Help:      "Number of master apps bundle pushes attempted by a cluster master, by result",
+	}, []string{"namespace", "name", "result"})
+
+	// decommissionDuration is the time taken by indexer cluster peers to decommission
+	decommissionDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: metricNamespace,
+		Name:      "decommission_duration_seconds",
+		Help:      "Time taken by a peer of an indexer cluster to decommission",
+		Buckets:   prometheus.ExponentialBuckets(30, 2, 10),
+	}, []string{"namespace", "name"})
+
+	// splunkRequestDuration is the latency of Splunk REST API requests sent by the operator
+	splunkRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: metricNamespace,
+		Name:      "splunk_request_duration_seconds",
+		Help:      "Latency of Splunk REST API requests, by SplunkClient method and status code",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "code"})
+)
+
+// phases tracks the phase reported for each custom resource, so that the series of its previous phase can be removed
+var phases = struct {
+	sync.Mutex
+	byResource map[string]string
+}{byResource: make(map[string]string)}
+
+// decommissionStartTimes tracks when each indexer cluster peer started decommissioning
+var decommissionStartTimes = struct {
+	sync.Mutex
+	byPeer map[string]time.Time
+}{byPeer: make(map[string]time.Time)}
+
+func init() {
+	metrics.Registry.MustRegister(
+		reconcileDuration,
+		reconcileErrors,
+		customResourcePhase,
+		podsRecycled,
+		bundlePushes,
+		decommissionDuration,
+		splunkRequestDuration,
+	)
+}
+
+// ObserveReconcile records the duration and outcome of a reconcile of a custom resource
+func ObserveReconcile(kind string, duration time.Duration, err error) {
+	reconcileDuration.WithLabelValues(kind).Observe(duration.Seconds())
+	if err != nil {
+		reconcileErrors.WithLabelValues(kind).Inc()
+	}
+}
+
+// SetPhase records the current phase of a custom resource
+func SetPhase(kind, namespace, name, phase string) {
+	key := kind + "/" + namespace + "/" + name
+	phases.Lock()
+	defer phases.Unlock()
+	if previous, ok := phases.byResource[key]; ok && previous != phase {
+		customResourcePhase.DeleteLabelValues(kind, namespace, name, previous)
+	}
+	phases.byResource[key] = phase
+	customResourcePhase.WithLabelValues(kind, namespace, name, phase).Set(1)
+}
+
+// DeletePhase removes the phase of a custom resource that no longer exists
+func DeletePhase(kind, namespace, name string) {
+	key := kind + "/" + namespace + "/" + name
+	phases.Lock()
+	defer phases.Unlock()
+	if previous, ok := phases.byResource[key]; ok {
+		customResourcePhase.DeleteLabelValues(kind, namespace, name, previous)
+		delete(phases.byResource, key)
+	}
+}
+
+// IncPodsRecycled records that a pod of a StatefulSet was deleted to pick up a new revision
+func IncPodsRecycled(namespace, statefulSet string) {
+	podsRecycled.WithLabelValues(namespace, statefulSet).Inc()
+}
+
+// ObserveBundlePush records the outcome of a master apps bundle push by a cluster master
+func ObserveBundlePush(namespace, name string, err error) {
+	result := "success"
+	if err != nil {
+		result = "failure"
+	}
+	bundlePushes.WithLabelValues(namespace, name, result).Inc()
+}
+
+// StartDecommission records that a peer of an indexer cluster started decommissioning
+func StartDecommission(namespace, peer string) {
+	decommissionStartTimes.Lock()
+	defer decommissionStartTimes.Unlock()
+	decommissionStartTimes.byPeer[namespace+"/"+peer] = time.Now()
+}
+
+// FinishDecommission records the time taken by a peer of an indexer cluster to decommission.
+// Nothing is recorded if the operator did not see the decommission start, for example after a restart.
+func FinishDecommission(namespace, name, peer string) {
+	key := namespace + "/" + peer
+	decommissionStartTimes.Lock()
+	defer decommissionStartTimes.Unlock()
+	start, ok := decommissionStartTimes.byPeer[key]
+	if !ok {
+		return
+	}
+	delete(decommissionStartTimes.byPeer, key)
+	decommissionDuration.WithLabelValues(namespace, name).Observe(time.Since(start).Seconds())
+}
+
+// ObserveSplunkRequest records the latency and status code of a Splunk REST API request.
+// A statusCode of 0 means that no response was received.
+func ObserveSplunkRequest(method string, statusCode int, duration time.Duration) {
+	code := "error"
+	if statusCode != 0 {
+		code = strconv.Itoa(statusCode)
+	}
+	splunkRequestDuration.WithLabelValues(method, code).Observe(duration.Seconds())
+}
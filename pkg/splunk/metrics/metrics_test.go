@@ -0,0 +1,113 @@
+// Copyright (c) 2018-2021 Splunk Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metrics
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func checkCount(t *testing.T, testname string, collector prometheus.Collector, want int) {
+	if got := testutil.CollectAndCount(collector); got != want {
+		t.Errorf("%s: collected %d series; want %d", testname, got, want)
+	}
+}
+
+func checkValue(t *testing.T, testname string, collector prometheus.Collector, want float64) {
+	if got := testutil.ToFloat64(collector); got != want {
+		t.Errorf("%s: got value %v; want %v", testname, got, want)
+	}
+}
+
+func TestObserveReconcile(t *testing.T) {
+	reconcileDuration.Reset()
+	reconcileErrors.Reset()
+
+	ObserveReconcile("Standalone", time.Second, nil)
+	ObserveReconcile("Standalone", time.Second, errors.New("failed"))
+	ObserveReconcile("IndexerCluster", time.Second, nil)
+
+	checkCount(t, "ObserveReconcile", reconcileDuration, 2)
+	checkCount(t, "ObserveReconcile", reconcileErrors, 1)
+	checkValue(t, "ObserveReconcile", reconcileErrors.WithLabelValues("Standalone"), 1)
+}
+
+func TestSetPhase(t *testing.T) {
+	customResourcePhase.Reset()
+
+	SetPhase("Standalone", "test", "stack1", "Pending")
+	SetPhase("Standalone", "test", "stack2", "Ready")
+	checkCount(t, "SetPhase", customResourcePhase, 2)
+
+	// the series of the previous phase is removed
+	SetPhase("Standalone", "test", "stack1", "Ready")
+	checkCount(t, "SetPhase", customResourcePhase, 2)
+	checkValue(t, "SetPhase", customResourcePhase.WithLabelValues("Standalone", "test", "stack1", "Ready"), 1)
+
+	DeletePhase("Standalone", "test", "stack1")
+	DeletePhase("Standalone", "test", "unknown")
+	checkCount(t, "DeletePhase", customResourcePhase, 1)
+}
+
+func TestIncPodsRecycled(t *testing.T) {
+	podsRecycled.Reset()
+
+	IncPodsRecycled("test", "splunk-stack1-indexer")
+	IncPodsRecycled("test", "splunk-stack1-indexer")
+	checkValue(t, "IncPodsRecycled", podsRecycled.WithLabelValues("test", "splunk-stack1-indexer"), 2)
+}
+
+func TestObserveBundlePush(t *testing.T) {
+	bundlePushes.Reset()
+
+	ObserveBundlePush("test", "master1", nil)
+	ObserveBundlePush("test", "master1", errors.New("failed"))
+	ObserveBundlePush("test", "master1", errors.New("failed"))
+	checkValue(t, "ObserveBundlePush", bundlePushes.WithLabelValues("test", "master1", "success"), 1)
+	checkValue(t, "ObserveBundlePush", bundlePushes.WithLabelValues("test", "master1", "failure"), 2)
+}
+
+func TestDecommission(t *testing.T) {
+	decommissionDuration.Reset()
+
+	// nothing is recorded for a decommission that was not seen to start
+	FinishDecommission("test", "stack1", "splunk-stack1-indexer-0")
+	checkCount(t, "FinishDecommission", decommissionDuration, 0)
+
+	StartDecommission("test", "splunk-stack1-indexer-1")
+	FinishDecommission("test", "stack1", "splunk-stack1-indexer-1")
+	checkCount(t, "FinishDecommission", decommissionDuration, 1)
+	if len(decommissionStartTimes.byPeer) != 0 {
+		t.Errorf("FinishDecommission kept start times %v", decommissionStartTimes.byPeer)
+	}
+}
+
+func TestObserveSplunkRequest(t *testing.T) {
+	splunkRequestDuration.Reset()
+
+	ObserveSplunkRequest("GetClusterMasterInfo", 200, time.Millisecond)
+	ObserveSplunkRequest("GetClusterMasterInfo", 0, time.Millisecond)
+	ObserveSplunkRequest("BundlePush", 200, time.Millisecond)
+	checkCount(t, "ObserveSplunkRequest", splunkRequestDuration, 3)
+
+	// a request without a response has the code "error"
+	if !splunkRequestDuration.DeleteLabelValues("GetClusterMasterInfo", "error") {
+		t.Errorf("ObserveSplunkRequest did not record code error for a request without a response")
+	}
+}
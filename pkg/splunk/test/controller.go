@@ -24,6 +24,7 @@ import (
 	enterprisev1 "github.com/splunk/splunk-operator/pkg/apis/enterprise/v1"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	policyv1beta1 "k8s.io/api/policy/v1beta1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -32,7 +33,7 @@ import (
 )
 
 func init() {
-	MockObjectCopiers = append(MockObjectCopiers, coreObjectCopier, appsObjectCopier, enterpriseObjCopier)
+	MockObjectCopiers = append(MockObjectCopiers, coreObjectCopier, appsObjectCopier, policyObjectCopier, enterpriseObjCopier)
 }
 
 // MockObjectCopiers is a slice of MockObjectCopier methods that MockClient uses to copy runtime.Objects
@@ -51,12 +52,22 @@ func enterpriseObjCopier(dst, src *runtime.Object) bool {
 		*dstP.(*enterprisev1.ClusterMaster) = *srcP.(*enterprisev1.ClusterMaster)
 	case *enterprisev1.IndexerCluster:
 		*dstP.(*enterprisev1.IndexerCluster) = *srcP.(*enterprisev1.IndexerCluster)
+	case *enterprisev1.IndexerClusterList:
+		*dstP.(*enterprisev1.IndexerClusterList) = *srcP.(*enterprisev1.IndexerClusterList)
 	case *enterprisev1.LicenseMaster:
 		*dstP.(*enterprisev1.LicenseMaster) = *srcP.(*enterprisev1.LicenseMaster)
 	case *enterprisev1.SearchHeadCluster:
 		*dstP.(*enterprisev1.SearchHeadCluster) = *srcP.(*enterprisev1.SearchHeadCluster)
+	case *enterprisev1.SearchHeadClusterList:
+		*dstP.(*enterprisev1.SearchHeadClusterList) = *srcP.(*enterprisev1.SearchHeadClusterList)
 	case *enterprisev1.Standalone:
 		*dstP.(*enterprisev1.Standalone) = *srcP.(*enterprisev1.Standalone)
+	case *enterprisev1.HeavyForwarder:
+		*dstP.(*enterprisev1.HeavyForwarder) = *srcP.(*enterprisev1.HeavyForwarder)
+	case *enterprisev1.DeploymentServer:
+		*dstP.(*enterprisev1.DeploymentServer) = *srcP.(*enterprisev1.DeploymentServer)
+	case *enterprisev1.MonitoringConsole:
+		*dstP.(*enterprisev1.MonitoringConsole) = *srcP.(*enterprisev1.MonitoringConsole)
 	default:
 		return false
 	}
@@ -107,6 +118,19 @@ func appsObjectCopier(dst, src *runtime.Object) bool {
 	return true
 }
 
+// policyObjectCopier is used to copy policyv1beta1 runtime.Objects
+func policyObjectCopier(dst, src *runtime.Object) bool {
+	srcP := *src
+	dstP := *dst
+	switch srcP.(type) {
+	case *policyv1beta1.PodDisruptionBudget:
+		*dstP.(*policyv1beta1.PodDisruptionBudget) = *srcP.(*policyv1beta1.PodDisruptionBudget)
+	default:
+		return false
+	}
+	return true
+}
+
 // copyMockObject uses the global MockObjectCopiers to perform the typed copy of a runtime.Object from src to dst
 func copyMockObject(dst, src *runtime.Object) {
 	for n := range MockObjectCopiers {
@@ -0,0 +1,27 @@
+// Copyright (c) 2018-2021 Splunk Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webhook
+
+import (
+	"sigs.k8s.io/controller-runtime/pkg/webhook/conversion"
+)
+
+// conversionPath is the path of the webhook converting custom resources between the API versions
+const conversionPath = "/convert"
+
+func init() {
+	// the conversion webhook converts between the versions registered with the scheme of the manager, using v1 as the hub
+	SplunkWebhooksToAdd[conversionPath] = &conversion.Webhook{}
+}
@@ -0,0 +1,60 @@
+// Copyright (c) 2018-2021 Splunk Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	enterprise "github.com/splunk/splunk-operator/pkg/splunk/enterprise"
+)
+
+const defaultingPath = "/mutate-enterprise-splunk-com-v1"
+
+func init() {
+	SplunkWebhooksToAdd[defaultingPath] = &admission.Webhook{Handler: &defaulter{}}
+}
+
+// defaulter stores the defaults of Splunk Enterprise custom resources with them
+type defaulter struct {
+	customResourceDecoder
+}
+
+// Handle patches the custom resource of a create or update admission request with its defaults
+func (d *defaulter) Handle(ctx context.Context, req admission.Request) admission.Response {
+	cr, err := d.decode(req.Kind, req.Object)
+	if err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+
+	if cr.GetDeletionTimestamp() != nil {
+		return admission.Allowed("")
+	}
+
+	// invalid custom resources are left unchanged for the validating webhook to reject
+	err = enterprise.SetCustomResourceDefaults(cr)
+	if err != nil {
+		return admission.Allowed("")
+	}
+
+	defaulted, err := json.Marshal(cr)
+	if err != nil {
+		return admission.Errored(http.StatusInternalServerError, err)
+	}
+	return admission.PatchResponseFromRaw(req.Object.Raw, defaulted)
+}
@@ -0,0 +1,81 @@
+// Copyright (c) 2018-2021 Splunk Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webhook
+
+import (
+	"context"
+	"net/http"
+
+	admissionv1beta1 "k8s.io/api/admission/v1beta1"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	splcommon "github.com/splunk/splunk-operator/pkg/splunk/common"
+	enterprise "github.com/splunk/splunk-operator/pkg/splunk/enterprise"
+)
+
+const validationPath = "/validate-enterprise-splunk-com-v1"
+
+func init() {
+	SplunkWebhooksToAdd[validationPath] = &admission.Webhook{Handler: &validator{}}
+}
+
+// validator rejects Splunk Enterprise custom resources that would fail to reconcile
+type validator struct {
+	customResourceDecoder
+	client splcommon.ControllerClient
+}
+
+// InjectConfig creates the client used to look up custom resources referenced by the validated one
+func (v *validator) InjectConfig(cfg *rest.Config) error {
+	// use a non-caching client for the same reasons as the controllers do
+	c, err := client.New(cfg, client.Options{})
+	if err != nil {
+		return err
+	}
+	v.client = c
+	return nil
+}
+
+// Handle validates the custom resource of a create or update admission request
+func (v *validator) Handle(ctx context.Context, req admission.Request) admission.Response {
+	cr, err := v.decode(req.Kind, req.Object)
+	if err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+
+	// never block the removal of finalizers from a custom resource being deleted
+	if cr.GetDeletionTimestamp() != nil {
+		return admission.Allowed("")
+	}
+
+	if req.Operation == admissionv1beta1.Update {
+		old, err := v.decode(req.Kind, req.OldObject)
+		if err != nil {
+			return admission.Errored(http.StatusBadRequest, err)
+		}
+		err = enterprise.ValidateCustomResourceUpdate(old, cr)
+		if err != nil {
+			return admission.Denied(err.Error())
+		}
+	}
+
+	err = enterprise.ValidateCustomResource(v.client, cr)
+	if err != nil {
+		return admission.Denied(err.Error())
+	}
+	return admission.Allowed("")
+}
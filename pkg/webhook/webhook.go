@@ -0,0 +1,75 @@
+// Copyright (c) 2018-2021 Splunk Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webhook
+
+import (
+	"fmt"
+	"net/http"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	splcommon "github.com/splunk/splunk-operator/pkg/splunk/common"
+)
+
+// SplunkWebhooksToAdd maps the paths of the webhook server to the Splunk webhooks served at them
+var SplunkWebhooksToAdd = map[string]http.Handler{}
+
+// AddToManager registers all Splunk Webhooks with the webhook server of the Manager
+func AddToManager(mgr manager.Manager) error {
+	server := mgr.GetWebhookServer()
+	for path, hook := range SplunkWebhooksToAdd {
+		server.Register(path, hook)
+	}
+	return nil
+}
+
+// customResourceDecoder decodes the Splunk Enterprise custom resources of admission requests
+type customResourceDecoder struct {
+	scheme  *runtime.Scheme
+	decoder *admission.Decoder
+}
+
+// InjectScheme injects the scheme used to create the custom resources to decode
+func (d *customResourceDecoder) InjectScheme(s *runtime.Scheme) error {
+	d.scheme = s
+	return nil
+}
+
+// InjectDecoder injects the decoder of admission requests
+func (d *customResourceDecoder) InjectDecoder(decoder *admission.Decoder) error {
+	d.decoder = decoder
+	return nil
+}
+
+// decode returns the custom resource of the given kind that is encoded in an admission request
+func (d *customResourceDecoder) decode(kind metav1.GroupVersionKind, raw runtime.RawExtension) (splcommon.MetaObject, error) {
+	obj, err := d.scheme.New(schema.GroupVersionKind{Group: kind.Group, Version: kind.Version, Kind: kind.Kind})
+	if err != nil {
+		return nil, err
+	}
+	cr, ok := obj.(splcommon.MetaObject)
+	if !ok {
+		return nil, fmt.Errorf("Invalid custom resource kind %s", kind.Kind)
+	}
+	err = d.decoder.DecodeRaw(raw, cr)
+	if err != nil {
+		return nil, err
+	}
+	return cr, nil
+}
@@ -0,0 +1,233 @@
+// Copyright (c) 2018-2021 Splunk Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	admissionv1beta1 "k8s.io/api/admission/v1beta1"
+	apix "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/runtime/inject"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/conversion"
+
+	"github.com/splunk/splunk-operator/pkg/apis"
+	enterprisev1 "github.com/splunk/splunk-operator/pkg/apis/enterprise/v1"
+	spltest "github.com/splunk/splunk-operator/pkg/splunk/test"
+)
+
+func convert(t *testing.T, desiredAPIVersion string, obj string) map[string]interface{} {
+	scheme := runtime.NewScheme()
+	if err := apis.AddToScheme(scheme); err != nil {
+		t.Fatalf("AddToScheme() returned error: %v", err)
+	}
+	hook := SplunkWebhooksToAdd[conversionPath].(*conversion.Webhook)
+	if err := hook.InjectScheme(scheme); err != nil {
+		t.Fatalf("InjectScheme() returned error: %v", err)
+	}
+
+	review := apix.ConversionReview{
+		TypeMeta: metav1.TypeMeta{APIVersion: "apiextensions.k8s.io/v1beta1", Kind: "ConversionReview"},
+		Request: &apix.ConversionRequest{
+			UID:               "1234",
+			DesiredAPIVersion: desiredAPIVersion,
+			Objects:           []runtime.RawExtension{{Raw: []byte(obj)}},
+		},
+	}
+	body, _ := json.Marshal(review)
+	w := httptest.NewRecorder()
+	hook.ServeHTTP(w, httptest.NewRequest("POST", conversionPath, bytes.NewReader(body)))
+
+	response := apix.ConversionReview{}
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatalf("conversion webhook returned invalid response %s: %v", w.Body.String(), err)
+	}
+	if response.Response.Result.Status != metav1.StatusSuccess || len(response.Response.ConvertedObjects) != 1 {
+		t.Fatalf("conversion webhook to %s failed: %+v", desiredAPIVersion, response.Response.Result)
+	}
+	converted := map[string]interface{}{}
+	if err := json.Unmarshal(response.Response.ConvertedObjects[0].Raw, &converted); err != nil {
+		t.Fatalf("conversion webhook returned invalid object: %v", err)
+	}
+	return converted
+}
+
+func TestConversionWebhook(t *testing.T) {
+	legacy := `{"apiVersion":"enterprise.splunk.com/v1alpha2","kind":"SearchHeadCluster","metadata":{"name":"stack1","namespace":"test"},` +
+		`"spec":{"replicas":3,"storageClassName":"gp2","indexerClusterRef":{"name":"idxc"},"sparkImage":"splunk/spark"}}`
+	got := convert(t, enterprisev1.APIVersion, legacy)
+	if got["apiVersion"] != enterprisev1.APIVersion || got["kind"] != "SearchHeadCluster" {
+		t.Errorf("conversion webhook returned %s %s; want %s SearchHeadCluster", got["apiVersion"], got["kind"], enterprisev1.APIVersion)
+	}
+	spec := got["spec"].(map[string]interface{})
+	if spec["replicas"] != 3.0 || spec["clusterMasterRef"].(map[string]interface{})["name"] != "idxc" {
+		t.Errorf("conversion webhook returned spec %v; want replicas 3 and clusterMasterRef idxc", spec)
+	}
+	annotations := got["metadata"].(map[string]interface{})["annotations"].(map[string]interface{})
+	if annotations[enterprisev1.ConversionDataAnnotation] != `{"spec":{"sparkImage":"splunk/spark"}}` {
+		t.Errorf("conversion webhook returned annotations %v; want sparkImage preserved", annotations)
+	}
+
+	hub := `{"apiVersion":"enterprise.splunk.com/v1","kind":"Standalone","metadata":{"name":"stack1","namespace":"test"},` +
+		`"spec":{"replicas":1,"clusterMasterRef":{"name":"cm"},"monitoringConsoleRef":{"name":"mc"}}}`
+	got = convert(t, "enterprise.splunk.com/v1beta1", hub)
+	if got["apiVersion"] != "enterprise.splunk.com/v1beta1" || got["kind"] != "Standalone" {
+		t.Errorf("conversion webhook returned %s %s; want enterprise.splunk.com/v1beta1 Standalone", got["apiVersion"], got["kind"])
+	}
+	if _, ok := got["metadata"].(map[string]interface{})["annotations"].(map[string]interface{})[enterprisev1.ConversionDataAnnotation]; !ok {
+		t.Errorf("conversion webhook did not preserve the v1 fields of %s", hub)
+	}
+}
+
+func newAdmissionWebhook(t *testing.T, path string) *admission.Webhook {
+	scheme := runtime.NewScheme()
+	if err := apis.AddToScheme(scheme); err != nil {
+		t.Fatalf("AddToScheme() returned error: %v", err)
+	}
+	hook := SplunkWebhooksToAdd[path].(*admission.Webhook)
+	if err := hook.InjectScheme(scheme); err != nil {
+		t.Fatalf("InjectScheme() returned error: %v", err)
+	}
+	if _, err := inject.SchemeInto(scheme, hook.Handler); err != nil {
+		t.Fatalf("InjectScheme() returned error: %v", err)
+	}
+	return hook
+}
+
+func newAdmissionRequest(operation admissionv1beta1.Operation, kind, obj, oldObj string) admission.Request {
+	return admission.Request{AdmissionRequest: admissionv1beta1.AdmissionRequest{
+		UID:       "1234",
+		Kind:      metav1.GroupVersionKind{Group: enterprisev1.SchemeGroupVersion.Group, Version: "v1", Kind: kind},
+		Operation: operation,
+		Object:    runtime.RawExtension{Raw: []byte(obj)},
+		OldObject: runtime.RawExtension{Raw: []byte(oldObj)},
+	}}
+}
+
+func TestValidationWebhook(t *testing.T) {
+	hook := newAdmissionWebhook(t, validationPath)
+	c := spltest.NewMockClient()
+	c.NotFoundError = k8serrors.NewNotFound(schema.GroupResource{Group: "enterprise.splunk.com", Resource: "clustermasters"}, "cm")
+	hook.Handler.(*validator).client = c
+
+	test := func(operation admissionv1beta1.Operation, kind, obj, oldObj string, wantAllowed bool) {
+		resp := hook.Handle(context.TODO(), newAdmissionRequest(operation, kind, obj, oldObj))
+		if resp.Allowed != wantAllowed {
+			t.Errorf("validation webhook %s of %s returned allowed=%t (%v); want %t", operation, obj, resp.Allowed, resp.Result, wantAllowed)
+		}
+	}
+
+	standalone := `{"apiVersion":"enterprise.splunk.com/v1","kind":"Standalone","metadata":{"name":"stack1","namespace":"test"},"spec":{%s}}`
+	spec := func(s string) string {
+		return fmt.Sprintf(standalone, s)
+	}
+	test(admissionv1beta1.Create, "Standalone", spec(`"replicas":1`), "", true)
+	test(admissionv1beta1.Create, "Standalone", spec(`"imagePullPolicy":"Sometimes"`), "", false)
+	test(admissionv1beta1.Create, "Standalone", spec(`"smartstore":{"indexes":[{"name":"idx1","volumeName":"missing"}]}`), "", false)
+	test(admissionv1beta1.Update, "Standalone", spec(`"replicas":2`), spec(`"replicas":1`), true)
+	test(admissionv1beta1.Update, "Standalone", spec(`"varVolumeStorageConfig":{"storageClassName":"gp2"}`), spec(""), false)
+	test(admissionv1beta1.Update, "Standalone", spec(`"etcVolumeStorageConfig":{"ephemeralStorage":true}`), spec(""), false)
+	test(admissionv1beta1.Create, "IndexerCluster", `{"apiVersion":"enterprise.splunk.com/v1","kind":"IndexerCluster","metadata":{"name":"idxc","namespace":"test"},"spec":{}}`, "", false)
+
+	// IndexerClusters can not have fewer replicas than the replication factor of their ClusterMaster
+	indexerCluster := `{"apiVersion":"enterprise.splunk.com/v1","kind":"IndexerCluster","metadata":{"name":"idxc","namespace":"test"},"spec":{"clusterMasterRef":{"name":"cm"},"replicas":%d}}`
+	test(admissionv1beta1.Create, "IndexerCluster", fmt.Sprintf(indexerCluster, 2), "", true)
+	c.AddObject(&enterprisev1.ClusterMaster{
+		ObjectMeta: metav1.ObjectMeta{Name: "cm", Namespace: "test"},
+		Spec:       enterprisev1.ClusterMasterSpec{Multisite: enterprisev1.MultisiteSpec{Sites: []string{"site1"}, SiteReplicationFactor: enterprisev1.SiteFactorSpec{Origin: 3, Total: 3}}},
+	})
+	test(admissionv1beta1.Create, "IndexerCluster", fmt.Sprintf(indexerCluster, 2), "", false)
+	test(admissionv1beta1.Update, "IndexerCluster", fmt.Sprintf(indexerCluster, 3), fmt.Sprintf(indexerCluster, 2), true)
+
+	test(admissionv1beta1.Create, "Standalone", spec(`"monitoringConsoleRef":{"name":"mc1","namespace":"monitoring"}`), "", true)
+	test(admissionv1beta1.Create, "MonitoringConsole", `{"apiVersion":"enterprise.splunk.com/v1","kind":"MonitoringConsole","metadata":{"name":"mc1","namespace":"test"},"spec":{}}`, "", true)
+	test(admissionv1beta1.Create, "MonitoringConsole", `{"apiVersion":"enterprise.splunk.com/v1","kind":"MonitoringConsole","metadata":{"name":"test","namespace":"test"},"spec":{}}`, "", false)
+	test(admissionv1beta1.Create, "Splunk", `{}`, "", false)
+}
+
+// patchedValue returns the value that a patch of an admission response sets at path, and whether one does
+func patchedValue(resp admission.Response, path string) (interface{}, bool) {
+	for _, patch := range resp.Patches {
+		if patch.Path == path {
+			return patch.Value, true
+		}
+		if !strings.HasPrefix(path, patch.Path+"/") {
+			continue
+		}
+		value := patch.Value
+		for _, key := range strings.Split(strings.TrimPrefix(path, patch.Path+"/"), "/") {
+			object, ok := value.(map[string]interface{})
+			if !ok {
+				return nil, false
+			}
+			value = object[key]
+		}
+		return value, true
+	}
+	return nil, false
+}
+
+func TestDefaultingWebhook(t *testing.T) {
+	hook := newAdmissionWebhook(t, defaultingPath)
+	defaults := map[string]interface{}{
+		"/spec/replicas":                               1.0,
+		"/spec/imagePullPolicy":                        "IfNotPresent",
+		"/spec/schedulerName":                          "default-scheduler",
+		"/spec/etcVolumeStorageConfig/storageCapacity": "10Gi",
+		"/spec/varVolumeStorageConfig/storageCapacity": "100Gi",
+		"/spec/resources/requests/memory":              "512Mi",
+	}
+
+	standalone := `{"apiVersion":"enterprise.splunk.com/v1","kind":"Standalone","metadata":{"name":"stack1","namespace":"test"},"spec":{%s}}`
+	resp := hook.Handle(context.TODO(), newAdmissionRequest(admissionv1beta1.Create, "Standalone", fmt.Sprintf(standalone, `"image":"splunk/splunk:8.1.0"`), ""))
+	if !resp.Allowed {
+		t.Fatalf("defaulting webhook returned %v; want allowed", resp.Result)
+	}
+	for path, want := range defaults {
+		if got, _ := patchedValue(resp, path); got != want {
+			t.Errorf("defaulting webhook set %s to %v; want %v", path, got, want)
+		}
+	}
+	if got, ok := patchedValue(resp, "/spec/image"); ok {
+		t.Errorf("defaulting webhook replaced image with %v", got)
+	}
+
+	// defaults applied at admission are not changed again
+	defaulted := fmt.Sprintf(standalone, `"replicas":1,"imagePullPolicy":"IfNotPresent","schedulerName":"default-scheduler","image":"splunk/splunk:8.1.0",`+
+		`"etcVolumeStorageConfig":{"storageCapacity":"10Gi"},"varVolumeStorageConfig":{"storageCapacity":"100Gi"},`+
+		`"resources":{"requests":{"cpu":"100m","memory":"512Mi"},"limits":{"cpu":"4","memory":"8Gi"}}`)
+	resp = hook.Handle(context.TODO(), newAdmissionRequest(admissionv1beta1.Update, "Standalone", defaulted, defaulted))
+	for path := range defaults {
+		if got, ok := patchedValue(resp, path); ok {
+			t.Errorf("defaulting webhook set %s of a defaulted custom resource to %v", path, got)
+		}
+	}
+
+	// invalid custom resources are not changed
+	resp = hook.Handle(context.TODO(), newAdmissionRequest(admissionv1beta1.Create, "Standalone", fmt.Sprintf(standalone, `"imagePullPolicy":"Sometimes"`), ""))
+	if !resp.Allowed || len(resp.Patches) != 0 {
+		t.Errorf("defaulting webhook returned %v with patches %v for an invalid custom resource; want allowed without patches", resp.Result, resp.Patches)
+	}
+}